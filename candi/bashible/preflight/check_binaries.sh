@@ -0,0 +1,27 @@
+{{- /*
+# Copyright 2024 Flant JSC
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+*/}}
+
+missing=""
+for binary in {{ .binaries | join " " }}; do
+  if ! command -v "$binary" >/dev/null 2>&1; then
+    missing="$missing $binary"
+  fi
+done
+
+if [ -n "$missing" ]; then
+  echo "Required binaries are not found on the node:$missing. You should install the corresponding packages."
+  exit 1
+fi
@@ -197,7 +197,7 @@ func run(ctx context.Context, operator *addon_operator.AddonOperator) error {
 		return err
 	}
 
-	debugserver.RegisterRoutes(operator.DebugServer)
+	debugserver.RegisterRoutes(operator)
 
 	dController.RunControllers()
 
@@ -34,6 +34,8 @@ const (
 	PhaseSuspended       = "Suspended"
 
 	approvalAnnotation = "modules.deckhouse.io/approved"
+	// RollbackAnnotation on a deployed release asks to roll the module back to the previously deployed release
+	RollbackAnnotation = "modules.deckhouse.io/rollback"
 )
 
 var (
@@ -83,6 +85,11 @@ func (mr *ModuleRelease) GetApplyAfter() *time.Time {
 	return &mr.Spec.ApplyAfter.Time
 }
 
+// GetAppearanceTime returns the time the release appeared in the cluster, the rollout wave delay is counted from it
+func (mr *ModuleRelease) GetAppearanceTime() time.Time {
+	return mr.CreationTimestamp.Time
+}
+
 func (mr *ModuleRelease) GetRequirements() map[string]string {
 	return mr.Spec.Requirements
 }
@@ -115,6 +122,11 @@ func (mr *ModuleRelease) GetApplyNow() bool {
 	return mr.Annotations["release.deckhouse.io/apply-now"] == "true"
 }
 
+// GetRollback returns true if the release has to be rolled back
+func (mr *ModuleRelease) GetRollback() bool {
+	return mr.Annotations[RollbackAnnotation] == "true"
+}
+
 func (mr *ModuleRelease) SetApprovedStatus(val bool) {
 	mr.Status.Approved = val
 }
@@ -144,6 +156,10 @@ func (mr *ModuleRelease) GetMessage() string {
 	return mr.Status.Message
 }
 
+func (mr *ModuleRelease) GetNotificationDelivery() string {
+	return mr.Annotations["release.deckhouse.io/notification-delivery"]
+}
+
 // GetModuleSource returns module source for this release
 func (mr *ModuleRelease) GetModuleSource() string {
 	for _, ref := range mr.GetOwnerReferences() {
@@ -57,6 +57,9 @@ type ModuleSource struct {
 type ModuleSourceSpec struct {
 	Registry       ModuleSourceSpecRegistry `json:"registry"`
 	ReleaseChannel string                   `json:"releaseChannel"`
+	// TrustPolicy, if set, requires every module and release image pulled from the source
+	// to carry a valid cosign signature stored in the same registry
+	TrustPolicy *ModuleSourceTrustPolicy `json:"trustPolicy,omitempty"`
 }
 
 type ModuleSourceSpecRegistry struct {
@@ -64,6 +67,47 @@ type ModuleSourceSpecRegistry struct {
 	Repo      string `json:"repo"`
 	DockerCFG string `json:"dockerCfg"`
 	CA        string `json:"ca"`
+	// Mirrors is an ordered list of registries with the same content,
+	// they are used in turn if the main registry is unavailable
+	Mirrors []ModuleSourceRegistryEndpoint `json:"mirrors,omitempty"`
+}
+
+type ModuleSourceRegistryEndpoint struct {
+	Scheme    string `json:"scheme,omitempty"`
+	Repo      string `json:"repo"`
+	DockerCFG string `json:"dockerCfg,omitempty"`
+	CA        string `json:"ca,omitempty"`
+}
+
+// Endpoints returns the main registry followed by the mirrors in the failover order
+func (r *ModuleSourceSpecRegistry) Endpoints() []ModuleSourceRegistryEndpoint {
+	endpoints := make([]ModuleSourceRegistryEndpoint, 0, len(r.Mirrors)+1)
+	endpoints = append(endpoints, ModuleSourceRegistryEndpoint{
+		Scheme:    r.Scheme,
+		Repo:      r.Repo,
+		DockerCFG: r.DockerCFG,
+		CA:        r.CA,
+	})
+
+	return append(endpoints, r.Mirrors...)
+}
+
+type ModuleSourceTrustPolicy struct {
+	// PublicKeys is a list of PEM encoded public keys, a signature made by any of them is accepted
+	PublicKeys []string `json:"publicKeys,omitempty"`
+	// CertificateIdentities is a list of keyless signing identities, a signature made with a certificate
+	// issued to any of them is accepted
+	CertificateIdentities []ModuleSourceCertificateIdentity `json:"certificateIdentities,omitempty"`
+	// CA is a PEM bundle of root certificates the signing certificates must chain to
+	CA string `json:"ca,omitempty"`
+	// TransparencyLogPublicKeys is a list of PEM encoded public keys of transparency logs, keyless signatures
+	// must carry an entry of any of them to prove the time of signing
+	TransparencyLogPublicKeys []string `json:"transparencyLogPublicKeys,omitempty"`
+}
+
+type ModuleSourceCertificateIdentity struct {
+	Subject string `json:"subject"`
+	Issuer  string `json:"issuer"`
 }
 
 type ModuleSourceStatus struct {
@@ -72,6 +116,36 @@ type ModuleSourceStatus struct {
 	AvailableModules []AvailableModule `json:"modules"`
 	Msg              string            `json:"message"`
 	ModuleErrors     []ModuleError     `json:"moduleErrors"`
+	// Registries is the health of the main registry and the mirrors
+	Registries []ModuleSourceRegistryStatus `json:"registries,omitempty"`
+}
+
+type ModuleSourceRegistryStatus struct {
+	Repo string `json:"repo"`
+	// Ready is true if the registry responded on the last check
+	Ready bool `json:"ready"`
+	// Active is true for the registry the modules are downloaded from
+	Active        bool        `json:"active,omitempty"`
+	Message       string      `json:"message,omitempty"`
+	LastCheckTime metav1.Time `json:"lastCheckTime,omitempty"`
+}
+
+// ActiveRegistry returns the registry endpoint the modules are downloaded from,
+// the main registry is returned if no endpoint has been checked yet
+func (in *ModuleSource) ActiveRegistry() ModuleSourceRegistryEndpoint {
+	endpoints := in.Spec.Registry.Endpoints()
+	for _, status := range in.Status.Registries {
+		if !status.Active {
+			continue
+		}
+		for _, endpoint := range endpoints {
+			if endpoint.Repo == status.Repo {
+				return endpoint
+			}
+		}
+	}
+
+	return endpoints[0]
 }
 
 type AvailableModule struct {
@@ -59,8 +59,25 @@ type ModuleUpdatePolicySpec struct {
 }
 
 type ModuleUpdatePolicySpecUpdate struct {
-	Mode    string         `json:"mode"`
-	Windows update.Windows `json:"windows"`
+	Mode    string                  `json:"mode"`
+	Windows update.Windows          `json:"windows"`
+	Wave    *ModuleUpdatePolicyWave `json:"wave,omitempty"`
+}
+
+// ModuleUpdatePolicyWave assigns the cluster to a wave of the staged rollout
+type ModuleUpdatePolicyWave struct {
+	// Name is one of Canary, Early or Main
+	Name string `json:"name"`
+	// Delay after the release appearance before it can be applied
+	Delay        metav1.Duration                     `json:"delay,omitempty"`
+	StatusSource *ModuleUpdatePolicyWaveStatusSource `json:"statusSource,omitempty"`
+	// ClusterName identifies the cluster in the statuses it publishes
+	ClusterName string `json:"clusterName,omitempty"`
+}
+
+// ModuleUpdatePolicyWaveStatusSource is a place where clusters of the earlier waves report rollout statuses
+type ModuleUpdatePolicyWaveStatusSource struct {
+	URL string `json:"url"`
 }
 
 type ModuleUpdatePolicySpecReleaseSelector struct {
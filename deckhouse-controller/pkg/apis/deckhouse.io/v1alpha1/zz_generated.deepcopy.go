@@ -472,7 +472,7 @@ func (in *ModuleSource) DeepCopyInto(out *ModuleSource) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 	return
 }
@@ -495,6 +495,22 @@ func (in *ModuleSource) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModuleSourceCertificateIdentity) DeepCopyInto(out *ModuleSourceCertificateIdentity) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModuleSourceCertificateIdentity.
+func (in *ModuleSourceCertificateIdentity) DeepCopy() *ModuleSourceCertificateIdentity {
+	if in == nil {
+		return nil
+	}
+	out := new(ModuleSourceCertificateIdentity)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ModuleSourceList) DeepCopyInto(out *ModuleSourceList) {
 	*out = *in
@@ -528,10 +544,48 @@ func (in *ModuleSourceList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModuleSourceRegistryEndpoint) DeepCopyInto(out *ModuleSourceRegistryEndpoint) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModuleSourceRegistryEndpoint.
+func (in *ModuleSourceRegistryEndpoint) DeepCopy() *ModuleSourceRegistryEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(ModuleSourceRegistryEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModuleSourceRegistryStatus) DeepCopyInto(out *ModuleSourceRegistryStatus) {
+	*out = *in
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModuleSourceRegistryStatus.
+func (in *ModuleSourceRegistryStatus) DeepCopy() *ModuleSourceRegistryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ModuleSourceRegistryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ModuleSourceSpec) DeepCopyInto(out *ModuleSourceSpec) {
 	*out = *in
-	out.Registry = in.Registry
+	in.Registry.DeepCopyInto(&out.Registry)
+	if in.TrustPolicy != nil {
+		in, out := &in.TrustPolicy, &out.TrustPolicy
+		*out = new(ModuleSourceTrustPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -548,6 +602,11 @@ func (in *ModuleSourceSpec) DeepCopy() *ModuleSourceSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ModuleSourceSpecRegistry) DeepCopyInto(out *ModuleSourceSpecRegistry) {
 	*out = *in
+	if in.Mirrors != nil {
+		in, out := &in.Mirrors, &out.Mirrors
+		*out = make([]ModuleSourceRegistryEndpoint, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -575,6 +634,13 @@ func (in *ModuleSourceStatus) DeepCopyInto(out *ModuleSourceStatus) {
 		*out = make([]ModuleError, len(*in))
 		copy(*out, *in)
 	}
+	if in.Registries != nil {
+		in, out := &in.Registries, &out.Registries
+		*out = make([]ModuleSourceRegistryStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -588,6 +654,37 @@ func (in *ModuleSourceStatus) DeepCopy() *ModuleSourceStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModuleSourceTrustPolicy) DeepCopyInto(out *ModuleSourceTrustPolicy) {
+	*out = *in
+	if in.PublicKeys != nil {
+		in, out := &in.PublicKeys, &out.PublicKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CertificateIdentities != nil {
+		in, out := &in.CertificateIdentities, &out.CertificateIdentities
+		*out = make([]ModuleSourceCertificateIdentity, len(*in))
+		copy(*out, *in)
+	}
+	if in.TransparencyLogPublicKeys != nil {
+		in, out := &in.TransparencyLogPublicKeys, &out.TransparencyLogPublicKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModuleSourceTrustPolicy.
+func (in *ModuleSourceTrustPolicy) DeepCopy() *ModuleSourceTrustPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ModuleSourceTrustPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ModuleStatus) DeepCopyInto(out *ModuleStatus) {
 	*out = *in
@@ -707,6 +804,11 @@ func (in *ModuleUpdatePolicySpecReleaseSelector) DeepCopy() *ModuleUpdatePolicyS
 func (in *ModuleUpdatePolicySpecUpdate) DeepCopyInto(out *ModuleUpdatePolicySpecUpdate) {
 	*out = *in
 	out.Windows = in.Windows.DeepCopy()
+	if in.Wave != nil {
+		in, out := &in.Wave, &out.Wave
+		*out = new(ModuleUpdatePolicyWave)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -719,3 +821,41 @@ func (in *ModuleUpdatePolicySpecUpdate) DeepCopy() *ModuleUpdatePolicySpecUpdate
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModuleUpdatePolicyWave) DeepCopyInto(out *ModuleUpdatePolicyWave) {
+	*out = *in
+	out.Delay = in.Delay
+	if in.StatusSource != nil {
+		in, out := &in.StatusSource, &out.StatusSource
+		*out = new(ModuleUpdatePolicyWaveStatusSource)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModuleUpdatePolicyWave.
+func (in *ModuleUpdatePolicyWave) DeepCopy() *ModuleUpdatePolicyWave {
+	if in == nil {
+		return nil
+	}
+	out := new(ModuleUpdatePolicyWave)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModuleUpdatePolicyWaveStatusSource) DeepCopyInto(out *ModuleUpdatePolicyWaveStatusSource) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModuleUpdatePolicyWaveStatusSource.
+func (in *ModuleUpdatePolicyWaveStatusSource) DeepCopy() *ModuleUpdatePolicyWaveStatusSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ModuleUpdatePolicyWaveStatusSource)
+	in.DeepCopyInto(out)
+	return out
+}
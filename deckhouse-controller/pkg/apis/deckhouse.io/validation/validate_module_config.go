@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 
 	kwhhttp "github.com/slok/kubewebhook/v2/pkg/http"
 	"github.com/slok/kubewebhook/v2/pkg/model"
@@ -53,6 +54,13 @@ func moduleConfigValidationHandler() http.Handler {
 			return allowResult(fmt.Sprintf("module name '%s' is unknown for deckhouse", cfg.Name))
 		}
 
+		// Reject enabling the module without modules it depends on.
+		if cfg.Spec.Enabled != nil && *cfg.Spec.Enabled {
+			if missing := d8config.Service().MissingModuleDependencies(cfg.Name); len(missing) > 0 {
+				return rejectResult(fmt.Sprintf("module '%s' requires disabled modules to be enabled first: %s", cfg.Name, strings.Join(missing, ", ")))
+			}
+		}
+
 		// Check if spec.version value is valid and the version is the latest.
 		// Validate spec.settings using the OpenAPI schema.
 		res := d8config.Service().ConfigValidator().Validate(cfg)
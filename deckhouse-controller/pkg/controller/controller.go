@@ -43,12 +43,14 @@ import (
 	"github.com/deckhouse/deckhouse/deckhouse-controller/pkg/client/informers/externalversions"
 	"github.com/deckhouse/deckhouse/deckhouse-controller/pkg/controller/models"
 	"github.com/deckhouse/deckhouse/deckhouse-controller/pkg/controller/module-controllers/release"
+	moduleRequirements "github.com/deckhouse/deckhouse/deckhouse-controller/pkg/controller/module-controllers/requirements"
 	"github.com/deckhouse/deckhouse/deckhouse-controller/pkg/controller/module-controllers/source"
 	d8utils "github.com/deckhouse/deckhouse/deckhouse-controller/pkg/controller/module-controllers/utils"
 	"github.com/deckhouse/deckhouse/deckhouse-controller/pkg/docs"
 	d8config "github.com/deckhouse/deckhouse/go_lib/deckhouse-config"
 	"github.com/deckhouse/deckhouse/go_lib/deckhouse-config/conversion"
 	d8http "github.com/deckhouse/deckhouse/go_lib/dependency/http"
+	"github.com/deckhouse/deckhouse/go_lib/dependency/requirements"
 )
 
 const (
@@ -275,6 +277,11 @@ func (dml *DeckhouseController) runEventLoop(moduleEventCh <-chan events.ModuleE
 			continue
 		}
 
+		if event.EventType == events.ModuleEnabled || event.EventType == events.ModuleDisabled {
+			// keep enabled modules to check requirements of module releases
+			requirements.SaveValue(moduleRequirements.EnabledModulesValueKey, dml.mm.GetEnabledModuleNames())
+		}
+
 		switch event.EventType {
 		case events.ModuleRegistered:
 			err := dml.handleModuleRegistration(mod)
@@ -417,6 +424,7 @@ func (dml *DeckhouseController) handleModuleRegistration(m *models.DeckhouseModu
 			// update d8service state
 			d8config.Service().AddModuleNameToSource(moduleName, src)
 			d8config.Service().AddPossibleName(moduleName)
+			d8config.Service().SetModuleDependencies(moduleName, m.GetRequirements().Modules)
 
 			existModule, err := dml.kubeClient.DeckhouseV1alpha1().Modules().Get(dml.ctx, newModule.GetName(), v1.GetOptions{})
 			if err != nil {
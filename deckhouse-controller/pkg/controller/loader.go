@@ -184,6 +184,7 @@ func (dml *DeckhouseController) overwriteModuleFromModuleYamlFile(absPath string
 	}
 	definition.Description = def.Description
 	definition.Stage = def.Stage
+	definition.Requirements = def.Requirements
 
 	return definition, nil
 }
@@ -27,5 +27,17 @@ type DeckhouseModuleDefinition struct {
 	Stage       string   `yaml:"stage"`
 	Description string   `yaml:"description"`
 
+	Requirements ModuleRequirements `yaml:"requirements,omitempty"`
+
 	Path string `yaml:"-"`
 }
+
+// ModuleRequirements describes what the module needs to be deployed
+type ModuleRequirements struct {
+	// Deckhouse is a semver constraint for the Deckhouse version, e.g. ">= 1.58"
+	Deckhouse string `yaml:"deckhouse,omitempty"`
+	// Kubernetes is a semver constraint for the Kubernetes version, e.g. ">= 1.26, <= 1.29"
+	Kubernetes string `yaml:"kubernetes,omitempty"`
+	// Modules is a list of modules which must be enabled
+	Modules []string `yaml:"modules,omitempty"`
+}
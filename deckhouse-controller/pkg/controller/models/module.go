@@ -30,9 +30,10 @@ import (
 type DeckhouseModule struct {
 	basic *modules.BasicModule
 
-	description string
-	stage       string
-	labels      map[string]string
+	description  string
+	stage        string
+	labels       map[string]string
+	requirements ModuleRequirements
 }
 
 func NewDeckhouseModule(def DeckhouseModuleDefinition, staticValues utils.Values, vv *validation.ValuesValidator) *DeckhouseModule {
@@ -48,10 +49,11 @@ func NewDeckhouseModule(def DeckhouseModuleDefinition, staticValues utils.Values
 	}
 
 	return &DeckhouseModule{
-		basic:       basic,
-		labels:      labels,
-		description: def.Description,
-		stage:       def.Stage,
+		basic:        basic,
+		labels:       labels,
+		description:  def.Description,
+		stage:        def.Stage,
+		requirements: def.Requirements,
 	}
 }
 
@@ -59,6 +61,10 @@ func (dm DeckhouseModule) GetBasicModule() *modules.BasicModule {
 	return dm.basic
 }
 
+func (dm DeckhouseModule) GetRequirements() ModuleRequirements {
+	return dm.requirements
+}
+
 func (dm DeckhouseModule) AsKubeObject(source string) *v1alpha1.Module {
 	if source == "" {
 		source = "Embedded"
@@ -17,12 +17,15 @@ limitations under the License.
 package downloader
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/deckhouse/deckhouse/deckhouse-controller/pkg/apis/deckhouse.io/v1alpha1"
+	"github.com/deckhouse/deckhouse/go_lib/dependency/cr"
 )
 
 func TestOpenapiInjection(t *testing.T) {
@@ -82,3 +85,31 @@ properties:
     type: object
 `, string(data))
 }
+
+func TestRegistryFailoverErrors(t *testing.T) {
+	ms := &v1alpha1.ModuleSource{}
+	ms.Spec.Registry.Repo = "registry.example.com/modules"
+	ms.Spec.Registry.Mirrors = []v1alpha1.ModuleSourceRegistryEndpoint{{Repo: "mirror.example.com/modules"}}
+	md := NewModuleDownloader(t.TempDir(), ms, nil)
+
+	var tried []string
+	err := md.withRegistryFailover(func(repo string, _ []cr.Option) error {
+		tried = append(tried, repo)
+		if repo == ms.Spec.Registry.Repo {
+			return errors.New("connection refused")
+		}
+		return fmt.Errorf("verify signature: %w", ErrNoValidSignature)
+	})
+
+	assert.Equal(t, []string{"registry.example.com/modules", "mirror.example.com/modules"}, tried)
+	assert.ErrorIs(t, err, ErrNoValidSignature)
+	assert.ErrorContains(t, err, "registry.example.com/modules: connection refused")
+
+	err = md.withRegistryFailover(func(repo string, _ []cr.Option) error {
+		if repo == ms.Spec.Registry.Repo {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+}
@@ -36,6 +36,7 @@ import (
 
 	"github.com/deckhouse/deckhouse/deckhouse-controller/pkg/apis/deckhouse.io/v1alpha1"
 	"github.com/deckhouse/deckhouse/deckhouse-controller/pkg/controller/models"
+	"github.com/deckhouse/deckhouse/deckhouse-controller/pkg/controller/module-controllers/utils"
 	"github.com/deckhouse/deckhouse/go_lib/dependency/cr"
 	"github.com/deckhouse/deckhouse/go_lib/module"
 )
@@ -158,12 +159,87 @@ func (md *ModuleDownloader) GetDocumentationArchive(moduleName, moduleVersion st
 }
 
 func (md *ModuleDownloader) fetchImage(moduleName, imageTag string) (v1.Image, error) {
-	regCli, err := cr.NewClient(path.Join(md.ms.Spec.Registry.Repo, moduleName), md.registryOptions...)
+	var img v1.Image
+
+	err := md.withRegistryFailover(func(repo string, opts []cr.Option) error {
+		regCli, err := cr.NewClient(path.Join(repo, moduleName), opts...)
+		if err != nil {
+			return fmt.Errorf("fetch module error: %v", err)
+		}
+
+		img, err = regCli.Image(imageTag)
+		if err != nil {
+			return err
+		}
+
+		return md.verifyImage(regCli, img)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return img, nil
+}
+
+// withRegistryFailover calls fn for the main registry of the module source and then for each mirror in turn
+// until fn succeeds. Registries which were unavailable on the last check of the source are tried last
+// to not wait for their timeouts. The error of the main registry is returned as is if there are no mirrors.
+func (md *ModuleDownloader) withRegistryFailover(fn func(repo string, opts []cr.Option) error) error {
+	endpoints := md.ms.Spec.Registry.Endpoints()
+
+	unavailable := make(map[string]bool, len(md.ms.Status.Registries))
+	for _, status := range md.ms.Status.Registries {
+		unavailable[status.Repo] = !status.Ready
+	}
+
+	order := make([]int, 0, len(endpoints))
+	for i, endpoint := range endpoints {
+		if !unavailable[endpoint.Repo] {
+			order = append(order, i)
+		}
+	}
+	for i, endpoint := range endpoints {
+		if unavailable[endpoint.Repo] {
+			order = append(order, i)
+		}
+	}
+
+	errs := make([]error, 0, len(endpoints))
+	for _, i := range order {
+		// options of the main registry are passed by the caller
+		opts := md.registryOptions
+		if i > 0 {
+			opts = utils.GenerateRegistryEndpointOptions(endpoints[i])
+		}
+
+		err := fn(endpoints[i].Repo, opts)
+		if err == nil {
+			return nil
+		}
+
+		if len(endpoints) == 1 {
+			return err
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", endpoints[i].Repo, err))
+	}
+
+	// errors are wrapped, so callers can check them with errors.Is, e.g. for ErrNoValidSignature
+	return fmt.Errorf("all registries failed: %w", errors.Join(errs...))
+}
+
+// verifyImage checks the image signature if the module source has a trust policy
+func (md *ModuleDownloader) verifyImage(regCli cr.Client, img v1.Image) error {
+	sv, err := newSignatureVerifier(md.ms.Spec.TrustPolicy)
 	if err != nil {
-		return nil, fmt.Errorf("fetch module error: %v", err)
+		return fmt.Errorf("trust policy: %w", err)
+	}
+
+	if sv == nil {
+		return nil
 	}
 
-	return regCli.Image(imageTag)
+	return sv.Verify(regCli, img)
 }
 
 func (md *ModuleDownloader) storeModule(moduleStorePath string, img v1.Image) (*DownloadStatistic, error) {
@@ -277,31 +353,55 @@ func (md *ModuleDownloader) copyLayersToFS(rootPath string, rc io.ReadCloser) (*
 
 func (md *ModuleDownloader) fetchModuleReleaseMetadataFromReleaseChannel(moduleName, releaseChannel, moduleChecksum string) (
 	/* moduleVersion */ string /*newChecksum*/, string /*changelog*/, map[string]any, error) {
-	regCli, err := cr.NewClient(path.Join(md.ms.Spec.Registry.Repo, moduleName, "release"), md.registryOptions...)
-	if err != nil {
-		return "", "", nil, fmt.Errorf("fetch release image error: %v", err)
-	}
+	var (
+		moduleVersion string
+		newChecksum   string
+		changelog     map[string]any
+	)
 
-	img, err := regCli.Image(strcase.ToKebab(releaseChannel))
-	if err != nil {
-		return "", "", nil, fmt.Errorf("fetch image error: %v", err)
-	}
+	err := md.withRegistryFailover(func(repo string, opts []cr.Option) error {
+		moduleVersion, newChecksum, changelog = "", "", nil
 
-	digest, err := img.Digest()
-	if err != nil {
-		return "", "", nil, fmt.Errorf("fetch digest error: %v", err)
-	}
+		regCli, err := cr.NewClient(path.Join(repo, moduleName, "release"), opts...)
+		if err != nil {
+			return fmt.Errorf("fetch release image error: %v", err)
+		}
 
-	if moduleChecksum == digest.String() {
-		return "", moduleChecksum, nil, nil
-	}
+		img, err := regCli.Image(strcase.ToKebab(releaseChannel))
+		if err != nil {
+			return fmt.Errorf("fetch image error: %v", err)
+		}
+
+		digest, err := img.Digest()
+		if err != nil {
+			return fmt.Errorf("fetch digest error: %v", err)
+		}
 
-	moduleMetadata, err := md.fetchModuleReleaseMetadata(img)
+		if moduleChecksum == digest.String() {
+			newChecksum = moduleChecksum
+			return nil
+		}
+
+		err = md.verifyImage(regCli, img)
+		if err != nil {
+			return fmt.Errorf("release image: %w", err)
+		}
+
+		moduleMetadata, err := md.fetchModuleReleaseMetadata(img)
+		if err != nil {
+			newChecksum = digest.String()
+			return fmt.Errorf("fetch release metadata error: %v", err)
+		}
+
+		moduleVersion, newChecksum, changelog = "v"+moduleMetadata.Version.String(), digest.String(), moduleMetadata.Changelog
+
+		return nil
+	})
 	if err != nil {
-		return "", digest.String(), nil, fmt.Errorf("fetch release metadata error: %v", err)
+		return "", newChecksum, nil, err
 	}
 
-	return "v" + moduleMetadata.Version.String(), digest.String(), moduleMetadata.Changelog, nil
+	return moduleVersion, newChecksum, changelog, nil
 }
 
 func (md *ModuleDownloader) fetchModuleDefinitionFromFS(moduleName, moduleVersionPath string) *models.DeckhouseModuleDefinition {
@@ -463,10 +563,13 @@ func InjectRegistryToModuleValues(moduleVersionPath string, moduleSource *v1alph
 }
 
 func mutateOpenapiSchema(sourceValuesData []byte, moduleSource *v1alpha1.ModuleSource) ([]byte, error) {
+	// modules pull their images from the registry which is currently available
+	registry := moduleSource.ActiveRegistry()
+
 	reg := new(registrySchemaForValues)
-	reg.SetBase(moduleSource.Spec.Registry.Repo)
-	reg.SetDockercfg(moduleSource.Spec.Registry.DockerCFG)
-	reg.SetScheme(moduleSource.Spec.Registry.Scheme)
+	reg.SetBase(registry.Repo)
+	reg.SetDockercfg(registry.DockerCFG)
+	reg.SetScheme(registry.Scheme)
 
 	var yamlData injectedValues
 
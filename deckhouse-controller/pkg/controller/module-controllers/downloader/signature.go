@@ -0,0 +1,420 @@
+// Copyright 2024 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package downloader
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+
+	"github.com/deckhouse/deckhouse/deckhouse-controller/pkg/apis/deckhouse.io/v1alpha1"
+	"github.com/deckhouse/deckhouse/go_lib/dependency/cr"
+)
+
+// cosign stores signatures of an image as layers of an image tagged `sha256-<digest>.sig` in the same repository
+const (
+	signatureTagSuffix = ".sig"
+
+	signatureAnnotation   = "dev.cosignproject.cosign/signature"
+	certificateAnnotation = "dev.sigstore.cosign/certificate"
+	chainAnnotation       = "dev.sigstore.cosign/chain"
+	bundleAnnotation      = "dev.sigstore.cosign/bundle"
+)
+
+var (
+	// Fulcio certificate extensions holding the OIDC issuer of the signing identity
+	oidIssuerV1 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}
+	oidIssuerV2 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}
+
+	ErrNoValidSignature = errors.New("no valid signature found")
+)
+
+// simpleSigningPayload is the signed document of the cosign signature
+type simpleSigningPayload struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+}
+
+// rekorBundle is the offline proof that the signature was uploaded to the Rekor transparency log
+type rekorBundle struct {
+	SignedEntryTimestamp []byte       `json:"SignedEntryTimestamp"`
+	Payload              rekorPayload `json:"Payload"`
+}
+
+// rekorPayload fields are declared in the canonical JSON order, the signed entry timestamp is made over the canonical JSON
+type rekorPayload struct {
+	Body           string `json:"body"`
+	IntegratedTime int64  `json:"integratedTime"`
+	LogID          string `json:"logID"`
+	LogIndex       int64  `json:"logIndex"`
+}
+
+// hashedRekord is the body of the transparency log entry made by cosign for the signature
+type hashedRekord struct {
+	Kind string `json:"kind"`
+	Spec struct {
+		Data struct {
+			Hash struct {
+				Algorithm string `json:"algorithm"`
+				Value     string `json:"value"`
+			} `json:"hash"`
+		} `json:"data"`
+		Signature struct {
+			Content   string `json:"content"`
+			PublicKey struct {
+				Content string `json:"content"`
+			} `json:"publicKey"`
+		} `json:"signature"`
+	} `json:"spec"`
+}
+
+type signatureVerifier struct {
+	keys       []crypto.PublicKey
+	identities []v1alpha1.ModuleSourceCertificateIdentity
+	roots      *x509.CertPool
+	// public keys of transparency logs by log ID
+	logKeys map[string]crypto.PublicKey
+}
+
+// newSignatureVerifier returns nil if the trust policy is not set, so the images are not verified
+func newSignatureVerifier(policy *v1alpha1.ModuleSourceTrustPolicy) (*signatureVerifier, error) {
+	if policy == nil || (len(policy.PublicKeys) == 0 && len(policy.CertificateIdentities) == 0) {
+		return nil, nil
+	}
+
+	sv := &signatureVerifier{
+		identities: policy.CertificateIdentities,
+	}
+
+	for i, key := range policy.PublicKeys {
+		pub, err := parsePublicKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("parse public key %d: %w", i, err)
+		}
+		sv.keys = append(sv.keys, pub)
+	}
+
+	if len(sv.identities) > 0 {
+		sv.roots = x509.NewCertPool()
+		if !sv.roots.AppendCertsFromPEM([]byte(policy.CA)) {
+			return nil, errors.New("trust policy with certificate identities requires a valid CA bundle")
+		}
+
+		if len(policy.TransparencyLogPublicKeys) == 0 {
+			return nil, errors.New("trust policy with certificate identities requires transparency log public keys")
+		}
+
+		sv.logKeys = make(map[string]crypto.PublicKey, len(policy.TransparencyLogPublicKeys))
+		for i, key := range policy.TransparencyLogPublicKeys {
+			block, _ := pem.Decode([]byte(key))
+			if block == nil {
+				return nil, fmt.Errorf("parse transparency log public key %d: PEM block not found", i)
+			}
+
+			pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("parse transparency log public key %d: %w", i, err)
+			}
+
+			// the log ID is the SHA256 of the DER encoded public key of the log
+			logID := sha256.Sum256(block.Bytes)
+			sv.logKeys[hex.EncodeToString(logID[:])] = pub
+		}
+	}
+
+	return sv, nil
+}
+
+func parsePublicKey(data string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("PEM block not found")
+	}
+
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+// Verify checks that the image has at least one signature accepted by the trust policy
+func (sv *signatureVerifier) Verify(regCli cr.Client, img v1.Image) error {
+	digest, err := img.Digest()
+	if err != nil {
+		return fmt.Errorf("get image digest: %w", err)
+	}
+
+	sigImg, err := regCli.Image(strings.Replace(digest.String(), ":", "-", 1) + signatureTagSuffix)
+	if err != nil {
+		return fmt.Errorf("%w: fetch signature of %s: %v", ErrNoValidSignature, digest, err)
+	}
+
+	manifest, err := sigImg.Manifest()
+	if err != nil {
+		return fmt.Errorf("%w: read signature manifest of %s: %v", ErrNoValidSignature, digest, err)
+	}
+
+	var errs []error
+	for _, desc := range manifest.Layers {
+		if err = sv.verifyLayer(sigImg, desc, digest); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("verify signature of %s: %w", digest, errors.Join(append([]error{ErrNoValidSignature}, errs...)...))
+}
+
+func (sv *signatureVerifier) verifyLayer(sigImg v1.Image, desc v1.Descriptor, digest v1.Hash) error {
+	signature, err := base64.StdEncoding.DecodeString(desc.Annotations[signatureAnnotation])
+	if err != nil || len(signature) == 0 {
+		return fmt.Errorf("layer %s: malformed signature", desc.Digest)
+	}
+
+	layer, err := sigImg.LayerByDigest(desc.Digest)
+	if err != nil {
+		return fmt.Errorf("layer %s: %w", desc.Digest, err)
+	}
+
+	rc, err := layer.Compressed()
+	if err != nil {
+		return fmt.Errorf("layer %s: %w", desc.Digest, err)
+	}
+	defer rc.Close()
+
+	payload, err := io.ReadAll(rc)
+	if err != nil {
+		return fmt.Errorf("layer %s: read payload: %w", desc.Digest, err)
+	}
+
+	if err = sv.verifyPayloadSignature(payload, signature, desc.Annotations); err != nil {
+		return fmt.Errorf("layer %s: %w", desc.Digest, err)
+	}
+
+	var p simpleSigningPayload
+	if err = json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("layer %s: unmarshal payload: %w", desc.Digest, err)
+	}
+
+	// signature must be made for this exact image, otherwise a signature of another image can be replayed
+	if p.Critical.Image.DockerManifestDigest != digest.String() {
+		return fmt.Errorf("layer %s: signed digest %q does not match image digest %q", desc.Digest, p.Critical.Image.DockerManifestDigest, digest)
+	}
+
+	return nil
+}
+
+func (sv *signatureVerifier) verifyPayloadSignature(payload, signature []byte, annotations map[string]string) error {
+	for _, key := range sv.keys {
+		if verifySignature(key, payload, signature) == nil {
+			return nil
+		}
+	}
+
+	if len(sv.identities) > 0 && annotations[certificateAnnotation] != "" {
+		cert, err := parseCertificate(annotations[certificateAnnotation])
+		if err != nil {
+			return fmt.Errorf("signing certificate: %w", err)
+		}
+
+		signedAt, err := sv.verifyTransparencyLogEntry(annotations[bundleAnnotation], cert, payload, signature)
+		if err != nil {
+			return err
+		}
+
+		if err = sv.verifyCertificate(cert, annotations[chainAnnotation], signedAt); err != nil {
+			return err
+		}
+
+		return verifySignature(cert.PublicKey, payload, signature)
+	}
+
+	return errors.New("signature does not match any trusted key")
+}
+
+func parseCertificate(certPEM string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(certPEM))
+	if block == nil {
+		return nil, errors.New("PEM block not found")
+	}
+
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// verifyTransparencyLogEntry checks the bundle is signed by a trusted transparency log and the log entry is made
+// for this signature and certificate. It returns the time the entry was integrated into the log,
+// the short-lived signing certificate must be valid at that time.
+func (sv *signatureVerifier) verifyTransparencyLogEntry(bundleJSON string, cert *x509.Certificate, payload, signature []byte) (time.Time, error) {
+	if bundleJSON == "" {
+		return time.Time{}, errors.New("transparency log bundle not found")
+	}
+
+	var bundle rekorBundle
+	if err := json.Unmarshal([]byte(bundleJSON), &bundle); err != nil {
+		return time.Time{}, fmt.Errorf("unmarshal transparency log bundle: %w", err)
+	}
+
+	logKey, ok := sv.logKeys[bundle.Payload.LogID]
+	if !ok {
+		return time.Time{}, fmt.Errorf("transparency log %q is not trusted", bundle.Payload.LogID)
+	}
+
+	canonical, err := json.Marshal(bundle.Payload)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("marshal transparency log entry: %w", err)
+	}
+
+	if err = verifySignature(logKey, canonical, bundle.SignedEntryTimestamp); err != nil {
+		return time.Time{}, fmt.Errorf("signed entry timestamp: %w", err)
+	}
+
+	body, err := base64.StdEncoding.DecodeString(bundle.Payload.Body)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("decode transparency log entry: %w", err)
+	}
+
+	var entry hashedRekord
+	if err = json.Unmarshal(body, &entry); err != nil {
+		return time.Time{}, fmt.Errorf("unmarshal transparency log entry: %w", err)
+	}
+
+	if entry.Kind != "hashedrekord" || entry.Spec.Data.Hash.Algorithm != "sha256" {
+		return time.Time{}, fmt.Errorf("unsupported transparency log entry %q", entry.Kind)
+	}
+
+	payloadHash := sha256.Sum256(payload)
+	if entry.Spec.Data.Hash.Value != hex.EncodeToString(payloadHash[:]) {
+		return time.Time{}, errors.New("transparency log entry is made for another payload")
+	}
+
+	if entry.Spec.Signature.Content != base64.StdEncoding.EncodeToString(signature) {
+		return time.Time{}, errors.New("transparency log entry is made for another signature")
+	}
+
+	entryCertPEM, err := base64.StdEncoding.DecodeString(entry.Spec.Signature.PublicKey.Content)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("decode transparency log entry certificate: %w", err)
+	}
+
+	entryCert, err := parseCertificate(string(entryCertPEM))
+	if err != nil || !entryCert.Equal(cert) {
+		return time.Time{}, errors.New("transparency log entry is made for another certificate")
+	}
+
+	return time.Unix(bundle.Payload.IntegratedTime, 0), nil
+}
+
+// verifyCertificate checks the signing certificate was valid at the signing time, chains to the trusted roots
+// and is issued to one of the trusted identities.
+func (sv *signatureVerifier) verifyCertificate(cert *x509.Certificate, chainPEM string, signedAt time.Time) error {
+	intermediates := x509.NewCertPool()
+	intermediates.AppendCertsFromPEM([]byte(chainPEM))
+
+	_, err := cert.Verify(x509.VerifyOptions{
+		Roots:         sv.roots,
+		Intermediates: intermediates,
+		CurrentTime:   signedAt,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	})
+	if err != nil {
+		return fmt.Errorf("signing certificate: %w", err)
+	}
+
+	issuer := certificateIssuer(cert)
+	for _, identity := range sv.identities {
+		if identity.Issuer != issuer {
+			continue
+		}
+
+		for _, subject := range certificateSubjects(cert) {
+			if subject == identity.Subject {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("signing certificate identity %v issued by %q is not trusted", certificateSubjects(cert), issuer)
+}
+
+func certificateSubjects(cert *x509.Certificate) []string {
+	subjects := make([]string, 0, len(cert.EmailAddresses)+len(cert.URIs))
+	subjects = append(subjects, cert.EmailAddresses...)
+	for _, uri := range cert.URIs {
+		subjects = append(subjects, uri.String())
+	}
+
+	return subjects
+}
+
+func certificateIssuer(cert *x509.Certificate) string {
+	for _, ext := range cert.Extensions {
+		switch {
+		case ext.Id.Equal(oidIssuerV2):
+			var issuer string
+			if _, err := asn1.Unmarshal(ext.Value, &issuer); err == nil {
+				return issuer
+			}
+
+		case ext.Id.Equal(oidIssuerV1):
+			return string(ext.Value)
+		}
+	}
+
+	return ""
+}
+
+func verifySignature(key crypto.PublicKey, payload, signature []byte) error {
+	hash := sha256.Sum256(payload)
+
+	switch pub := key.(type) {
+	case *ecdsa.PublicKey:
+		if ecdsa.VerifyASN1(pub, hash[:], signature) {
+			return nil
+		}
+
+	case *rsa.PublicKey:
+		if rsa.VerifyPKCS1v15(pub, crypto.SHA256, hash[:], signature) == nil {
+			return nil
+		}
+
+	case ed25519.PublicKey:
+		if ed25519.Verify(pub, payload, signature) {
+			return nil
+		}
+
+	default:
+		return fmt.Errorf("unsupported public key type %T", key)
+	}
+
+	return errors.New("invalid signature")
+}
@@ -0,0 +1,334 @@
+// Copyright 2024 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package downloader
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/deckhouse/deckhouse/deckhouse-controller/pkg/apis/deckhouse.io/v1alpha1"
+)
+
+type fakeRegistry map[string]v1.Image
+
+func (f fakeRegistry) Image(tag string) (v1.Image, error) {
+	img, ok := f[tag]
+	if !ok {
+		return nil, fmt.Errorf("tag %q not found", tag)
+	}
+	return img, nil
+}
+
+func (f fakeRegistry) Digest(tag string) (string, error) {
+	img, err := f.Image(tag)
+	if err != nil {
+		return "", err
+	}
+	d, err := img.Digest()
+	return d.String(), err
+}
+
+func (f fakeRegistry) ListTags() ([]string, error) {
+	tags := make([]string, 0, len(f))
+	for tag := range f {
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+func signatureTag(t *testing.T, img v1.Image) string {
+	digest, err := img.Digest()
+	require.NoError(t, err)
+	return strings.Replace(digest.String(), ":", "-", 1) + ".sig"
+}
+
+func payloadFor(t *testing.T, img v1.Image) []byte {
+	digest, err := img.Digest()
+	require.NoError(t, err)
+	return []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"registry.example.io/modules/foo"},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`, digest))
+}
+
+func sign(t *testing.T, key *ecdsa.PrivateKey, data []byte) []byte {
+	hash := sha256.Sum256(data)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
+	require.NoError(t, err)
+	return sig
+}
+
+func signatureImage(t *testing.T, payload []byte, key *ecdsa.PrivateKey, annotations map[string]string) v1.Image {
+	return signedImage(t, payload, sign(t, key, payload), annotations)
+}
+
+func signedImage(t *testing.T, payload, sig []byte, annotations map[string]string) v1.Image {
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[signatureAnnotation] = base64.StdEncoding.EncodeToString(sig)
+
+	img, err := mutate.Append(empty.Image, mutate.Addendum{
+		Layer:       static.NewLayer(payload, "application/vnd.dev.cosign.simplesigning.v1+json"),
+		Annotations: annotations,
+		MediaType:   types.MediaType("application/vnd.dev.cosign.simplesigning.v1+json"),
+	})
+	require.NoError(t, err)
+	return img
+}
+
+func publicKeyPEM(t *testing.T, key crypto.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func TestVerifyWithPublicKey(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	img, err := random.Image(1024, 1)
+	require.NoError(t, err)
+	anotherImg, err := random.Image(1024, 1)
+	require.NoError(t, err)
+
+	sv, err := newSignatureVerifier(&v1alpha1.ModuleSourceTrustPolicy{PublicKeys: []string{publicKeyPEM(t, &key.PublicKey)}})
+	require.NoError(t, err)
+
+	t.Run("valid signature", func(t *testing.T) {
+		reg := fakeRegistry{signatureTag(t, img): signatureImage(t, payloadFor(t, img), key, nil)}
+		assert.NoError(t, sv.Verify(reg, img))
+	})
+
+	t.Run("signed with untrusted key", func(t *testing.T) {
+		reg := fakeRegistry{signatureTag(t, img): signatureImage(t, payloadFor(t, img), otherKey, nil)}
+		assert.ErrorIs(t, sv.Verify(reg, img), ErrNoValidSignature)
+	})
+
+	t.Run("signature of another image", func(t *testing.T) {
+		reg := fakeRegistry{signatureTag(t, img): signatureImage(t, payloadFor(t, anotherImg), key, nil)}
+		err := sv.Verify(reg, img)
+		assert.ErrorIs(t, err, ErrNoValidSignature)
+		assert.ErrorContains(t, err, "does not match image digest")
+	})
+
+	t.Run("no signature", func(t *testing.T) {
+		assert.ErrorIs(t, sv.Verify(fakeRegistry{}, img), ErrNoValidSignature)
+	})
+}
+
+// rekorBundleFor returns the bundle of the transparency log entry made for the signature, as cosign uploads it
+func rekorBundleFor(t *testing.T, logKey *ecdsa.PrivateKey, certPEM string, payload, sig []byte, integratedTime time.Time) string {
+	payloadHash := sha256.Sum256(payload)
+	body, err := json.Marshal(map[string]interface{}{
+		"apiVersion": "0.0.1",
+		"kind":       "hashedrekord",
+		"spec": map[string]interface{}{
+			"data": map[string]interface{}{
+				"hash": map[string]string{"algorithm": "sha256", "value": hex.EncodeToString(payloadHash[:])},
+			},
+			"signature": map[string]interface{}{
+				"content":   base64.StdEncoding.EncodeToString(sig),
+				"publicKey": map[string]string{"content": base64.StdEncoding.EncodeToString([]byte(certPEM))},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKIXPublicKey(&logKey.PublicKey)
+	require.NoError(t, err)
+	logID := sha256.Sum256(der)
+
+	entry := rekorPayload{
+		Body:           base64.StdEncoding.EncodeToString(body),
+		IntegratedTime: integratedTime.Unix(),
+		LogID:          hex.EncodeToString(logID[:]),
+		LogIndex:       42,
+	}
+	canonical, err := json.Marshal(entry)
+	require.NoError(t, err)
+
+	bundle, err := json.Marshal(rekorBundle{SignedEntryTimestamp: sign(t, logKey, canonical), Payload: entry})
+	require.NoError(t, err)
+	return string(bundle)
+}
+
+func TestVerifyWithCertificateIdentity(t *testing.T) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+	caPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}))
+
+	issuer, err := asn1.Marshal("https://token.actions.githubusercontent.com")
+	require.NoError(t, err)
+
+	signingKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	// short-lived certificate, already expired at the moment of verification
+	notBefore := time.Now().Add(-30 * time.Minute)
+	leafTemplate := &x509.Certificate{
+		SerialNumber:    big.NewInt(2),
+		NotBefore:       notBefore,
+		NotAfter:        notBefore.Add(10 * time.Minute),
+		KeyUsage:        x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		EmailAddresses:  []string{"release@example.com"},
+		ExtraExtensions: []pkix.Extension{{Id: oidIssuerV2, Value: issuer}},
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, caCert, &signingKey.PublicKey, caKey)
+	require.NoError(t, err)
+	leafPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leafDER}))
+
+	logKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherLogKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	img, err := random.Image(1024, 1)
+	require.NoError(t, err)
+	payload := payloadFor(t, img)
+	sig := sign(t, signingKey, payload)
+	signedAt := notBefore.Add(time.Minute)
+
+	registryWithBundle := func(bundle string) fakeRegistry {
+		annotations := map[string]string{certificateAnnotation: leafPEM}
+		if bundle != "" {
+			annotations[bundleAnnotation] = bundle
+		}
+		return fakeRegistry{signatureTag(t, img): signedImage(t, payload, sig, annotations)}
+	}
+
+	policy := func(subject string) *v1alpha1.ModuleSourceTrustPolicy {
+		return &v1alpha1.ModuleSourceTrustPolicy{
+			CertificateIdentities: []v1alpha1.ModuleSourceCertificateIdentity{
+				{Subject: subject, Issuer: "https://token.actions.githubusercontent.com"},
+			},
+			CA:                        caPEM,
+			TransparencyLogPublicKeys: []string{publicKeyPEM(t, &logKey.PublicKey)},
+		}
+	}
+
+	trusted, err := newSignatureVerifier(policy("release@example.com"))
+	require.NoError(t, err)
+
+	t.Run("trusted identity", func(t *testing.T) {
+		reg := registryWithBundle(rekorBundleFor(t, logKey, leafPEM, payload, sig, signedAt))
+		assert.NoError(t, trusted.Verify(reg, img))
+	})
+
+	t.Run("untrusted identity", func(t *testing.T) {
+		sv, err := newSignatureVerifier(policy("someone@example.com"))
+		require.NoError(t, err)
+		err = sv.Verify(registryWithBundle(rekorBundleFor(t, logKey, leafPEM, payload, sig, signedAt)), img)
+		assert.ErrorIs(t, err, ErrNoValidSignature)
+		assert.ErrorContains(t, err, "is not trusted")
+	})
+
+	t.Run("no transparency log bundle", func(t *testing.T) {
+		err := trusted.Verify(registryWithBundle(""), img)
+		assert.ErrorIs(t, err, ErrNoValidSignature)
+		assert.ErrorContains(t, err, "transparency log bundle not found")
+	})
+
+	t.Run("bundle of untrusted transparency log", func(t *testing.T) {
+		err := trusted.Verify(registryWithBundle(rekorBundleFor(t, otherLogKey, leafPEM, payload, sig, signedAt)), img)
+		assert.ErrorIs(t, err, ErrNoValidSignature)
+		assert.ErrorContains(t, err, "is not trusted")
+	})
+
+	t.Run("tampered bundle", func(t *testing.T) {
+		bundle := rekorBundleFor(t, logKey, leafPEM, payload, sig, signedAt)
+		var b rekorBundle
+		require.NoError(t, json.Unmarshal([]byte(bundle), &b))
+		b.Payload.IntegratedTime++
+		tampered, err := json.Marshal(b)
+		require.NoError(t, err)
+
+		err = trusted.Verify(registryWithBundle(string(tampered)), img)
+		assert.ErrorIs(t, err, ErrNoValidSignature)
+		assert.ErrorContains(t, err, "signed entry timestamp")
+	})
+
+	t.Run("bundle of another signature", func(t *testing.T) {
+		err := trusted.Verify(registryWithBundle(rekorBundleFor(t, logKey, leafPEM, payload, sign(t, signingKey, payload), signedAt)), img)
+		assert.ErrorIs(t, err, ErrNoValidSignature)
+		assert.ErrorContains(t, err, "another signature")
+	})
+
+	t.Run("signed after the certificate expired", func(t *testing.T) {
+		err := trusted.Verify(registryWithBundle(rekorBundleFor(t, logKey, leafPEM, payload, sig, time.Now())), img)
+		assert.ErrorIs(t, err, ErrNoValidSignature)
+		assert.ErrorContains(t, err, "signing certificate")
+	})
+
+	t.Run("identities without CA", func(t *testing.T) {
+		p := policy("release@example.com")
+		p.CA = ""
+		_, err := newSignatureVerifier(p)
+		assert.Error(t, err)
+	})
+
+	t.Run("identities without transparency log keys", func(t *testing.T) {
+		p := policy("release@example.com")
+		p.TransparencyLogPublicKeys = nil
+		_, err := newSignatureVerifier(p)
+		assert.Error(t, err)
+	})
+}
+
+func TestEmptyTrustPolicy(t *testing.T) {
+	sv, err := newSignatureVerifier(nil)
+	require.NoError(t, err)
+	assert.Nil(t, sv)
+
+	sv, err = newSignatureVerifier(&v1alpha1.ModuleSourceTrustPolicy{})
+	require.NoError(t, err)
+	assert.Nil(t, sv)
+}
@@ -385,6 +385,10 @@ func (c *Controller) createOrUpdateReconcile(ctx context.Context, roMR *v1alpha1
 		return ctrl.Result{}, nil
 
 	case v1alpha1.PhaseDeployed:
+		if mr.GetRollback() {
+			return c.rollbackRelease(ctx, mr)
+		}
+
 		err := c.documentationUpdater.SendDocumentation(ctx, mr)
 		if err != nil {
 			return ctrl.Result{Requeue: true}, fmt.Errorf("send documentation: %w", err)
@@ -510,8 +514,20 @@ func (c *Controller) reconcilePendingRelease(ctx context.Context, mr *v1alpha1.M
 	}
 
 	kubeAPI := newKubeAPI(c.logger, c.d8ClientSet, c.moduleSourcesLister, c.moduleReleasesLister, c.externalModulesDir, c.symlinksDir, c.modulesValidator)
+	kubeAPI.wavePublisher = newWaveStatusPublisher(policy.Spec.Update.Wave)
 	releaseUpdater := newModuleUpdater(c.logger, nConfig, policy.Spec.Update.Mode, kubeAPI)
 
+	if policy.Spec.Update.Wave != nil {
+		wave, err := newRolloutWave(policy.Spec.Update.Wave)
+		if err != nil {
+			if e := c.updateModuleReleaseStatusMessage(ctx, mr, fmt.Sprintf("Update policy %s has invalid rollout wave: %v", policy.Name, err)); e != nil {
+				return ctrl.Result{Requeue: true}, e
+			}
+			return ctrl.Result{RequeueAfter: defaultCheckInterval}, nil
+		}
+		releaseUpdater.SetRolloutWave(wave)
+	}
+
 	releaseUpdater.PrepareReleases(otherReleases)
 	if releaseUpdater.ReleasesCount() == 0 {
 		return ctrl.Result{}, nil
@@ -561,12 +577,15 @@ func (c *Controller) reconcilePendingRelease(ctx context.Context, mr *v1alpha1.M
 		return ctrl.Result{}, nil
 	}
 
+	predictedRelease := otherReleases[releaseUpdater.GetPredictedReleaseIndex()]
+
 	if releaseUpdater.PredictedReleaseIsPatch() {
 		// patch release does not respect update windows or ManualMode
 		if !releaseUpdater.ApplyPredictedRelease(nil) {
 			return ctrl.Result{RequeueAfter: defaultCheckInterval}, nil
 		}
 
+		c.publishWaveStatus(ctx, policy.Spec.Update.Wave, predictedRelease, updater.WaveStatusDeployed, "")
 		modulesChangedReason = "a new module release found"
 		return ctrl.Result{}, nil
 	}
@@ -580,6 +599,7 @@ func (c *Controller) reconcilePendingRelease(ctx context.Context, mr *v1alpha1.M
 		return ctrl.Result{RequeueAfter: defaultCheckInterval}, nil
 	}
 
+	c.publishWaveStatus(ctx, policy.Spec.Update.Wave, predictedRelease, updater.WaveStatusDeployed, "")
 	modulesChangedReason = "a new module release found"
 	return ctrl.Result{}, nil
 }
@@ -0,0 +1,134 @@
+// Copyright 2024 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package release
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/deckhouse/deckhouse/deckhouse-controller/pkg/apis/deckhouse.io/v1alpha1"
+	"github.com/deckhouse/deckhouse/go_lib/updater"
+)
+
+// rollbackRelease re-points the module to the previously deployed release, which is still kept on the filesystem,
+// and suspends the current release, so the updater doesn't apply it again
+func (c *Controller) rollbackRelease(ctx context.Context, mr *v1alpha1.ModuleRelease) (ctrl.Result, error) {
+	moduleName := mr.Spec.ModuleName
+
+	otherReleases, err := c.moduleReleasesLister.List(labels.SelectorFromValidatedSet(map[string]string{"module": moduleName}))
+	if err != nil {
+		return ctrl.Result{Requeue: true}, err
+	}
+
+	prev := findRollbackTarget(c.externalModulesDir, mr, otherReleases)
+	if prev == nil {
+		c.logger.Warnf("Rollback of the release %q is impossible: no previous release found on the filesystem", mr.Name)
+		if e := c.updateModuleReleaseStatusMessage(ctx, mr, "Rollback is impossible: no previously deployed release found on the filesystem"); e != nil {
+			return ctrl.Result{Requeue: true}, e
+		}
+
+		return ctrl.Result{}, c.removeRollbackAnnotation(ctx, mr.Name)
+	}
+
+	currentModuleSymlink, err := findExistingModuleSymlink(c.symlinksDir, moduleName)
+	if err != nil {
+		currentModuleSymlink = "900-" + moduleName // fallback
+	}
+	newModuleSymlink := path.Join(c.symlinksDir, fmt.Sprintf("%d-%s", prev.Spec.Weight, moduleName))
+
+	err = enableModule(c.externalModulesDir, currentModuleSymlink, newModuleSymlink, generateModulePath(moduleName, prev.Spec.Version.String()))
+	if err != nil {
+		return ctrl.Result{Requeue: true}, fmt.Errorf("rollback module %q to %s: %w", moduleName, prev.GetReleaseVersion(), err)
+	}
+
+	now := metav1.NewTime(time.Now().UTC())
+
+	prev = prev.DeepCopy()
+	prev.Status.Phase = v1alpha1.PhaseDeployed
+	prev.Status.Message = fmt.Sprintf("Deployed by the rollback from %s", mr.GetReleaseVersion())
+	prev.Status.TransitionTime = now
+	if err = c.updateModuleReleaseStatus(ctx, prev); err != nil {
+		return ctrl.Result{Requeue: true}, err
+	}
+
+	mr.Status.Phase = v1alpha1.PhaseSuspended
+	mr.Status.Message = fmt.Sprintf("Rolled back to %s", prev.GetReleaseVersion())
+	mr.Status.TransitionTime = now
+	if err = c.updateModuleReleaseStatus(ctx, mr); err != nil {
+		return ctrl.Result{Requeue: true}, err
+	}
+
+	if err = c.removeRollbackAnnotation(ctx, mr.Name); err != nil {
+		return ctrl.Result{Requeue: true}, err
+	}
+
+	// disable target module hooks so as not to invoke them before restart
+	if c.modulesValidator.GetModule(moduleName) != nil {
+		c.modulesValidator.DisableModuleHooks(moduleName)
+	}
+
+	c.publishWaveStatus(ctx, c.releaseWave(mr), mr, updater.WaveStatusFailed, mr.Status.Message)
+
+	c.logger.Infof("Module %q rolled back from %s to %s", moduleName, mr.GetReleaseVersion(), prev.GetReleaseVersion())
+	c.emitRestart(fmt.Sprintf("module %s rolled back", moduleName))
+
+	return ctrl.Result{}, nil
+}
+
+// findRollbackTarget returns the latest release older than the current one which was deployed and still exists on the filesystem.
+// Skipped patch releases are superseded too, but they were never downloaded, so they are filtered out by the filesystem check.
+func findRollbackTarget(externalModulesDir string, current *v1alpha1.ModuleRelease, releases []*v1alpha1.ModuleRelease) *v1alpha1.ModuleRelease {
+	var target *v1alpha1.ModuleRelease
+
+	for _, rl := range releases {
+		if rl.Status.Phase != v1alpha1.PhaseSuperseded || !rl.Spec.Version.LessThan(current.Spec.Version) {
+			continue
+		}
+
+		if target != nil && !rl.Spec.Version.GreaterThan(target.Spec.Version) {
+			continue
+		}
+
+		if _, err := os.Stat(path.Join(externalModulesDir, rl.Spec.ModuleName, rl.GetReleaseVersion())); err != nil {
+			continue
+		}
+
+		target = rl
+	}
+
+	return target
+}
+
+func (c *Controller) removeRollbackAnnotation(ctx context.Context, releaseName string) error {
+	patch, _ := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]any{
+				v1alpha1.RollbackAnnotation: nil,
+			},
+		},
+	})
+
+	_, err := c.d8ClientSet.DeckhouseV1alpha1().ModuleReleases().Patch(ctx, releaseName, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
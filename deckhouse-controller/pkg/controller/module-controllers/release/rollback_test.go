@@ -0,0 +1,142 @@
+// Copyright 2024 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package release
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/deckhouse/deckhouse/deckhouse-controller/pkg/apis/deckhouse.io/v1alpha1"
+	"github.com/deckhouse/deckhouse/go_lib/updater"
+)
+
+func testModuleRelease(version, phase string) *v1alpha1.ModuleRelease {
+	mr := &v1alpha1.ModuleRelease{}
+	mr.Name = "echo-v" + version
+	mr.Spec.ModuleName = "echo"
+	mr.Spec.Version = semver.MustParse(version)
+	mr.Status.Phase = phase
+	return mr
+}
+
+func TestFindRollbackTarget(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  string
+		releases []*v1alpha1.ModuleRelease
+		// versions of the module kept on the filesystem
+		downloaded []string
+		want       string
+	}{
+		{
+			name:    "previous deployed release",
+			current: "1.3.0",
+			releases: []*v1alpha1.ModuleRelease{
+				testModuleRelease("1.1.0", v1alpha1.PhaseSuperseded),
+				testModuleRelease("1.2.0", v1alpha1.PhaseSuperseded),
+				testModuleRelease("1.3.0", v1alpha1.PhaseDeployed),
+			},
+			downloaded: []string{"1.1.0", "1.2.0", "1.3.0"},
+			want:       "1.2.0",
+		},
+		{
+			name:    "no previous deployed release",
+			current: "1.0.0",
+			releases: []*v1alpha1.ModuleRelease{
+				testModuleRelease("1.0.0", v1alpha1.PhaseDeployed),
+				testModuleRelease("1.1.0", v1alpha1.PhasePending),
+			},
+			downloaded: []string{"1.0.0"},
+		},
+		{
+			name:    "previous release is not on the filesystem",
+			current: "1.3.0",
+			releases: []*v1alpha1.ModuleRelease{
+				testModuleRelease("1.1.0", v1alpha1.PhaseSuperseded),
+				testModuleRelease("1.2.0", v1alpha1.PhaseSuperseded),
+				testModuleRelease("1.3.0", v1alpha1.PhaseDeployed),
+			},
+			downloaded: []string{"1.1.0", "1.3.0"},
+			want:       "1.1.0",
+		},
+		{
+			name:    "skipped release",
+			current: "1.3.0",
+			releases: []*v1alpha1.ModuleRelease{
+				testModuleRelease("1.1.0", v1alpha1.PhaseSuperseded),
+				testModuleRelease("1.2.0", updater.PhaseSkipped),
+				testModuleRelease("1.3.0", v1alpha1.PhaseDeployed),
+			},
+			downloaded: []string{"1.1.0", "1.2.0", "1.3.0"},
+			want:       "1.1.0",
+		},
+		{
+			name:    "suspended release",
+			current: "1.3.0",
+			releases: []*v1alpha1.ModuleRelease{
+				testModuleRelease("1.1.0", v1alpha1.PhaseSuperseded),
+				testModuleRelease("1.2.0", v1alpha1.PhaseSuspended),
+				testModuleRelease("1.3.0", v1alpha1.PhaseDeployed),
+			},
+			downloaded: []string{"1.1.0", "1.2.0", "1.3.0"},
+			want:       "1.1.0",
+		},
+		{
+			name:    "only suspended and skipped releases",
+			current: "1.3.0",
+			releases: []*v1alpha1.ModuleRelease{
+				testModuleRelease("1.1.0", updater.PhaseSkipped),
+				testModuleRelease("1.2.0", v1alpha1.PhaseSuspended),
+				testModuleRelease("1.3.0", v1alpha1.PhaseDeployed),
+			},
+			downloaded: []string{"1.1.0", "1.2.0", "1.3.0"},
+		},
+		{
+			name:    "pinned version older than superseded releases",
+			current: "1.2.0",
+			releases: []*v1alpha1.ModuleRelease{
+				testModuleRelease("1.1.0", v1alpha1.PhaseSuperseded),
+				testModuleRelease("1.2.0", v1alpha1.PhaseDeployed),
+				testModuleRelease("1.3.0", v1alpha1.PhaseSuperseded),
+				testModuleRelease("1.4.0", v1alpha1.PhaseSuspended),
+			},
+			downloaded: []string{"1.1.0", "1.2.0", "1.3.0", "1.4.0"},
+			want:       "1.1.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, version := range tt.downloaded {
+				require.NoError(t, os.MkdirAll(path.Join(dir, "echo", "v"+version), 0o755))
+			}
+
+			target := findRollbackTarget(dir, testModuleRelease(tt.current, v1alpha1.PhaseDeployed), tt.releases)
+			if tt.want == "" {
+				assert.Nil(t, target)
+				return
+			}
+
+			require.NotNil(t, target)
+			assert.Equal(t, "v"+tt.want, target.GetReleaseVersion())
+		})
+	}
+}
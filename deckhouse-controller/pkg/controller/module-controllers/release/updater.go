@@ -33,6 +33,7 @@ import (
 	d8listers "github.com/deckhouse/deckhouse/deckhouse-controller/pkg/client/listers/deckhouse.io/v1alpha1"
 	"github.com/deckhouse/deckhouse/deckhouse-controller/pkg/controller/models"
 	"github.com/deckhouse/deckhouse/deckhouse-controller/pkg/controller/module-controllers/downloader"
+	"github.com/deckhouse/deckhouse/deckhouse-controller/pkg/controller/module-controllers/requirements"
 	"github.com/deckhouse/deckhouse/deckhouse-controller/pkg/controller/module-controllers/utils"
 	"github.com/deckhouse/deckhouse/go_lib/updater"
 )
@@ -80,6 +81,8 @@ type kubeAPI struct {
 	externalModulesDir  string
 	symlinksDir         string
 	modulesValidator    moduleValidator
+	// wavePublisher reports failed deployments to the clusters of the later waves, nil if the policy has no status source
+	wavePublisher *waveStatusPublisher
 }
 
 func (k *kubeAPI) UpdateReleaseStatus(release *v1alpha1.ModuleRelease, msg, phase string) error {
@@ -135,9 +138,24 @@ func (k *kubeAPI) DeployRelease(release *v1alpha1.ModuleRelease) error {
 		return fmt.Errorf("list module sources: %w", err)
 	}
 
+	// patch releases skip the updater requirements check, so check them here for every release
+	if err = requirements.Check(release.Spec.Requirements); err != nil {
+		msg := fmt.Sprintf("Module requirements are not met: %s", err)
+		if e := k.UpdateReleaseStatus(release, msg, updater.PhasePending); e != nil {
+			return e
+		}
+		return errors.New(msg)
+	}
+
 	md := downloader.NewModuleDownloader(k.externalModulesDir, ms, utils.GenerateRegistryOptions(ms))
 	_, err = md.DownloadByModuleVersion(release.Spec.ModuleName, release.Spec.Version.String())
 	if err != nil {
+		// keep the release pending until a valid signature is published
+		if errors.Is(err, downloader.ErrNoValidSignature) {
+			if e := k.UpdateReleaseStatus(release, "signature verification failed: "+err.Error(), release.Status.Phase); e != nil {
+				return e
+			}
+		}
 		return fmt.Errorf("download module: %w", err)
 	}
 
@@ -157,8 +175,10 @@ func (k *kubeAPI) DeployRelease(release *v1alpha1.ModuleRelease) error {
 		if e := k.UpdateReleaseStatus(release, "validation failed: "+err.Error(), release.Status.Phase); e != nil {
 			return e
 		}
+		k.publishDeployFailure(release, "validation failed: "+err.Error())
 
-		return nil
+		// the release must not be marked as deployed
+		return fmt.Errorf("validate module: %w", err)
 	}
 
 	// search symlink for module by regexp
@@ -174,6 +194,9 @@ func (k *kubeAPI) DeployRelease(release *v1alpha1.ModuleRelease) error {
 		if e := k.suspendModuleVersionForRelease(release, err); e != nil {
 			return e
 		}
+		k.publishDeployFailure(release, "deploy failed: "+err.Error())
+
+		return fmt.Errorf("enable module: %w", err)
 	}
 
 	// disable target module hooks so as not to invoke them before restart
@@ -184,6 +207,17 @@ func (k *kubeAPI) DeployRelease(release *v1alpha1.ModuleRelease) error {
 	return nil
 }
 
+// publishDeployFailure reports the failed release to the wave status source, a failure to publish is only logged
+func (k *kubeAPI) publishDeployFailure(release *v1alpha1.ModuleRelease, message string) {
+	if k.wavePublisher == nil {
+		return
+	}
+
+	if err := k.wavePublisher.Publish(context.Background(), release.Name, updater.WaveStatusFailed, message); err != nil {
+		k.logger.Warnf("Publish the %s rollout status of the release %q: %v", updater.WaveStatusFailed, release.Name, err)
+	}
+}
+
 func (k *kubeAPI) suspendModuleVersionForRelease(release *v1alpha1.ModuleRelease, err error) error {
 	if os.IsNotExist(err) {
 		err = errors.New("not found")
@@ -0,0 +1,183 @@
+// Copyright 2024 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package release
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/deckhouse/deckhouse/deckhouse-controller/pkg/apis/deckhouse.io/v1alpha1"
+	d8http "github.com/deckhouse/deckhouse/go_lib/dependency/http"
+	"github.com/deckhouse/deckhouse/go_lib/updater"
+)
+
+// newRolloutWave converts the wave of the update policy to the updater settings
+func newRolloutWave(wave *v1alpha1.ModuleUpdatePolicyWave) (*updater.RolloutWave, error) {
+	if err := updater.ValidateWaveName(wave.Name); err != nil {
+		return nil, err
+	}
+
+	rw := &updater.RolloutWave{
+		Name:  wave.Name,
+		Delay: wave.Delay.Duration,
+	}
+
+	// the canary wave is the first one, there are no earlier waves to wait for
+	if wave.Name != updater.WaveCanary && wave.StatusSource != nil {
+		rw.StatusGetter = &waveStatusGetter{
+			httpClient: d8http.NewClient(d8http.WithTimeout(10 * time.Second)),
+			url:        wave.StatusSource.URL,
+		}
+	}
+
+	return rw, nil
+}
+
+// waveStatusGetter reads the rollout statuses reported by the clusters of the earlier waves from the shared status endpoint
+type waveStatusGetter struct {
+	httpClient d8http.Client
+	url        string
+}
+
+func (g *waveStatusGetter) GetWaveStatuses() ([]updater.WaveStatus, error) {
+	statuses, err := g.fromURL(g.url)
+	if err != nil {
+		return nil, fmt.Errorf("status endpoint: %w", err)
+	}
+
+	return statuses, nil
+}
+
+func (g *waveStatusGetter) fromURL(url string) ([]updater.WaveStatus, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return updater.ParseWaveStatusReport(data)
+}
+
+// waveStatusPublisher publishes the rollout statuses of the releases of the cluster to the status source,
+// so the clusters of the later waves can block the failed releases
+type waveStatusPublisher struct {
+	httpClient d8http.Client
+	wave       *v1alpha1.ModuleUpdatePolicyWave
+}
+
+// newWaveStatusPublisher returns nil if the policy has no status source to publish to
+func newWaveStatusPublisher(wave *v1alpha1.ModuleUpdatePolicyWave) *waveStatusPublisher {
+	if wave == nil || wave.StatusSource == nil {
+		return nil
+	}
+
+	return &waveStatusPublisher{
+		httpClient: d8http.NewClient(d8http.WithTimeout(10 * time.Second)),
+		wave:       wave,
+	}
+}
+
+func (p *waveStatusPublisher) Publish(ctx context.Context, releaseName, status, message string) error {
+	ws := updater.WaveStatus{
+		Release: releaseName,
+		Wave:    p.wave.Name,
+		Cluster: p.wave.ClusterName,
+		Status:  status,
+		Message: message,
+	}
+
+	if err := p.toURL(ctx, p.wave.StatusSource.URL, ws); err != nil {
+		return fmt.Errorf("status endpoint: %w", err)
+	}
+
+	return nil
+}
+
+// toURL sends the report with the single status, the endpoint merges it with the reports of other clusters
+func (p *waveStatusPublisher) toURL(ctx context.Context, url string, status updater.WaveStatus) error {
+	data, err := json.Marshal(updater.WaveStatusReport{Releases: []updater.WaveStatus{status}})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// releaseWave returns the rollout wave of the update policy of the release, if any
+func (c *Controller) releaseWave(mr *v1alpha1.ModuleRelease) *v1alpha1.ModuleUpdatePolicyWave {
+	policyName, found := mr.ObjectMeta.Labels[UpdatePolicyLabel]
+	if !found {
+		return nil
+	}
+
+	if policyName == "" {
+		return c.deckhouseEmbeddedPolicy.Update.Wave
+	}
+
+	policy, err := c.moduleUpdatePoliciesLister.Get(policyName)
+	if err != nil {
+		return nil
+	}
+
+	return policy.Spec.Update.Wave
+}
+
+// publishWaveStatus reports the status of the release to the clusters of the later waves.
+// The release is already processed at this point, so a failure to publish is only logged
+func (c *Controller) publishWaveStatus(ctx context.Context, wave *v1alpha1.ModuleUpdatePolicyWave, mr *v1alpha1.ModuleRelease, status, message string) {
+	publisher := newWaveStatusPublisher(wave)
+	if publisher == nil {
+		return
+	}
+
+	if err := publisher.Publish(ctx, mr.Name, status, message); err != nil {
+		c.logger.Warnf("Publish the %s rollout status of the release %q: %v", status, mr.Name, err)
+	}
+}
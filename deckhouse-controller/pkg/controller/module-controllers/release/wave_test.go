@@ -0,0 +1,92 @@
+// Copyright 2024 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package release
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/deckhouse/deckhouse/deckhouse-controller/pkg/apis/deckhouse.io/v1alpha1"
+	"github.com/deckhouse/deckhouse/go_lib/updater"
+)
+
+func TestWaveStatusPublisher(t *testing.T) {
+	assert.Nil(t, newWaveStatusPublisher(nil))
+	assert.Nil(t, newWaveStatusPublisher(&v1alpha1.ModuleUpdatePolicyWave{Name: updater.WaveCanary}))
+
+	t.Run("report is sent to the status endpoint", func(t *testing.T) {
+		var received []byte
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			received, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer srv.Close()
+
+		p := newWaveStatusPublisher(&v1alpha1.ModuleUpdatePolicyWave{
+			Name:         updater.WaveEarly,
+			StatusSource: &v1alpha1.ModuleUpdatePolicyWaveStatusSource{URL: srv.URL},
+		})
+
+		require.NoError(t, p.Publish(context.Background(), "echo-v1.0.0", updater.WaveStatusDeployed, ""))
+		statuses, err := updater.ParseWaveStatusReport(received)
+		require.NoError(t, err)
+		assert.Equal(t, []updater.WaveStatus{{Release: "echo-v1.0.0", Wave: updater.WaveEarly, Status: updater.WaveStatusDeployed}}, statuses)
+	})
+
+	t.Run("status endpoint fails", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer srv.Close()
+
+		p := newWaveStatusPublisher(&v1alpha1.ModuleUpdatePolicyWave{
+			Name:         updater.WaveEarly,
+			StatusSource: &v1alpha1.ModuleUpdatePolicyWaveStatusSource{URL: srv.URL},
+		})
+
+		assert.ErrorContains(t, p.Publish(context.Background(), "echo-v1.0.0", updater.WaveStatusDeployed, ""), "unexpected status code 502")
+	})
+
+	t.Run("failed deployment is published", func(t *testing.T) {
+		var received []byte
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer srv.Close()
+
+		k := &kubeAPI{wavePublisher: newWaveStatusPublisher(&v1alpha1.ModuleUpdatePolicyWave{
+			Name:         updater.WaveCanary,
+			ClusterName:  "canary-1",
+			StatusSource: &v1alpha1.ModuleUpdatePolicyWaveStatusSource{URL: srv.URL},
+		})}
+		release := &v1alpha1.ModuleRelease{ObjectMeta: metav1.ObjectMeta{Name: "echo-v1.0.0"}}
+
+		k.publishDeployFailure(release, "validation failed: bad values")
+		statuses, err := updater.ParseWaveStatusReport(received)
+		require.NoError(t, err)
+		assert.Equal(t, []updater.WaveStatus{
+			{Release: "echo-v1.0.0", Wave: updater.WaveCanary, Cluster: "canary-1", Status: updater.WaveStatusFailed, Message: "validation failed: bad values"},
+		}, statuses)
+	})
+}
@@ -0,0 +1,167 @@
+// Copyright 2024 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package requirements
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/deckhouse/deckhouse/deckhouse-controller/pkg/controller/models"
+	"github.com/deckhouse/deckhouse/go_lib/dependency/requirements"
+	"github.com/deckhouse/deckhouse/go_lib/set"
+)
+
+// requirement keys of the ModuleRelease, they are filled from the `requirements` section of the module.yaml.
+// Checks are registered in the global registry shared with the Deckhouse release requirements, so keys are prefixed
+// not to collide with them
+const (
+	DeckhouseVersionKey  = "externalModule.deckhouse"
+	KubernetesVersionKey = "externalModule.kubernetes"
+	ModulesKey           = "externalModule.modules"
+)
+
+// keys of the values in the requirements memory storage
+const (
+	DeckhouseVersionValueKey  = "global.deckhouseVersion"
+	KubernetesVersionValueKey = "global.discovery.kubernetesVersion"
+	EnabledModulesValueKey    = "global.enabledModules"
+)
+
+func init() {
+	requirements.RegisterCheck(DeckhouseVersionKey, checkDeckhouseVersion)
+	requirements.RegisterCheck(KubernetesVersionKey, checkKubernetesVersion)
+	requirements.RegisterCheck(ModulesKey, checkModules)
+}
+
+// FromDefinition converts module.yaml requirements to the ModuleRelease requirements
+func FromDefinition(def models.ModuleRequirements) map[string]string {
+	res := make(map[string]string)
+
+	if def.Deckhouse != "" {
+		res[DeckhouseVersionKey] = def.Deckhouse
+	}
+
+	if def.Kubernetes != "" {
+		res[KubernetesVersionKey] = def.Kubernetes
+	}
+
+	if len(def.Modules) > 0 {
+		res[ModulesKey] = strings.Join(def.Modules, ",")
+	}
+
+	if len(res) == 0 {
+		return nil
+	}
+
+	return res
+}
+
+// Check evaluates all release requirements and returns the first unmet one
+func Check(reqs map[string]string) error {
+	keys := make([]string, 0, len(reqs))
+	for key := range reqs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		passed, err := requirements.CheckRequirement(key, reqs[key])
+		if passed {
+			continue
+		}
+
+		if err == nil {
+			err = errors.New("not met")
+		}
+
+		return fmt.Errorf("%q requirement %q: %w", key, reqs[key], err)
+	}
+
+	return nil
+}
+
+func checkDeckhouseVersion(requirementValue string, getter requirements.ValueGetter) (bool, error) {
+	constraint, err := semver.NewConstraint(requirementValue)
+	if err != nil {
+		return false, fmt.Errorf("invalid constraint: %w", err)
+	}
+
+	currentVersionRaw, exists := getter.Get(DeckhouseVersionValueKey)
+	if !exists {
+		return false, errors.New("deckhouse version is not discovered yet")
+	}
+
+	currentVersion, err := semver.NewVersion(currentVersionRaw.(string))
+	if err != nil {
+		// dev builds have no semver version, everything is allowed for them
+		return true, nil
+	}
+
+	if !constraint.Check(currentVersion) {
+		return false, fmt.Errorf("current deckhouse version %s does not match", currentVersion)
+	}
+
+	return true, nil
+}
+
+func checkKubernetesVersion(requirementValue string, getter requirements.ValueGetter) (bool, error) {
+	constraint, err := semver.NewConstraint(requirementValue)
+	if err != nil {
+		return false, fmt.Errorf("invalid constraint: %w", err)
+	}
+
+	currentVersionRaw, exists := getter.Get(KubernetesVersionValueKey)
+	if !exists {
+		return false, errors.New("kubernetes version is not discovered yet")
+	}
+
+	currentVersion, err := semver.NewVersion(currentVersionRaw.(string))
+	if err != nil {
+		return false, err
+	}
+
+	if !constraint.Check(currentVersion) {
+		return false, fmt.Errorf("current kubernetes version %s does not match", currentVersion)
+	}
+
+	return true, nil
+}
+
+func checkModules(requirementValue string, getter requirements.ValueGetter) (bool, error) {
+	enabledModulesRaw, exists := getter.Get(EnabledModulesValueKey)
+	if !exists {
+		return false, errors.New("enabled modules are not discovered yet")
+	}
+
+	enabledModules := set.New(enabledModulesRaw.([]string)...)
+
+	var missing []string
+	for _, module := range strings.Split(requirementValue, ",") {
+		module = strings.TrimSpace(module)
+		if module != "" && !enabledModules.Has(module) {
+			missing = append(missing, module)
+		}
+	}
+
+	if len(missing) > 0 {
+		return false, fmt.Errorf("required modules are not enabled: %s", strings.Join(missing, ", "))
+	}
+
+	return true, nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2024 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package requirements
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/deckhouse/deckhouse/deckhouse-controller/pkg/controller/models"
+	"github.com/deckhouse/deckhouse/go_lib/dependency/requirements"
+)
+
+func TestFromDefinition(t *testing.T) {
+	assert.Nil(t, FromDefinition(models.ModuleRequirements{}))
+
+	assert.Equal(t, map[string]string{
+		DeckhouseVersionKey:  ">= 1.58",
+		KubernetesVersionKey: ">= 1.26, <= 1.29",
+		ModulesKey:           "cert-manager,ingress-nginx",
+	}, FromDefinition(models.ModuleRequirements{
+		Deckhouse:  ">= 1.58",
+		Kubernetes: ">= 1.26, <= 1.29",
+		Modules:    []string{"cert-manager", "ingress-nginx"},
+	}))
+}
+
+func TestCheck(t *testing.T) {
+	requirements.SaveValue(DeckhouseVersionValueKey, "v1.58.3")
+	requirements.SaveValue(KubernetesVersionValueKey, "1.27.5")
+	requirements.SaveValue(EnabledModulesValueKey, []string{"cert-manager", "prometheus"})
+	defer func() {
+		requirements.RemoveValue(DeckhouseVersionValueKey)
+		requirements.RemoveValue(KubernetesVersionValueKey)
+		requirements.RemoveValue(EnabledModulesValueKey)
+	}()
+
+	t.Run("met", func(t *testing.T) {
+		require.NoError(t, Check(map[string]string{
+			DeckhouseVersionKey:  ">= 1.58",
+			KubernetesVersionKey: ">= 1.26, <= 1.29",
+			ModulesKey:           "cert-manager",
+		}))
+	})
+
+	t.Run("deckhouse is too old", func(t *testing.T) {
+		err := Check(map[string]string{DeckhouseVersionKey: ">= 1.59"})
+		assert.ErrorContains(t, err, "current deckhouse version 1.58.3 does not match")
+	})
+
+	t.Run("kubernetes is out of range", func(t *testing.T) {
+		err := Check(map[string]string{KubernetesVersionKey: ">= 1.28, <= 1.29"})
+		assert.ErrorContains(t, err, "current kubernetes version 1.27.5 does not match")
+	})
+
+	t.Run("module is not enabled", func(t *testing.T) {
+		err := Check(map[string]string{ModulesKey: "cert-manager,ingress-nginx"})
+		assert.ErrorContains(t, err, "required modules are not enabled: ingress-nginx")
+	})
+
+	t.Run("dev build", func(t *testing.T) {
+		requirements.SaveValue(DeckhouseVersionValueKey, "dev")
+		require.NoError(t, Check(map[string]string{DeckhouseVersionKey: ">= 1.58"}))
+	})
+}
@@ -46,6 +46,7 @@ import (
 	d8listers "github.com/deckhouse/deckhouse/deckhouse-controller/pkg/client/listers/deckhouse.io/v1alpha1"
 	"github.com/deckhouse/deckhouse/deckhouse-controller/pkg/controller/module-controllers/downloader"
 	"github.com/deckhouse/deckhouse/deckhouse-controller/pkg/controller/module-controllers/release"
+	"github.com/deckhouse/deckhouse/deckhouse-controller/pkg/controller/module-controllers/requirements"
 	controllerUtils "github.com/deckhouse/deckhouse/deckhouse-controller/pkg/controller/module-controllers/utils"
 	"github.com/deckhouse/deckhouse/go_lib/dependency/cr"
 )
@@ -128,6 +129,9 @@ func NewController(
 				return
 			}
 
+			// spec is changed (e.g. a trust policy is added), so all modules have to be checked again
+			controller.saveSourceChecksums(newMS.Name, make(moduleChecksum))
+
 			controller.enqueueModuleSource(new)
 		},
 		DeleteFunc: controller.enqueueModuleSource,
@@ -290,24 +294,16 @@ func (c *Controller) createOrUpdateReconcile(ctx context.Context, roMS *v1alpha1
 
 	opts := controllerUtils.GenerateRegistryOptions(ms)
 
-	regCli, err := cr.NewClient(ms.Spec.Registry.Repo, opts...)
+	// modules are listed from the main registry or the first available mirror
+	moduleNames, requeue, err := listSourceModules(ms, cr.NewClient)
 	if err != nil {
 		ms.Status.Msg = err.Error()
 		if e := c.updateModuleSourceStatus(ms); e != nil {
 			return ctrl.Result{Requeue: true}, e
 		}
 
-		// error can occur on wrong auth only, we don't want to requeue the source until auth is fixed
-		return ctrl.Result{Requeue: false}, err
-	}
-
-	moduleNames, err := regCli.ListTags()
-	if err != nil {
-		ms.Status.Msg = err.Error()
-		if e := c.updateModuleSourceStatus(ms); e != nil {
-			return ctrl.Result{Requeue: true}, e
-		}
-		return ctrl.Result{Requeue: true}, err
+		// if the client can't be created because of wrong auth, we don't want to requeue the source until auth is fixed
+		return ctrl.Result{Requeue: requeue}, err
 	}
 
 	// check, by means of comparing registry settings to the checkSum annotation, if new registry settings should be propagated to deployed module release
@@ -504,6 +500,11 @@ func (c *Controller) createModuleRelease(ctx context.Context, ms *v1alpha1.Modul
 	// so make md5 sum here
 	checksum := fmt.Sprintf("%x", md5.Sum([]byte(result.Checksum)))
 
+	var reqs map[string]string
+	if result.ModuleDefinition != nil {
+		reqs = requirements.FromDefinition(result.ModuleDefinition.Requirements)
+	}
+
 	rl := &v1alpha1.ModuleRelease{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "ModuleRelease",
@@ -532,6 +533,8 @@ func (c *Controller) createModuleRelease(ctx context.Context, ms *v1alpha1.Modul
 			Version:    semver.MustParse(result.ModuleVersion),
 			Weight:     result.ModuleWeight,
 			Changelog:  v1alpha1.Changelog(result.Changelog),
+
+			Requirements: reqs,
 		},
 	}
 
@@ -621,11 +624,10 @@ func (c *Controller) getReleasePolicy(sourceName, moduleName string, policies []
 	return matchedPolicy, nil
 }
 
-// checkAndPropagateRegistrySettings checks if modules source registry settings were updated (comparing checksumAnnotation annotation and current registry spec)
+// checkAndPropagateRegistrySettings checks if modules source registry settings were updated (comparing checksumAnnotation annotation and current registry settings)
 // and update relevant module releases' openapi values files if it the case
 func (c *Controller) checkAndPropagateRegistrySettings(msCopy *v1alpha1.ModuleSource) ( /* update required */ bool, error) {
-	// get registry settings checksum
-	currentChecksum := fmt.Sprintf("%x", md5.Sum([]byte(fmt.Sprintf("%s/%s", msCopy.Spec.Registry.Repo, msCopy.Spec.Registry.DockerCFG))))
+	currentChecksum := registrySettingsChecksum(msCopy.Spec.Registry)
 	// if there is no annotations - only set the current checksum value
 	if msCopy.ObjectMeta.Annotations == nil {
 		msCopy.ObjectMeta.Annotations = make(map[string]string)
@@ -662,3 +664,15 @@ func (c *Controller) checkAndPropagateRegistrySettings(msCopy *v1alpha1.ModuleSo
 
 	return true, nil
 }
+
+// registrySettingsChecksum is calculated over the configured endpoints, not the active one,
+// so registry availability changes don't rewrite values of modules and restart them
+func registrySettingsChecksum(registry v1alpha1.ModuleSourceSpecRegistry) string {
+	// the same as before mirrors were introduced, not to update values of all modules after the upgrade
+	data := fmt.Sprintf("%s/%s", registry.Repo, registry.DockerCFG)
+	for _, mirror := range registry.Mirrors {
+		data += fmt.Sprintf("\n%s/%s/%s/%s", mirror.Scheme, mirror.Repo, mirror.DockerCFG, mirror.CA)
+	}
+
+	return fmt.Sprintf("%x", md5.Sum([]byte(data)))
+}
@@ -0,0 +1,112 @@
+// Copyright 2024 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/deckhouse/deckhouse/deckhouse-controller/pkg/apis/deckhouse.io/v1alpha1"
+	controllerUtils "github.com/deckhouse/deckhouse/deckhouse-controller/pkg/controller/module-controllers/utils"
+	"github.com/deckhouse/deckhouse/go_lib/dependency/cr"
+)
+
+// listSourceModules checks the main registry and the mirrors of the source, records their health in the source status
+// and returns the modules of the first available registry, which becomes active.
+// requeue is false if none of the registries can be used until the source is changed, e.g. on wrong credentials.
+func listSourceModules(ms *v1alpha1.ModuleSource, newClient func(repo string, opts ...cr.Option) (cr.Client, error)) ( /*modules*/ []string /*requeue*/, bool, error) {
+	endpoints := ms.Spec.Registry.Endpoints()
+	checkTime := metav1.NewTime(time.Now().UTC())
+
+	var (
+		moduleNames []string
+		active      bool
+		requeue     bool
+	)
+
+	statuses := make([]v1alpha1.ModuleSourceRegistryStatus, 0, len(endpoints))
+	errs := make([]error, 0, len(endpoints))
+
+	for _, endpoint := range endpoints {
+		status := v1alpha1.ModuleSourceRegistryStatus{Repo: endpoint.Repo, LastCheckTime: checkTime}
+
+		names, err := listRegistryModules(endpoint, newClient)
+		if err != nil {
+			// client can't be created on wrong auth only, it makes no sense to retry
+			if _, ok := err.(*registryListError); ok {
+				requeue = true
+			}
+			status.Message = err.Error()
+			statuses = append(statuses, status)
+			errs = append(errs, err)
+			continue
+		}
+
+		status.Ready = true
+		if !active {
+			active = true
+			status.Active = true
+			moduleNames = names
+		}
+		statuses = append(statuses, status)
+	}
+
+	ms.Status.Registries = statuses
+
+	if active {
+		return moduleNames, false, nil
+	}
+
+	if len(errs) == 1 {
+		return nil, requeue, errs[0]
+	}
+
+	msgs := make([]string, 0, len(errs))
+	for i, err := range errs {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", endpoints[i].Repo, err))
+	}
+
+	return nil, requeue, fmt.Errorf("all registries are unavailable: %s", strings.Join(msgs, "; "))
+}
+
+// registryListError is returned if the registry client is created, but the registry doesn't respond
+type registryListError struct {
+	err error
+}
+
+func (e *registryListError) Error() string {
+	return e.err.Error()
+}
+
+func (e *registryListError) Unwrap() error {
+	return e.err
+}
+
+func listRegistryModules(endpoint v1alpha1.ModuleSourceRegistryEndpoint, newClient func(repo string, opts ...cr.Option) (cr.Client, error)) ([]string, error) {
+	regCli, err := newClient(endpoint.Repo, controllerUtils.GenerateRegistryEndpointOptions(endpoint)...)
+	if err != nil {
+		return nil, err
+	}
+
+	moduleNames, err := regCli.ListTags()
+	if err != nil {
+		return nil, &registryListError{err: err}
+	}
+
+	return moduleNames, nil
+}
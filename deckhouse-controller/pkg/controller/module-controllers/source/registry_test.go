@@ -0,0 +1,99 @@
+// Copyright 2024 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/deckhouse/deckhouse/deckhouse-controller/pkg/apis/deckhouse.io/v1alpha1"
+	"github.com/deckhouse/deckhouse/go_lib/dependency/cr"
+)
+
+func TestListSourceModules(t *testing.T) {
+	ms := &v1alpha1.ModuleSource{}
+	ms.Spec.Registry.Repo = "registry.example.com/modules"
+	ms.Spec.Registry.Mirrors = []v1alpha1.ModuleSourceRegistryEndpoint{
+		{Repo: "mirror-1.example.com/modules"},
+		{Repo: "mirror-2.example.com/modules"},
+	}
+
+	newClient := func(tags map[string][]string) func(repo string, _ ...cr.Option) (cr.Client, error) {
+		return func(repo string, _ ...cr.Option) (cr.Client, error) {
+			cli := cr.NewClientMock(t)
+			if names, ok := tags[repo]; ok {
+				cli.ListTagsMock.Return(names, nil)
+			} else {
+				cli.ListTagsMock.Return(nil, errors.New("connection refused"))
+			}
+			return cli, nil
+		}
+	}
+
+	t.Run("main registry is available", func(t *testing.T) {
+		modules, _, err := listSourceModules(ms, newClient(map[string][]string{
+			"registry.example.com/modules": {"echo"},
+			"mirror-2.example.com/modules": {"echo"},
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"echo"}, modules)
+
+		require.Len(t, ms.Status.Registries, 3)
+		assert.True(t, ms.Status.Registries[0].Active)
+		assert.False(t, ms.Status.Registries[1].Ready)
+		assert.Equal(t, "connection refused", ms.Status.Registries[1].Message)
+		assert.True(t, ms.Status.Registries[2].Ready)
+		assert.False(t, ms.Status.Registries[2].Active)
+		assert.Equal(t, "registry.example.com/modules", ms.ActiveRegistry().Repo)
+	})
+
+	t.Run("failover to a mirror", func(t *testing.T) {
+		modules, _, err := listSourceModules(ms, newClient(map[string][]string{
+			"mirror-2.example.com/modules": {"echo", "parca"},
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"echo", "parca"}, modules)
+		assert.False(t, ms.Status.Registries[0].Ready)
+		assert.True(t, ms.Status.Registries[2].Active)
+		assert.Equal(t, "mirror-2.example.com/modules", ms.ActiveRegistry().Repo)
+	})
+
+	t.Run("all registries are unavailable", func(t *testing.T) {
+		_, requeue, err := listSourceModules(ms, newClient(nil))
+		require.Error(t, err)
+		assert.True(t, requeue)
+		assert.ErrorContains(t, err, "mirror-1.example.com/modules: connection refused")
+		assert.Equal(t, "registry.example.com/modules", ms.ActiveRegistry().Repo)
+	})
+}
+
+func TestRegistrySettingsChecksum(t *testing.T) {
+	registry := v1alpha1.ModuleSourceSpecRegistry{Repo: "registry.example.com/modules", DockerCFG: "e30="}
+	// the checksum of a source without mirrors is the same as before mirrors were introduced
+	assert.Equal(t, "57703f6140cb99f95968c9440618e93b", registrySettingsChecksum(registry))
+
+	withMirror := registry
+	withMirror.Mirrors = []v1alpha1.ModuleSourceRegistryEndpoint{{Repo: "mirror.example.com/modules", DockerCFG: "e30="}}
+	checksum := registrySettingsChecksum(withMirror)
+	assert.NotEqual(t, registrySettingsChecksum(registry), checksum)
+
+	// credentials of a mirror are rotated
+	rotated := withMirror
+	rotated.Mirrors = []v1alpha1.ModuleSourceRegistryEndpoint{{Repo: "mirror.example.com/modules", DockerCFG: "e30K"}}
+	assert.NotEqual(t, checksum, registrySettingsChecksum(rotated))
+}
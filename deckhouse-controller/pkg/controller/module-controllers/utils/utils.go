@@ -27,18 +27,23 @@ const (
 
 // GenerateRegistryOptions feteches settings from ModuleSource and generate registry options from them
 func GenerateRegistryOptions(ms *v1alpha1.ModuleSource) []cr.Option {
+	return GenerateRegistryEndpointOptions(ms.Spec.Registry.Endpoints()[0])
+}
+
+// GenerateRegistryEndpointOptions generates registry options for the main registry or a mirror of ModuleSource
+func GenerateRegistryEndpointOptions(endpoint v1alpha1.ModuleSourceRegistryEndpoint) []cr.Option {
 	opts := make([]cr.Option, 0)
-	if ms.Spec.Registry.DockerCFG != "" {
-		opts = append(opts, cr.WithAuth(ms.Spec.Registry.DockerCFG))
+	if endpoint.DockerCFG != "" {
+		opts = append(opts, cr.WithAuth(endpoint.DockerCFG))
 	} else {
 		opts = append(opts, cr.WithDisabledAuth())
 	}
 
-	if ms.Spec.Registry.CA != "" {
-		opts = append(opts, cr.WithCA(ms.Spec.Registry.CA))
+	if endpoint.CA != "" {
+		opts = append(opts, cr.WithCA(endpoint.CA))
 	}
 
-	if ms.Spec.Registry.Scheme == "HTTP" {
+	if endpoint.Scheme == "HTTP" {
 		opts = append(opts, cr.WithInsecureSchema(true))
 	}
 
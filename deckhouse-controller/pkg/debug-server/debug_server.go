@@ -17,14 +17,18 @@ package debugserver
 import (
 	"net/http"
 
-	"github.com/flant/shell-operator/pkg/debug"
+	addon_operator "github.com/flant/addon-operator/pkg/addon-operator"
 
 	"github.com/deckhouse/deckhouse/go_lib/dependency/requirements"
 )
 
-// RegisterRoutes register routes for dumping requirements memory storage
-func RegisterRoutes(dbgSrv *debug.Server) {
-	dbgSrv.RegisterHandler(http.MethodGet, "/requirements", func(req *http.Request) (interface{}, error) {
+// RegisterRoutes register routes for dumping requirements memory storage and for dry-run of ModuleConfig changes
+func RegisterRoutes(op *addon_operator.AddonOperator) {
+	op.DebugServer.RegisterHandler(http.MethodGet, "/requirements", func(req *http.Request) (interface{}, error) {
 		return requirements.DumpValues(), nil
 	})
+
+	op.DebugServer.RegisterHandler(http.MethodPost, "/module/dry-run", func(req *http.Request) (interface{}, error) {
+		return moduleDryRun(op, req)
+	})
 }
@@ -0,0 +1,163 @@
+// Copyright 2024 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debugserver
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"sigs.k8s.io/yaml"
+)
+
+// workloads with a pod template, changing the template leads to the pods restart
+var podTemplateKinds = map[string]struct{}{
+	"Deployment":  {},
+	"DaemonSet":   {},
+	"StatefulSet": {},
+}
+
+type manifestObject map[string]interface{}
+
+func (o manifestObject) key() string {
+	metadata, _ := o["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	namespace, _ := metadata["namespace"].(string)
+
+	if namespace != "" {
+		name = namespace + "/" + name
+	}
+
+	return fmt.Sprintf("%s %s %s", o["apiVersion"], o["kind"], name)
+}
+
+func (o manifestObject) podTemplate() interface{} {
+	spec, _ := o["spec"].(map[string]interface{})
+	return spec["template"]
+}
+
+// parseManifest splits a multi-document helm manifest into objects indexed by apiVersion, kind, namespace and name
+func parseManifest(manifest string) (map[string]manifestObject, error) {
+	objects := make(map[string]manifestObject)
+
+	for _, doc := range releaseutil.SplitManifests(manifest) {
+		var obj manifestObject
+		if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+			return nil, fmt.Errorf("parse manifest: %w", err)
+		}
+
+		if len(obj) == 0 {
+			continue
+		}
+
+		objects[obj.key()] = obj
+	}
+
+	return objects, nil
+}
+
+// diffManifests returns a human-readable resource-level diff between the deployed and rendered manifests
+func diffManifests(deployed, rendered string) (string, error) {
+	deployedObjects, err := parseManifest(deployed)
+	if err != nil {
+		return "", fmt.Errorf("deployed: %w", err)
+	}
+
+	renderedObjects, err := parseManifest(rendered)
+	if err != nil {
+		return "", fmt.Errorf("rendered: %w", err)
+	}
+
+	keys := make([]string, 0, len(deployedObjects)+len(renderedObjects))
+	for key := range deployedObjects {
+		keys = append(keys, key)
+	}
+	for key := range renderedObjects {
+		if _, ok := deployedObjects[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var (
+		summary                   strings.Builder
+		details                   strings.Builder
+		created, changed, deleted int
+	)
+
+	for _, key := range keys {
+		oldObj, existed := deployedObjects[key]
+		newObj, exists := renderedObjects[key]
+
+		switch {
+		case !existed:
+			created++
+			fmt.Fprintf(&summary, "+ %s will be created\n", key)
+
+		case !exists:
+			deleted++
+			fmt.Fprintf(&summary, "- %s will be deleted\n", key)
+
+		case !reflect.DeepEqual(oldObj, newObj):
+			changed++
+			msg := "will be changed"
+			if _, ok := podTemplateKinds[fmt.Sprint(newObj["kind"])]; ok && !reflect.DeepEqual(oldObj.podTemplate(), newObj.podTemplate()) {
+				msg = "will be changed, pods will be restarted"
+			}
+			fmt.Fprintf(&summary, "~ %s %s\n", key, msg)
+
+			diff, err := diffObjects(key, oldObj, newObj)
+			if err != nil {
+				return "", err
+			}
+			details.WriteString(diff)
+		}
+	}
+
+	if created+changed+deleted == 0 {
+		return "No changes.\n", nil
+	}
+
+	fmt.Fprintf(&summary, "\n%d to create, %d to change, %d to delete.\n", created, changed, deleted)
+	if details.Len() > 0 {
+		summary.WriteString("\n")
+		summary.WriteString(details.String())
+	}
+
+	return summary.String(), nil
+}
+
+func diffObjects(key string, oldObj, newObj manifestObject) (string, error) {
+	oldYAML, err := yaml.Marshal(oldObj)
+	if err != nil {
+		return "", err
+	}
+
+	newYAML, err := yaml.Marshal(newObj)
+	if err != nil {
+		return "", err
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(oldYAML)),
+		B:        difflib.SplitLines(string(newYAML)),
+		FromFile: key + " (deployed)",
+		ToFile:   key + " (rendered)",
+		Context:  3,
+	})
+}
@@ -0,0 +1,110 @@
+// Copyright 2024 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debugserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const deployedManifest = `
+---
+# Source: ingress-nginx/templates/daemonset.yaml
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: controller-main
+  namespace: d8-ingress-nginx
+spec:
+  template:
+    spec:
+      containers:
+      - name: controller
+        args: ["--enable-ssl-passthrough=false"]
+---
+# Source: ingress-nginx/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: old-config
+  namespace: d8-ingress-nginx
+data:
+  foo: bar
+---
+# Source: ingress-nginx/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: controller
+  namespace: d8-ingress-nginx
+spec:
+  type: ClusterIP
+`
+
+func TestDiffManifests(t *testing.T) {
+	t.Run("no changes", func(t *testing.T) {
+		diff, err := diffManifests(deployedManifest, deployedManifest)
+		require.NoError(t, err)
+		assert.Equal(t, "No changes.\n", diff)
+	})
+
+	t.Run("changes", func(t *testing.T) {
+		rendered := `
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: controller-main
+  namespace: d8-ingress-nginx
+spec:
+  template:
+    spec:
+      containers:
+      - name: controller
+        args: ["--enable-ssl-passthrough=true"]
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: new-config
+  namespace: d8-ingress-nginx
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: controller
+  namespace: d8-ingress-nginx
+spec:
+  type: LoadBalancer
+`
+		diff, err := diffManifests(deployedManifest, rendered)
+		require.NoError(t, err)
+
+		assert.Contains(t, diff, "~ apps/v1 DaemonSet d8-ingress-nginx/controller-main will be changed, pods will be restarted\n")
+		assert.Contains(t, diff, "+ v1 ConfigMap d8-ingress-nginx/new-config will be created\n")
+		assert.Contains(t, diff, "- v1 ConfigMap d8-ingress-nginx/old-config will be deleted\n")
+		assert.Contains(t, diff, "~ v1 Service d8-ingress-nginx/controller will be changed\n")
+		assert.Contains(t, diff, "1 to create, 2 to change, 1 to delete.")
+		assert.Contains(t, diff, "-  type: ClusterIP\n+  type: LoadBalancer\n")
+	})
+
+	t.Run("module is disabled", func(t *testing.T) {
+		diff, err := diffManifests(deployedManifest, "")
+		require.NoError(t, err)
+		assert.Contains(t, diff, "0 to create, 0 to change, 3 to delete.")
+	})
+}
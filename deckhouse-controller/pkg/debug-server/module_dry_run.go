@@ -0,0 +1,156 @@
+// Copyright 2024 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debugserver
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	addon_operator "github.com/flant/addon-operator/pkg/addon-operator"
+	"github.com/flant/addon-operator/pkg/app"
+	"github.com/flant/addon-operator/pkg/module_manager/models/modules"
+	"github.com/flant/addon-operator/pkg/utils"
+	"github.com/flant/shell-operator/pkg/debug"
+	"sigs.k8s.io/yaml"
+
+	"github.com/deckhouse/deckhouse/deckhouse-controller/pkg/apis/deckhouse.io/v1alpha1"
+	d8config "github.com/deckhouse/deckhouse/go_lib/deckhouse-config"
+	"github.com/deckhouse/deckhouse/go_lib/dependency/helm"
+)
+
+// moduleDryRun validates the ModuleConfig passed in the `config` form field, renders the module with the resulting values
+// and returns changes of the module resources against the deployed helm release.
+// Values patched by the module hooks are taken from the current module values, hooks are not executed.
+func moduleDryRun(op *addon_operator.AddonOperator, r *http.Request) (interface{}, error) {
+	var cfg v1alpha1.ModuleConfig
+	if err := yaml.Unmarshal([]byte(r.PostFormValue("config")), &cfg); err != nil {
+		return nil, &debug.BadRequestError{Msg: fmt.Sprintf("parse ModuleConfig: %v", err)}
+	}
+
+	if cfg.Kind != "" && cfg.Kind != "ModuleConfig" {
+		return nil, &debug.BadRequestError{Msg: fmt.Sprintf("expected ModuleConfig, got %s", cfg.Kind)}
+	}
+
+	moduleName := cfg.GetName()
+	if moduleName == "" {
+		return nil, &debug.BadRequestError{Msg: "ModuleConfig metadata.name is required"}
+	}
+
+	if moduleName == "global" {
+		return nil, &debug.BadRequestError{Msg: "dry-run of the global ModuleConfig is not supported, it affects all modules"}
+	}
+
+	module := op.ModuleManager.GetModule(moduleName)
+	if module == nil {
+		return nil, &debug.BadRequestError{Msg: fmt.Sprintf("module %q not found", moduleName)}
+	}
+
+	var out strings.Builder
+
+	res := d8config.Service().ConfigValidator().Validate(&cfg)
+	if res.HasError() {
+		return nil, &debug.BadRequestError{Msg: fmt.Sprintf("ModuleConfig %s is not valid: %s", moduleName, res.Error)}
+	}
+	if res.Warning != "" {
+		fmt.Fprintf(&out, "Warning: %s\n", res.Warning)
+	}
+
+	// ModuleConfig without spec.enabled keeps the current state of the module
+	enabled := op.ModuleManager.IsModuleEnabled(moduleName)
+	if cfg.Spec.Enabled != nil {
+		enabled = *cfg.Spec.Enabled
+	}
+
+	if enabled {
+		if missing := d8config.Service().MissingModuleDependencies(moduleName); len(missing) > 0 {
+			return nil, &debug.BadRequestError{Msg: fmt.Sprintf("module %s requires disabled modules: %s", moduleName, strings.Join(missing, ", "))}
+		}
+	}
+
+	var rendered string
+	if enabled {
+		settings := d8config.Service().ConfigValidator().SettingsWithDefaults(moduleName, res.Settings)
+
+		var err error
+		rendered, err = renderModule(op, module, settings)
+		if err != nil {
+			return nil, fmt.Errorf("render module %s: %w", moduleName, err)
+		}
+	}
+
+	deployed, err := helm.GetDeployedManifest(app.Namespace, moduleName)
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := diffManifests(deployed, rendered)
+	if err != nil {
+		return nil, err
+	}
+	out.WriteString(diff)
+
+	return out.String(), nil
+}
+
+// renderModule renders the module chart with the current module values where the settings from the config are replaced with the new ones
+func renderModule(op *addon_operator.AddonOperator, module *modules.BasicModule, settings utils.Values) (string, error) {
+	hm, err := modules.NewHelmModule(module, op.ModuleManager.TempDir, &modules.HelmModuleDependencies{HelmClientFactory: op.Helm}, nil)
+	if err != nil {
+		return "", err
+	}
+
+	// module is not a helm chart, there is nothing to render
+	if hm == nil {
+		return "", nil
+	}
+
+	// values of the module are shared with addon-operator, so they are copied before changing
+	values := module.GetValues(false).Copy()
+	for key := range module.GetConfigValues(false) {
+		delete(values, key)
+	}
+	for key, value := range settings {
+		values[key] = value
+	}
+
+	chartValues := utils.Values{
+		"global": op.ModuleManager.GetGlobal().GetValues(false),
+		utils.ModuleNameToValuesKey(module.GetName()): values,
+	}
+
+	data, err := chartValues.YamlBytes()
+	if err != nil {
+		return "", err
+	}
+
+	valuesFile, err := os.CreateTemp(op.ModuleManager.TempDir, filepath.Base(module.GetName())+".dry-run-values-*.yaml")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(valuesFile.Name())
+
+	if _, err = valuesFile.Write(data); err != nil {
+		valuesFile.Close()
+		return "", err
+	}
+	if err = valuesFile.Close(); err != nil {
+		return "", err
+	}
+
+	return op.Helm.NewClient().Render(module.GetName(), module.GetPath(), []string{valuesFile.Name()}, nil, app.Namespace, false)
+}
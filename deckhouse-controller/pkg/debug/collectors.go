@@ -0,0 +1,241 @@
+// Copyright 2024 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debug
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	sh_debug "github.com/flant/shell-operator/pkg/debug"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/deckhouse/deckhouse/go_lib/debugbundle"
+)
+
+const logsTailLines = 3000
+
+var (
+	modulesGVR            = schema.GroupVersionResource{Group: "deckhouse.io", Version: "v1alpha1", Resource: "modules"}
+	nodeGroupsGVR         = schema.GroupVersionResource{Group: "deckhouse.io", Version: "v1", Resource: "nodegroups"}
+	machinesGVR           = schema.GroupVersionResource{Group: "machine.sapcloud.io", Version: "v1alpha1", Resource: "machines"}
+	deckhouseReleasesGVR  = schema.GroupVersionResource{Group: "deckhouse.io", Version: "v1alpha1", Resource: "deckhousereleases"}
+	clusterAlertsGVR      = schema.GroupVersionResource{Group: "deckhouse.io", Version: "v1alpha1", Resource: "clusteralerts"}
+	nodesGVR              = schema.GroupVersionResource{Version: "v1", Resource: "nodes"}
+	deckhouseResourceGVRs = []schema.GroupVersionResource{
+		{Version: "v1", Resource: "pods"},
+		{Version: "v1", Resource: "services"},
+		{Group: "apps", Version: "v1", Resource: "deployments"},
+		{Group: "apps", Version: "v1", Resource: "daemonsets"},
+		{Group: "apps", Version: "v1", Resource: "statefulsets"},
+		{Group: "apps", Version: "v1", Resource: "replicasets"},
+		{Group: "batch", Version: "v1", Resource: "jobs"},
+		{Group: "batch", Version: "v1", Resource: "cronjobs"},
+	}
+)
+
+func init() {
+	register := func(file, module string, collect debugbundle.CollectFunc) {
+		debugbundle.Register(debugbundle.Collector{File: file, Module: module, Collect: collect})
+	}
+
+	register("queue.txt", "", debugSocket("http://unix/queue/list.text?showEmpty=false"))
+	register("global-values.json", "", debugSocket("http://unix/global/values.json"))
+	register("deckhouse-enabled-modules.json", "", listItems(modulesGVR, "", ""))
+	register("events.json", "", collectEvents)
+	register("d8-all.json", "", collectDeckhouseResources)
+	register("node-groups.json", "", listItems(nodeGroupsGVR, "", ""))
+	register("nodes.json", "", listItems(nodesGVR, "", ""))
+	register("deckhouse-version.json", "", collectDeckhouseVersion)
+	register("deckhouse-releases.json", "", listItems(deckhouseReleasesGVR, "", ""))
+	register("deckhouse-logs.json", "", podLogs("d8-system", "app=deckhouse", "deckhouse"))
+	register("ccm-logs.txt", "", collectCloudControllerManagerLogs)
+	register("alerts.json", "", listItems(clusterAlertsGVR, "", ""))
+
+	register("machines.json", "node-manager", listItems(machinesGVR, "d8-cloud-instance-manager", ""))
+	register("mcm-logs.txt", "node-manager", podLogs("d8-cloud-instance-manager", "app=machine-controller-manager", "controller"))
+	register("cluster-autoscaler-logs.txt", "node-manager", podLogs("d8-cloud-instance-manager", "app=cluster-autoscaler", "cluster-autoscaler"))
+	register("vpa-admission-controller-logs.txt", "vertical-pod-autoscaler", podLogs("kube-system", "app=vpa-admission-controller", "admission-controller"))
+	register("vpa-recommender-logs.txt", "vertical-pod-autoscaler", podLogs("kube-system", "app=vpa-recommender", "recommender"))
+	register("vpa-updater-logs.txt", "vertical-pod-autoscaler", podLogs("kube-system", "app=vpa-updater", "updater"))
+	register("prometheus-logs.txt", "prometheus", podLogs("d8-monitoring", "prometheus=main", "prometheus"))
+	register("terraform-check.json", "terraform-manager", collectTerraformCheck)
+}
+
+func debugSocket(url string) debugbundle.CollectFunc {
+	return func(_ context.Context, _ *debugbundle.Env) ([]byte, error) {
+		return sh_debug.DefaultClient().Get(url)
+	}
+}
+
+func listItems(gvr schema.GroupVersionResource, namespace, selector string) debugbundle.CollectFunc {
+	return func(ctx context.Context, env *debugbundle.Env) ([]byte, error) {
+		return env.ListItems(ctx, gvr, namespace, metav1.ListOptions{LabelSelector: selector})
+	}
+}
+
+func podLogs(namespace, selector, container string) debugbundle.CollectFunc {
+	return func(ctx context.Context, env *debugbundle.Env) ([]byte, error) {
+		return env.PodLogs(ctx, namespace, selector, container, logsTailLines)
+	}
+}
+
+// collectEvents returns events of all namespaces sorted by the creation time.
+func collectEvents(ctx context.Context, env *debugbundle.Env) ([]byte, error) {
+	events, err := env.Kube.CoreV1().Events("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list events: %w", err)
+	}
+
+	items := make([]corev1.Event, 0, len(events.Items))
+	for _, event := range events.Items {
+		if !env.Since.IsZero() && eventTime(event).Time.Before(env.Since) {
+			continue
+		}
+		items = append(items, event)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].CreationTimestamp.Before(&items[j].CreationTimestamp)
+	})
+
+	return json.Marshal(items)
+}
+
+func eventTime(event corev1.Event) metav1.Time {
+	switch {
+	case event.Series != nil && !event.Series.LastObservedTime.IsZero():
+		return metav1.Time{Time: event.Series.LastObservedTime.Time}
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp
+	case !event.EventTime.IsZero():
+		return metav1.Time{Time: event.EventTime.Time}
+	}
+	return event.CreationTimestamp
+}
+
+// collectDeckhouseResources returns workloads of namespaces with the heritage=deckhouse label and the kube-system namespace.
+func collectDeckhouseResources(ctx context.Context, env *debugbundle.Env) ([]byte, error) {
+	namespaces, err := env.Kube.CoreV1().Namespaces().List(ctx, metav1.ListOptions{LabelSelector: "heritage=deckhouse"})
+	if err != nil {
+		return nil, fmt.Errorf("list namespaces: %w", err)
+	}
+
+	names := []string{"kube-system"}
+	for _, ns := range namespaces.Items {
+		names = append(names, ns.Name)
+	}
+
+	items := make([]interface{}, 0)
+	for _, ns := range names {
+		for _, gvr := range deckhouseResourceGVRs {
+			list, err := env.Kube.Dynamic().Resource(gvr).Namespace(ns).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("list %s in %s: %w", gvr.Resource, ns, err)
+			}
+			for _, item := range list.Items {
+				items = append(items, item.Object)
+			}
+		}
+	}
+
+	return json.Marshal(items)
+}
+
+func collectDeckhouseVersion(ctx context.Context, env *debugbundle.Env) ([]byte, error) {
+	deployment, err := env.Kube.AppsV1().Deployments("d8-system").Get(ctx, "deckhouse", metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("get deckhouse deployment: %w", err)
+	}
+
+	version := map[string]string{
+		"core.deckhouse.io/edition": deployment.Annotations["core.deckhouse.io/edition"],
+		"core.deckhouse.io/version": deployment.Annotations["core.deckhouse.io/version"],
+	}
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		if container.Name == "deckhouse" {
+			version["image"] = container.Image
+		}
+	}
+
+	return json.Marshal(version)
+}
+
+func collectCloudControllerManagerLogs(ctx context.Context, env *debugbundle.Env) ([]byte, error) {
+	namespaces, err := env.Kube.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list namespaces: %w", err)
+	}
+
+	var buf bytes.Buffer
+	for _, ns := range namespaces.Items {
+		if !strings.HasPrefix(ns.Name, "d8-cloud-provider") {
+			continue
+		}
+
+		logs, err := env.PodLogs(ctx, ns.Name, "app=cloud-controller-manager", "", logsTailLines)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(logs)
+	}
+	return buf.Bytes(), nil
+}
+
+// collectTerraformCheck runs dhctl terraform check in the terraform-state-exporter pod.
+func collectTerraformCheck(ctx context.Context, env *debugbundle.Env) ([]byte, error) {
+	if env.RestConfig == nil {
+		return nil, fmt.Errorf("exec is not supported without kubernetes client config")
+	}
+
+	pods, err := env.Kube.CoreV1().Pods("d8-system").List(ctx, metav1.ListOptions{LabelSelector: "app=terraform-state-exporter"})
+	if err != nil {
+		return nil, fmt.Errorf("list terraform-state-exporter pods: %w", err)
+	}
+	if len(pods.Items) == 0 {
+		return nil, fmt.Errorf("terraform-state-exporter pod not found")
+	}
+
+	req := env.Kube.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace("d8-system").
+		Name(pods.Items[0].Name).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: "exporter",
+			Command:   []string{"dhctl", "terraform", "check", "--logger-type", "json", "-o", "json"},
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(env.RestConfig, "POST", req.URL())
+	if err != nil {
+		return nil, fmt.Errorf("create executor: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	err = executor.StreamWithContext(ctx, remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr})
+	if err != nil {
+		return nil, fmt.Errorf("exec dhctl terraform check: %w: %s", err, stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
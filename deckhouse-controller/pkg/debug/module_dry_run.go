@@ -0,0 +1,48 @@
+// Copyright 2024 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debug
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	sh_debug "github.com/flant/shell-operator/pkg/debug"
+)
+
+// moduleDryRun sends the ModuleConfig to the debug server of the running deckhouse, the module is rendered there
+func moduleDryRun(configPath string) error {
+	var (
+		config []byte
+		err    error
+	)
+
+	if configPath == "-" {
+		config, err = io.ReadAll(os.Stdin)
+	} else {
+		config, err = os.ReadFile(configPath)
+	}
+	if err != nil {
+		return fmt.Errorf("read ModuleConfig: %w", err)
+	}
+
+	out, err := sh_debug.DefaultClient().Post("http://unix/module/dry-run", map[string][]string{"config": {string(config)}})
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(string(out))
+	return nil
+}
@@ -53,6 +53,26 @@ func DefineModuleConfigDebugCommands(kpApp *kingpin.Application) {
 			return moduleSwitch(cli, moduleName, false, "disable")
 		})
 	moduleDisableCmd.Arg("module_name", "").Required().StringVar(&moduleName)
+
+	moduleRollbackCmd := moduleCmd.Command("rollback", "Roll back an external module to the previously deployed ModuleRelease. The current release becomes Suspended.").
+		Action(func(c *kingpin.ParseContext) error {
+			log.SetLevel(log.ErrorLevel)
+			cli := client.New()
+			err := cli.Init()
+			if err != nil {
+				return err
+			}
+
+			return moduleRollback(cli, moduleName)
+		})
+	moduleRollbackCmd.Arg("module_name", "").Required().StringVar(&moduleName)
+
+	var configPath string
+	moduleDryRunCmd := moduleCmd.Command("dry-run", "Validate the ModuleConfig from the file, render the module with the resulting values and show changes of the module resources against the deployed ones. Nothing is applied.").
+		Action(func(c *kingpin.ParseContext) error {
+			return moduleDryRun(configPath)
+		})
+	moduleDryRunCmd.Flag("config", "Path to the file with the ModuleConfig manifest, '-' to read from stdin.").Required().StringVar(&configPath)
 }
 
 func moduleSwitch(kubeClient *client.Client, moduleName string, enabled bool, actionDesc string) error {
@@ -0,0 +1,61 @@
+// Copyright 2024 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debug
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/flant/kube-client/client"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/deckhouse/deckhouse/deckhouse-controller/pkg/apis/deckhouse.io/v1alpha1"
+)
+
+// moduleRollback annotates the deployed release of the module, the rollback itself is done by the ModuleRelease controller
+func moduleRollback(kubeClient *client.Client, moduleName string) error {
+	ctx := context.TODO()
+
+	releases, err := kubeClient.Dynamic().Resource(v1alpha1.ModuleReleaseGVR).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("module=%s,status=deployed", moduleName),
+	})
+	if err != nil {
+		return fmt.Errorf("list ModuleReleases: %w", err)
+	}
+
+	if len(releases.Items) == 0 {
+		return fmt.Errorf("deployed ModuleRelease for the module %q not found", moduleName)
+	}
+
+	patch, _ := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]any{
+				v1alpha1.RollbackAnnotation: "true",
+			},
+		},
+	})
+
+	for _, release := range releases.Items {
+		_, err = kubeClient.Dynamic().Resource(v1alpha1.ModuleReleaseGVR).Patch(ctx, release.GetName(), types.MergePatchType, patch, metav1.PatchOptions{})
+		if err != nil {
+			return fmt.Errorf("annotate ModuleRelease/%s: %w", release.GetName(), err)
+		}
+		fmt.Printf("ModuleRelease %s is marked for rollback\n", release.GetName())
+	}
+
+	return nil
+}
@@ -65,8 +65,14 @@ func mirrorPushDeckhouseToPrivateRegistry() error {
 		RegistryPath:          app.MirrorRegistryPath,
 		DeckhouseRegistryRepo: app.MirrorSourceRegistryRepo,
 		BundlePath:            app.MirrorImagesBundlePath,
-		UnpackedImagesPath:    filepath.Join(app.TmpDirName, time.Now().Format("mirror_tmp_02-01-2006_15-04-05")),
 		ValidationMode:        mirror.ValidationMode(app.MirrorValidationMode),
+		PushWorkers:           app.MirrorPushWorkers,
+		PushProgressPath: filepath.Join(
+			app.TmpDirName,
+			"mirror_push",
+			fmt.Sprintf("%x", md5.Sum([]byte(app.MirrorImagesBundlePath+"|"+app.MirrorRegistry))),
+			"progress.json",
+		),
 	}
 
 	if app.MirrorRegistryUsername != "" {
@@ -76,7 +82,11 @@ func mirrorPushDeckhouseToPrivateRegistry() error {
 		})
 	}
 
-	defer os.RemoveAll(mirrorCtx.UnpackedImagesPath)
+	if app.MirrorDontContinuePartialPush || lastPushWasTooLongAgoToRetry(mirrorCtx) {
+		if err := os.RemoveAll(filepath.Dir(mirrorCtx.PushProgressPath)); err != nil {
+			return fmt.Errorf("Cleanup last unfinished push data: %w", err)
+		}
+	}
 
 	if err := mirror.ValidateWriteAccessForRepo(
 		mirrorCtx.RegistryHost+mirrorCtx.RegistryPath,
@@ -89,14 +99,8 @@ func mirrorPushDeckhouseToPrivateRegistry() error {
 		}
 	}
 
-	err := log.Process("mirror", "Unpacking Deckhouse bundle", func() error {
-		return mirror.UnpackBundle(mirrorCtx)
-	})
-	if err != nil {
-		return err
-	}
-
-	err = log.Process("mirror", "Push Deckhouse images to registry", func() error {
+	// Images are streamed straight from the bundle chunks, the bundle is not unpacked to disk.
+	err := log.Process("mirror", "Push Deckhouse images to registry", func() error {
 		return operations.PushDeckhouseToRegistry(mirrorCtx)
 	})
 	if err != nil {
@@ -146,6 +150,14 @@ func mirrorPullDeckhouseToLocalFilesystem() error {
 
 	var versionsToMirror []semver.Version
 	var err error
+
+	if app.MirrorSinceBundle != "" {
+		mirrorCtx.SinceBundle, err = mirror.LoadBundleManifest(app.MirrorSinceBundle)
+		if err != nil {
+			return fmt.Errorf("Load previous bundle manifest: %w", err)
+		}
+		log.InfoF("Only images and layers missing from the bundle %s will be packed\n", mirrorCtx.SinceBundle.ID)
+	}
 	err = log.Process("mirror", "Looking for required Deckhouse releases", func() error {
 		if mirrorCtx.SpecificVersion != nil {
 			versionsToMirror = append(versionsToMirror, *mirrorCtx.SpecificVersion)
@@ -246,6 +258,15 @@ func lastPullWasTooLongAgoToRetry(mirrorCtx *mirror.Context) bool {
 	return time.Since(s.ModTime()) > 24*time.Hour
 }
 
+func lastPushWasTooLongAgoToRetry(mirrorCtx *mirror.Context) bool {
+	s, err := os.Lstat(mirrorCtx.PushProgressPath)
+	if err != nil {
+		return false
+	}
+
+	return time.Since(s.ModTime()) > 24*time.Hour
+}
+
 func getSourceRegistryAuthProvider() authn.Authenticator {
 	if app.MirrorSourceRegistryLogin != "" {
 		return authn.FromConfig(authn.AuthConfig{
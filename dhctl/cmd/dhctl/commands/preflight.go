@@ -0,0 +1,83 @@
+// Copyright 2024 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/app"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/config"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/preflight"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/system/ssh"
+)
+
+func DefinePreflightCommand(kpApp *kingpin.Application) *kingpin.CmdClause {
+	cmd := kpApp.Command("preflight", "Run all bootstrap preflight checks and print the report.")
+	app.DefineSSHFlags(cmd)
+	app.DefineConfigFlags(cmd)
+	app.DefineBecomeFlags(cmd)
+	app.DefinePreflight(cmd)
+	app.DefinePreflightReportFlags(cmd)
+
+	cmd.Action(func(c *kingpin.ParseContext) error {
+		metaConfig, err := config.LoadConfigFromFile(app.ConfigPath)
+		if err != nil {
+			return err
+		}
+
+		installConfig, err := config.PrepareDeckhouseInstallConfig(metaConfig)
+		if err != nil {
+			return err
+		}
+
+		var sshClient *ssh.Client
+		if metaConfig.ClusterType != config.CloudClusterType {
+			sshClient, err = ssh.NewInitClientFromFlagsWithHosts(true)
+			if err != nil {
+				return err
+			}
+			defer sshClient.Stop()
+		}
+
+		checker := preflight.NewChecker(sshClient, installConfig, metaConfig)
+		report := checker.RunAll()
+
+		var out io.Writer = os.Stdout
+		if app.PreflightReportPath != "" {
+			f, err := os.Create(app.PreflightReportPath)
+			if err != nil {
+				return fmt.Errorf("create report file: %w", err)
+			}
+			defer f.Close()
+			out = f
+		}
+
+		if err := report.Write(out, app.PreflightReportFormat); err != nil {
+			return fmt.Errorf("write report: %w", err)
+		}
+
+		if report.Failed() {
+			return fmt.Errorf("Preflight checks failed")
+		}
+
+		return nil
+	})
+
+	return cmd
+}
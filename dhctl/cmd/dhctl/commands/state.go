@@ -0,0 +1,92 @@
+// Copyright 2024 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/app"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/state/cache"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/util/input"
+)
+
+func DefineStateHistoryCommand(parent *kingpin.CmdClause) *kingpin.CmdClause {
+	cmd := parent.Command("history", "Show previous revisions of the state stored in kubernetes secrets.")
+	app.DefineCacheFlags(cmd)
+
+	cmd.Action(func(c *kingpin.ParseContext) error {
+		stateCache, err := cache.KubernetesStateCache()
+		if err != nil {
+			return err
+		}
+
+		history, err := stateCache.History()
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "REVISION\tCREATED\tREASON\tKEYS")
+		for _, rev := range history {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", rev.Revision, rev.CreatedAt.Format(time.RFC3339), rev.Reason, strings.Join(rev.Keys, ","))
+		}
+
+		return w.Flush()
+	})
+	return cmd
+}
+
+func DefineStateRollbackCommand(parent *kingpin.CmdClause) *kingpin.CmdClause {
+	var revision int
+
+	cmd := parent.Command("rollback", "Restore the state from the previous revision. The current state is saved as a new revision.")
+	app.DefineCacheFlags(cmd)
+	app.DefineSanityFlags(cmd)
+	cmd.Flag("revision", "Revision number to restore (see \"dhctl state history\").").
+		Required().
+		IntVar(&revision)
+
+	cmd.Action(func(c *kingpin.ParseContext) error {
+		stateCache, err := cache.KubernetesStateCache()
+		if err != nil {
+			return err
+		}
+
+		if !app.SanityCheck {
+			approve := input.NewConfirmation().
+				WithMessage(fmt.Sprintf("Do you want to replace the state %s/%s with revision %d?", app.CacheKubeNamespace, app.CacheKubeName, revision)).
+				Ask()
+			if !approve {
+				return fmt.Errorf("Don't confirm state rollback")
+			}
+		}
+
+		err = stateCache.Rollback(revision)
+		if err != nil {
+			return err
+		}
+
+		log.InfoF("State %s/%s was restored from revision %d\n", app.CacheKubeNamespace, app.CacheKubeName, revision)
+		return nil
+	})
+	return cmd
+}
@@ -68,6 +68,7 @@ func main() {
 	}
 
 	bootstrap.DefineBootstrapCommand(kpApp)
+	commands.DefinePreflightCommand(kpApp)
 	bootstrapPhaseCmd := kpApp.Command("bootstrap-phase", "Commands to run a single phase of the bootstrap process.")
 	{
 		bootstrap.DefineBootstrapExecuteBashibleCommand(bootstrapPhaseCmd)
@@ -88,6 +89,12 @@ func main() {
 
 	commands.DefineDestroyCommand(kpApp)
 
+	stateCmd := kpApp.Command("state", "Inspect and restore the state stored in kubernetes secrets.")
+	{
+		commands.DefineStateHistoryCommand(stateCmd)
+		commands.DefineStateRollbackCommand(stateCmd)
+	}
+
 	terraformCmd := kpApp.Command("terraform", "Terraform commands.")
 	{
 		commands.DefineTerraformConvergeExporterCommand(terraformCmd)
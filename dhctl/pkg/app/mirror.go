@@ -21,6 +21,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 
 	"github.com/Masterminds/semver/v3"
 	"gopkg.in/alecthomas/kingpin.v2"
@@ -66,8 +67,12 @@ var (
 
 	MirrorDoGOSTDigest            bool
 	MirrorDontContinuePartialPull bool
+	MirrorDontContinuePartialPush bool
+	MirrorPushWorkers             = 4
 
 	MirrorWithoutModules bool
+
+	MirrorSinceBundle string
 )
 
 func DefineMirrorFlags(cmd *kingpin.CmdClause) {
@@ -131,6 +136,16 @@ func DefineMirrorFlags(cmd *kingpin.CmdClause) {
 		BoolVar(&MirrorDoGOSTDigest)
 	cmd.Flag("no-pull-resume", "Do not continue last unfinished pull operation.").
 		BoolVar(&MirrorDontContinuePartialPull)
+	cmd.Flag("since-bundle", "Path to the previous bundle or to its manifest. Only images and layers missing from it are written to the new bundle.").
+		Envar(configEnvName("MIRROR_SINCE_BUNDLE")).
+		PlaceHolder("PATH").
+		StringVar(&MirrorSinceBundle)
+	cmd.Flag("no-push-resume", "Do not continue last unfinished push operation.").
+		BoolVar(&MirrorDontContinuePartialPush)
+	cmd.Flag("push-workers", "Number of blobs uploaded to the registry concurrently.").
+		Envar(configEnvName("MIRROR_PUSH_WORKERS")).
+		Default(strconv.Itoa(MirrorPushWorkers)).
+		IntVar(&MirrorPushWorkers)
 	cmd.Flag("no-modules", "Do not pull Deckhouse modules into bundle.").
 		BoolVar(&MirrorWithoutModules)
 	cmd.Flag("tls-skip-verify", "Disable TLS certificate validation.").
@@ -155,6 +170,12 @@ func DefineMirrorFlags(cmd *kingpin.CmdClause) {
 		if err = validateChunkSizeFlag(); err != nil {
 			return err
 		}
+		if err = validatePushWorkersFlag(); err != nil {
+			return err
+		}
+		if err = validateSinceBundleFlag(); err != nil {
+			return err
+		}
 
 		return nil
 	})
@@ -237,3 +258,28 @@ func validateChunkSizeFlag() error {
 
 	return nil
 }
+
+func validatePushWorkersFlag() error {
+	if MirrorPushWorkers < 1 {
+		return errors.New("Number of push workers cannot be less than one")
+	}
+
+	return nil
+}
+
+func validateSinceBundleFlag() error {
+	if MirrorSinceBundle == "" {
+		return nil
+	}
+
+	if MirrorRegistry != "" {
+		return errors.New("--since-bundle is used to pull a delta bundle and cannot be used with --registry")
+	}
+
+	MirrorSinceBundle = filepath.Clean(MirrorSinceBundle)
+	if filepath.Ext(MirrorSinceBundle) != ".tar" && filepath.Ext(MirrorSinceBundle) != ".json" {
+		return errors.New("--since-bundle should be a path to the bundle (.tar) or to its manifest (.json)")
+	}
+
+	return nil
+}
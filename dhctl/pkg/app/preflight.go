@@ -14,7 +14,11 @@
 
 package app
 
-import "gopkg.in/alecthomas/kingpin.v2"
+import (
+	"fmt"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+)
 
 var (
 	PreflightSkipAll                   = false
@@ -23,6 +27,16 @@ var (
 	PreflightSkipResolvingLocalhost    = false
 	PreflightSkipDeckhouseVersionCheck = false
 	PreflightSkipRegistryThroughProxy  = false
+	PreflightSkipClockSkew             = false
+	PreflightSkipKernelVersion         = false
+	PreflightSkipDiskSpace             = false
+	PreflightSkipPublicDomainTemplate  = false
+	PreflightSkipCIDRIntersection      = false
+	PreflightSkipRequiredBinaries      = false
+
+	PreflightSkipChecks   = make([]string, 0)
+	PreflightReportFormat = "text"
+	PreflightReportPath   = ""
 )
 
 const (
@@ -31,6 +45,13 @@ const (
 	ResolvingLocalhostArgName        = "preflight-skip-resolving-localhost-check"
 	DeckhouseVersionCheckArgName     = "preflight-skip-deckhouse-version-check"
 	RegistryThroughProxyCheckArgName = "preflight-skip-registry-through-proxy"
+	ClockSkewArgName                 = "preflight-skip-clock-skew-check"
+	KernelVersionArgName             = "preflight-skip-kernel-version-check"
+	DiskSpaceArgName                 = "preflight-skip-disk-space-check"
+	PublicDomainTemplateArgName      = "preflight-skip-public-domain-template-check"
+	CIDRIntersectionArgName          = "preflight-skip-cidr-intersection-check"
+	RequiredBinariesArgName          = "preflight-skip-required-binaries-check"
+	PreflightSkipCheckArgName        = "preflight-skip-check"
 )
 
 func DefinePreflight(cmd *kingpin.CmdClause) {
@@ -52,4 +73,44 @@ func DefinePreflight(cmd *kingpin.CmdClause) {
 	cmd.Flag(RegistryThroughProxyCheckArgName, "Skip verifying deckhouse version").
 		Envar(configEnvName("PREFLIGHT_SKIP_REGISTRY_THROUGH_PROXY")).
 		BoolVar(&PreflightSkipRegistryThroughProxy)
+	cmd.Flag(ClockSkewArgName, "Skip verifying clock skew between the node and the local machine").
+		Envar(configEnvName("PREFLIGHT_SKIP_CLOCK_SKEW_CHECK")).
+		BoolVar(&PreflightSkipClockSkew)
+	cmd.Flag(KernelVersionArgName, "Skip verifying the node kernel version").
+		Envar(configEnvName("PREFLIGHT_SKIP_KERNEL_VERSION_CHECK")).
+		BoolVar(&PreflightSkipKernelVersion)
+	cmd.Flag(DiskSpaceArgName, "Skip verifying free disk space on the node").
+		Envar(configEnvName("PREFLIGHT_SKIP_DISK_SPACE_CHECK")).
+		BoolVar(&PreflightSkipDiskSpace)
+	cmd.Flag(PublicDomainTemplateArgName, "Skip resolving the public domain template on the node").
+		Envar(configEnvName("PREFLIGHT_SKIP_PUBLIC_DOMAIN_TEMPLATE_CHECK")).
+		BoolVar(&PreflightSkipPublicDomainTemplate)
+	cmd.Flag(CIDRIntersectionArgName, "Skip verifying intersection of pod and service subnets with the node routes").
+		Envar(configEnvName("PREFLIGHT_SKIP_CIDR_INTERSECTION_CHECK")).
+		BoolVar(&PreflightSkipCIDRIntersection)
+	cmd.Flag(RequiredBinariesArgName, "Skip verifying presence of required binaries on the node").
+		Envar(configEnvName("PREFLIGHT_SKIP_REQUIRED_BINARIES_CHECK")).
+		BoolVar(&PreflightSkipRequiredBinaries)
+	cmd.Flag(PreflightSkipCheckArgName, "Skip preflight check by ID (ssh-forward, registry-through-proxy, ports-availability, localhost-domain, "+
+		"clock-skew, kernel-version, disk-space, public-domain-template, cidr-intersection, required-binaries, deckhouse-version). Can be specified multiple times").
+		Envar(configEnvName("PREFLIGHT_SKIP_CHECK")).
+		StringsVar(&PreflightSkipChecks)
+}
+
+func DefinePreflightReportFlags(cmd *kingpin.CmdClause) {
+	cmd.Flag("preflight-report-format", "Format of the preflight checks report").
+		Envar(configEnvName("PREFLIGHT_REPORT_FORMAT")).
+		Default("text").
+		EnumVar(&PreflightReportFormat, "text", "json", "junit")
+	cmd.Flag("preflight-report-path", "Path to the file to write the preflight checks report to. "+
+		"Text report is printed to stdout if not set, json and junit reports require the path to not be mixed with logs").
+		Envar(configEnvName("PREFLIGHT_REPORT_PATH")).
+		StringVar(&PreflightReportPath)
+
+	cmd.PreAction(func(c *kingpin.ParseContext) error {
+		if PreflightReportFormat != "text" && PreflightReportPath == "" {
+			return fmt.Errorf("--preflight-report-path is required for the %s report format", PreflightReportFormat)
+		}
+		return nil
+	})
 }
@@ -40,6 +40,7 @@ var (
 	CacheKubeNamespace       = ""
 	CacheKubeName            = ""
 	CacheKubeLabels          = make(map[string]string)
+	CacheKubeHistoryLimit    = 10
 )
 
 func DefineCacheFlags(cmd *kingpin.CmdClause) {
@@ -74,6 +75,10 @@ func DefineCacheFlags(cmd *kingpin.CmdClause) {
 	cmd.Flag("kube-cachestore-name", "Name for cache secret").
 		Envar(configEnvName("CACHE_STORE_KUBE_NAME")).
 		StringVar(&CacheKubeName)
+	cmd.Flag("kube-cachestore-history-limit", "Number of previous terraform state revisions to keep in kubernetes secrets. 0 disables the history").
+		Envar(configEnvName("CACHE_STORE_KUBE_HISTORY_LIMIT")).
+		Default("10").
+		IntVar(&CacheKubeHistoryLimit)
 }
 
 func DefineDropCacheFlags(cmd *kingpin.CmdClause) {
@@ -19,22 +19,28 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/gob"
+	"errors"
 	"fmt"
 	"path/filepath"
 	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	typedv1core "k8s.io/client-go/kubernetes/typed/core/v1"
-	kuberetry "k8s.io/client-go/util/retry"
 
 	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/state"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/util/retry"
 )
 
+// ErrStateConflict is returned when the state secret was changed by somebody else
+// after it had been read or written by this cache.
+var ErrStateConflict = errors.New("state was changed by another process, restart the operation to get the actual state")
+
 func labelKey(name string) string {
 	return fmt.Sprintf("dhctl.deckhouse.io/%s", name)
 }
@@ -47,6 +53,18 @@ type StateCache struct {
 	secretName string
 	namespace  string
 	tmpDir     string
+
+	historyLimit int
+	// revisionSaved is set after the first change with a reason, a revision is kept once per dhctl operation
+	revisionSaved bool
+
+	// existingOnly forbids creating the state secret, it is set for caches opened to inspect or restore the state
+	existingOnly bool
+
+	// all changes are serialized to keep observed resourceVersion consistent
+	mu              sync.Mutex
+	resourceVersion string
+	versionObserved bool
 }
 
 func NewK8sStateCache(client *KubernetesClient, namespace, secretName, tmpDir string) *StateCache {
@@ -56,12 +74,40 @@ func NewK8sStateCache(client *KubernetesClient, namespace, secretName, tmpDir st
 		tmpDir:     tmpDir,
 		labels:     make(map[string]string),
 		secretName: secretName,
+		namespace:  namespace,
 	}
 }
 
 func (c *StateCache) Init() error {
-	_, err := c.populateSecret()
-	return err
+	s, err := c.populateSecret()
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.observeVersion(s)
+
+	return nil
+}
+
+// Open observes the existing state secret, unlike Init it never creates the secret:
+// it is used to inspect or restore the state without writing to the cluster
+func (c *StateCache) Open() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.existingOnly = true
+
+	s, err := c.getSecret()
+	if err != nil {
+		return err
+	}
+
+	c.observeVersion(s)
+
+	return nil
 }
 
 func (c *StateCache) WithLabels(labels map[string]string) *StateCache {
@@ -69,8 +115,29 @@ func (c *StateCache) WithLabels(labels map[string]string) *StateCache {
 	return c
 }
 
+// WithHistoryLimit enables keeping previous terraform states revisions in separate secrets,
+// the state is saved as a revision before the first terraform state change made through the cache
+func (c *StateCache) WithHistoryLimit(limit int) *StateCache {
+	c.historyLimit = limit
+	return c
+}
+
+func (c *StateCache) observeVersion(s *v1.Secret) {
+	c.resourceVersion = s.ResourceVersion
+	c.versionObserved = true
+}
+
 func (c *StateCache) getSecret() (*v1.Secret, error) {
-	s, err := c.populateSecret()
+	var s *v1.Secret
+	var err error
+	if c.existingOnly {
+		s, err = c.readSecret()
+		if err == nil && s == nil {
+			err = fmt.Errorf("state secret %s/%s not found", c.namespace, c.secretName)
+		}
+	} else {
+		s, err = c.populateSecret()
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -82,7 +149,8 @@ func (c *StateCache) getSecret() (*v1.Secret, error) {
 	return s, nil
 }
 
-func (c *StateCache) populateSecret() (*v1.Secret, error) {
+// readSecret returns the state secret or nil if it does not exist
+func (c *StateCache) readSecret() (*v1.Secret, error) {
 	var secret *v1.Secret
 	var lastError error
 	err := retry.NewSilentLoop("get cache secret", 3, 2*time.Second).Run(func() error {
@@ -105,6 +173,15 @@ func (c *StateCache) populateSecret() (*v1.Secret, error) {
 		return nil, lastError
 	}
 
+	return secret, nil
+}
+
+func (c *StateCache) populateSecret() (*v1.Secret, error) {
+	secret, err := c.readSecret()
+	if err != nil {
+		return nil, err
+	}
+
 	if secret != nil {
 		return secret, nil
 	}
@@ -120,6 +197,7 @@ func (c *StateCache) populateSecret() (*v1.Secret, error) {
 		preparedLabels[k] = v
 	}
 
+	var lastError error
 	err = retry.NewSilentLoop("save cache secret", 3, 2*time.Second).Run(func() error {
 		var err error
 
@@ -149,19 +227,49 @@ func (c *StateCache) populateSecret() (*v1.Secret, error) {
 	return secret, nil
 }
 
+// update changes the state secret with compare-and-swap semantics:
+// it fails if the secret was changed since the last observation by this cache
 func (c *StateCache) update(action func(map[string][]byte) map[string][]byte) error {
-	return kuberetry.RetryOnConflict(kuberetry.DefaultBackoff, func() error {
-		s, err := c.getSecret()
-		if err != nil {
-			return err
-		}
+	return c.updateWithRevision("", action)
+}
+
+// updateWithRevision saves the current state as a history revision before the first change with not empty reason
+func (c *StateCache) updateWithRevision(reason string, action func(map[string][]byte) map[string][]byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s, err := c.getSecret()
+	if err != nil {
+		return err
+	}
+
+	if c.versionObserved && s.ResourceVersion != c.resourceVersion {
+		return fmt.Errorf("%w: secret %s resourceVersion %s, expected %s", ErrStateConflict, c.secretName, s.ResourceVersion, c.resourceVersion)
+	}
 
-		s.Data = action(s.Data)
+	if reason != "" && c.historyLimit > 0 && !c.revisionSaved {
+		if len(s.Data) > 0 {
+			if err := c.saveRevision(s, reason); err != nil {
+				// history is an auxiliary feature, do not break converge because of it
+				log.WarnF("Cannot save state revision: %v\n", err)
+			}
+		}
+		c.revisionSaved = true
+	}
 
-		_, err = c.secretsAPI.Update(context.TODO(), s, metav1.UpdateOptions{})
+	s.Data = action(s.Data)
 
+	updated, err := c.secretsAPI.Update(context.TODO(), s, metav1.UpdateOptions{})
+	if err != nil {
+		if apierrors.IsConflict(err) {
+			return fmt.Errorf("%w: %v", ErrStateConflict, err)
+		}
 		return err
-	})
+	}
+
+	c.observeVersion(updated)
+
+	return nil
 }
 
 func (c *StateCache) get(s *v1.Secret, key string) ([]byte, error) {
@@ -186,7 +294,12 @@ func (c *StateCache) prepareContent(content []byte) []byte {
 func (c *StateCache) Save(name string, content []byte) error {
 	buf := c.prepareContent(content)
 
-	return c.update(func(curState map[string][]byte) map[string][]byte {
+	var reason string
+	if strings.HasSuffix(name, ".tfstate") {
+		reason = fmt.Sprintf("save %s", name)
+	}
+
+	return c.updateWithRevision(reason, func(curState map[string][]byte) map[string][]byte {
 		curState[name] = buf
 		return curState
 	})
@@ -0,0 +1,165 @@
+// Copyright 2024 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
+)
+
+const revisionReasonAnnotation = "dhctl.deckhouse.io/revision-reason"
+
+// StateRevision is a previous state of the cache stored in a separate secret
+type StateRevision struct {
+	Revision  int
+	CreatedAt time.Time
+	Reason    string
+	Keys      []string
+}
+
+func (c *StateCache) revisionSecretName(revision int) string {
+	return fmt.Sprintf("%s-rev-%d", c.secretName, revision)
+}
+
+func (c *StateCache) revisionsSelector() string {
+	return labels.SelectorFromSet(labels.Set{
+		labelKey("state-history"): "true",
+		labelKey("cluster-name"):  c.secretName,
+	}).String()
+}
+
+func (c *StateCache) listRevisions() ([]v1.Secret, error) {
+	list, err := c.secretsAPI.List(context.TODO(), metav1.ListOptions{LabelSelector: c.revisionsSelector()})
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := list.Items
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisionNumber(&revisions[i]) < revisionNumber(&revisions[j])
+	})
+
+	return revisions, nil
+}
+
+func revisionNumber(s *v1.Secret) int {
+	n, _ := strconv.Atoi(s.Labels[labelKey("revision")])
+	return n
+}
+
+// saveRevision copies the state secret data into a new revision secret and removes the oldest revisions
+func (c *StateCache) saveRevision(s *v1.Secret, reason string) error {
+	revisions, err := c.listRevisions()
+	if err != nil {
+		return err
+	}
+
+	next := 1
+	if len(revisions) > 0 {
+		next = revisionNumber(&revisions[len(revisions)-1]) + 1
+	}
+
+	revisionLabels := map[string]string{
+		"heritage": "dhctl-job",
+
+		labelKey("state-history"): "true",
+		labelKey("cluster-name"):  c.secretName,
+		labelKey("revision"):      strconv.Itoa(next),
+	}
+
+	for k, v := range c.labels {
+		revisionLabels[k] = v
+	}
+
+	data := make(map[string][]byte, len(s.Data))
+	for k, v := range s.Data {
+		data[k] = v
+	}
+
+	_, err = c.secretsAPI.Create(context.TODO(), &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        c.revisionSecretName(next),
+			Namespace:   c.namespace,
+			Labels:      revisionLabels,
+			Annotations: map[string]string{revisionReasonAnnotation: reason},
+		},
+		Data: data,
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return err
+	}
+
+	// new revision is not in the list yet
+	for i := 0; i < len(revisions)+1-c.historyLimit; i++ {
+		err := c.secretsAPI.Delete(context.TODO(), revisions[i].Name, metav1.DeleteOptions{})
+		if err != nil {
+			log.WarnF("Cannot delete state revision %s: %v\n", revisions[i].Name, err)
+		}
+	}
+
+	return nil
+}
+
+// History returns stored previous revisions of the state, the oldest first
+func (c *StateCache) History() ([]StateRevision, error) {
+	secrets, err := c.listRevisions()
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]StateRevision, 0, len(secrets))
+	for i := range secrets {
+		keys := make([]string, 0, len(secrets[i].Data))
+		for k := range secrets[i].Data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		history = append(history, StateRevision{
+			Revision:  revisionNumber(&secrets[i]),
+			CreatedAt: secrets[i].CreationTimestamp.Time,
+			Reason:    secrets[i].Annotations[revisionReasonAnnotation],
+			Keys:      keys,
+		})
+	}
+
+	return history, nil
+}
+
+// Rollback restores the state from the revision, the current state is saved as a new revision
+func (c *StateCache) Rollback(revision int) error {
+	revisionSecret, err := c.secretsAPI.Get(context.TODO(), c.revisionSecretName(revision), metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("cannot get state revision %d: %w", revision, err)
+	}
+
+	return c.updateWithRevision(fmt.Sprintf("rollback to revision %d", revision), func(map[string][]byte) map[string][]byte {
+		data := make(map[string][]byte, len(revisionSecret.Data))
+		for k, v := range revisionSecret.Data {
+			data[k] = v
+		}
+
+		return data
+	})
+}
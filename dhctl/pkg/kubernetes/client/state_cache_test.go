@@ -97,4 +97,106 @@ func TestDeckhouseInstall(t *testing.T) {
 
 		tests.RunStateCacheTests(t, cacheState)
 	})
+	t.Run("returns conflict error when secret was changed by another process", func(t *testing.T) {
+		fakeClient := NewFakeKubernetesClient()
+
+		namespace := "test-ns"
+		name := "tst-state"
+
+		k8sCache := NewK8sStateCache(fakeClient, namespace, name, "/tmp/dhctl_tst")
+		err := k8sCache.Init()
+		require.NoError(t, err)
+
+		err = k8sCache.Save("first", []byte("first"))
+		require.NoError(t, err)
+
+		secret, err := fakeClient.CoreV1().Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+
+		secret.ResourceVersion = "another-process"
+		_, err = fakeClient.CoreV1().Secrets(namespace).Update(context.TODO(), secret, metav1.UpdateOptions{})
+		require.NoError(t, err)
+
+		err = k8sCache.Save("second", []byte("second"))
+		require.ErrorIs(t, err, ErrStateConflict)
+
+		inCache, err := k8sCache.InCache("second")
+		require.NoError(t, err)
+		require.False(t, inCache)
+	})
+
+	t.Run("does not create secret when opening existing state", func(t *testing.T) {
+		fakeClient := NewFakeKubernetesClient()
+
+		namespace := "test-ns"
+		name := "tst-state"
+
+		k8sCache := NewK8sStateCache(fakeClient, namespace, name, "/tmp/dhctl_tst")
+
+		err := k8sCache.Open()
+		require.Error(t, err)
+
+		err = k8sCache.Rollback(1)
+		require.Error(t, err)
+
+		secrets, err := fakeClient.CoreV1().Secrets(namespace).List(context.TODO(), metav1.ListOptions{})
+		require.NoError(t, err)
+		require.Empty(t, secrets.Items)
+	})
+
+	t.Run("keeps limited history of terraform states and rollbacks to revision", func(t *testing.T) {
+		fakeClient := NewFakeKubernetesClient()
+
+		namespace := "test-ns"
+		name := "tst-state"
+
+		var k8sCache *StateCache
+		// every cache is a separate dhctl operation, e.g. converge
+		for _, operation := range [][]string{{"v1", "v2"}, {"v3", "v4"}, {"v5"}, {"v6", "v7"}} {
+			k8sCache = NewK8sStateCache(fakeClient, namespace, name, "/tmp/dhctl_tst").WithHistoryLimit(2)
+			err := k8sCache.Init()
+			require.NoError(t, err)
+
+			for _, content := range operation {
+				err = k8sCache.Save("base-infrastructure.tfstate", []byte(content))
+				require.NoError(t, err)
+			}
+		}
+
+		// not a terraform state, revision is not created
+		err := k8sCache.Save("uuid", []byte("uuid"))
+		require.NoError(t, err)
+
+		// the state before every operation except the first one, it starts with the empty state
+		history, err := k8sCache.History()
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		require.Equal(t, 2, history[0].Revision)
+		require.Equal(t, 3, history[1].Revision)
+		require.Equal(t, "save base-infrastructure.tfstate", history[1].Reason)
+		require.Equal(t, []string{"base-infrastructure.tfstate"}, history[1].Keys)
+
+		// a separate cache to inspect and restore the state, like "dhctl state rollback" does
+		k8sCache = NewK8sStateCache(fakeClient, namespace, name, "/tmp/dhctl_tst").WithHistoryLimit(2)
+		err = k8sCache.Open()
+		require.NoError(t, err)
+
+		err = k8sCache.Rollback(3)
+		require.NoError(t, err)
+
+		content, err := k8sCache.Load("base-infrastructure.tfstate")
+		require.NoError(t, err)
+		require.Equal(t, []byte("v5"), content)
+
+		inCache, err := k8sCache.InCache("uuid")
+		require.NoError(t, err)
+		require.False(t, inCache)
+
+		// the state before rollback is saved as a new revision
+		history, err = k8sCache.History()
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		require.Equal(t, 4, history[1].Revision)
+		require.Equal(t, "rollback to revision 3", history[1].Reason)
+	})
 }
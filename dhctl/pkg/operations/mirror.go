@@ -15,9 +15,7 @@
 package operations
 
 import (
-	"errors"
 	"fmt"
-	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
@@ -150,20 +148,42 @@ func validateLayoutsIfRequired(layouts *mirror.ImageLayouts, validationMode mirr
 }
 
 func PushDeckhouseToRegistry(mirrorCtx *mirror.Context) error {
+	storage, err := openLayoutsStorage(mirrorCtx)
+	if err != nil {
+		return fmt.Errorf("Open images bundle: %w", err)
+	}
+	if bundle, ok := storage.(*mirror.Bundle); ok {
+		defer bundle.Close()
+	}
+
 	log.InfoF("Find Deckhouse images to push...\t")
-	ociLayouts, modulesList, err := findLayoutsToPush(mirrorCtx)
+	ociLayouts, modulesList, err := findLayoutsToPush(mirrorCtx, storage)
 	if err != nil {
 		return fmt.Errorf("Find OCI Image Layouts to push: %w", err)
 	}
 	log.InfoLn("✅")
 
+	progress, err := mirror.LoadPushProgress(mirrorCtx.PushProgressPath)
+	if err != nil {
+		return err
+	}
+
+	if bundle, ok := storage.(*mirror.Bundle); ok {
+		if err = prepareDeltaBundlePush(mirrorCtx, bundle, progress); err != nil {
+			return err
+		}
+	}
+
 	refOpts, remoteOpts := mirror.MakeRemoteRegistryRequestOptionsFromMirrorContext(mirrorCtx)
+	if mirrorCtx.PushWorkers > 0 {
+		remoteOpts = append(remoteOpts, remote.WithJobs(mirrorCtx.PushWorkers))
+	}
 
-	for originalRepo, ociLayout := range ociLayouts {
+	for originalRepo, layoutPath := range ociLayouts {
 		log.InfoLn("Mirroring", originalRepo)
-		index, err := ociLayout.ImageIndex()
+		index, err := storage.ImageIndex(layoutPath)
 		if err != nil {
-			return fmt.Errorf("read image index from %s: %w", ociLayout, err)
+			return fmt.Errorf("read image index for %s: %w", originalRepo, err)
 		}
 
 		indexManifest, err := index.IndexManifest()
@@ -177,6 +197,12 @@ func PushDeckhouseToRegistry(mirrorCtx *mirror.Context) error {
 			tag := manifest.Annotations["io.deckhouse.image.short_tag"]
 			imageRef := repo + ":" + tag
 
+			if progress.IsPushed(imageRef, manifest.Digest) {
+				log.InfoF("[%d / %d] Image %s is already pushed, skipping\n", pushCount, len(indexManifest.Manifests), imageRef)
+				pushCount++
+				continue
+			}
+
 			img, err := index.Image(manifest.Digest)
 			if err != nil {
 				return fmt.Errorf("read image: %w", err)
@@ -187,12 +213,14 @@ func PushDeckhouseToRegistry(mirrorCtx *mirror.Context) error {
 				return fmt.Errorf("parse oci layout reference: %w", err)
 			}
 
+			// registry uploads the layer as usual if it refuses to mount the blob from another repo
+			imgToPush := mirror.WithCrossRepoMounts(img, repo, progress, refOpts...)
 			err = retry.NewLoop(
 				fmt.Sprintf("[%d / %d] Pushing image %s...", pushCount, len(indexManifest.Manifests), imageRef),
 				20,
 				3*time.Second,
 			).Run(func() error {
-				if err = remote.Write(ref, img, remoteOpts...); err != nil {
+				if err = remote.Write(ref, imgToPush, remoteOpts...); err != nil {
 					if mirror.IsTrivyMediaTypeNotAllowedError(err) {
 						log.WarnLn(customTrivyMediaTypesWarning)
 						os.Exit(1)
@@ -205,6 +233,10 @@ func PushDeckhouseToRegistry(mirrorCtx *mirror.Context) error {
 				return err
 			}
 
+			if err = progress.MarkPushed(imageRef, manifest.Digest, repo, img); err != nil {
+				return fmt.Errorf("save push progress: %w", err)
+			}
+
 			pushCount++
 		}
 		log.InfoF("Repo %s is mirrored ✅\n", originalRepo)
@@ -212,19 +244,52 @@ func PushDeckhouseToRegistry(mirrorCtx *mirror.Context) error {
 
 	log.InfoLn("All repositories are mirrored ✅")
 
-	if len(modulesList) == 0 {
+	if len(modulesList) > 0 {
+		log.InfoLn("Pushing modules tags...")
+		if err = pushModulesTags(mirrorCtx, modulesList); err != nil {
+			return fmt.Errorf("Push modules tags: %w", err)
+		}
+		log.InfoF("All modules tags are pushed ✅\n")
+	}
+
+	if err = progress.Remove(); err != nil {
+		log.WarnF("Cannot remove push progress file: %v\n", err)
+	}
+
+	return nil
+}
+
+// prepareDeltaBundlePush checks that the parent bundle content is in the registry and allows to mount its layers
+func prepareDeltaBundlePush(mirrorCtx *mirror.Context, bundle *mirror.Bundle, progress *mirror.PushProgress) error {
+	manifest, err := bundle.Manifest()
+	if err != nil {
+		return fmt.Errorf("Read bundle manifest: %w", err)
+	}
+	if manifest == nil || !manifest.IsDelta() {
 		return nil
 	}
 
-	log.InfoLn("Pushing modules tags...")
-	if err = pushModulesTags(mirrorCtx, modulesList); err != nil {
-		return fmt.Errorf("Push modules tags: %w", err)
+	log.InfoF("Bundle is a delta of the bundle %s, checking parent bundle images in the registry...\t", manifest.Parent)
+	if err = mirror.ValidateParentBundleContent(mirrorCtx, manifest); err != nil {
+		return err
+	}
+	log.InfoLn("✅")
+
+	for digest, layoutPath := range manifest.Layers {
+		progress.RememberBlob(digest, mirror.TargetRepoForLayout(mirrorCtx, layoutPath))
 	}
-	log.InfoF("All modules tags are pushed ✅\n")
 
 	return nil
 }
 
+func openLayoutsStorage(mirrorCtx *mirror.Context) (mirror.LayoutsStorage, error) {
+	if mirrorCtx.UnpackedImagesPath != "" {
+		return mirror.UnpackedLayouts(mirrorCtx.UnpackedImagesPath), nil
+	}
+
+	return mirror.OpenBundle(mirrorCtx.BundlePath)
+}
+
 func pushModulesTags(mirrorCtx *mirror.Context, modulesList []string) error {
 	if len(modulesList) == 0 {
 		return nil
@@ -255,70 +320,30 @@ func pushModulesTags(mirrorCtx *mirror.Context, modulesList []string) error {
 	return nil
 }
 
-func findLayoutsToPush(mirrorCtx *mirror.Context) (map[string]layout.Path, []string, error) {
+// findLayoutsToPush returns paths to OCI Image Layouts within the storage by repos they should be pushed to
+func findLayoutsToPush(mirrorCtx *mirror.Context, storage mirror.LayoutsStorage) (map[string]string, []string, error) {
 	deckhouseIndexRef := mirrorCtx.RegistryHost + mirrorCtx.RegistryPath
 	installersIndexRef := filepath.Join(deckhouseIndexRef, "install")
 	releasesIndexRef := filepath.Join(deckhouseIndexRef, "release-channel")
 	securityIndexRef := filepath.Join(deckhouseIndexRef, "security", "trivy-db")
 
-	deckhouseLayoutPath := mirrorCtx.UnpackedImagesPath
-	installersLayoutPath := filepath.Join(mirrorCtx.UnpackedImagesPath, "install")
-	releasesLayoutPath := filepath.Join(mirrorCtx.UnpackedImagesPath, "release-channel")
-	securityLayoutPath := filepath.Join(mirrorCtx.UnpackedImagesPath, "security", "trivy-db")
-
-	deckhouseLayout, err := layout.FromPath(deckhouseLayoutPath)
-	if err != nil {
-		return nil, nil, err
-	}
-	installersLayout, err := layout.FromPath(installersLayoutPath)
-	if err != nil {
-		return nil, nil, err
-	}
-	releasesLayout, err := layout.FromPath(releasesLayoutPath)
-	if err != nil {
-		return nil, nil, err
-	}
-	securityLayout, err := layout.FromPath(securityLayoutPath)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	modulesPath := filepath.Join(mirrorCtx.UnpackedImagesPath, "modules")
-	ociLayouts := map[string]layout.Path{
-		deckhouseIndexRef:  deckhouseLayout,
-		installersIndexRef: installersLayout,
-		releasesIndexRef:   releasesLayout,
-		securityIndexRef:   securityLayout,
+	ociLayouts := map[string]string{
+		deckhouseIndexRef:  "",
+		installersIndexRef: "install",
+		releasesIndexRef:   "release-channel",
+		securityIndexRef:   path.Join("security", "trivy-db"),
 	}
 
-	modulesNames := make([]string, 0)
-	dirs, err := os.ReadDir(modulesPath)
-	switch {
-	case errors.Is(err, fs.ErrNotExist):
-		return ociLayouts, []string{}, nil
-	case err != nil:
+	modulesNames, err := storage.ListModules()
+	if err != nil {
 		return nil, nil, err
 	}
 
-	for _, dir := range dirs {
-		if !dir.IsDir() {
-			continue
-		}
-
-		moduleName := dir.Name()
-		modulesNames = append(modulesNames, moduleName)
+	for _, moduleName := range modulesNames {
 		moduleRef := filepath.Join(mirrorCtx.RegistryHost+mirrorCtx.RegistryPath, "modules", moduleName)
 		moduleReleasesRef := filepath.Join(mirrorCtx.DeckhouseRegistryRepo, "modules", moduleName, "release")
-		moduleLayout, err := layout.FromPath(filepath.Join(modulesPath, moduleName))
-		if err != nil {
-			return nil, nil, fmt.Errorf("create module layout from path: %w", err)
-		}
-		moduleReleaseLayout, err := layout.FromPath(filepath.Join(modulesPath, moduleName, "release"))
-		if err != nil {
-			return nil, nil, fmt.Errorf("create module release layout from path: %w", err)
-		}
-		ociLayouts[moduleRef] = moduleLayout
-		ociLayouts[moduleReleasesRef] = moduleReleaseLayout
+		ociLayouts[moduleRef] = path.Join("modules", moduleName)
+		ociLayouts[moduleReleasesRef] = path.Join("modules", moduleName, "release")
 	}
 	return ociLayouts, modulesNames, nil
 }
@@ -17,6 +17,7 @@ package mirror
 import (
 	"archive/tar"
 	"bufio"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -26,18 +27,13 @@ import (
 )
 
 func UnpackBundle(mirrorCtx *Context) error {
-	bundleDir := filepath.Dir(mirrorCtx.BundlePath)
-	catalog, err := os.ReadDir(bundleDir)
+	chunks, err := bundleChunksPaths(mirrorCtx.BundlePath)
 	if err != nil {
-		return fmt.Errorf("read tar bundle directory: %w", err)
+		return err
 	}
 	streams := make([]io.Reader, 0)
-	for _, entry := range catalog {
-		fileName := entry.Name()
-		if !entry.Type().IsRegular() || filepath.Ext(fileName) != ".chunk" {
-			continue
-		}
-		chunkStream, err := os.Open(filepath.Join(bundleDir, fileName))
+	for _, chunkPath := range chunks {
+		chunkStream, err := os.Open(chunkPath)
 		if err != nil {
 			return fmt.Errorf("open bundle chunk for reading: %w", err)
 		}
@@ -59,6 +55,10 @@ func UnpackBundle(mirrorCtx *Context) error {
 		if errors.Is(err, io.EOF) {
 			break
 		}
+		if tarHdr.Name == BundleManifestFileName {
+			// Manifest describes the bundle itself and is not a part of images layouts
+			continue
+		}
 		writePath := filepath.Join(
 			mirrorCtx.UnpackedImagesPath,
 			filepath.Clean(tarHdr.Name),
@@ -97,23 +97,50 @@ func PackBundle(mirrorCtx *Context) error {
 		tarStream = tarFile
 	}
 
+	manifest, content, err := prepareBundleContent(mirrorCtx.UnpackedImagesPath, mirrorCtx.SinceBundle)
+	if err != nil {
+		return fmt.Errorf("prepare bundle manifest: %w", err)
+	}
+
 	tarWriter := tar.NewWriter(tarStream)
-	if err := filepath.Walk(mirrorCtx.UnpackedImagesPath, packFunc(mirrorCtx, tarWriter)); err != nil {
+	if err = filepath.Walk(mirrorCtx.UnpackedImagesPath, packFunc(mirrorCtx, tarWriter, content)); err != nil {
 		return fmt.Errorf("pack mirrored images into tar: %w", err)
 	}
 
-	if err := tarWriter.Close(); err != nil {
+	manifestContent, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal bundle manifest: %w", err)
+	}
+	err = tarWriter.WriteHeader(&tar.Header{
+		Name:    BundleManifestFileName,
+		Size:    int64(len(manifestContent)),
+		Mode:    0644,
+		ModTime: manifest.CreatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("write tar header: %w", err)
+	}
+	if _, err = tarWriter.Write(manifestContent); err != nil {
+		return fmt.Errorf("write bundle manifest to tar: %w", err)
+	}
+
+	if err = tarWriter.Close(); err != nil {
 		return fmt.Errorf("write tar trailer: %w", err)
 	}
-	if err := tarStream.Close(); err != nil {
+	if err = tarStream.Close(); err != nil {
 		return fmt.Errorf("close tar: %w", err)
 	}
 
+	// Manifest next to the bundle can be passed to --since-bundle instead of the whole bundle
+	if err = os.WriteFile(bundleManifestPathFor(mirrorCtx.BundlePath), manifestContent, 0644); err != nil {
+		return fmt.Errorf("write bundle manifest: %w", err)
+	}
+
 	return nil
 
 }
 
-func packFunc(mirrorCtx *Context, out *tar.Writer) filepath.WalkFunc {
+func packFunc(mirrorCtx *Context, out *tar.Writer, content *bundleContent) filepath.WalkFunc {
 	return func(path string, info fs.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -122,15 +149,38 @@ func packFunc(mirrorCtx *Context, out *tar.Writer) filepath.WalkFunc {
 			return nil
 		}
 
-		blobFile, err := os.Open(path)
+		pathInTar, err := filepath.Rel(mirrorCtx.UnpackedImagesPath, path)
 		if err != nil {
-			return fmt.Errorf("read file: %w", err)
+			return fmt.Errorf("build file path within bundle: %w", err)
+		}
+		if _, skip := content.skippedFiles[filepath.ToSlash(pathInTar)]; skip {
+			// Layer is already in the parent bundle, it is kept in place to be able to resume the pull
+			return nil
 		}
 
-		pathInTar, err := filepath.Rel(mirrorCtx.UnpackedImagesPath, path)
+		if replacement, replace := content.replacedFiles[filepath.ToSlash(pathInTar)]; replace {
+			err = out.WriteHeader(&tar.Header{
+				Name:    pathInTar,
+				Size:    int64(len(replacement)),
+				Mode:    int64(info.Mode()),
+				ModTime: info.ModTime(),
+			})
+			if err != nil {
+				return fmt.Errorf("write tar header: %w", err)
+			}
+
+			if _, err = out.Write(replacement); err != nil {
+				return fmt.Errorf("write file to tar: %w", err)
+			}
+
+			return nil
+		}
+
+		blobFile, err := os.Open(path)
 		if err != nil {
-			return fmt.Errorf("build file path within bundle: %w", err)
+			return fmt.Errorf("read file: %w", err)
 		}
+
 		err = out.WriteHeader(&tar.Header{
 			Name:    pathInTar,
 			Size:    info.Size(),
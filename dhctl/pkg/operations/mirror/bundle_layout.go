@@ -0,0 +1,217 @@
+// Copyright 2024 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mirror
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sync"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// bundleLayout is an OCI Image Layout stored in the bundle, it mirrors layout.Path but reads blobs from the bundle
+type bundleLayout struct {
+	bundle *Bundle
+	path   string
+}
+
+func (l bundleLayout) blobPath(h v1.Hash) string {
+	return path.Join(l.path, "blobs", h.Algorithm, h.Hex)
+}
+
+func (l bundleLayout) Blob(h v1.Hash) (io.ReadCloser, error) {
+	r, err := l.bundle.Open(l.blobPath(h))
+	if err != nil {
+		return nil, err
+	}
+
+	return io.NopCloser(r), nil
+}
+
+func (l bundleLayout) Bytes(h v1.Hash) ([]byte, error) {
+	return l.bundle.ReadFile(l.blobPath(h))
+}
+
+type bundleIndex struct {
+	mediaType types.MediaType
+	layout    bundleLayout
+	rawIndex  []byte
+}
+
+var _ v1.ImageIndex = (*bundleIndex)(nil)
+
+func (i *bundleIndex) MediaType() (types.MediaType, error) {
+	return i.mediaType, nil
+}
+
+func (i *bundleIndex) Digest() (v1.Hash, error) {
+	return partial.Digest(i)
+}
+
+func (i *bundleIndex) Size() (int64, error) {
+	return partial.Size(i)
+}
+
+func (i *bundleIndex) IndexManifest() (*v1.IndexManifest, error) {
+	var index v1.IndexManifest
+	err := json.Unmarshal(i.rawIndex, &index)
+	return &index, err
+}
+
+func (i *bundleIndex) RawManifest() ([]byte, error) {
+	return i.rawIndex, nil
+}
+
+func (i *bundleIndex) Image(h v1.Hash) (v1.Image, error) {
+	desc, err := i.findDescriptor(h)
+	if err != nil {
+		return nil, err
+	}
+
+	if !desc.MediaType.IsImage() {
+		return nil, fmt.Errorf("unexpected media type for %v: %s", h, desc.MediaType)
+	}
+
+	return partial.CompressedToImage(&bundleImage{layout: i.layout, desc: *desc})
+}
+
+func (i *bundleIndex) ImageIndex(h v1.Hash) (v1.ImageIndex, error) {
+	desc, err := i.findDescriptor(h)
+	if err != nil {
+		return nil, err
+	}
+
+	if !desc.MediaType.IsIndex() {
+		return nil, fmt.Errorf("unexpected media type for %v: %s", h, desc.MediaType)
+	}
+
+	rawIndex, err := i.layout.Bytes(h)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bundleIndex{
+		mediaType: desc.MediaType,
+		layout:    i.layout,
+		rawIndex:  rawIndex,
+	}, nil
+}
+
+func (i *bundleIndex) findDescriptor(h v1.Hash) (*v1.Descriptor, error) {
+	im, err := i.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, desc := range im.Manifests {
+		if desc.Digest == h {
+			return &desc, nil
+		}
+	}
+
+	return nil, fmt.Errorf("could not find descriptor in index: %s", h)
+}
+
+type bundleImage struct {
+	layout       bundleLayout
+	desc         v1.Descriptor
+	manifestLock sync.Mutex // Protects rawManifest
+	rawManifest  []byte
+}
+
+var _ partial.CompressedImageCore = (*bundleImage)(nil)
+
+func (bi *bundleImage) MediaType() (types.MediaType, error) {
+	return bi.desc.MediaType, nil
+}
+
+func (bi *bundleImage) Manifest() (*v1.Manifest, error) {
+	return partial.Manifest(bi)
+}
+
+func (bi *bundleImage) RawManifest() ([]byte, error) {
+	bi.manifestLock.Lock()
+	defer bi.manifestLock.Unlock()
+	if bi.rawManifest != nil {
+		return bi.rawManifest, nil
+	}
+
+	b, err := bi.layout.Bytes(bi.desc.Digest)
+	if err != nil {
+		return nil, err
+	}
+
+	bi.rawManifest = b
+	return bi.rawManifest, nil
+}
+
+func (bi *bundleImage) RawConfigFile() ([]byte, error) {
+	manifest, err := bi.Manifest()
+	if err != nil {
+		return nil, err
+	}
+
+	return bi.layout.Bytes(manifest.Config.Digest)
+}
+
+func (bi *bundleImage) LayerByDigest(h v1.Hash) (partial.CompressedLayer, error) {
+	manifest, err := bi.Manifest()
+	if err != nil {
+		return nil, err
+	}
+
+	if h == manifest.Config.Digest {
+		return &bundleBlob{layout: bi.layout, desc: manifest.Config}, nil
+	}
+
+	for _, desc := range manifest.Layers {
+		if h == desc.Digest {
+			return &bundleBlob{layout: bi.layout, desc: desc}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("could not find layer in image: %s", h)
+}
+
+// bundleBlob is a layer streamed straight from the bundle
+type bundleBlob struct {
+	layout bundleLayout
+	desc   v1.Descriptor
+}
+
+func (b *bundleBlob) Digest() (v1.Hash, error) {
+	return b.desc.Digest, nil
+}
+
+func (b *bundleBlob) Compressed() (io.ReadCloser, error) {
+	return b.layout.Blob(b.desc.Digest)
+}
+
+func (b *bundleBlob) Size() (int64, error) {
+	return b.desc.Size, nil
+}
+
+func (b *bundleBlob) MediaType() (types.MediaType, error) {
+	return b.desc.MediaType, nil
+}
+
+func (b *bundleBlob) Descriptor() (*v1.Descriptor, error) {
+	return &b.desc, nil
+}
@@ -0,0 +1,321 @@
+// Copyright 2024 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mirror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/google/uuid"
+)
+
+// BundleManifestFileName is the name of the bundle manifest within the bundle
+const BundleManifestFileName = "bundle-manifest.json"
+
+const shortTagAnnotation = "io.deckhouse.image.short_tag"
+
+// BundleManifest describes the bundle content. Delta bundle contains only images and layers
+// missing from the parent bundle, content of the parent is listed to check it in the target registry.
+type BundleManifest struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	// Parent is the ID of the bundle this delta bundle is based on
+	Parent string `json:"parent,omitempty"`
+
+	Images       []BundleImage `json:"images"`
+	ParentImages []BundleImage `json:"parentImages,omitempty"`
+	// Layers maps digests of image layers of the bundle and its parents to the layout they are stored in
+	Layers map[string]string `json:"layers"`
+}
+
+// BundleImage is an image of the OCI Image Layout stored in the bundle
+type BundleImage struct {
+	Layout string `json:"layout"`
+	Tag    string `json:"tag"`
+	Digest string `json:"digest"`
+}
+
+func (i BundleImage) key() string {
+	return i.Layout + ":" + i.Tag + "@" + i.Digest
+}
+
+// IsDelta reports whether the bundle requires its parent bundle content in the target registry
+func (m *BundleManifest) IsDelta() bool {
+	return m.Parent != ""
+}
+
+// allImages returns images of the bundle together with images of its parents
+func (m *BundleManifest) allImages() map[string]struct{} {
+	images := make(map[string]struct{}, len(m.Images)+len(m.ParentImages))
+	for _, img := range m.Images {
+		images[img.key()] = struct{}{}
+	}
+	for _, img := range m.ParentImages {
+		images[img.key()] = struct{}{}
+	}
+
+	return images
+}
+
+// LoadBundleManifest reads the manifest file or the manifest stored in the bundle
+func LoadBundleManifest(manifestOrBundlePath string) (*BundleManifest, error) {
+	var (
+		content []byte
+		err     error
+	)
+
+	if filepath.Ext(manifestOrBundlePath) == ".json" {
+		content, err = os.ReadFile(manifestOrBundlePath)
+	} else {
+		var bundle *Bundle
+		bundle, err = OpenBundle(manifestOrBundlePath)
+		if err != nil {
+			return nil, fmt.Errorf("open bundle: %w", err)
+		}
+		defer bundle.Close()
+
+		content, err = bundle.ReadFile(BundleManifestFileName)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("bundle %s has no manifest, it was created by an older version of dhctl", manifestOrBundlePath)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read bundle manifest: %w", err)
+	}
+
+	manifest := &BundleManifest{}
+	if err = json.Unmarshal(content, manifest); err != nil {
+		return nil, fmt.Errorf("parse bundle manifest: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// bundleManifestPathFor returns the path of the manifest file written next to the bundle
+func bundleManifestPathFor(bundlePath string) string {
+	return strings.TrimSuffix(bundlePath, filepath.Ext(bundlePath)) + ".manifest.json"
+}
+
+// bundleContent describes how the unpacked image layouts are packed into the bundle
+type bundleContent struct {
+	// skippedFiles are paths to the layers which must not be packed into the bundle
+	skippedFiles map[string]struct{}
+	// replacedFiles are contents of the files which are packed instead of the files in the unpacked layouts,
+	// unpacked layouts are left untouched to allow resuming the interrupted pull
+	replacedFiles map[string][]byte
+}
+
+// prepareBundleContent builds the manifest of the bundle from the image layouts in the unpackedPath.
+// If the parent manifest is passed, images of the parent are removed from the packed layouts indexes
+// and layers of the parent are not packed into the layouts they were pushed from.
+func prepareBundleContent(unpackedPath string, parent *BundleManifest) (*BundleManifest, *bundleContent, error) {
+	manifest := &BundleManifest{
+		ID:        uuid.NewString(),
+		CreatedAt: time.Now().UTC(),
+		Layers:    make(map[string]string),
+	}
+
+	parentImages := make(map[string]struct{})
+	if parent != nil {
+		manifest.Parent = parent.ID
+		parentImages = parent.allImages()
+		for digest, layoutPath := range parent.Layers {
+			manifest.Layers[digest] = layoutPath
+		}
+	}
+
+	layoutsPaths, err := findLayouts(unpackedPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content := &bundleContent{
+		skippedFiles:  make(map[string]struct{}),
+		replacedFiles: make(map[string][]byte),
+	}
+	for _, layoutPath := range layoutsPaths {
+		l, err := layout.FromPath(filepath.Join(unpackedPath, layoutPath))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		index, err := l.ImageIndex()
+		if err != nil {
+			return nil, nil, fmt.Errorf("read image index of %q layout: %w", layoutPath, err)
+		}
+		indexManifest, err := index.IndexManifest()
+		if err != nil {
+			return nil, nil, fmt.Errorf("read index manifest of %q layout: %w", layoutPath, err)
+		}
+
+		kept := make([]v1.Descriptor, 0, len(indexManifest.Manifests))
+		for _, desc := range indexManifest.Manifests {
+			img := BundleImage{Layout: layoutPath, Tag: desc.Annotations[shortTagAnnotation], Digest: desc.Digest.String()}
+			if _, found := parentImages[img.key()]; found {
+				manifest.ParentImages = append(manifest.ParentImages, img)
+				continue
+			}
+
+			kept = append(kept, desc)
+			manifest.Images = append(manifest.Images, img)
+
+			if !desc.MediaType.IsImage() {
+				continue
+			}
+
+			image, err := index.Image(desc.Digest)
+			if err != nil {
+				return nil, nil, fmt.Errorf("read image %s: %w", desc.Digest, err)
+			}
+			imageManifest, err := image.Manifest()
+			if err != nil {
+				return nil, nil, fmt.Errorf("read image %s manifest: %w", desc.Digest, err)
+			}
+
+			for _, layer := range imageManifest.Layers {
+				if _, found := manifest.Layers[layer.Digest.String()]; !found {
+					manifest.Layers[layer.Digest.String()] = layoutPath
+				}
+			}
+		}
+
+		if parent == nil {
+			continue
+		}
+
+		// layers of the parent bundle are already in the repo of the layout they were pushed from,
+		// layers of other layouts are packed to be uploaded if the registry refuses to mount them
+		for digest, parentLayout := range parent.Layers {
+			if parentLayout != layoutPath {
+				continue
+			}
+			hash, err := v1.NewHash(digest)
+			if err != nil {
+				return nil, nil, fmt.Errorf("parse layer digest %q of the parent bundle: %w", digest, err)
+			}
+			content.skippedFiles[path.Join(layoutPath, "blobs", hash.Algorithm, hash.Hex)] = struct{}{}
+		}
+
+		indexManifest.Manifests = kept
+		indexContent, err := json.MarshalIndent(indexManifest, "", "   ")
+		if err != nil {
+			return nil, nil, fmt.Errorf("marshal index manifest of %q layout: %w", layoutPath, err)
+		}
+		content.replacedFiles[path.Join(layoutPath, "index.json")] = indexContent
+	}
+
+	sort.Slice(manifest.Images, func(i, j int) bool { return manifest.Images[i].key() < manifest.Images[j].key() })
+	sort.Slice(manifest.ParentImages, func(i, j int) bool { return manifest.ParentImages[i].key() < manifest.ParentImages[j].key() })
+
+	return manifest, content, nil
+}
+
+// findLayouts returns paths to the OCI Image Layouts relative to the rootPath
+func findLayouts(rootPath string) ([]string, error) {
+	layouts := make([]string, 0)
+	err := filepath.WalkDir(rootPath, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == "blobs" {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || d.Name() != "index.json" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(rootPath, filepath.Dir(p))
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			rel = ""
+		}
+		layouts = append(layouts, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("find image layouts: %w", err)
+	}
+
+	return layouts, nil
+}
+
+// Manifest returns the manifest stored in the bundle or nil if the bundle has no manifest
+func (b *Bundle) Manifest() (*BundleManifest, error) {
+	content, err := b.ReadFile(BundleManifestFileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &BundleManifest{}
+	if err = json.Unmarshal(content, manifest); err != nil {
+		return nil, fmt.Errorf("parse bundle manifest: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// TargetRepoForLayout returns the repo in the target registry the layout is pushed to
+func TargetRepoForLayout(mirrorCtx *Context, layoutPath string) string {
+	return path.Join(mirrorCtx.RegistryHost+mirrorCtx.RegistryPath, layoutPath)
+}
+
+// ValidateParentBundleContent checks that images of the parent bundle were pushed to the target registry before the delta
+func ValidateParentBundleContent(mirrorCtx *Context, manifest *BundleManifest) error {
+	refOpts, remoteOpts := MakeRemoteRegistryRequestOptionsFromMirrorContext(mirrorCtx)
+
+	missing := make([]string, 0)
+	for _, img := range manifest.ParentImages {
+		imageRef := TargetRepoForLayout(mirrorCtx, img.Layout) + "@" + img.Digest
+		ref, err := name.ParseReference(imageRef, refOpts...)
+		if err != nil {
+			return fmt.Errorf("parse image reference: %w", err)
+		}
+
+		if _, err = remote.Head(ref, remoteOpts...); err != nil {
+			var terr *transport.Error
+			if errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound {
+				missing = append(missing, imageRef)
+				continue
+			}
+			return fmt.Errorf("check %s: %w", imageRef, err)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%d images of the parent bundle %s are not found in the registry, push the parent bundle first:\n%s",
+			len(missing), manifest.Parent, strings.Join(missing, "\n"))
+	}
+
+	return nil
+}
@@ -0,0 +1,246 @@
+// Copyright 2024 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mirror
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// Bundle provides random access to files of the tar bundle without unpacking it.
+// Bundle split into chunks by the chunkedFileWriter is read as a single tar stream.
+type Bundle struct {
+	files   []*os.File
+	reader  *multiReaderAt
+	entries map[string]bundleEntry
+}
+
+type bundleEntry struct {
+	offset int64
+	size   int64
+}
+
+// OpenBundle indexes files in the tar bundle or in its chunks placed next to the bundlePath
+func OpenBundle(bundlePath string) (*Bundle, error) {
+	chunks, err := bundleChunksPaths(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) == 0 {
+		chunks = []string{bundlePath}
+	}
+
+	b := &Bundle{
+		reader:  &multiReaderAt{},
+		entries: make(map[string]bundleEntry),
+	}
+
+	for _, chunkPath := range chunks {
+		chunk, err := os.Open(chunkPath)
+		if err != nil {
+			_ = b.Close()
+			return nil, fmt.Errorf("open bundle chunk for reading: %w", err)
+		}
+		b.files = append(b.files, chunk)
+
+		stat, err := chunk.Stat()
+		if err != nil {
+			_ = b.Close()
+			return nil, fmt.Errorf("stat bundle chunk: %w", err)
+		}
+		b.reader.add(chunk, stat.Size())
+	}
+
+	if err = b.index(); err != nil {
+		_ = b.Close()
+		return nil, fmt.Errorf("index tar bundle: %w", err)
+	}
+
+	return b, nil
+}
+
+// index reads tar headers and remembers positions of files data, the data itself is skipped
+func (b *Bundle) index() error {
+	stream := io.NewSectionReader(b.reader, 0, b.reader.size)
+	tarReader := tar.NewReader(stream)
+	for {
+		tarHdr, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if tarHdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		offset, err := stream.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+
+		b.entries[path.Clean(filepath.ToSlash(tarHdr.Name))] = bundleEntry{offset: offset, size: tarHdr.Size}
+	}
+}
+
+// Open returns a reader of the file stored in the bundle
+func (b *Bundle) Open(name string) (*io.SectionReader, error) {
+	entry, found := b.entries[path.Clean(name)]
+	if !found {
+		return nil, fmt.Errorf("%s: %w", name, fs.ErrNotExist)
+	}
+
+	return io.NewSectionReader(b.reader, entry.offset, entry.size), nil
+}
+
+// ReadFile reads the whole file stored in the bundle
+func (b *Bundle) ReadFile(name string) ([]byte, error) {
+	r, err := b.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(r)
+}
+
+// ImageIndex returns an index of the OCI Image Layout stored in the bundle at layoutPath
+func (b *Bundle) ImageIndex(layoutPath string) (v1.ImageIndex, error) {
+	rawIndex, err := b.ReadFile(path.Join(layoutPath, "index.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &bundleIndex{
+		mediaType: types.OCIImageIndex,
+		layout:    bundleLayout{bundle: b, path: layoutPath},
+		rawIndex:  rawIndex,
+	}, nil
+}
+
+// ListModules returns names of the modules which image layouts are stored in the bundle
+func (b *Bundle) ListModules() ([]string, error) {
+	modules := make(map[string]struct{})
+	for name := range b.entries {
+		parts := strings.Split(name, "/")
+		if len(parts) == 3 && parts[0] == "modules" && parts[2] == "index.json" {
+			modules[parts[1]] = struct{}{}
+		}
+	}
+
+	names := make([]string, 0, len(modules))
+	for module := range modules {
+		names = append(names, module)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+func (b *Bundle) Close() error {
+	var errs []error
+	for _, f := range b.files {
+		if err := f.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	b.files = nil
+
+	return errors.Join(errs...)
+}
+
+// bundleChunksPaths returns paths to bundle chunks in the order they were written
+func bundleChunksPaths(bundlePath string) ([]string, error) {
+	bundleDir := filepath.Dir(bundlePath)
+	catalog, err := os.ReadDir(bundleDir)
+	if err != nil {
+		return nil, fmt.Errorf("read tar bundle directory: %w", err)
+	}
+
+	chunks := make([]string, 0)
+	for _, entry := range catalog {
+		fileName := entry.Name()
+		if !entry.Type().IsRegular() || filepath.Ext(fileName) != ".chunk" {
+			continue
+		}
+		chunks = append(chunks, filepath.Join(bundleDir, fileName))
+	}
+
+	return chunks, nil
+}
+
+// multiReaderAt is a logical concatenation of readers, like io.MultiReader but with random access
+type multiReaderAt struct {
+	parts []readerAtPart
+	size  int64
+}
+
+type readerAtPart struct {
+	io.ReaderAt
+	offset int64
+	size   int64
+}
+
+func (m *multiReaderAt) add(r io.ReaderAt, size int64) {
+	m.parts = append(m.parts, readerAtPart{ReaderAt: r, offset: m.size, size: size})
+	m.size += size
+}
+
+func (m *multiReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off >= m.size {
+		return 0, io.EOF
+	}
+
+	// first part which ends after the offset
+	i := sort.Search(len(m.parts), func(i int) bool {
+		return m.parts[i].offset+m.parts[i].size > off
+	})
+
+	read := 0
+	for ; i < len(m.parts) && read < len(p); i++ {
+		part := m.parts[i]
+		partOff := off + int64(read) - part.offset
+		toRead := p[read:]
+		if rest := part.size - partOff; int64(len(toRead)) > rest {
+			toRead = toRead[:rest]
+		}
+
+		n, err := part.ReadAt(toRead, partOff)
+		read += n
+		if err != nil && !errors.Is(err, io.EOF) {
+			return read, err
+		}
+		if n < len(toRead) {
+			return read, io.ErrUnexpectedEOF
+		}
+	}
+
+	if read < len(p) {
+		return read, io.EOF
+	}
+
+	return read, nil
+}
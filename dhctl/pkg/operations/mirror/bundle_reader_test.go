@@ -0,0 +1,91 @@
+// Copyright 2024 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mirror
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/rand"
+	"io"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiReaderAt(t *testing.T) {
+	data := []byte("0123456789abcdefghij")
+	r := &multiReaderAt{}
+	r.add(bytes.NewReader(data[:7]), 7)
+	r.add(bytes.NewReader(data[7:8]), 1)
+	r.add(bytes.NewReader(data[8:]), int64(len(data)-8))
+
+	buf := make([]byte, 10)
+	n, err := r.ReadAt(buf, 5)
+	require.NoError(t, err)
+	require.Equal(t, 10, n)
+	require.Equal(t, data[5:15], buf)
+
+	n, err = r.ReadAt(buf, 15)
+	require.ErrorIs(t, err, io.EOF)
+	require.Equal(t, 5, n)
+	require.Equal(t, data[15:], buf[:n])
+
+	_, err = r.ReadAt(buf, int64(len(data)))
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestOpenChunkedBundle(t *testing.T) {
+	workingDir := t.TempDir()
+
+	files := map[string][]byte{
+		"index.json":                  []byte(`{"schemaVersion": 2}`),
+		"blobs/sha256/aaaa":           make([]byte, 300*1024),
+		"modules/console/index.json":  []byte(`{}`),
+		"modules/console/blobs/bbbb":  []byte("module blob"),
+		"modules/console/release/idx": []byte("release"),
+	}
+	_, err := rand.Read(files["blobs/sha256/aaaa"])
+	require.NoError(t, err)
+
+	chunkWriter := newChunkWriter(100*1024, workingDir, "d8.tar")
+	tarWriter := tar.NewWriter(chunkWriter)
+	for _, name := range []string{"index.json", "blobs/sha256/aaaa", "modules/console/index.json", "modules/console/blobs/bbbb", "modules/console/release/idx"} {
+		require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: name, Size: int64(len(files[name])), Mode: 0644}))
+		_, err = tarWriter.Write(files[name])
+		require.NoError(t, err)
+	}
+	require.NoError(t, tarWriter.Close())
+	require.NoError(t, chunkWriter.Close())
+
+	bundle, err := OpenBundle(workingDir + "/d8.tar")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = bundle.Close()
+	})
+
+	for name, want := range files {
+		got, err := bundle.ReadFile(name)
+		require.NoError(t, err)
+		require.Equal(t, want, got, name)
+	}
+
+	_, err = bundle.ReadFile("missing")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	modules, err := bundle.ListModules()
+	require.NoError(t, err)
+	require.Equal(t, []string{"console"}, modules)
+}
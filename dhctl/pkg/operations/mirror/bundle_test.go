@@ -23,6 +23,10 @@ import (
 	"strings"
 	"testing"
 
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/random"
 	"github.com/stretchr/testify/require"
 )
 
@@ -61,6 +65,89 @@ func TestBundlePackingAndUnpacking(t *testing.T) {
 	require.Equal(t, expectedFiles, resultingFiles, "Expected to find same file trees under source and target dirs")
 }
 
+func TestDeltaBundlePackingKeepsUnpackedLayouts(t *testing.T) {
+	packFromDir := t.TempDir()
+	unpackToDir := t.TempDir()
+	tarBundlePath := filepath.Join(t.TempDir(), "delta.tar")
+
+	layoutPath := filepath.Join(packFromDir, "install")
+	l, err := layout.Write(layoutPath, empty.Index)
+	require.NoError(t, err)
+
+	parentImage, err := random.Image(128, 2)
+	require.NoError(t, err)
+	newImage, err := random.Image(128, 2)
+	require.NoError(t, err)
+	require.NoError(t, l.AppendImage(parentImage, layout.WithAnnotations(map[string]string{shortTagAnnotation: "v1"})))
+	require.NoError(t, l.AppendImage(newImage, layout.WithAnnotations(map[string]string{shortTagAnnotation: "v2"})))
+
+	// the same image in another layout is pushed to another repo
+	otherLayout, err := layout.Write(filepath.Join(packFromDir, "release-channel"), empty.Index)
+	require.NoError(t, err)
+	require.NoError(t, otherLayout.AppendImage(parentImage, layout.WithAnnotations(map[string]string{shortTagAnnotation: "stable"})))
+
+	parentDigest, err := parentImage.Digest()
+	require.NoError(t, err)
+	parentLayers, err := parentImage.Layers()
+	require.NoError(t, err)
+
+	parent := &BundleManifest{
+		ID:     "parent",
+		Images: []BundleImage{{Layout: "install", Tag: "v1", Digest: parentDigest.String()}},
+		Layers: make(map[string]string),
+	}
+	parentBlobs := make([]string, 0, len(parentLayers))
+	for _, layer := range parentLayers {
+		digest, err := layer.Digest()
+		require.NoError(t, err)
+		parent.Layers[digest.String()] = "install"
+		parentBlobs = append(parentBlobs, filepath.Join("install", "blobs", digest.Algorithm, digest.Hex))
+	}
+
+	err = PackBundle(&Context{
+		BundlePath:         tarBundlePath,
+		UnpackedImagesPath: packFromDir,
+		SinceBundle:        parent,
+	})
+	require.NoError(t, err)
+
+	// unpacked layouts are left intact to be able to resume the interrupted pull
+	index, err := layout.ImageIndexFromPath(layoutPath)
+	require.NoError(t, err)
+	require.Len(t, indexManifests(t, index), 2)
+	for _, blob := range parentBlobs {
+		require.FileExists(t, filepath.Join(packFromDir, blob))
+	}
+
+	err = UnpackBundle(&Context{
+		BundlePath:         tarBundlePath,
+		UnpackedImagesPath: unpackToDir,
+	})
+	require.NoError(t, err)
+
+	index, err = layout.ImageIndexFromPath(filepath.Join(unpackToDir, "install"))
+	require.NoError(t, err)
+	manifests := indexManifests(t, index)
+	require.Len(t, manifests, 1)
+	require.Equal(t, "v2", manifests[0].Annotations[shortTagAnnotation])
+	for _, blob := range parentBlobs {
+		require.NoFileExists(t, filepath.Join(unpackToDir, blob))
+	}
+
+	// layers of the parent are packed into other layouts
+	for _, blob := range parentBlobs {
+		require.FileExists(t, filepath.Join(unpackToDir, "release-channel", strings.TrimPrefix(blob, "install")))
+	}
+}
+
+func indexManifests(t *testing.T, index v1.ImageIndex) []v1.Descriptor {
+	t.Helper()
+
+	indexManifest, err := index.IndexManifest()
+	require.NoError(t, err)
+	return indexManifest.Manifests
+}
+
 func TestChunkedBundlePackingAndUnpacking(t *testing.T) {
 	tmpDir := os.TempDir()
 	bundlePath := filepath.Join(tmpDir, "pack_test.tar")
@@ -32,11 +32,16 @@ type Context struct {
 
 	DeckhouseRegistryRepo string // --source
 
-	BundlePath         string // --images-bundle-path
-	BundleChunkSize    int64  // Plain bytes
-	UnpackedImagesPath string
+	BundlePath         string         // --images-bundle-path
+	BundleChunkSize    int64          // Plain bytes
+	UnpackedImagesPath string         // Images are pushed straight from the bundle if empty
 	ValidationMode     ValidationMode // --validation, hidden flag
 
+	SinceBundle *BundleManifest // --since-bundle, only content missing from this bundle is packed
+
+	PushWorkers      int    // --push-workers
+	PushProgressPath string // Progress of the push is not saved if empty
+
 	// Only one of those 2 is used at a time or none at all.
 	MinVersion      *semver.Version // --min-version
 	SpecificVersion *semver.Version // --release
@@ -0,0 +1,259 @@
+// Copyright 2024 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mirror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// LayoutsStorage provides OCI Image Layouts to push, they are either unpacked to the directory or stored in the bundle
+type LayoutsStorage interface {
+	ImageIndex(layoutPath string) (v1.ImageIndex, error)
+	ListModules() ([]string, error)
+}
+
+var (
+	_ LayoutsStorage = (*Bundle)(nil)
+	_ LayoutsStorage = UnpackedLayouts("")
+)
+
+// UnpackedLayouts is a directory with OCI Image Layouts unpacked from the bundle
+type UnpackedLayouts string
+
+func (u UnpackedLayouts) ImageIndex(layoutPath string) (v1.ImageIndex, error) {
+	l, err := layout.FromPath(filepath.Join(string(u), layoutPath))
+	if err != nil {
+		return nil, err
+	}
+
+	return l.ImageIndex()
+}
+
+func (u UnpackedLayouts) ListModules() ([]string, error) {
+	dirs, err := os.ReadDir(filepath.Join(string(u), "modules"))
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return []string{}, nil
+	case err != nil:
+		return nil, err
+	}
+
+	modules := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		if dir.IsDir() {
+			modules = append(modules, dir.Name())
+		}
+	}
+
+	return modules, nil
+}
+
+// PushProgress records pushed images and blobs to resume the interrupted push
+type PushProgress struct {
+	path string
+	mu   sync.Mutex
+
+	// Images are references of pushed images in the form of repo:tag@digest
+	Images map[string]struct{} `json:"images"`
+	// Blobs maps digests of pushed blobs to the repo they were pushed to
+	Blobs map[string]string `json:"blobs"`
+}
+
+// LoadPushProgress reads the progress of the previous push from the file, progress is not persisted if path is empty
+func LoadPushProgress(path string) (*PushProgress, error) {
+	p := &PushProgress{
+		path:   path,
+		Images: make(map[string]struct{}),
+		Blobs:  make(map[string]string),
+	}
+
+	if path == "" {
+		return p, nil
+	}
+
+	content, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return p, nil
+	case err != nil:
+		return nil, fmt.Errorf("read push progress: %w", err)
+	}
+
+	if err = json.Unmarshal(content, p); err != nil {
+		return nil, fmt.Errorf("parse push progress %s: %w", path, err)
+	}
+	if p.Images == nil {
+		p.Images = make(map[string]struct{})
+	}
+	if p.Blobs == nil {
+		p.Blobs = make(map[string]string)
+	}
+
+	return p, nil
+}
+
+func (p *PushProgress) IsPushed(imageRef string, digest v1.Hash) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, pushed := p.Images[imageRef+"@"+digest.String()]
+	return pushed
+}
+
+// BlobRepo returns the repo in the target registry which already has the blob
+func (p *PushProgress) BlobRepo(digest v1.Hash) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	repo, found := p.Blobs[digest.String()]
+	return repo, found
+}
+
+// RememberBlob records the blob which is known to be in the repo, the first known repo is kept
+func (p *PushProgress) RememberBlob(digest, repo string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, found := p.Blobs[digest]; !found {
+		p.Blobs[digest] = repo
+	}
+}
+
+// MarkPushed records the image and its blobs as pushed to the repo and saves the progress
+func (p *PushProgress) MarkPushed(imageRef string, digest v1.Hash, repo string, img v1.Image) error {
+	layers, err := img.Layers()
+	if err != nil {
+		return fmt.Errorf("read image layers: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, l := range layers {
+		layerDigest, err := l.Digest()
+		if err != nil {
+			return fmt.Errorf("read layer digest: %w", err)
+		}
+		if _, found := p.Blobs[layerDigest.String()]; !found {
+			p.Blobs[layerDigest.String()] = repo
+		}
+	}
+	p.Images[imageRef+"@"+digest.String()] = struct{}{}
+
+	return p.save()
+}
+
+func (p *PushProgress) save() error {
+	if p.path == "" {
+		return nil
+	}
+
+	content, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("marshal push progress: %w", err)
+	}
+
+	if err = os.MkdirAll(filepath.Dir(p.path), 0755); err != nil {
+		return fmt.Errorf("create push progress directory: %w", err)
+	}
+
+	// write to the temporary file first not to leave broken progress if interrupted
+	tmpPath := p.path + ".tmp"
+	if err = os.WriteFile(tmpPath, content, 0644); err != nil {
+		return fmt.Errorf("write push progress: %w", err)
+	}
+
+	return os.Rename(tmpPath, p.path)
+}
+
+// Remove deletes the progress file after the push is completed
+func (p *PushProgress) Remove() error {
+	if p.path == "" {
+		return nil
+	}
+
+	err := os.Remove(p.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+// WithCrossRepoMounts makes remote.Write try to mount layers which were already pushed to other repos of the registry
+// instead of uploading them again. Existing blobs in the target repo are skipped by remote.Write with a HEAD request anyway.
+func WithCrossRepoMounts(img v1.Image, repo string, progress *PushProgress, refOpts ...name.Option) v1.Image {
+	return &mountableImage{Image: img, repo: repo, progress: progress, refOpts: refOpts}
+}
+
+type mountableImage struct {
+	v1.Image
+	repo     string
+	progress *PushProgress
+	refOpts  []name.Option
+}
+
+func (i *mountableImage) Layers() ([]v1.Layer, error) {
+	layers, err := i.Image.Layers()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]v1.Layer, 0, len(layers))
+	for _, l := range layers {
+		result = append(result, i.mountable(l))
+	}
+
+	return result, nil
+}
+
+func (i *mountableImage) LayerByDigest(h v1.Hash) (v1.Layer, error) {
+	l, err := i.Image.LayerByDigest(h)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.mountable(l), nil
+}
+
+func (i *mountableImage) mountable(l v1.Layer) v1.Layer {
+	digest, err := l.Digest()
+	if err != nil {
+		return l
+	}
+
+	repo, found := i.progress.BlobRepo(digest)
+	if !found || repo == i.repo {
+		return l
+	}
+
+	ref, err := name.NewDigest(repo+"@"+digest.String(), i.refOpts...)
+	if err != nil {
+		return l
+	}
+
+	return &remote.MountableLayer{Layer: l, Reference: ref}
+}
@@ -0,0 +1,80 @@
+// Copyright 2024 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mirror
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPushProgress(t *testing.T) {
+	progressPath := filepath.Join(t.TempDir(), "push", "progress.json")
+
+	img, err := random.Image(256, 2)
+	require.NoError(t, err)
+	digest, err := img.Digest()
+	require.NoError(t, err)
+
+	progress, err := LoadPushProgress(progressPath)
+	require.NoError(t, err)
+	require.False(t, progress.IsPushed("registry.example.com/deckhouse:v1.56.5", digest))
+	require.NoError(t, progress.MarkPushed("registry.example.com/deckhouse:v1.56.5", digest, "registry.example.com/deckhouse", img))
+
+	resumed, err := LoadPushProgress(progressPath)
+	require.NoError(t, err)
+	require.True(t, resumed.IsPushed("registry.example.com/deckhouse:v1.56.5", digest))
+	require.False(t, resumed.IsPushed("registry.example.com/deckhouse:v1.55.7", digest))
+
+	layers, err := img.Layers()
+	require.NoError(t, err)
+	layerDigest, err := layers[0].Digest()
+	require.NoError(t, err)
+	repo, found := resumed.BlobRepo(layerDigest)
+	require.True(t, found)
+	require.Equal(t, "registry.example.com/deckhouse", repo)
+
+	require.NoError(t, resumed.Remove())
+	require.NoFileExists(t, progressPath)
+}
+
+func TestWithCrossRepoMounts(t *testing.T) {
+	progress, err := LoadPushProgress("")
+	require.NoError(t, err)
+
+	img, err := random.Image(256, 2)
+	require.NoError(t, err)
+	digest, err := img.Digest()
+	require.NoError(t, err)
+	require.NoError(t, progress.MarkPushed("registry.example.com/deckhouse:v1.56.5", digest, "registry.example.com/deckhouse", img))
+
+	layers, err := WithCrossRepoMounts(img, "registry.example.com/deckhouse/install", progress).Layers()
+	require.NoError(t, err)
+	for _, l := range layers {
+		ml, ok := l.(*remote.MountableLayer)
+		require.True(t, ok, "layer pushed to other repo should be mountable")
+		require.Equal(t, "registry.example.com/deckhouse", ml.Reference.Context().String())
+	}
+
+	layers, err = WithCrossRepoMounts(img, "registry.example.com/deckhouse", progress).Layers()
+	require.NoError(t, err)
+	for _, l := range layers {
+		_, ok := l.(*remote.MountableLayer)
+		require.False(t, ok, "layer pushed to the same repo should not be mounted")
+	}
+}
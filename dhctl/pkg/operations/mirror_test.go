@@ -96,6 +96,141 @@ func TestMirrorE2E_Insecure(t *testing.T) {
 	require.Subset(t, sourceBlobHandler.ListBlobs(), targetBlobHandler.ListBlobs())
 }
 
+func TestMirrorE2E_PushFromChunkedBundle(t *testing.T) {
+	logger.InitLogger("pretty")
+
+	tmpDir, err := os.MkdirTemp(os.TempDir(), "mirror_e2e_bundle")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = os.RemoveAll(tmpDir)
+	})
+
+	sourceHost, sourceRepoPath, sourceBlobHandler := setupEmptyRegistryRepo(false)
+	targetHost, targetRepoPath, targetBlobHandler := setupEmptyRegistryRepo(false)
+
+	createDeckhouseControllersAndInstallersInRegistry(t, sourceHost+sourceRepoPath)
+	createTrivyVulnerabilityDatabaseInRegistry(t, sourceHost+sourceRepoPath, true, false)
+	createDeckhouseReleaseChannelsInRegistry(t, sourceHost+sourceRepoPath)
+
+	pullCtx := &mirror.Context{
+		Insecure:              true,
+		BundlePath:            filepath.Join(tmpDir, "d8.tar"),
+		BundleChunkSize:       16 * 1024,
+		DeckhouseRegistryRepo: sourceHost + sourceRepoPath,
+		UnpackedImagesPath:    filepath.Join(tmpDir, "pull"),
+		ValidationMode:        mirror.NoValidation,
+	}
+	pushCtx := &mirror.Context{
+		Insecure:              true,
+		BundlePath:            filepath.Join(tmpDir, "d8.tar"),
+		DeckhouseRegistryRepo: sourceHost + sourceRepoPath,
+		RegistryHost:          targetHost,
+		RegistryPath:          targetRepoPath,
+		ValidationMode:        mirror.NoValidation,
+		PushWorkers:           2,
+		PushProgressPath:      filepath.Join(tmpDir, "push", "progress.json"),
+	}
+
+	err = MirrorDeckhouseToLocalFS(pullCtx, []semver.Version{*semver.MustParse("v1.56.5")})
+	require.NoError(t, err, "Pull should be completed without errors")
+	require.NoError(t, mirror.PackBundle(pullCtx))
+	require.NoError(t, os.RemoveAll(pullCtx.UnpackedImagesPath))
+
+	err = PushDeckhouseToRegistry(pushCtx)
+	require.NoError(t, err, "Push should be completed without errors")
+
+	require.Subset(t, sourceBlobHandler.ListBlobs(), targetBlobHandler.ListBlobs())
+	require.NotEmpty(t, targetBlobHandler.ListBlobs())
+	require.NoFileExists(t, pushCtx.PushProgressPath, "Progress should be removed after the push is completed")
+}
+
+func TestMirrorE2E_DeltaBundle(t *testing.T) {
+	logger.InitLogger("pretty")
+
+	tmpDir, err := os.MkdirTemp(os.TempDir(), "mirror_e2e_delta")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = os.RemoveAll(tmpDir)
+	})
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "full"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "delta"), 0755))
+
+	sourceHost, sourceRepoPath, sourceBlobHandler := setupEmptyRegistryRepo(false)
+	targetHost, targetRepoPath, targetBlobHandler := setupEmptyRegistryRepo(false)
+	emptyHost, emptyRepoPath, _ := setupEmptyRegistryRepo(false)
+
+	createDeckhouseControllersAndInstallersInRegistry(t, sourceHost+sourceRepoPath)
+	createTrivyVulnerabilityDatabaseInRegistry(t, sourceHost+sourceRepoPath, true, false)
+	createDeckhouseReleaseChannelsInRegistry(t, sourceHost+sourceRepoPath)
+
+	fullPullCtx := &mirror.Context{
+		Insecure:              true,
+		BundlePath:            filepath.Join(tmpDir, "full", "d8.tar"),
+		DeckhouseRegistryRepo: sourceHost + sourceRepoPath,
+		UnpackedImagesPath:    filepath.Join(tmpDir, "full-pull"),
+		ValidationMode:        mirror.NoValidation,
+		SpecificVersion:       semver.MustParse("v1.55.7"),
+	}
+	require.NoError(t, MirrorDeckhouseToLocalFS(fullPullCtx, []semver.Version{*semver.MustParse("v1.55.7")}))
+	require.NoError(t, mirror.PackBundle(fullPullCtx))
+
+	fullManifest, err := mirror.LoadBundleManifest(filepath.Join(tmpDir, "full", "d8.manifest.json"))
+	require.NoError(t, err)
+	require.False(t, fullManifest.IsDelta())
+
+	deltaPullCtx := &mirror.Context{
+		Insecure:              true,
+		BundlePath:            filepath.Join(tmpDir, "delta", "d8.tar"),
+		DeckhouseRegistryRepo: sourceHost + sourceRepoPath,
+		UnpackedImagesPath:    filepath.Join(tmpDir, "delta-pull"),
+		ValidationMode:        mirror.NoValidation,
+		SinceBundle:           fullManifest,
+	}
+	require.NoError(t, MirrorDeckhouseToLocalFS(deltaPullCtx, []semver.Version{*semver.MustParse("v1.56.5"), *semver.MustParse("v1.55.7")}))
+	require.NoError(t, mirror.PackBundle(deltaPullCtx))
+
+	deltaManifest, err := mirror.LoadBundleManifest(deltaPullCtx.BundlePath)
+	require.NoError(t, err)
+	require.True(t, deltaManifest.IsDelta())
+	require.Equal(t, fullManifest.ID, deltaManifest.Parent)
+	require.NotEmpty(t, deltaManifest.ParentImages)
+
+	deltaBundle, err := mirror.OpenBundle(deltaPullCtx.BundlePath)
+	require.NoError(t, err)
+	for digest := range fullManifest.Layers {
+		hash, err := v1.NewHash(digest)
+		require.NoError(t, err)
+		_, err = deltaBundle.Open(filepath.Join("blobs", hash.Algorithm, hash.Hex))
+		require.Error(t, err, "Layers of the parent bundle should not be packed into the delta bundle")
+	}
+	require.NoError(t, deltaBundle.Close())
+
+	pushCtx := func(host, repoPath, bundlePath string) *mirror.Context {
+		return &mirror.Context{
+			Insecure:              true,
+			BundlePath:            bundlePath,
+			DeckhouseRegistryRepo: sourceHost + sourceRepoPath,
+			RegistryHost:          host,
+			RegistryPath:          repoPath,
+			ValidationMode:        mirror.NoValidation,
+		}
+	}
+
+	err = PushDeckhouseToRegistry(pushCtx(emptyHost, emptyRepoPath, deltaPullCtx.BundlePath))
+	require.ErrorContains(t, err, "push the parent bundle first")
+
+	require.NoError(t, PushDeckhouseToRegistry(pushCtx(targetHost, targetRepoPath, fullPullCtx.BundlePath)))
+	require.NoError(t, PushDeckhouseToRegistry(pushCtx(targetHost, targetRepoPath, deltaPullCtx.BundlePath)))
+
+	require.Subset(t, sourceBlobHandler.ListBlobs(), targetBlobHandler.ListBlobs())
+	for _, img := range append(deltaManifest.Images, deltaManifest.ParentImages...) {
+		ref, err := name.ParseReference(mirror.TargetRepoForLayout(pushCtx(targetHost, targetRepoPath, ""), img.Layout)+"@"+img.Digest, name.Insecure)
+		require.NoError(t, err)
+		_, err = remote.Head(ref)
+		require.NoError(t, err, "Image %s should be pushed", ref)
+	}
+}
+
 func setupEmptyRegistryRepo(useTLS bool) (host, repoPath string, blobHandler *ListableBlobHandler) {
 	memBlobHandler := registry.NewInMemoryBlobHandler()
 	bh := &ListableBlobHandler{
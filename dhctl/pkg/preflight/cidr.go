@@ -0,0 +1,121 @@
+// Copyright 2024 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preflight
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/app"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
+)
+
+// routeTypes are the route types which precede the destination in the 'ip route' output
+var routeTypes = map[string]struct{}{
+	"unicast": {}, "local": {}, "broadcast": {}, "multicast": {}, "throw": {},
+	"unreachable": {}, "prohibit": {}, "blackhole": {}, "nat": {},
+}
+
+func (pc *Checker) CheckCIDRIntersection() error {
+	if app.PreflightSkipCIDRIntersection {
+		log.InfoLn("CIDR intersection preflight check was skipped")
+		return nil
+	}
+
+	if pc.metaConfig == nil {
+		return nil
+	}
+
+	subnets := make(map[string]string, 2)
+	for _, key := range []string{"podSubnetCIDR", "serviceSubnetCIDR"} {
+		var cidr string
+		if err := json.Unmarshal(pc.metaConfig.ClusterConfig[key], &cidr); err != nil {
+			return fmt.Errorf("Could not get %s from ClusterConfiguration: %w", key, err)
+		}
+		subnets[key] = cidr
+	}
+
+	log.DebugLn("Checking intersection of pod and service subnets with the node routes")
+
+	out, _, err := pc.sshClient.Command("ip", "-4", "route", "show").Output()
+	if err != nil {
+		return fmt.Errorf("Could not get routes of the node: %w", err)
+	}
+
+	routes, err := parseRoutes(string(out))
+	if err != nil {
+		return err
+	}
+
+	var errs []string
+	for _, key := range []string{"podSubnetCIDR", "serviceSubnetCIDR"} {
+		_, subnet, err := net.ParseCIDR(subnets[key])
+		if err != nil {
+			return fmt.Errorf("Could not parse %s %q: %w", key, subnets[key], err)
+		}
+
+		for _, route := range routes {
+			if subnetsIntersect(subnet, route) {
+				errs = append(errs, fmt.Sprintf("%s %s intersects with the node route %s", key, subnet, route))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("Cluster subnets intersect with the node networks:\n%s", strings.Join(errs, "\n"))
+	}
+
+	return nil
+}
+
+// parseRoutes returns destinations of the 'ip -4 route show' output except the default route
+func parseRoutes(ipRouteOutput string) ([]*net.IPNet, error) {
+	var routes []*net.IPNet
+
+	for _, line := range strings.Split(ipRouteOutput, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		dst := fields[0]
+		if _, ok := routeTypes[dst]; ok && len(fields) > 1 {
+			dst = fields[1]
+		}
+
+		if dst == "default" {
+			continue
+		}
+
+		if !strings.Contains(dst, "/") {
+			dst += "/32"
+		}
+
+		_, route, err := net.ParseCIDR(dst)
+		if err != nil {
+			return nil, fmt.Errorf("Could not parse the node route %q: %w", line, err)
+		}
+
+		routes = append(routes, route)
+	}
+
+	return routes, nil
+}
+
+func subnetsIntersect(a, b *net.IPNet) bool {
+	return a.Contains(b.IP) || b.Contains(a.IP)
+}
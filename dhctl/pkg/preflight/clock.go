@@ -0,0 +1,76 @@
+// Copyright 2024 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preflight
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/app"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
+)
+
+// maxClockSkew is the maximum allowed difference between the node clock and the local clock
+const maxClockSkew = 10 * time.Second
+
+func (pc *Checker) CheckClockSkew() error {
+	if app.PreflightSkipClockSkew {
+		log.InfoLn("Clock skew preflight check was skipped")
+		return nil
+	}
+
+	log.DebugLn("Checking clock skew between the node and the local machine")
+
+	before := time.Now()
+	out, _, err := pc.sshClient.Command("date", "+%s").Output()
+	if err != nil {
+		return fmt.Errorf("Could not get the time on the node: %w", err)
+	}
+	after := time.Now()
+
+	// the node time is compared with the middle of the command execution to exclude the connection time
+	local := before.Add(after.Sub(before) / 2)
+
+	skew, err := clockSkew(string(out), local)
+	if err != nil {
+		return err
+	}
+
+	log.DebugF("Clock skew between the node and the local machine: %s\n", skew)
+
+	if skew > maxClockSkew {
+		return fmt.Errorf("Clock skew between the node and the local machine is %s, it must be less than %s", skew, maxClockSkew)
+	}
+
+	return nil
+}
+
+// clockSkew returns the absolute difference between unix time in the output of 'date +%s' and the local time
+func clockSkew(dateOutput string, local time.Time) (time.Duration, error) {
+	seconds, err := strconv.ParseInt(strings.TrimSpace(dateOutput), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Could not parse the time on the node %q: %w", dateOutput, err)
+	}
+
+	skew := local.Sub(time.Unix(seconds, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+
+	// 'date +%s' has a one second resolution
+	return skew.Truncate(time.Second), nil
+}
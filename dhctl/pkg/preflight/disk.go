@@ -0,0 +1,115 @@
+// Copyright 2024 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preflight
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/app"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
+)
+
+const gib = 1024 * 1024 * 1024
+
+type diskRequirement struct {
+	path  string
+	bytes uint64
+}
+
+// requiredDiskSpace contains minimal free space for the container runtime, etcd and kubelet data (/var/lib)
+// and for the deckhouse binaries (/opt)
+var requiredDiskSpace = []diskRequirement{
+	{path: "/var/lib", bytes: 20 * gib},
+	{path: "/opt", bytes: 1 * gib},
+}
+
+func (pc *Checker) CheckDiskSpace() error {
+	if app.PreflightSkipDiskSpace {
+		log.InfoLn("Disk space preflight check was skipped")
+		return nil
+	}
+
+	log.DebugLn("Checking free disk space on the node")
+
+	args := []string{"-P", "-k"}
+	for _, r := range requiredDiskSpace {
+		args = append(args, r.path)
+	}
+
+	out, _, err := pc.sshClient.Command("df", args...).Output()
+	if err != nil {
+		return fmt.Errorf("Could not get free disk space on the node: %w", err)
+	}
+
+	return checkDiskSpace(string(out), requiredDiskSpace)
+}
+
+// checkDiskSpace parses the output of 'df -P -k' for the required paths in the same order.
+// Requirements of paths on the same filesystem are summed up.
+func checkDiskSpace(dfOutput string, requirements []diskRequirement) error {
+	lines := strings.Split(strings.TrimSpace(dfOutput), "\n")
+	if len(lines) != len(requirements)+1 {
+		return fmt.Errorf("Unexpected df output:\n%s", dfOutput)
+	}
+
+	type mount struct {
+		paths     []string
+		available uint64
+		required  uint64
+	}
+
+	mounts := make(map[string]*mount)
+	order := make([]string, 0, len(requirements))
+
+	for i, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) < 6 {
+			return fmt.Errorf("Unexpected df output line: %q", line)
+		}
+
+		availableKB, err := strconv.ParseUint(fields[3], 10, 64)
+		if err != nil {
+			return fmt.Errorf("Could not parse available space in df output line %q: %w", line, err)
+		}
+
+		mountPoint := fields[5]
+		m, ok := mounts[mountPoint]
+		if !ok {
+			m = &mount{available: availableKB * 1024}
+			mounts[mountPoint] = m
+			order = append(order, mountPoint)
+		}
+
+		m.paths = append(m.paths, requirements[i].path)
+		m.required += requirements[i].bytes
+	}
+
+	var errs []string
+	for _, mountPoint := range order {
+		m := mounts[mountPoint]
+		if m.available < m.required {
+			errs = append(errs, fmt.Sprintf("%s (mounted on %s): %.1f GiB available, %.1f GiB required",
+				strings.Join(m.paths, ", "), mountPoint, float64(m.available)/gib, float64(m.required)/gib))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("Not enough free disk space on the node:\n%s", strings.Join(errs, "\n"))
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2024 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preflight
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/app"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
+)
+
+// minimalKernelVersionKey is the key of the version map (candi/version_map.yml) with the kernel constraint,
+// the same constraint is checked by the check-kernel-version init container of cni-cilium
+const minimalKernelVersionKey = "minimalKernelVersion"
+
+func (pc *Checker) CheckKernelVersion() error {
+	if app.PreflightSkipKernelVersion {
+		log.InfoLn("Kernel version preflight check was skipped")
+		return nil
+	}
+
+	log.DebugLn("Checking the node kernel version")
+
+	out, _, err := pc.sshClient.Command("uname", "-r").Output()
+	if err != nil {
+		return fmt.Errorf("Could not get the kernel version of the node: %w", err)
+	}
+
+	constraint, err := pc.minimalKernelVersionConstraint()
+	if err != nil {
+		return err
+	}
+
+	return checkKernelVersion(strings.TrimSpace(string(out)), constraint)
+}
+
+func (pc *Checker) minimalKernelVersionConstraint() (string, error) {
+	if pc.metaConfig != nil {
+		if constraint, ok := pc.metaConfig.VersionMap[minimalKernelVersionKey].(string); ok && constraint != "" {
+			return constraint, nil
+		}
+	}
+
+	return "", fmt.Errorf("Could not find %q in the version map", minimalKernelVersionKey)
+}
+
+func checkKernelVersion(kernelVersion, constraint string) error {
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return err
+	}
+
+	// Versions like `5.15.0-52-generic` are parsed by semver as prerelease versions
+	// which are less than `5.15`, so only the part before the dash is compared.
+	v, err := semver.NewVersion(strings.Split(kernelVersion, "-")[0])
+	if err != nil {
+		return fmt.Errorf("Could not parse the kernel version %q: %w", kernelVersion, err)
+	}
+
+	if !c.Check(v) {
+		return fmt.Errorf("The kernel %s does not meet the requirements: %s", kernelVersion, constraint)
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+// Copyright 2024 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preflight
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/config"
+)
+
+func TestClockSkew(t *testing.T) {
+	local := time.Unix(1700000000, 500000000)
+
+	skew, err := clockSkew("1700000000\n", local)
+	require.NoError(t, err)
+	require.Equal(t, time.Duration(0), skew)
+
+	skew, err = clockSkew("1700000030\n", local)
+	require.NoError(t, err)
+	require.Equal(t, 29*time.Second, skew)
+
+	_, err = clockSkew("Thu Nov 16 00:00:00 UTC 2023", local)
+	require.Error(t, err)
+}
+
+func TestCheckKernelVersion(t *testing.T) {
+	require.NoError(t, checkKernelVersion("5.15.0-52-generic", ">= 4.9.17"))
+	require.NoError(t, checkKernelVersion("4.9.17", ">= 4.9.17"))
+	require.Error(t, checkKernelVersion("3.10.0-1160.el7.x86_64", ">= 4.9.17"))
+	require.Error(t, checkKernelVersion("unknown", ">= 4.9.17"))
+}
+
+func TestMinimalKernelVersionConstraint(t *testing.T) {
+	metaConfig := &config.MetaConfig{}
+	require.NoError(t, metaConfig.LoadVersionMap("../../../candi/version_map.yml"))
+
+	pc := &Checker{metaConfig: metaConfig}
+	constraint, err := pc.minimalKernelVersionConstraint()
+	require.NoError(t, err)
+	require.NoError(t, checkKernelVersion("4.9.17", constraint))
+
+	pc = &Checker{metaConfig: &config.MetaConfig{}}
+	_, err = pc.minimalKernelVersionConstraint()
+	require.Error(t, err)
+}
+
+func TestCheckDiskSpace(t *testing.T) {
+	requirements := []diskRequirement{
+		{path: "/var/lib", bytes: 20 * gib},
+		{path: "/opt", bytes: 1 * gib},
+	}
+
+	t.Run("separate filesystems", func(t *testing.T) {
+		out := `Filesystem     1024-blocks     Used Available Capacity Mounted on
+/dev/sdb1         51475068  1048576  47784808       3% /var/lib
+/dev/sda1         20511312 10485760   2097152      84% /
+`
+		require.NoError(t, checkDiskSpace(out, requirements))
+	})
+
+	t.Run("requirements of the same filesystem are summed up", func(t *testing.T) {
+		out := `Filesystem     1024-blocks     Used Available Capacity Mounted on
+/dev/sda1         51475068 30408704  21495808      59% /
+/dev/sda1         51475068 30408704  21495808      59% /
+`
+		err := checkDiskSpace(out, requirements)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "/var/lib, /opt (mounted on /)")
+	})
+
+	t.Run("unexpected output", func(t *testing.T) {
+		require.Error(t, checkDiskSpace("df: /opt: No such file or directory", requirements))
+	})
+}
+
+func TestParseRoutes(t *testing.T) {
+	out := `default via 192.168.1.1 dev eth0 proto dhcp metric 100
+10.0.0.0/8 via 192.168.1.254 dev eth0
+blackhole 172.16.0.0/12
+192.168.1.0/24 dev eth0 proto kernel scope link src 192.168.1.10
+192.168.100.5 dev tun0 scope link
+`
+	routes, err := parseRoutes(out)
+	require.NoError(t, err)
+	require.Len(t, routes, 4)
+	require.Equal(t, "192.168.100.5/32", routes[3].String())
+
+	_, podSubnet, _ := net.ParseCIDR("10.111.0.0/16")
+	_, serviceSubnet, _ := net.ParseCIDR("10.222.0.0/16")
+	_, otherSubnet, _ := net.ParseCIDR("100.64.0.0/16")
+
+	require.True(t, subnetsIntersect(podSubnet, routes[0]))
+	require.True(t, subnetsIntersect(serviceSubnet, routes[0]))
+	for _, route := range routes {
+		require.False(t, subnetsIntersect(otherSubnet, route))
+	}
+}
+
+func TestPublicDomainTemplate(t *testing.T) {
+	mcs := []*config.ModuleConfig{
+		{Spec: config.ModuleConfigSpec{Enabled: nil}},
+		{Spec: config.ModuleConfigSpec{Settings: config.SettingsValues{
+			"modules": map[string]interface{}{"publicDomainTemplate": "%s.example.com"},
+		}}},
+	}
+	mcs[0].SetName("deckhouse")
+	mcs[1].SetName("global")
+
+	require.Equal(t, "%s.example.com", publicDomainTemplate(mcs))
+	require.Equal(t, "", publicDomainTemplate(mcs[:1]))
+}
@@ -34,8 +34,11 @@ type Checker struct {
 }
 
 type checkStep struct {
+	id             string
 	successMessage string
 	skipFlag       string
+	skipped        bool
+	remediation    string
 	fun            func() error
 }
 
@@ -49,40 +52,196 @@ func NewChecker(sshClient *ssh.Client, config *config.DeckhouseInstaller, metaCo
 	}
 }
 
-func (pc *Checker) Static() error {
-	return pc.do("Preflight checks for static-cluster", []checkStep{
+func (pc *Checker) staticChecks() []checkStep {
+	return []checkStep{
 		{
+			id:             SSHForwardCheckID,
 			fun:            pc.CheckSSHTunnel,
 			successMessage: "ssh tunnel will up",
 			skipFlag:       app.SSHForwardArgName,
+			skipped:        app.PreflightSkipSSHForword,
+			remediation:    "Check connectivity to the node and set 'AllowTcpForwarding yes' in the sshd config.",
 		},
 		{
+			id:             RegistryThroughProxyCheckID,
 			fun:            pc.CheckRegistryAccessThroughProxy,
 			successMessage: "registry access through proxy",
 			skipFlag:       app.RegistryThroughProxyCheckArgName,
+			skipped:        app.PreflightSkipRegistryThroughProxy,
+			remediation:    "Check proxy settings in ClusterConfiguration and that the registry is accessible through the proxy from the node.",
 		},
 		{
+			id:             PortsAvailabilityCheckID,
 			fun:            pc.CheckAvailabilityPorts,
 			successMessage: "required ports availability",
 			skipFlag:       app.PortsAvailabilityArgName,
+			skipped:        app.PreflightSkipAvailabilityPorts,
+			remediation:    "Stop the processes which listen on ports required by Kubernetes components on the node.",
 		},
 		{
+			id:             LocalhostDomainCheckID,
 			fun:            pc.CheckLocalhostDomain,
 			successMessage: "resolve the localhost domain",
 			skipFlag:       app.ResolvingLocalhostArgName,
+			skipped:        app.PreflightSkipResolvingLocalhost,
+			remediation:    "Make sure that the localhost domain resolves to 127.0.0.1 on the node (check /etc/hosts).",
 		},
-	})
+		{
+			id:             ClockSkewCheckID,
+			fun:            pc.CheckClockSkew,
+			successMessage: "clock skew between the node and the local machine",
+			skipFlag:       app.ClockSkewArgName,
+			skipped:        app.PreflightSkipClockSkew,
+			remediation:    "Configure time synchronization (chrony or systemd-timesyncd) on the node and on the machine running dhctl.",
+		},
+		{
+			id:             KernelVersionCheckID,
+			fun:            pc.CheckKernelVersion,
+			successMessage: "node kernel version",
+			skipFlag:       app.KernelVersionArgName,
+			skipped:        app.PreflightSkipKernelVersion,
+			remediation:    "Upgrade the kernel on the node or use a supported OS distribution.",
+		},
+		{
+			id:             DiskSpaceCheckID,
+			fun:            pc.CheckDiskSpace,
+			successMessage: "free disk space on the node",
+			skipFlag:       app.DiskSpaceArgName,
+			skipped:        app.PreflightSkipDiskSpace,
+			remediation:    "Free up or extend the disk space for /var/lib and /opt on the node.",
+		},
+		{
+			id:             PublicDomainTemplateCheckID,
+			fun:            pc.CheckPublicDomainTemplate,
+			successMessage: "resolve the public domain template",
+			skipFlag:       app.PublicDomainTemplateArgName,
+			skipped:        app.PreflightSkipPublicDomainTemplate,
+			remediation:    "Create a wildcard DNS record for the publicDomainTemplate of the global ModuleConfig and check DNS servers of the node.",
+		},
+		{
+			id:             CIDRIntersectionCheckID,
+			fun:            pc.CheckCIDRIntersection,
+			successMessage: "pod and service subnets do not intersect with the node routes",
+			skipFlag:       app.CIDRIntersectionArgName,
+			skipped:        app.PreflightSkipCIDRIntersection,
+			remediation:    "Change podSubnetCIDR and serviceSubnetCIDR in ClusterConfiguration so that they do not intersect with the node networks.",
+		},
+		{
+			id:             RequiredBinariesCheckID,
+			fun:            pc.CheckRequiredBinaries,
+			successMessage: "required binaries on the node",
+			skipFlag:       app.RequiredBinariesArgName,
+			skipped:        app.PreflightSkipRequiredBinaries,
+			remediation:    "Install the missing packages on the node.",
+		},
+	}
 }
 
-func (pc *Checker) Cloud() error {
+func (pc *Checker) cloudChecks() []checkStep {
 	return nil
 }
 
-func (pc *Checker) Global() error {
+func (pc *Checker) globalChecks() []checkStep {
 	return nil
 }
 
+// standaloneChecks are run by "dhctl preflight" only, they are not a part of the bootstrap
+func (pc *Checker) standaloneChecks() []checkStep {
+	return []checkStep{
+		{
+			id:             DeckhouseVersionCheckID,
+			fun:            pc.CheckDhctlVersionObsolescence,
+			successMessage: "dhctl version compatibility",
+			skipFlag:       app.DeckhouseVersionCheckArgName,
+			skipped:        app.PreflightSkipDeckhouseVersionCheck,
+			remediation:    "Pull the installer image of the release channel with '--pull=always'.",
+		},
+	}
+}
+
+func (pc *Checker) Static() error {
+	return pc.do("Preflight checks for static-cluster", pc.staticChecks())
+}
+
+func (pc *Checker) Cloud() error {
+	return pc.do("Preflight checks for cloud-cluster", pc.cloudChecks())
+}
+
+func (pc *Checker) Global() error {
+	return pc.do("Global preflight checks", pc.globalChecks())
+}
+
+// RunAll runs all checks for the cluster type without stopping at the first failure
+func (pc *Checker) RunAll() *Report {
+	checks := append(pc.standaloneChecks(), pc.globalChecks()...)
+	var suite string
+
+	if pc.metaConfig != nil && pc.metaConfig.ClusterType == config.CloudClusterType {
+		checks = append(checks, pc.cloudChecks()...)
+		suite = "cloud"
+	} else {
+		checks = append(checks, pc.staticChecks()...)
+		suite = "static"
+	}
+
+	report := &Report{Suite: suite}
+
+	_ = log.Process("common", "Preflight checks", func() error {
+		for _, check := range checks {
+			report.Results = append(report.Results, pc.runCheck(check))
+		}
+		return nil
+	})
+
+	return report
+}
+
+func (pc *Checker) runCheck(check checkStep) CheckResult {
+	result := CheckResult{
+		ID:    check.id,
+		Title: check.successMessage,
+	}
+
+	if isSkipped(check) {
+		log.InfoF("Preflight check %q was skipped\n", check.id)
+		result.Status = CheckStatusSkipped
+		return result
+	}
+
+	start := time.Now()
+	err := retry.NewLoop(fmt.Sprintf("Checking %s", check.successMessage), 1, 10*time.Second).Run(check.fun)
+	result.Duration = time.Since(start)
+
+	if err != nil {
+		result.Status = CheckStatusFailed
+		result.Error = err.Error()
+		result.Remediation = check.remediation
+		return result
+	}
+
+	result.Status = CheckStatusPassed
+	return result
+}
+
+func isSkipped(check checkStep) bool {
+	if app.PreflightSkipAll || check.skipped {
+		return true
+	}
+
+	for _, id := range app.PreflightSkipChecks {
+		if id == check.id {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (pc *Checker) do(title string, checks []checkStep) error {
+	if len(checks) == 0 {
+		return nil
+	}
+
 	return log.Process("common", title, func() error {
 		if app.PreflightSkipAll {
 			log.WarnLn("Preflight checks were skipped")
@@ -90,10 +249,15 @@ func (pc *Checker) do(title string, checks []checkStep) error {
 		}
 
 		for _, check := range checks {
+			if isSkipped(check) {
+				log.InfoF("Preflight check %q was skipped\n", check.id)
+				continue
+			}
+
 			loop := retry.NewLoop(fmt.Sprintf("Checking %s", check.successMessage), 1, 10*time.Second)
 			if err := loop.Run(check.fun); err != nil {
 				return fmt.Errorf("Installation aborted: %w\n"+
-					`Please fix this problem or skip it if you're sure with %s flag`, err, check.skipFlag)
+					`Please fix this problem or skip it if you're sure with %s flag or --%s=%s`, err, check.skipFlag, app.PreflightSkipCheckArgName, check.id)
 			}
 		}
 
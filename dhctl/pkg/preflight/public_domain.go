@@ -0,0 +1,75 @@
+// Copyright 2024 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preflight
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/app"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/config"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
+)
+
+// publicDomainCheckName is substituted into the public domain template, the documentation module is enabled by default
+const publicDomainCheckName = "documentation"
+
+func (pc *Checker) CheckPublicDomainTemplate() error {
+	if app.PreflightSkipPublicDomainTemplate {
+		log.InfoLn("Public domain template preflight check was skipped")
+		return nil
+	}
+
+	var moduleConfigs []*config.ModuleConfig
+	if pc.metaConfig != nil {
+		moduleConfigs = pc.metaConfig.ModuleConfigs
+	}
+
+	domainTemplate := publicDomainTemplate(moduleConfigs)
+	if domainTemplate == "" {
+		log.DebugLn("Public domain template is not set, skipping the check")
+		return nil
+	}
+
+	domain := strings.ReplaceAll(domainTemplate, "%s", publicDomainCheckName)
+	log.DebugF("Checking if %s resolves on the node\n", domain)
+
+	out, _, err := pc.sshClient.Command("getent", "ahosts", domain).Output()
+	if err != nil {
+		return fmt.Errorf("Domain %s of the public domain template %q is not resolved on the node: %w", domain, domainTemplate, err)
+	}
+
+	log.DebugLn(string(out))
+	return nil
+}
+
+// publicDomainTemplate returns modules.publicDomainTemplate from the global ModuleConfig
+func publicDomainTemplate(moduleConfigs []*config.ModuleConfig) string {
+	for _, mc := range moduleConfigs {
+		if mc == nil || mc.GetName() != "global" {
+			continue
+		}
+
+		modules, ok := mc.Spec.Settings["modules"].(map[string]interface{})
+		if !ok {
+			return ""
+		}
+
+		tpl, _ := modules["publicDomainTemplate"].(string)
+		return tpl
+	}
+
+	return ""
+}
@@ -0,0 +1,204 @@
+// Copyright 2024 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preflight
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Stable check IDs, they are used to skip checks with the --preflight-skip-check flag
+const (
+	SSHForwardCheckID           = "ssh-forward"
+	RegistryThroughProxyCheckID = "registry-through-proxy"
+	PortsAvailabilityCheckID    = "ports-availability"
+	LocalhostDomainCheckID      = "localhost-domain"
+	ClockSkewCheckID            = "clock-skew"
+	KernelVersionCheckID        = "kernel-version"
+	DiskSpaceCheckID            = "disk-space"
+	PublicDomainTemplateCheckID = "public-domain-template"
+	CIDRIntersectionCheckID     = "cidr-intersection"
+	RequiredBinariesCheckID     = "required-binaries"
+	DeckhouseVersionCheckID     = "deckhouse-version"
+)
+
+const (
+	ReportFormatText  = "text"
+	ReportFormatJSON  = "json"
+	ReportFormatJUnit = "junit"
+)
+
+type CheckStatus string
+
+const (
+	CheckStatusPassed  CheckStatus = "passed"
+	CheckStatusFailed  CheckStatus = "failed"
+	CheckStatusSkipped CheckStatus = "skipped"
+)
+
+type CheckResult struct {
+	ID          string        `json:"id"`
+	Title       string        `json:"title"`
+	Status      CheckStatus   `json:"status"`
+	Duration    time.Duration `json:"-"`
+	Error       string        `json:"error,omitempty"`
+	Remediation string        `json:"remediation,omitempty"`
+}
+
+func (r CheckResult) MarshalJSON() ([]byte, error) {
+	type result CheckResult
+	return json.Marshal(struct {
+		result
+		DurationSeconds float64 `json:"durationSeconds"`
+	}{
+		result:          result(r),
+		DurationSeconds: r.Duration.Seconds(),
+	})
+}
+
+// Report contains results of all preflight checks
+type Report struct {
+	Suite   string        `json:"suite"`
+	Results []CheckResult `json:"results"`
+}
+
+func (r *Report) Failed() bool {
+	for _, result := range r.Results {
+		if result.Status == CheckStatusFailed {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Report) count(status CheckStatus) int {
+	var n int
+	for _, result := range r.Results {
+		if result.Status == status {
+			n++
+		}
+	}
+
+	return n
+}
+
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case ReportFormatJSON:
+		return r.WriteJSON(w)
+	case ReportFormatJUnit:
+		return r.WriteJUnit(w)
+	case ReportFormatText, "":
+		return r.WriteText(w)
+	}
+
+	return fmt.Errorf("unknown report format %q", format)
+}
+
+func (r *Report) WriteText(w io.Writer) error {
+	for _, result := range r.Results {
+		_, err := fmt.Fprintf(w, "[%s] %s (%s)\n", result.Status, result.ID, result.Duration.Round(time.Millisecond))
+		if err != nil {
+			return err
+		}
+
+		if result.Status != CheckStatusFailed {
+			continue
+		}
+
+		_, err = fmt.Fprintf(w, "\terror: %s\n\tremediation: %s\n", result.Error, result.Remediation)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+type junitTestSuite struct {
+	XMLName  xml.Name        `xml:"testsuite"`
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *struct{}     `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func (r *Report) WriteJUnit(w io.Writer) error {
+	suite := junitTestSuite{
+		Name:     "preflight." + r.Suite,
+		Tests:    len(r.Results),
+		Failures: r.count(CheckStatusFailed),
+		Skipped:  r.count(CheckStatusSkipped),
+	}
+
+	var total time.Duration
+	for _, result := range r.Results {
+		total += result.Duration
+
+		tc := junitTestCase{
+			Name:      result.ID,
+			ClassName: suite.Name,
+			Time:      fmt.Sprintf("%.3f", result.Duration.Seconds()),
+		}
+
+		switch result.Status {
+		case CheckStatusFailed:
+			tc.Failure = &junitFailure{Message: result.Error, Text: result.Remediation}
+		case CheckStatusSkipped:
+			tc.Skipped = &struct{}{}
+		}
+
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suite.Time = fmt.Sprintf("%.3f", total.Seconds())
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suite); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
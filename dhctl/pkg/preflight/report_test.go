@@ -0,0 +1,93 @@
+// Copyright 2024 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preflight
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/app"
+)
+
+func testReport() *Report {
+	return &Report{
+		Suite: "static",
+		Results: []CheckResult{
+			{ID: SSHForwardCheckID, Title: "ssh tunnel will up", Status: CheckStatusPassed, Duration: 1500 * time.Millisecond},
+			{ID: PortsAvailabilityCheckID, Title: "required ports availability", Status: CheckStatusFailed, Error: "port 6443 is busy", Remediation: "stop it"},
+			{ID: LocalhostDomainCheckID, Title: "resolve the localhost domain", Status: CheckStatusSkipped},
+		},
+	}
+}
+
+func TestReportJSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	require.NoError(t, testReport().Write(buf, ReportFormatJSON))
+
+	var decoded struct {
+		Suite   string                   `json:"suite"`
+		Results []map[string]interface{} `json:"results"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+
+	require.Equal(t, "static", decoded.Suite)
+	require.Len(t, decoded.Results, 3)
+	require.Equal(t, "ssh-forward", decoded.Results[0]["id"])
+	require.Equal(t, 1.5, decoded.Results[0]["durationSeconds"])
+	require.Equal(t, "failed", decoded.Results[1]["status"])
+	require.Equal(t, "stop it", decoded.Results[1]["remediation"])
+}
+
+func TestReportJUnit(t *testing.T) {
+	buf := new(bytes.Buffer)
+	require.NoError(t, testReport().Write(buf, ReportFormatJUnit))
+
+	out := buf.String()
+	require.Contains(t, out, `<testsuite name="preflight.static" tests="3" failures="1" skipped="1" time="1.500">`)
+	require.Contains(t, out, `<failure message="port 6443 is busy">stop it</failure>`)
+	require.Contains(t, out, `<skipped></skipped>`)
+}
+
+func TestReportFailed(t *testing.T) {
+	require.True(t, testReport().Failed())
+	require.False(t, (&Report{Results: []CheckResult{{Status: CheckStatusPassed}}}).Failed())
+}
+
+func TestCheckSkippedByID(t *testing.T) {
+	defer func() { app.PreflightSkipChecks = nil }()
+
+	var called bool
+	check := checkStep{id: PortsAvailabilityCheckID, fun: func() error {
+		called = true
+		return nil
+	}}
+
+	app.PreflightSkipChecks = []string{PortsAvailabilityCheckID}
+	pc := NewChecker(nil, nil, nil)
+	result := pc.runCheck(check)
+
+	require.False(t, called)
+	require.Equal(t, CheckStatusSkipped, result.Status)
+
+	app.PreflightSkipChecks = nil
+	result = pc.runCheck(check)
+
+	require.True(t, called)
+	require.Equal(t, CheckStatusPassed, result.Status)
+}
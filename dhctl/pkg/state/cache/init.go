@@ -55,6 +55,43 @@ func choiceCache(identity string, opts CacheOptions) (state.Cache, error) {
 		return cache.NewStateCache(tmpDir)
 	}
 
+	secretName := identity
+	if app.CacheKubeName != "" {
+		secretName = app.CacheKubeName
+	}
+
+	k8sCache, err := newK8sStateCache(secretName, tmpDir)
+	if err != nil {
+		return nil, err
+	}
+
+	hasTombstone, err := k8sCache.InCache(state.TombstoneKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if hasTombstone {
+		return nil, fmt.Errorf("Cache exchaused")
+	}
+
+	return k8sCache, nil
+}
+
+func newK8sStateCache(secretName, tmpDir string) (*client.StateCache, error) {
+	k8sCache, err := k8sStateCache(secretName, tmpDir)
+	if err != nil {
+		return nil, err
+	}
+
+	err = k8sCache.Init()
+	if err != nil {
+		return nil, err
+	}
+
+	return k8sCache, nil
+}
+
+func k8sStateCache(secretName, tmpDir string) (*client.StateCache, error) {
 	kubeCl := client.NewKubernetesClient()
 	err := kubeCl.Init(&client.KubernetesInitParams{
 		KubeConfig:          app.CacheKubeConfig,
@@ -65,26 +102,31 @@ func choiceCache(identity string, opts CacheOptions) (state.Cache, error) {
 		return nil, err
 	}
 
-	secretName := identity
-	if app.CacheKubeName != "" {
-		secretName = app.CacheKubeName
-	}
+	return client.NewK8sStateCache(kubeCl, app.CacheKubeNamespace, secretName, tmpDir).
+		WithLabels(app.CacheKubeLabels).
+		WithHistoryLimit(app.CacheKubeHistoryLimit), nil
+}
 
-	k8sCache := client.NewK8sStateCache(kubeCl, app.CacheKubeNamespace, secretName, tmpDir).
-		WithLabels(app.CacheKubeLabels)
+// KubernetesStateCache returns the existing state cache stored in the kubernetes secret
+// to inspect or restore its history. Namespace and name of the secret must be set by flags.
+func KubernetesStateCache() (*client.StateCache, error) {
+	if app.CacheKubeNamespace == "" || app.CacheKubeName == "" {
+		return nil, fmt.Errorf("--kube-cachestore-namespace and --kube-cachestore-name flags are required")
+	}
 
-	err = k8sCache.Init()
-	if err != nil {
-		return nil, err
+	tmpDir := filepath.Join(app.CacheDir, stringsutil.Sha256Encode(app.CacheKubeName))
+	if err := os.MkdirAll(tmpDir, 0o755); err != nil {
+		return nil, fmt.Errorf("can't create cache directory: %w", err)
 	}
 
-	hasTombstone, err := k8sCache.InCache(state.TombstoneKey)
+	k8sCache, err := k8sStateCache(app.CacheKubeName, tmpDir)
 	if err != nil {
 		return nil, err
 	}
 
-	if hasTombstone {
-		return nil, fmt.Errorf("Cache exchaused")
+	// do not create the state secret, there is nothing to inspect or restore if it does not exist
+	if err = k8sCache.Open(); err != nil {
+		return nil, err
 	}
 
 	return k8sCache, nil
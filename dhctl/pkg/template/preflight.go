@@ -19,6 +19,7 @@ import "github.com/deckhouse/deckhouse/dhctl/pkg/log"
 const (
 	checkPortsScriptPath     = candiBashibleDir + "/preflight/check_ports.sh"
 	checkLocalhostScriptPath = candiBashibleDir + "/preflight/check_localhost.sh"
+	checkBinariesScriptPath  = candiBashibleDir + "/preflight/check_binaries.sh"
 )
 
 func RenderAndSavePreflightCheckPortsScript() (string, error) {
@@ -32,3 +33,11 @@ func RenderAndSavePreflightCheckLocalhostScript() (string, error) {
 
 	return RenderAndSaveTemplate("check_localhost.sh", checkLocalhostScriptPath, map[string]interface{}{})
 }
+
+func RenderAndSavePreflightCheckBinariesScript(binaries []string) (string, error) {
+	log.DebugLn("Start render check binaries script")
+
+	return RenderAndSaveTemplate("check_binaries.sh", checkBinariesScriptPath, map[string]interface{}{
+		"binaries": binaries,
+	})
+}
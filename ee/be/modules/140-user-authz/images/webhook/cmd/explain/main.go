@@ -0,0 +1,196 @@
+/*
+Copyright 2024 Flant JSC
+Licensed under the Deckhouse Platform Enterprise Edition (EE) license. See https://github.com/deckhouse/deckhouse/blob/main/ee/LICENSE
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"user-authz-webhook/web"
+	"user-authz-webhook/web/hook"
+)
+
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringsFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+func main() {
+	var (
+		groups stringsFlag
+		query  = url.Values{}
+		params = []struct{ name, usage string }{
+			{"user", "name of the user"},
+			{"serviceAccount", "ServiceAccount in the namespace:name form instead of the user and groups"},
+			{"verb", "verb of the request, e.g., get"},
+			{"apiGroup", "API group of the resource, empty for the core group"},
+			{"version", "API version of the resource"},
+			{"resource", "resource of the request, e.g., pods"},
+			{"subresource", "subresource of the request, e.g., log"},
+			{"name", "name of the requested object"},
+			{"namespace", "namespace of the request"},
+		}
+		values = make(map[string]*string, len(params))
+	)
+
+	for _, param := range params {
+		values[param.name] = flag.String(param.name, "", param.usage)
+	}
+	flag.Var(&groups, "group", "group of the user, can be repeated")
+	matrix := flag.Bool("matrix", false, "print the access of the subject to all namespaces")
+	output := flag.String("o", "text", "output format: text or json")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), `Explains which authorization rules allow or deny a request.
+
+Usage:
+  explain -user=<name> [-group=<group> ...] [-verb=<verb> -resource=<resource> [-apiGroup=<group>] [-namespace=<namespace>]]
+  explain -serviceAccount=<namespace>:<name> [...]
+  explain -user=<name> [-group=<group> ...] -matrix
+
+`)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	for _, param := range params {
+		if v := *values[param.name]; v != "" {
+			query.Set(param.name, v)
+		}
+	}
+	for _, group := range groups {
+		query.Add("group", group)
+	}
+
+	path := "explain"
+	if *matrix {
+		path = "access-matrix"
+	}
+
+	body := get(path, query)
+
+	if *output == "json" {
+		os.Stdout.Write(body)
+		return
+	}
+
+	if *matrix {
+		var m hook.AccessMatrix
+		check(json.Unmarshal(body, &m))
+		printMatrix(os.Stdout, &m)
+		return
+	}
+
+	var e hook.Explanation
+	check(json.Unmarshal(body, &e))
+	printExplanation(os.Stdout, &e)
+}
+
+func get(path string, query url.Values) []byte {
+	client, err := web.NewClient()
+	check(err)
+
+	addr := url.URL{
+		Scheme:   "https",
+		Host:     web.ListenAddr,
+		Path:     path,
+		RawQuery: query.Encode(),
+	}
+	response, err := client.Get(addr.String())
+	check(err)
+
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	check(err)
+
+	if response.StatusCode != http.StatusOK {
+		log.Fatalln(strings.TrimSpace(string(body)))
+	}
+	return body
+}
+
+func printExplanation(w io.Writer, e *hook.Explanation) {
+	fmt.Fprintf(w, "Subject: %s %v\n", e.Subject.User, e.Subject.Groups)
+
+	fmt.Fprintln(w, "\nClusterAuthorizationRules:")
+	if len(e.Rules) == 0 {
+		fmt.Fprintln(w, "  none")
+	}
+	for _, rule := range e.Rules {
+		fmt.Fprintf(w, "  %s (subject %s, accessLevel %s)\n", rule.Name, rule.Subject, rule.AccessLevel)
+		if len(rule.LimitNamespaces) > 0 {
+			fmt.Fprintf(w, "    limitNamespaces: %v, matched: %v\n", rule.LimitNamespaces, rule.MatchedLimitNamespaces)
+		}
+		if rule.NamespaceSelector != nil {
+			fmt.Fprintf(w, "    namespaceSelector matched: %v\n", rule.NamespaceSelectorMatched)
+		}
+		if rule.AllowAccessToSystemNamespaces {
+			fmt.Fprintln(w, "    allowAccessToSystemNamespaces: true")
+		}
+		if rule.ValidFrom != "" || rule.ValidUntil != "" {
+			fmt.Fprintf(w, "    valid from %q until %q\n", rule.ValidFrom, rule.ValidUntil)
+		}
+	}
+
+	if e.Webhook != nil {
+		fmt.Fprintf(w, "\nWebhook: denied=%v %s\n", e.Webhook.Denied, e.Webhook.Reason)
+		for _, step := range e.Webhook.Steps {
+			fmt.Fprintf(w, "  - %s\n", step)
+		}
+	}
+
+	fmt.Fprintln(w, "\nRBAC bindings:")
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, grant := range e.RBAC.Grants {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", grant.Binding, grant.Role, grant.Rule)
+	}
+	tw.Flush()
+	if len(e.RBAC.Grants) == 0 {
+		fmt.Fprintln(w, "  none")
+	}
+
+	if e.Allowed != nil {
+		fmt.Fprintf(w, "\nSubjectAccessReview: allowed=%v %s\n", e.RBAC.Allowed, e.RBAC.Reason)
+		fmt.Fprintf(w, "\nAllowed: %v\n", *e.Allowed)
+	}
+}
+
+func printMatrix(w io.Writer, m *hook.AccessMatrix) {
+	fmt.Fprintf(w, "Subject: %s %v\n", m.Subject.User, m.Subject.Groups)
+	fmt.Fprintf(w, "Cluster roles: %s\n", strings.Join(m.ClusterRoles, ", "))
+	fmt.Fprintf(w, "Cluster-scoped requests for namespaced resources limited: %v\n\n", m.ClusterScopedLimited)
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAMESPACE\tACCESS\tROLES")
+	for _, ns := range m.Namespaces {
+		access := "allowed"
+		if ns.Denied {
+			access = "denied"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", ns.Namespace, access, strings.Join(ns.Roles, ", "))
+	}
+	tw.Flush()
+}
+
+func check(err error) {
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
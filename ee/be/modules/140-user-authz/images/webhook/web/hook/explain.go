@@ -0,0 +1,505 @@
+/*
+Copyright 2024 Flant JSC
+Licensed under the Deckhouse Platform Enterprise Edition (EE) license. See https://github.com/deckhouse/deckhouse/blob/main/ee/LICENSE
+*/
+
+package hook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const generatedBindingPrefix = "user-authz:"
+
+// trace collects the steps of the decision, a nil trace records nothing
+type trace []string
+
+func (t *trace) addf(format string, args ...interface{}) {
+	if t == nil {
+		return
+	}
+	*t = append(*t, fmt.Sprintf(format, args...))
+}
+
+// Subject is a user with its groups whose access is explained
+type Subject struct {
+	User   string   `json:"user"`
+	Groups []string `json:"groups,omitempty"`
+}
+
+// ServiceAccountSubject returns the subject the ServiceAccount is authenticated as
+func ServiceAccountSubject(namespace, name string) Subject {
+	return Subject{
+		User: "system:serviceaccount:" + namespace + ":" + name,
+		Groups: []string{
+			"system:serviceaccounts",
+			"system:serviceaccounts:" + namespace,
+			"system:authenticated",
+		},
+	}
+}
+
+// Explanation describes why the webhook and the RBAC rules generated from authorization rules
+// allow or deny the request
+type Explanation struct {
+	Subject    Subject                    `json:"subject"`
+	Attributes *WebhookResourceAttributes `json:"resourceAttributes,omitempty"`
+	// Rules are ClusterAuthorizationRules applied to the user or its groups
+	Rules   []RuleMatch      `json:"rules"`
+	Webhook *WebhookDecision `json:"webhook,omitempty"`
+	RBAC    RBACDecision     `json:"rbac"`
+	// Allowed is the final decision, it is set only if the verb and the resource are specified
+	Allowed *bool `json:"allowed,omitempty"`
+}
+
+// RuleMatch is the rule applied to the subject with the details of its match to the namespace
+type RuleMatch struct {
+	RuleRef
+	MatchedLimitNamespaces   []string `json:"matchedLimitNamespaces,omitempty"`
+	NamespaceSelectorMatched bool     `json:"namespaceSelectorMatched,omitempty"`
+}
+
+// WebhookDecision is the decision of the webhook with the steps that led to it
+type WebhookDecision struct {
+	Denied bool     `json:"denied"`
+	Reason string   `json:"reason,omitempty"`
+	Steps  []string `json:"steps"`
+}
+
+// RBACDecision lists the bindings of the subject. If the verb and the resource are specified,
+// the request is checked by the API server with the SubjectAccessReview. The webhook takes part in the review as well,
+// the reason of the RBAC authorizer names the binding allowing the request.
+type RBACDecision struct {
+	Allowed bool    `json:"allowed"`
+	Reason  string  `json:"reason,omitempty"`
+	Grants  []Grant `json:"grants"`
+}
+
+// Grant is a role bound to the subject
+type Grant struct {
+	Binding string `json:"binding"`
+	Role    string `json:"role"`
+	// Rule is the authorization rule the binding is generated from
+	Rule string `json:"rule,omitempty"`
+}
+
+// AccessMatrix is the access of a single subject to all namespaces
+type AccessMatrix struct {
+	Subject Subject `json:"subject"`
+	// ClusterRoles are bound to the subject cluster-wide
+	ClusterRoles []string `json:"clusterRoles"`
+	// ClusterScopedLimited means that cluster-scoped requests for namespaced resources are denied
+	ClusterScopedLimited bool              `json:"clusterScopedLimited"`
+	Namespaces           []NamespaceAccess `json:"namespaces"`
+}
+
+// NamespaceAccess is the access of the subject to a namespace
+type NamespaceAccess struct {
+	Namespace string   `json:"namespace"`
+	Denied    bool     `json:"denied,omitempty"`
+	Reason    string   `json:"reason,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
+}
+
+// Explain evaluates the request for the subject. Attributes can be nil to list the rules and bindings of the subject.
+func (h *Handler) Explain(ctx context.Context, subject Subject, attrs *WebhookResourceAttributes) (*Explanation, error) {
+	e := &Explanation{
+		Subject:    subject,
+		Attributes: attrs,
+		Rules:      h.affectedRules(subject),
+	}
+
+	namespace := ""
+	if attrs != nil {
+		namespace = attrs.Namespace
+	}
+
+	if namespace != "" {
+		if err := h.matchRulesToNamespace(ctx, e.Rules, namespace); err != nil {
+			return nil, err
+		}
+	}
+
+	if attrs != nil && (attrs.Namespace != "" || attrs.Resource != "") {
+		var t trace
+		request := &WebhookRequest{Spec: WebhookResourceSpec{
+			User:               subject.User,
+			Group:              subject.Groups,
+			ResourceAttributes: *attrs,
+		}}
+		request = h.authorize(request, &t)
+		e.Webhook = &WebhookDecision{
+			Denied: request.Status.Denied,
+			Reason: request.Status.Reason,
+			Steps:  t,
+		}
+	}
+
+	bindings, err := h.subjectBindings(ctx, subject, namespace, namespace == "")
+	if err != nil {
+		return nil, err
+	}
+
+	e.RBAC.Grants = make([]Grant, 0, len(bindings))
+	for _, b := range bindings {
+		e.RBAC.Grants = append(e.RBAC.Grants, b.grant)
+	}
+
+	if attrs != nil && attrs.Verb != "" && attrs.Resource != "" {
+		status, err := h.reviewAccess(ctx, subject, attrs)
+		if err != nil {
+			return nil, err
+		}
+		e.RBAC.Allowed = status.Allowed
+		e.RBAC.Reason = status.Reason
+		if status.EvaluationError != "" {
+			e.RBAC.Reason = strings.TrimSpace(e.RBAC.Reason + " " + status.EvaluationError)
+		}
+
+		allowed := e.RBAC.Allowed && !e.Webhook.Denied
+		e.Allowed = &allowed
+	}
+
+	return e, nil
+}
+
+// reviewAccess checks the request of the subject with the SubjectAccessReview
+func (h *Handler) reviewAccess(ctx context.Context, subject Subject, attrs *WebhookResourceAttributes) (*authorizationv1.SubjectAccessReviewStatus, error) {
+	review, err := h.kubeclient.AuthorizationV1().SubjectAccessReviews().Create(ctx, &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   subject.User,
+			Groups: subject.Groups,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace:   attrs.Namespace,
+				Verb:        attrs.Verb,
+				Group:       attrs.Group,
+				Version:     attrs.Version,
+				Resource:    attrs.Resource,
+				Subresource: attrs.Subresource,
+				Name:        attrs.Name,
+			},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("subject access review: %w", err)
+	}
+	return &review.Status, nil
+}
+
+// AccessMatrix evaluates the access of the subject to all namespaces
+func (h *Handler) AccessMatrix(ctx context.Context, subject Subject) (*AccessMatrix, error) {
+	namespaces, err := h.kubeclient.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	bindings, err := h.subjectBindings(ctx, subject, "", true)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &AccessMatrix{
+		Subject:      subject,
+		ClusterRoles: make([]string, 0),
+		Namespaces:   make([]NamespaceAccess, 0, len(namespaces.Items)),
+	}
+
+	var clusterRoles []string
+	namespacedRoles := make(map[string][]string)
+	for _, b := range bindings {
+		if b.namespace == "" {
+			clusterRoles = append(clusterRoles, b.grant.Role)
+			continue
+		}
+		namespacedRoles[b.namespace] = append(namespacedRoles[b.namespace], b.grant.Role)
+	}
+	m.ClusterRoles = uniqueSorted(clusterRoles)
+
+	if dirs := h.affectedDirs(&WebhookRequest{Spec: WebhookResourceSpec{User: subject.User, Group: subject.Groups}}); len(dirs) > 0 {
+		combinedDir := combineDirEntries(dirs)
+		m.ClusterScopedLimited = hasAnyFilters(&combinedDir)
+	}
+
+	for _, ns := range namespaces.Items {
+		request := &WebhookRequest{Spec: WebhookResourceSpec{
+			User:               subject.User,
+			Group:              subject.Groups,
+			ResourceAttributes: WebhookResourceAttributes{Namespace: ns.Name},
+		}}
+		request = h.authorize(request, nil)
+
+		m.Namespaces = append(m.Namespaces, NamespaceAccess{
+			Namespace: ns.Name,
+			Denied:    request.Status.Denied,
+			Reason:    request.Status.Reason,
+			Roles:     uniqueSorted(append(namespacedRoles[ns.Name], clusterRoles...)),
+		})
+	}
+
+	sort.Slice(m.Namespaces, func(i, j int) bool {
+		return m.Namespaces[i].Namespace < m.Namespaces[j].Namespace
+	})
+
+	return m, nil
+}
+
+// affectedRules returns the rules applied to the user or its groups
+func (h *Handler) affectedRules(subject Subject) []RuleMatch {
+	var refs []RuleRef
+
+	h.mu.RLock()
+	refs = append(refs, h.rules["User"][subject.User]...)
+	refs = append(refs, h.rules["ServiceAccount"][subject.User]...)
+	for _, group := range subject.Groups {
+		refs = append(refs, h.rules["Group"][group]...)
+	}
+	h.mu.RUnlock()
+
+	matches := make([]RuleMatch, 0, len(refs))
+	for _, ref := range refs {
+		matches = append(matches, RuleMatch{RuleRef: ref})
+	}
+	return matches
+}
+
+// matchRulesToNamespace fills the regexes and selectors of the rules matching the namespace
+func (h *Handler) matchRulesToNamespace(ctx context.Context, rules []RuleMatch, namespace string) error {
+	var nsLabels labels.Set
+	for i := range rules {
+		rule := &rules[i]
+
+		if rule.NamespaceSelector == nil {
+			for _, ln := range rule.LimitNamespaces {
+				r, err := regexp.Compile(wrapRegex(ln))
+				if err == nil && r.MatchString(namespace) {
+					rule.MatchedLimitNamespaces = append(rule.MatchedLimitNamespaces, ln)
+				}
+			}
+			continue
+		}
+
+		if rule.NamespaceSelector.MatchAny {
+			rule.NamespaceSelectorMatched = true
+			continue
+		}
+		if rule.NamespaceSelector.LabelSelector == nil {
+			continue
+		}
+
+		if nsLabels == nil {
+			ns, err := h.kubeclient.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			nsLabels = labels.Set(ns.GetLabels())
+			if nsLabels == nil {
+				nsLabels = labels.Set{}
+			}
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(rule.NamespaceSelector.LabelSelector)
+		if err != nil {
+			return fmt.Errorf("rule %s: %v", rule.Name, err)
+		}
+		rule.NamespaceSelectorMatched = selector.Matches(nsLabels)
+	}
+	return nil
+}
+
+// subjectBinding is a RoleBinding or ClusterRoleBinding of the subject
+type subjectBinding struct {
+	namespace string
+	grant     Grant
+}
+
+// subjectBindings returns ClusterRoleBindings and RoleBindings of the subject. RoleBindings are taken from the namespace,
+// or from all namespaces if allNamespaces is set.
+func (h *Handler) subjectBindings(ctx context.Context, subject Subject, namespace string, allNamespaces bool) ([]subjectBinding, error) {
+	client := h.kubeclient.RbacV1()
+
+	var bindings []subjectBinding
+
+	crbs, err := client.ClusterRoleBindings().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, crb := range crbs.Items {
+		if !subjectMatches(subject, crb.Subjects) {
+			continue
+		}
+		bindings = append(bindings, subjectBinding{
+			grant: Grant{
+				Binding: "ClusterRoleBinding/" + crb.Name,
+				Role:    crb.RoleRef.Kind + "/" + crb.RoleRef.Name,
+				Rule:    generatingRule("ClusterAuthorizationRule", "", crb.Name),
+			},
+		})
+	}
+
+	if namespace == "" && !allNamespaces {
+		return bindings, nil
+	}
+
+	rbs, err := client.RoleBindings(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, rb := range rbs.Items {
+		if !subjectMatches(subject, rb.Subjects) {
+			continue
+		}
+		bindings = append(bindings, subjectBinding{
+			namespace: rb.Namespace,
+			grant: Grant{
+				Binding: "RoleBinding/" + rb.Namespace + "/" + rb.Name,
+				Role:    rb.RoleRef.Kind + "/" + rb.RoleRef.Name,
+				Rule:    generatingRule("AuthorizationRule", rb.Namespace, rb.Name),
+			},
+		})
+	}
+
+	return bindings, nil
+}
+
+func subjectMatches(subject Subject, subjects []rbacv1.Subject) bool {
+	for _, s := range subjects {
+		switch s.Kind {
+		case rbacv1.UserKind:
+			if s.Name == subject.User {
+				return true
+			}
+		case rbacv1.GroupKind:
+			for _, group := range subject.Groups {
+				if s.Name == group {
+					return true
+				}
+			}
+		case rbacv1.ServiceAccountKind:
+			if "system:serviceaccount:"+s.Namespace+":"+s.Name == subject.User {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// generatingRule returns the authorization rule the binding is generated from by the user-authz module templates,
+// binding names look like "user-authz:<rule name>:<access level>"
+func generatingRule(kind, namespace, bindingName string) string {
+	if !strings.HasPrefix(bindingName, generatedBindingPrefix) {
+		return ""
+	}
+	parts := strings.SplitN(strings.TrimPrefix(bindingName, generatedBindingPrefix), ":", 2)
+	if len(parts) != 2 {
+		return ""
+	}
+	if namespace != "" {
+		return kind + "/" + namespace + "/" + parts[0]
+	}
+	return kind + "/" + parts[0]
+}
+
+func uniqueSorted(list []string) []string {
+	set := make(map[string]struct{}, len(list))
+	result := make([]string, 0, len(list))
+	for _, item := range list {
+		if _, ok := set[item]; ok {
+			continue
+		}
+		set[item] = struct{}{}
+		result = append(result, item)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// ServeExplain explains the decision for the subject and the request attributes passed in the query
+func (h *Handler) ServeExplain(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is supported.", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	subject, err := parseSubject(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var attrs *WebhookResourceAttributes
+	if query.Get("verb") != "" || query.Get("resource") != "" || query.Get("namespace") != "" {
+		attrs = &WebhookResourceAttributes{
+			Verb:        query.Get("verb"),
+			Group:       query.Get("apiGroup"),
+			Version:     query.Get("version"),
+			Resource:    query.Get("resource"),
+			Subresource: query.Get("subresource"),
+			Name:        query.Get("name"),
+			Namespace:   query.Get("namespace"),
+		}
+	}
+
+	explanation, err := h.Explain(r.Context(), subject, attrs)
+	h.writeJSON(w, explanation, err)
+}
+
+// ServeAccessMatrix returns the access matrix for the subject passed in the query
+func (h *Handler) ServeAccessMatrix(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is supported.", http.StatusMethodNotAllowed)
+		return
+	}
+
+	subject, err := parseSubject(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	matrix, err := h.AccessMatrix(r.Context(), subject)
+	h.writeJSON(w, matrix, err)
+}
+
+func (h *Handler) writeJSON(w http.ResponseWriter, v interface{}, err error) {
+	if err != nil {
+		h.logger.Printf("cannot explain access: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.logger.Printf("cannot write response: %v", err)
+	}
+}
+
+// parseSubject reads the subject from "user" and "group" parameters, or from the "serviceAccount" parameter
+// in the "namespace:name" form
+func parseSubject(query url.Values) (Subject, error) {
+	if sa := query.Get("serviceAccount"); sa != "" {
+		parts := strings.SplitN(sa, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return Subject{}, fmt.Errorf("serviceAccount %q must be in the namespace:name form", sa)
+		}
+		return ServiceAccountSubject(parts[0], parts[1]), nil
+	}
+
+	subject := Subject{User: query.Get("user"), Groups: query["group"]}
+	if subject.User == "" && len(subject.Groups) == 0 {
+		return Subject{}, fmt.Errorf("user, group or serviceAccount is required")
+	}
+	return subject, nil
+}
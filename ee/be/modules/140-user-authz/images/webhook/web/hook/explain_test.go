@@ -0,0 +1,288 @@
+/*
+Copyright 2024 Flant JSC
+Licensed under the Deckhouse Platform Enterprise Edition (EE) license. See https://github.com/deckhouse/deckhouse/blob/main/ee/LICENSE
+*/
+
+package hook
+
+import (
+	"context"
+	"io"
+	"log"
+	"reflect"
+	"regexp"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newExplainHandler() *Handler {
+	devRegex, _ := regexp.Compile("^dev-.*$")
+
+	objects := []runtime.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "dev-shop"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod-shop"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "d8-system"}},
+		&rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: "user-authz:editor"},
+			Rules: []rbacv1.PolicyRule{{
+				APIGroups: []string{"", "apps"},
+				Resources: []string{"pods", "deployments"},
+				Verbs:     []string{"get", "list", "create", "delete"},
+			}},
+		},
+		&rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: "user-authz:port-forward"},
+			Rules: []rbacv1.PolicyRule{{
+				APIGroups: []string{""},
+				Resources: []string{"pods/portforward"},
+				Verbs:     []string{"create"},
+			}},
+		},
+		&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "user-authz:developers:editor"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "user-authz:editor"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.GroupKind, Name: "developers"}},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "user-authz:debug:port-forward", Namespace: "prod-shop"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "user-authz:port-forward"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.UserKind, Name: "alice"}},
+		},
+	}
+
+	client := fake.NewSimpleClientset(objects...)
+	// the API server allows the requests granted by the roles above
+	client.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		attrs := review.Spec.ResourceAttributes
+		switch {
+		case attrs.Subresource == "" && (attrs.Resource == "pods" || attrs.Resource == "deployments"):
+			review.Status = authorizationv1.SubjectAccessReviewStatus{Allowed: true, Reason: `RBAC: allowed by ClusterRoleBinding "user-authz:developers:editor"`}
+		case attrs.Subresource == "portforward" && attrs.Namespace == "prod-shop":
+			review.Status = authorizationv1.SubjectAccessReviewStatus{Allowed: true, Reason: `RBAC: allowed by RoleBinding "user-authz:debug:port-forward/prod-shop"`}
+		}
+		return true, review, nil
+	})
+
+	return &Handler{
+		logger:     log.New(io.Discard, "", 0),
+		kubeclient: client,
+		cache: &dummyCache{
+			data: map[string]map[string]bool{
+				"v1": {"pods": true, "namespaces": false},
+			},
+		},
+		directory: map[string]map[string]DirectoryEntry{
+			"Group": {
+				"developers": {LimitNamespaces: []*regexp.Regexp{devRegex}},
+			},
+		},
+		rules: map[string]map[string][]RuleRef{
+			"Group": {
+				"developers": {{
+					Name:            "developers",
+					Subject:         "Group/developers",
+					AccessLevel:     "Editor",
+					LimitNamespaces: []string{"dev-.*"},
+				}},
+			},
+		},
+	}
+}
+
+func TestExplain(t *testing.T) {
+	subject := Subject{User: "alice", Groups: []string{"developers"}}
+
+	tc := []struct {
+		Name       string
+		Attributes *WebhookResourceAttributes
+		Allowed    *bool
+		RBAC       bool
+		Denied     bool
+		Matched    []string
+		Grants     []Grant
+	}{
+		{
+			Name:       "Allowed in the limited namespace",
+			Attributes: &WebhookResourceAttributes{Verb: "delete", Resource: "pods", Namespace: "dev-shop"},
+			Allowed:    boolPtr(true),
+			RBAC:       true,
+			Matched:    []string{"dev-.*"},
+			Grants: []Grant{{
+				Binding: "ClusterRoleBinding/user-authz:developers:editor",
+				Role:    "ClusterRole/user-authz:editor",
+				Rule:    "ClusterAuthorizationRule/developers",
+			}},
+		},
+		{
+			Name:       "Denied by the webhook in other namespaces",
+			Attributes: &WebhookResourceAttributes{Verb: "get", Resource: "pods", Namespace: "prod-shop"},
+			Allowed:    boolPtr(false),
+			RBAC:       true,
+			Denied:     true,
+			Grants: []Grant{{
+				Binding: "ClusterRoleBinding/user-authz:developers:editor",
+				Role:    "ClusterRole/user-authz:editor",
+				Rule:    "ClusterAuthorizationRule/developers",
+			}, {
+				Binding: "RoleBinding/prod-shop/user-authz:debug:port-forward",
+				Role:    "ClusterRole/user-authz:port-forward",
+				Rule:    "AuthorizationRule/prod-shop/debug",
+			}},
+		},
+		{
+			Name:       "Not allowed by RBAC",
+			Attributes: &WebhookResourceAttributes{Verb: "get", Resource: "secrets", Namespace: "dev-shop"},
+			Allowed:    boolPtr(false),
+			Matched:    []string{"dev-.*"},
+			Grants: []Grant{{
+				Binding: "ClusterRoleBinding/user-authz:developers:editor",
+				Role:    "ClusterRole/user-authz:editor",
+				Rule:    "ClusterAuthorizationRule/developers",
+			}},
+		},
+		{
+			Name:       "Subresource granted by a RoleBinding",
+			Attributes: &WebhookResourceAttributes{Verb: "create", Resource: "pods", Subresource: "portforward", Namespace: "prod-shop"},
+			Allowed:    boolPtr(false),
+			RBAC:       true,
+			Denied:     true,
+			Grants: []Grant{{
+				Binding: "ClusterRoleBinding/user-authz:developers:editor",
+				Role:    "ClusterRole/user-authz:editor",
+				Rule:    "ClusterAuthorizationRule/developers",
+			}, {
+				Binding: "RoleBinding/prod-shop/user-authz:debug:port-forward",
+				Role:    "ClusterRole/user-authz:port-forward",
+				Rule:    "AuthorizationRule/prod-shop/debug",
+			}},
+		},
+		{
+			Name: "Subject bindings without request",
+			Grants: []Grant{
+				{
+					Binding: "ClusterRoleBinding/user-authz:developers:editor",
+					Role:    "ClusterRole/user-authz:editor",
+					Rule:    "ClusterAuthorizationRule/developers",
+				},
+				{
+					Binding: "RoleBinding/prod-shop/user-authz:debug:port-forward",
+					Role:    "ClusterRole/user-authz:port-forward",
+					Rule:    "AuthorizationRule/prod-shop/debug",
+				},
+			},
+		},
+	}
+
+	for _, testCase := range tc {
+		t.Run(testCase.Name, func(t *testing.T) {
+			e, err := newExplainHandler().Explain(context.Background(), subject, testCase.Attributes)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(e.Allowed, testCase.Allowed) {
+				t.Errorf("allowed: got %v | expected %v", e.Allowed, testCase.Allowed)
+			}
+
+			if e.RBAC.Allowed != testCase.RBAC {
+				t.Errorf("rbac allowed: got %v | expected %v, reason: %q", e.RBAC.Allowed, testCase.RBAC, e.RBAC.Reason)
+			}
+
+			if testCase.Attributes != nil && e.Webhook.Denied != testCase.Denied {
+				t.Errorf("denied: got %v | expected %v, steps: %v", e.Webhook.Denied, testCase.Denied, e.Webhook.Steps)
+			}
+
+			if len(e.Rules) != 1 {
+				t.Fatalf("rules: got %d | expected 1", len(e.Rules))
+			}
+			if !reflect.DeepEqual(e.Rules[0].MatchedLimitNamespaces, testCase.Matched) {
+				t.Errorf("matched limitNamespaces: got %v | expected %v", e.Rules[0].MatchedLimitNamespaces, testCase.Matched)
+			}
+
+			if !reflect.DeepEqual(e.RBAC.Grants, testCase.Grants) {
+				t.Errorf("grants: got %+v | expected %+v", e.RBAC.Grants, testCase.Grants)
+			}
+		})
+	}
+}
+
+func TestExplainSteps(t *testing.T) {
+	attrs := &WebhookResourceAttributes{Verb: "get", Resource: "pods", Namespace: "dev-shop"}
+
+	e, err := newExplainHandler().Explain(context.Background(), Subject{User: "alice", Groups: []string{"developers"}}, attrs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{`namespace "dev-shop" matches limitNamespaces regex "^dev-.*$"`}
+	if !reflect.DeepEqual(e.Webhook.Steps, expected) {
+		t.Errorf("steps: got %q | expected %q", e.Webhook.Steps, expected)
+	}
+}
+
+func TestAccessMatrix(t *testing.T) {
+	m, err := newExplainHandler().AccessMatrix(context.Background(), Subject{User: "alice", Groups: []string{"developers"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !m.ClusterScopedLimited {
+		t.Errorf("cluster-scoped requests should be limited")
+	}
+
+	expectedClusterRoles := []string{"ClusterRole/user-authz:editor"}
+	if !reflect.DeepEqual(m.ClusterRoles, expectedClusterRoles) {
+		t.Errorf("cluster roles: got %v | expected %v", m.ClusterRoles, expectedClusterRoles)
+	}
+
+	expected := []NamespaceAccess{
+		{
+			Namespace: "d8-system",
+			Denied:    true,
+			Reason:    noNamespaceAccessReason,
+			Roles:     []string{"ClusterRole/user-authz:editor"},
+		},
+		{
+			Namespace: "dev-shop",
+			Roles:     []string{"ClusterRole/user-authz:editor"},
+		},
+		{
+			Namespace: "prod-shop",
+			Denied:    true,
+			Reason:    noNamespaceAccessReason,
+			Roles:     []string{"ClusterRole/user-authz:editor", "ClusterRole/user-authz:port-forward"},
+		},
+	}
+	if !reflect.DeepEqual(m.Namespaces, expected) {
+		t.Errorf("namespaces: got %+v | expected %+v", m.Namespaces, expected)
+	}
+}
+
+func TestParseSubject(t *testing.T) {
+	subject, err := parseSubject(map[string][]string{"serviceAccount": {"shop:deployer"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject.User != "system:serviceaccount:shop:deployer" {
+		t.Errorf("user: got %q", subject.User)
+	}
+
+	if _, err := parseSubject(map[string][]string{"serviceAccount": {"deployer"}}); err == nil {
+		t.Errorf("expected an error for the ServiceAccount without a namespace")
+	}
+	if _, err := parseSubject(map[string][]string{}); err == nil {
+		t.Errorf("expected an error for the empty subject")
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
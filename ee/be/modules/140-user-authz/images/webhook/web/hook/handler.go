@@ -8,6 +8,7 @@ package hook
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
@@ -47,6 +48,10 @@ type Handler struct {
 	//        [user type] [user name]
 	mu        sync.RWMutex
 	directory map[string]map[string]DirectoryEntry
+	// temporary keeps entries of rules with the validity period, they are checked on every request
+	temporary map[string]map[string][]temporaryEntry
+	// rules keeps the source rules of directory entries to explain decisions
+	rules map[string]map[string][]RuleRef
 }
 
 func NewHandler(logger *log.Logger, discoveryCache cache.Cache) (*Handler, error) {
@@ -94,9 +99,12 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.logger.Printf("response body: %s", respData)
 }
 
-func (h *Handler) authorizeNamespacedRequest(request *WebhookRequest, entry *DirectoryEntry) *WebhookRequest {
+func (h *Handler) authorizeNamespacedRequest(request *WebhookRequest, entry *DirectoryEntry, t *trace) *WebhookRequest {
+	namespace := request.Spec.ResourceAttributes.Namespace
+
 	if !hasAnyFilters(entry) {
 		// User has no namespaced restriction.
+		t.addf("namespaces are not limited by rules")
 		return request
 	}
 
@@ -107,21 +115,27 @@ func (h *Handler) authorizeNamespacedRequest(request *WebhookRequest, entry *Dir
 		request.Status.Reason = noNamespaceAccessReason
 		// check if the target namespace is in limitNamespaces list
 		for _, pattern := range entry.LimitNamespaces {
-			if pattern.MatchString(request.Spec.ResourceAttributes.Namespace) {
+			if pattern.MatchString(namespace) {
+				t.addf("namespace %q matches limitNamespaces regex %q", namespace, pattern.String())
 				request.Status.Denied = false
 				request.Status.Reason = ""
 				break
 			}
 		}
+		if request.Status.Denied {
+			t.addf("namespace %q matches none of limitNamespaces regexes", namespace)
+		}
 	} else {
 		// there is no filters - assume a positive outcome
+		t.addf("there is a rule without limitNamespaces and namespaceSelector")
 		request.Status.Denied = false
 	}
 
 	if !request.Status.Denied && !entry.AllowAccessToSystemNamespaces {
 		// check if the target namespace is a system one and restricted
 		for _, pattern := range systemNamespacesRegex {
-			if pattern.MatchString(request.Spec.ResourceAttributes.Namespace) {
+			if pattern.MatchString(namespace) {
+				t.addf("namespace %q matches system namespace regex %q, and allowAccessToSystemNamespaces is not set", namespace, pattern.String())
 				request.Status.Denied = true
 				request.Status.Reason = noNamespaceAccessReason
 				break
@@ -131,12 +145,16 @@ func (h *Handler) authorizeNamespacedRequest(request *WebhookRequest, entry *Dir
 
 	// if request is still denied - check available namespace selectors if any of them matches the request namespace, doesn't matter a system one or not
 	if request.Status.Denied && len(entry.NamespaceSelectors) > 0 {
-		match, err := h.namespaceLabelsMatchSelector(request.Spec.ResourceAttributes.Namespace, entry.NamespaceSelectors)
+		match, err := h.namespaceLabelsMatchSelector(namespace, entry.NamespaceSelectors)
 		if err != nil {
+			t.addf("cannot match namespace labels: %v", err)
 			request.Status.Reason = err.Error()
 		} else if match {
+			t.addf("namespace %q labels match a namespaceSelector", namespace)
 			request.Status.Denied = false
 			request.Status.Reason = ""
+		} else {
+			t.addf("namespace %q labels match none of namespaceSelectors", namespace)
 		}
 	}
 
@@ -154,7 +172,7 @@ func (h *Handler) fillDenyRequest(request *WebhookRequest, reason, logEntry stri
 	return request
 }
 
-func (h *Handler) authorizeClusterScopedRequest(request *WebhookRequest, entry *DirectoryEntry) *WebhookRequest {
+func (h *Handler) authorizeClusterScopedRequest(request *WebhookRequest, entry *DirectoryEntry, t *trace) *WebhookRequest {
 	// if resource is not nil and namespace is nil
 	apiGroup := request.Spec.ResourceAttributes.Version
 	group := request.Spec.ResourceAttributes.Group
@@ -180,26 +198,51 @@ func (h *Handler) authorizeClusterScopedRequest(request *WebhookRequest, entry *
 	namespaced, err := h.cache.Get(apiGroup, request.Spec.ResourceAttributes.Resource)
 	if err != nil {
 		// could not check whether resource is namespaced or not (from cache) - deny access
+		t.addf("cannot discover whether %s %q is namespaced: %v", apiGroup, request.Spec.ResourceAttributes.Resource, err)
 		h.fillDenyRequest(request, internalErrorReason, err.Error())
 
 	} else if namespaced && hasAnyFilters(entry) {
 		// we should not allow cluster-scoped requests for the namespaced objects if access to the namespaces is limited
+		t.addf("%s %q is namespaced, and namespaces are limited by rules", apiGroup, request.Spec.ResourceAttributes.Resource)
 		h.fillDenyRequest(request, namespaceLimitedAccessReason, "")
+	} else {
+		t.addf("cluster-scoped request is not limited (namespaced: %v)", namespaced)
 	}
 
 	return request
 }
 
 func (h *Handler) authorizeRequest(request *WebhookRequest) *WebhookRequest {
+	return h.authorize(request, nil)
+}
+
+// authorize makes the decision and records its steps to the trace if it is not nil
+func (h *Handler) authorize(request *WebhookRequest, t *trace) *WebhookRequest {
 	dirEntriesAffected := h.affectedDirs(request)
 	if len(dirEntriesAffected) == 0 {
+		t.addf("no rules match the user and groups, the request is not limited")
 		return request
 	}
 
+	combinedDir := combineDirEntries(dirEntriesAffected)
+
+	if request.Spec.ResourceAttributes.Namespace != "" {
+		return h.authorizeNamespacedRequest(request, &combinedDir, t)
+	}
+
+	if request.Spec.ResourceAttributes.Resource != "" {
+		return h.authorizeClusterScopedRequest(request, &combinedDir, t)
+	}
+
+	t.addf("non-resource requests are not limited")
+	return request
+}
+
+// combineDirEntries combines dirs for the current request. Users may have more than one rule attached to their groups or usernames.
+func combineDirEntries(dirEntries []DirectoryEntry) DirectoryEntry {
 	var combinedDir DirectoryEntry
 
-	// Combine dirs for the current request. Users may have more than one rule attached to their groups or usernames.
-	for _, dirEntry := range dirEntriesAffected {
+	for _, dirEntry := range dirEntries {
 		if !combinedDir.AllowAccessToSystemNamespaces {
 			combinedDir.AllowAccessToSystemNamespaces = dirEntry.AllowAccessToSystemNamespaces
 		}
@@ -214,15 +257,7 @@ func (h *Handler) authorizeRequest(request *WebhookRequest) *WebhookRequest {
 		combinedDir.NamespaceFiltersAbsent = combinedDir.NamespaceFiltersAbsent || dirEntry.NamespaceFiltersAbsent
 	}
 
-	if request.Spec.ResourceAttributes.Namespace != "" {
-		return h.authorizeNamespacedRequest(request, &combinedDir)
-	}
-
-	if request.Spec.ResourceAttributes.Resource != "" {
-		return h.authorizeClusterScopedRequest(request, &combinedDir)
-	}
-
-	return request
+	return combinedDir
 }
 
 // renewDirectories reads the configuration file (actually it is a json file with all CRs from the cluster) and composes
@@ -258,9 +293,26 @@ func (h *Handler) renewDirectories() {
 		"Group":          make(map[string]DirectoryEntry),
 		"ServiceAccount": make(map[string]DirectoryEntry),
 	}
+	temporary := map[string]map[string][]temporaryEntry{
+		"User":           make(map[string][]temporaryEntry),
+		"Group":          make(map[string][]temporaryEntry),
+		"ServiceAccount": make(map[string][]temporaryEntry),
+	}
+	rules := map[string]map[string][]RuleRef{
+		"User":           make(map[string][]RuleRef),
+		"Group":          make(map[string][]RuleRef),
+		"ServiceAccount": make(map[string][]RuleRef),
+	}
 
 	// fill limited namespaces by subjects kinds/names
 	for _, crd := range config.CRDs {
+		validFrom, validUntil, err := parseValidity(crd.Spec.ValidFrom, crd.Spec.ValidUntil)
+		if err != nil {
+			h.logger.Printf("rule %s is skipped: %v", crd.Name, err)
+			continue
+		}
+		isTemporary := !validFrom.IsZero() || !validUntil.IsZero()
+
 		for _, subject := range crd.Spec.Subjects {
 			name := subject.Name
 			namespace := subject.Namespace
@@ -271,7 +323,7 @@ func (h *Handler) renewDirectories() {
 			}
 
 			dirEntry, ok := directory[kind][name]
-			if !ok {
+			if !ok || isTemporary {
 				dirEntry = DirectoryEntry{}
 			}
 
@@ -295,7 +347,30 @@ func (h *Handler) renewDirectories() {
 				dirEntry.NamespaceSelectors = append(dirEntry.NamespaceSelectors, crd.Spec.NamespaceSelector)
 			}
 
-			directory[kind][name] = dirEntry
+			if isTemporary {
+				if _, ok := temporary[kind]; ok {
+					temporary[kind][name] = append(temporary[kind][name], temporaryEntry{
+						validFrom:  validFrom,
+						validUntil: validUntil,
+						entry:      dirEntry,
+					})
+				}
+			} else {
+				directory[kind][name] = dirEntry
+			}
+
+			if _, ok := rules[kind]; ok {
+				rules[kind][name] = append(rules[kind][name], RuleRef{
+					Name:                          crd.Name,
+					Subject:                       kind + "/" + name,
+					AccessLevel:                   crd.Spec.AccessLevel,
+					AllowAccessToSystemNamespaces: crd.Spec.AllowAccessToSystemNamespaces,
+					LimitNamespaces:               crd.Spec.LimitNamespaces,
+					NamespaceSelector:             crd.Spec.NamespaceSelector,
+					ValidFrom:                     crd.Spec.ValidFrom,
+					ValidUntil:                    crd.Spec.ValidUntil,
+				})
+			}
 		}
 	}
 
@@ -303,9 +378,31 @@ func (h *Handler) renewDirectories() {
 	defer h.mu.Unlock()
 
 	h.directory = directory
+	h.temporary = temporary
+	h.rules = rules
 	h.logger.Println("configuration was reloaded successfully")
 }
 
+// parseValidity parses the validity period of a rule, zero times mean that the period is not limited
+func parseValidity(validFrom, validUntil string) (time.Time, time.Time, error) {
+	var from, until time.Time
+	var err error
+
+	if validFrom != "" {
+		from, err = time.Parse(time.RFC3339, validFrom)
+		if err != nil {
+			return from, until, fmt.Errorf("validFrom: %v", err)
+		}
+	}
+	if validUntil != "" {
+		until, err = time.Parse(time.RFC3339, validUntil)
+		if err != nil {
+			return from, until, fmt.Errorf("validUntil: %v", err)
+		}
+	}
+	return from, until, nil
+}
+
 func isLabelSelectorApplied(namespaceSelector *NamespaceSelector) bool {
 	if namespaceSelector != nil && namespaceSelector.LabelSelector != nil {
 		return true
@@ -353,9 +450,30 @@ func (h *Handler) affectedDirs(r *WebhookRequest) []DirectoryEntry {
 		}
 	}
 
+	now := time.Now()
+	dirEntriesAffected = append(dirEntriesAffected, h.temporaryDirs("User", r.Spec.User, now)...)
+	dirEntriesAffected = append(dirEntriesAffected, h.temporaryDirs("ServiceAccount", r.Spec.User, now)...)
+	for _, group := range r.Spec.Group {
+		dirEntriesAffected = append(dirEntriesAffected, h.temporaryDirs("Group", group, now)...)
+	}
+
 	return dirEntriesAffected
 }
 
+// temporaryDirs returns entries of rules with the validity period. The access granted by a rule that is not in effect
+// remains in RBAC until the rule is removed from the config, so the rule limits the user to no namespaces meanwhile.
+func (h *Handler) temporaryDirs(kind, name string, now time.Time) []DirectoryEntry {
+	var dirEntries []DirectoryEntry
+	for _, temporary := range h.temporary[kind][name] {
+		if temporary.active(now) {
+			dirEntries = append(dirEntries, temporary.entry)
+			continue
+		}
+		dirEntries = append(dirEntries, DirectoryEntry{})
+	}
+	return dirEntries
+}
+
 // checks if labels of a namespace match provided labelselector
 func (h *Handler) namespaceLabelsMatchSelector(namespaceName string, namespaceSelectors []*NamespaceSelector) (bool, error) {
 	var labelsSet labels.Set
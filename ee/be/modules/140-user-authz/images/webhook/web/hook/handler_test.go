@@ -11,6 +11,7 @@ import (
 	"log"
 	"regexp"
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -679,6 +680,80 @@ func TestAuthorizeRequest(t *testing.T) {
 	}
 }
 
+func TestAuthorizeTemporaryRules(t *testing.T) {
+	now := time.Now()
+
+	tc := []struct {
+		Name       string
+		ValidFrom  time.Time
+		ValidUntil time.Time
+		Denied     bool
+	}{
+		{
+			Name:       "Active",
+			ValidFrom:  now.Add(-time.Hour),
+			ValidUntil: now.Add(time.Hour),
+		},
+		{
+			Name:       "Active without start",
+			ValidUntil: now.Add(time.Hour),
+		},
+		{
+			Name:       "Expired",
+			ValidUntil: now.Add(-time.Second),
+			Denied:     true,
+		},
+		{
+			Name:      "Pending",
+			ValidFrom: now.Add(time.Hour),
+			Denied:    true,
+		},
+	}
+
+	for _, testCase := range tc {
+		t.Run(testCase.Name, func(t *testing.T) {
+			handler := &Handler{
+				logger:     log.New(io.Discard, "", 0),
+				kubeclient: fake.NewSimpleClientset(),
+				cache:      &dummyCache{},
+				temporary: map[string]map[string][]temporaryEntry{
+					"User": {
+						"on-call": {{
+							validFrom:  testCase.ValidFrom,
+							validUntil: testCase.ValidUntil,
+							entry:      DirectoryEntry{NamespaceFiltersAbsent: true},
+						}},
+					},
+				},
+			}
+
+			req := handler.authorizeRequest(&WebhookRequest{
+				Spec: WebhookResourceSpec{
+					User:               "on-call",
+					ResourceAttributes: WebhookResourceAttributes{Resource: "pods", Namespace: "shop"},
+				},
+			})
+			if req.Status.Denied != testCase.Denied {
+				t.Errorf("denied: got %v | expected %v", req.Status.Denied, testCase.Denied)
+			}
+		})
+	}
+}
+
+func TestParseValidity(t *testing.T) {
+	from, until, err := parseValidity("2024-06-01T09:00:00Z", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !from.Equal(time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)) || !until.IsZero() {
+		t.Errorf("got %v, %v", from, until)
+	}
+
+	if _, _, err := parseValidity("", "tomorrow"); err == nil {
+		t.Errorf("expected an error for the invalid validUntil")
+	}
+}
+
 type dummyCache struct {
 	data              map[string]map[string]bool
 	preferredVersions map[string]string
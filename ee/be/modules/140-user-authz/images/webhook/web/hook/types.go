@@ -7,6 +7,7 @@ package hook
 
 import (
 	"regexp"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -22,6 +23,33 @@ type DirectoryEntry struct {
 	NamespaceFiltersAbsent bool
 }
 
+// RuleRef describes a ClusterAuthorizationRule applied to a single subject
+type RuleRef struct {
+	Name                          string             `json:"name"`
+	Subject                       string             `json:"subject"`
+	AccessLevel                   string             `json:"accessLevel,omitempty"`
+	AllowAccessToSystemNamespaces bool               `json:"allowAccessToSystemNamespaces,omitempty"`
+	LimitNamespaces               []string           `json:"limitNamespaces,omitempty"`
+	NamespaceSelector             *NamespaceSelector `json:"namespaceSelector,omitempty"`
+	ValidFrom                     string             `json:"validFrom,omitempty"`
+	ValidUntil                    string             `json:"validUntil,omitempty"`
+}
+
+// temporaryEntry is an entry of a rule with the validity period for a single user
+type temporaryEntry struct {
+	validFrom  time.Time
+	validUntil time.Time
+	entry      DirectoryEntry
+}
+
+// active checks if the rule is in effect at the moment
+func (e *temporaryEntry) active(now time.Time) bool {
+	if !e.validFrom.IsZero() && now.Before(e.validFrom) {
+		return false
+	}
+	return e.validUntil.IsZero() || now.Before(e.validUntil)
+}
+
 type NamespaceSelector struct {
 	LabelSelector *metav1.LabelSelector `json:"labelSelector"`
 	MatchAny      bool                  `json:"matchAny"`
@@ -38,6 +66,8 @@ type UserAuthzConfig struct {
 			AllowAccessToSystemNamespaces bool               `json:"allowAccessToSystemNamespaces"`
 			LimitNamespaces               []string           `json:"limitNamespaces"`
 			NamespaceSelector             *NamespaceSelector `json:"namespaceSelector"`
+			ValidFrom                     string             `json:"validFrom"`
+			ValidUntil                    string             `json:"validUntil"`
 			AdditionalRoles               []struct {
 				APIGroup string `json:"apiGroup"`
 				Kind     string `json:"kind"`
@@ -73,6 +103,9 @@ type WebhookResourceAttributes struct {
 	Namespace string `json:"namespace,omitempty"`
 	Resource  string `json:"resource"`
 	Verb      string `json:"verb"`
+	// Subresource and Name are used only to explain RBAC decisions
+	Subresource string `json:"subresource,omitempty"`
+	Name        string `json:"name,omitempty"`
 }
 
 type WebhookRequestStatus struct {
@@ -29,6 +29,9 @@ const (
 	authClientCA = "/etc/ssl/apiserver-authentication-requestheader-client-ca/ca.crt"
 
 	ListenAddr = "127.0.0.1:40443"
+
+	// explainTimeout limits the explain handlers to respond before the server write timeout
+	explainTimeout = 8 * time.Second
 )
 
 func buildTLSConfig() (*tls.Config, error) {
@@ -74,6 +77,8 @@ func (s *Server) prepareHTTPServer() (*http.Server, error) {
 	router := http.NewServeMux()
 
 	router.Handle("/", s.handler)
+	router.Handle("/explain", http.TimeoutHandler(http.HandlerFunc(s.handler.ServeExplain), explainTimeout, "Explain timed out."))
+	router.Handle("/access-matrix", http.TimeoutHandler(http.HandlerFunc(s.handler.ServeAccessMatrix), explainTimeout, "Access matrix timed out."))
 	router.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
 		err := s.cache.Check()
 		if err == nil {
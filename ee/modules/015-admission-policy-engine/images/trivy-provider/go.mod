@@ -13,7 +13,9 @@ require (
 	github.com/go-chi/chi/v5 v5.0.10
 	github.com/go-logr/logr v1.2.4
 	github.com/go-logr/zapr v1.2.3
+	github.com/google/go-containerregistry v0.14.0
 	github.com/open-policy-agent/frameworks/constraint v0.0.0-20221006234738-a3d297b3152f
+	github.com/stretchr/testify v1.8.3
 	go.uber.org/zap v1.24.0
 	modernc.org/sqlite v1.20.3
 )
@@ -148,7 +150,6 @@ require (
 	github.com/google/btree v1.1.2 // indirect
 	github.com/google/gnostic v0.5.7-v3refs // indirect
 	github.com/google/go-cmp v0.5.9 // indirect
-	github.com/google/go-containerregistry v0.14.0 // indirect
 	github.com/google/gofuzz v1.2.0 // indirect
 	github.com/google/licenseclassifier/v2 v2.0.0 // indirect
 	github.com/google/s2a-go v0.1.3 // indirect
@@ -267,7 +268,6 @@ require (
 	github.com/spf13/cobra v1.7.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
 	github.com/stretchr/objx v0.5.0 // indirect
-	github.com/stretchr/testify v1.8.3 // indirect
 	github.com/tchap/go-patricia/v2 v2.3.1 // indirect
 	github.com/twitchtv/twirp v8.1.2+incompatible // indirect
 	github.com/ulikunitz/xz v0.5.10 // indirect
@@ -31,6 +31,8 @@ func main() {
 		host           string = "0.0.0.0"
 		port           int    = 8443
 		timeoutSeconds int    = 10
+
+		verificationTimeoutSeconds = 7
 	)
 	flag.StringVar(&keyFile, "key-file", keyFile, "Path to file containing TLS certificate key.")
 	flag.StringVar(&certFile, "cert-file", certFile, "Path to file containing TLS certificate.")
@@ -38,6 +40,7 @@ func main() {
 	flag.StringVar(&host, "host", host, "Host for for the server to listen on.")
 	flag.IntVar(&port, "port", port, "Port for the server to listen on.")
 	flag.IntVar(&timeoutSeconds, "timeout", timeoutSeconds, "Scanning timeout in seconds.")
+	flag.IntVar(&verificationTimeoutSeconds, "verification-timeout", verificationTimeoutSeconds, "Image signatures verification timeout in seconds.")
 	flag.Parse()
 
 	zapLog, err := zap.NewDevelopment(zap.AddStacktrace(zap.ErrorLevel))
@@ -64,6 +67,11 @@ func main() {
 	validator := web.NewHandler(scanner, scanningTimeout, logger)
 	handler.HandleFunc("/validate", validator.HandleRequest())
 
+	verifier := validators.NewSignatureVerifier(logger.WithName("signatures"))
+	verificationTimeout := time.Duration(verificationTimeoutSeconds) * time.Second
+	signatures := web.NewHandler(verifier, verificationTimeout, logger)
+	handler.HandleFunc("/verify-signatures", signatures.HandleRequest())
+
 	logger.Info("starting server...")
 	if err = server.ListenAndServeTLS(certFile, keyFile); err != nil {
 		panic(err)
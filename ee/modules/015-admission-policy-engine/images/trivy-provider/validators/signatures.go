@@ -0,0 +1,292 @@
+/*
+Copyright 2024 Flant JSC
+Licensed under the Deckhouse Platform Enterprise Edition (EE) license. See https://github.com/deckhouse/deckhouse/blob/main/ee/LICENSE
+*/
+
+package validators
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/externaldata"
+)
+
+const (
+	// cosign stores signatures of an image in the same repository, tagged by the image digest
+	signatureTagSuffix        = ".sig"
+	signatureAnnotation       = "dev.cosignproject.cosign/signature"
+	maxSignaturePayloadSize   = 1 << 20
+	verifiedSignaturesTTL     = 10 * time.Minute
+	verifiedSignaturesMaxSize = 10000
+)
+
+// verificationRequest is the key of the external data request, it is built by the D8VerifyImageSignatures constraint
+type verificationRequest struct {
+	Image      string   `json:"image"`
+	PublicKeys []string `json:"publicKeys"`
+}
+
+// simpleSigningPayload is the signed payload of the cosign signature
+type simpleSigningPayload struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+	} `json:"critical"`
+}
+
+type signatureVerifier struct {
+	logger   logr.Logger
+	keychain authn.Keychain
+
+	mu sync.Mutex
+	// expiration times of verified image digests and public keys, to not pull signatures on every admission and audit
+	verified map[string]time.Time
+}
+
+func NewSignatureVerifier(logger logr.Logger) *signatureVerifier {
+	return &signatureVerifier{
+		logger:   logger,
+		keychain: authn.DefaultKeychain,
+		verified: make(map[string]time.Time),
+	}
+}
+
+// ScanReport verifies signatures of the requested images
+func (v *signatureVerifier) ScanReport(ctx context.Context, data []byte) externaldata.Response {
+	var providerRequest externaldata.ProviderRequest
+	if err := json.Unmarshal(data, &providerRequest); err != nil {
+		err = fmt.Errorf("unable to unmarshal data to externaldata.ProviderRequest: %w", err)
+		v.logger.Error(err, "Error verifying signatures")
+		return externaldata.Response{SystemError: err.Error()}
+	}
+
+	results := make([]externaldata.Item, 0, len(providerRequest.Request.Keys))
+	for _, key := range providerRequest.Request.Keys {
+		results = append(results, v.verifyImage(ctx, key))
+	}
+	return externaldata.Response{Items: results}
+}
+
+func (v *signatureVerifier) verifyImage(ctx context.Context, key string) externaldata.Item {
+	var req verificationRequest
+	if err := json.Unmarshal([]byte(key), &req); err != nil {
+		return externaldata.Item{
+			Key:   key,
+			Error: fmt.Errorf("unable to unmarshal verification request: %w", err).Error(),
+		}
+	}
+
+	v.logger.Info("verify signatures", "image", req.Image)
+	if err := v.verify(ctx, req); err != nil {
+		v.logger.Info("verify signatures", "image", req.Image, "error", err.Error())
+		return externaldata.Item{
+			Key:   key,
+			Error: err.Error(),
+		}
+	}
+
+	return externaldata.Item{
+		Key:   key,
+		Value: "signature verified",
+	}
+}
+
+func (v *signatureVerifier) verify(ctx context.Context, req verificationRequest) error {
+	keys, err := parsePublicKeys(req.PublicKeys)
+	if err != nil {
+		return err
+	}
+
+	ref, err := name.ParseReference(req.Image)
+	if err != nil {
+		return fmt.Errorf("unable to parse image reference: %w", err)
+	}
+
+	opts := []remote.Option{remote.WithContext(ctx), remote.WithAuthFromKeychain(v.keychain)}
+
+	digest, err := imageDigest(ref, opts)
+	if err != nil {
+		return fmt.Errorf("unable to get image digest: %w", err)
+	}
+
+	cacheKey := verifiedCacheKey(ref.Context().Name(), digest, req.PublicKeys)
+	if v.isVerified(cacheKey) {
+		return nil
+	}
+
+	sigRef := ref.Context().Tag(fmt.Sprintf("%s-%s%s", digest.Algorithm, digest.Hex, signatureTagSuffix))
+	sigImage, err := remote.Image(sigRef, opts...)
+	if err != nil {
+		var terr *transport.Error
+		if errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("no signatures found")
+		}
+		return fmt.Errorf("unable to get signatures: %w", err)
+	}
+
+	manifest, err := sigImage.Manifest()
+	if err != nil {
+		return fmt.Errorf("unable to get signatures manifest: %w", err)
+	}
+
+	for _, layer := range manifest.Layers {
+		encodedSignature, ok := layer.Annotations[signatureAnnotation]
+		if !ok {
+			continue
+		}
+
+		signature, err := base64.StdEncoding.DecodeString(encodedSignature)
+		if err != nil {
+			continue
+		}
+
+		payload, err := layerPayload(sigImage, layer.Digest)
+		if err != nil {
+			return fmt.Errorf("unable to get signature payload: %w", err)
+		}
+
+		if !verifySignature(keys, payload, signature) {
+			continue
+		}
+
+		var signed simpleSigningPayload
+		if err := json.Unmarshal(payload, &signed); err != nil {
+			continue
+		}
+
+		if signed.Critical.Image.DockerManifestDigest == digest.String() {
+			v.setVerified(cacheKey)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no signatures made by the specified public keys")
+}
+
+// imageDigest returns the digest the image reference points to, digest references are not requested from the registry
+func imageDigest(ref name.Reference, opts []remote.Option) (v1.Hash, error) {
+	if d, ok := ref.(name.Digest); ok {
+		return v1.NewHash(d.DigestStr())
+	}
+
+	desc, err := remote.Head(ref, opts...)
+	if err != nil {
+		return v1.Hash{}, err
+	}
+	return desc.Digest, nil
+}
+
+func layerPayload(image v1.Image, digest v1.Hash) ([]byte, error) {
+	layer, err := image.LayerByDigest(digest)
+	if err != nil {
+		return nil, err
+	}
+
+	rc, err := layer.Compressed()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return io.ReadAll(io.LimitReader(rc, maxSignaturePayloadSize))
+}
+
+func parsePublicKeys(encodedKeys []string) ([]crypto.PublicKey, error) {
+	keys := make([]crypto.PublicKey, 0, len(encodedKeys))
+	for _, encoded := range encodedKeys {
+		block, _ := pem.Decode([]byte(strings.TrimSpace(encoded)))
+		if block == nil {
+			return nil, fmt.Errorf("unable to decode PEM public key")
+		}
+
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse public key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// verifySignature checks the signature is made by any of the keys, cosign signs the SHA256 digest of the payload
+func verifySignature(keys []crypto.PublicKey, payload, signature []byte) bool {
+	hash := sha256.Sum256(payload)
+	for _, key := range keys {
+		switch k := key.(type) {
+		case *ecdsa.PublicKey:
+			if ecdsa.VerifyASN1(k, hash[:], signature) {
+				return true
+			}
+		case *rsa.PublicKey:
+			if rsa.VerifyPKCS1v15(k, crypto.SHA256, hash[:], signature) == nil {
+				return true
+			}
+			if rsa.VerifyPSS(k, crypto.SHA256, hash[:], signature, nil) == nil {
+				return true
+			}
+		case ed25519.PublicKey:
+			if ed25519.Verify(k, payload, signature) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func verifiedCacheKey(repository string, digest v1.Hash, publicKeys []string) string {
+	hash := sha256.New()
+	hash.Write([]byte(repository + "\n" + digest.String()))
+	for _, key := range publicKeys {
+		hash.Write([]byte("\n" + strings.TrimSpace(key)))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (v *signatureVerifier) isVerified(key string) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	expiresAt, ok := v.verified[key]
+	return ok && time.Now().Before(expiresAt)
+}
+
+func (v *signatureVerifier) setVerified(key string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	now := time.Now()
+	if len(v.verified) >= verifiedSignaturesMaxSize {
+		for k, expiresAt := range v.verified {
+			if now.After(expiresAt) {
+				delete(v.verified, k)
+			}
+		}
+	}
+	if len(v.verified) < verifiedSignaturesMaxSize {
+		v.verified[key] = now.Add(verifiedSignaturesTTL)
+	}
+}
@@ -0,0 +1,294 @@
+/*
+Copyright 2024 Flant JSC
+Licensed under the Deckhouse Platform Enterprise Edition (EE) license. See https://github.com/deckhouse/deckhouse/blob/main/ee/LICENSE
+*/
+
+package validators
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signer signs the payload the way cosign does for the key type
+type signer struct {
+	publicKey crypto.PublicKey
+	sign      func(payload []byte) ([]byte, error)
+}
+
+func ecdsaSigner(t *testing.T) signer {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return signer{publicKey: &key.PublicKey, sign: func(payload []byte) ([]byte, error) {
+		hash := sha256.Sum256(payload)
+		return ecdsa.SignASN1(rand.Reader, key, hash[:])
+	}}
+}
+
+func rsaSigner(t *testing.T, pss bool) signer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return signer{publicKey: &key.PublicKey, sign: func(payload []byte) ([]byte, error) {
+		hash := sha256.Sum256(payload)
+		if pss {
+			return rsa.SignPSS(rand.Reader, key, crypto.SHA256, hash[:], nil)
+		}
+		return rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	}}
+}
+
+func ed25519Signer(t *testing.T) signer {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return signer{publicKey: public, sign: func(payload []byte) ([]byte, error) {
+		return ed25519.Sign(private, payload), nil
+	}}
+}
+
+func encodePublicKey(t *testing.T, key crypto.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func simpleSigning(digest string) []byte {
+	return []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"app"},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`, digest))
+}
+
+func TestVerifySignature(t *testing.T) {
+	payload := simpleSigning("sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08")
+
+	tests := []struct {
+		name   string
+		signer signer
+	}{
+		{name: "ECDSA", signer: ecdsaSigner(t)},
+		{name: "RSA PKCS1v15", signer: rsaSigner(t, false)},
+		{name: "RSA PSS", signer: rsaSigner(t, true)},
+		{name: "Ed25519", signer: ed25519Signer(t)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signature, err := tt.signer.sign(payload)
+			require.NoError(t, err)
+
+			other := ecdsaSigner(t)
+			assert.True(t, verifySignature([]crypto.PublicKey{other.publicKey, tt.signer.publicKey}, payload, signature))
+			assert.False(t, verifySignature([]crypto.PublicKey{other.publicKey}, payload, signature), "signature of another key")
+			assert.False(t, verifySignature([]crypto.PublicKey{tt.signer.publicKey}, append(payload, ' '), signature), "modified payload")
+		})
+	}
+}
+
+func TestParsePublicKeys(t *testing.T) {
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("not a key")})
+
+	tests := []struct {
+		name    string
+		keys    []string
+		wantErr string
+	}{
+		{name: "ECDSA and Ed25519 keys", keys: []string{encodePublicKey(t, ecdsaSigner(t).publicKey), "\n" + encodePublicKey(t, ed25519Signer(t).publicKey)}},
+		{name: "RSA key", keys: []string{encodePublicKey(t, rsaSigner(t, false).publicKey)}},
+		{name: "not PEM", keys: []string{"MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAE"}, wantErr: "unable to decode PEM public key"},
+		{name: "not a public key", keys: []string{string(certificate)}, wantErr: "unable to parse public key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := parsePublicKeys(tt.keys)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, keys, len(tt.keys))
+		})
+	}
+}
+
+// testRegistry is the in-memory registry counting the requests
+type testRegistry struct {
+	host     string
+	requests atomic.Int64
+}
+
+func newTestRegistry(t *testing.T) *testRegistry {
+	r := &testRegistry{}
+	handler := registry.New()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.requests.Add(1)
+		handler.ServeHTTP(w, req)
+	}))
+	t.Cleanup(srv.Close)
+	r.host = strings.TrimPrefix(srv.URL, "http://")
+	return r
+}
+
+// pushImage pushes a random image and returns its digest reference
+func (r *testRegistry) pushImage(t *testing.T, repository string) name.Digest {
+	image, err := random.Image(256, 1)
+	require.NoError(t, err)
+
+	tag, err := name.NewTag(r.host + "/" + repository + ":v1")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(tag, image))
+
+	digest, err := image.Digest()
+	require.NoError(t, err)
+	return tag.Context().Digest(digest.String())
+}
+
+// pushSignature pushes the cosign signature of the payload for the image
+func (r *testRegistry) pushSignature(t *testing.T, image name.Digest, s signer, payload []byte) {
+	signature, err := s.sign(payload)
+	require.NoError(t, err)
+
+	sigImage, err := mutate.Append(empty.Image, mutate.Addendum{
+		Layer:       static.NewLayer(payload, "application/vnd.dev.cosign.simplesigning.v1+json"),
+		Annotations: map[string]string{signatureAnnotation: base64.StdEncoding.EncodeToString(signature)},
+	})
+	require.NoError(t, err)
+
+	hash, err := v1.NewHash(image.DigestStr())
+	require.NoError(t, err)
+	sigTag := image.Context().Tag(fmt.Sprintf("%s-%s%s", hash.Algorithm, hash.Hex, signatureTagSuffix))
+	require.NoError(t, remote.Write(sigTag, sigImage))
+}
+
+func TestSignatureVerifier(t *testing.T) {
+	reg := newTestRegistry(t)
+	key := ecdsaSigner(t)
+	publicKeys := []string{encodePublicKey(t, key.publicKey)}
+
+	signed := reg.pushImage(t, "apps/signed")
+	reg.pushSignature(t, signed, key, simpleSigning(signed.DigestStr()))
+
+	unsigned := reg.pushImage(t, "apps/unsigned")
+
+	otherKey := reg.pushImage(t, "apps/other-key")
+	reg.pushSignature(t, otherKey, ecdsaSigner(t), simpleSigning(otherKey.DigestStr()))
+
+	// the signature is made by the key, but for another image
+	mismatch := reg.pushImage(t, "apps/mismatch")
+	reg.pushSignature(t, mismatch, key, simpleSigning(signed.DigestStr()))
+
+	tests := []struct {
+		name    string
+		image   string
+		keys    []string
+		wantErr string
+	}{
+		{name: "signed image", image: signed.String()},
+		{name: "signed image by tag", image: signed.Context().Tag("v1").String()},
+		{name: "unsigned image", image: unsigned.String(), wantErr: "no signatures found"},
+		{name: "signed by another key", image: otherKey.String(), wantErr: "no signatures made by the specified public keys"},
+		{name: "digest mismatch", image: mismatch.String(), wantErr: "no signatures made by the specified public keys"},
+		{name: "invalid public key", image: signed.String(), keys: []string{"invalid"}, wantErr: "unable to decode PEM public key"},
+		{name: "invalid reference", image: "registry.example.com/App:v1", wantErr: "unable to parse image reference"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := tt.keys
+			if keys == nil {
+				keys = publicKeys
+			}
+
+			err := NewSignatureVerifier(logr.Discard()).verify(context.Background(), verificationRequest{Image: tt.image, PublicKeys: keys})
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("verified signatures are cached", func(t *testing.T) {
+		v := NewSignatureVerifier(logr.Discard())
+		req := verificationRequest{Image: signed.String(), PublicKeys: publicKeys}
+
+		require.NoError(t, v.verify(context.Background(), req))
+		requests := reg.requests.Load()
+
+		require.NoError(t, v.verify(context.Background(), req))
+		assert.Equal(t, requests, reg.requests.Load(), "signatures must not be pulled again")
+
+		// another set of keys is verified again
+		req.PublicKeys = append(req.PublicKeys, encodePublicKey(t, ed25519Signer(t).publicKey))
+		require.NoError(t, v.verify(context.Background(), req))
+		assert.Greater(t, reg.requests.Load(), requests)
+	})
+
+	t.Run("failed verifications are not cached", func(t *testing.T) {
+		v := NewSignatureVerifier(logr.Discard())
+		req := verificationRequest{Image: unsigned.String(), PublicKeys: publicKeys}
+
+		require.Error(t, v.verify(context.Background(), req))
+		reg.pushSignature(t, unsigned, key, simpleSigning(unsigned.DigestStr()))
+		assert.NoError(t, v.verify(context.Background(), req))
+	})
+}
+
+func TestScanReport(t *testing.T) {
+	reg := newTestRegistry(t)
+	key := ecdsaSigner(t)
+	publicKeys := []string{encodePublicKey(t, key.publicKey)}
+
+	signed := reg.pushImage(t, "apps/signed")
+	reg.pushSignature(t, signed, key, simpleSigning(signed.DigestStr()))
+	unsigned := reg.pushImage(t, "apps/unsigned")
+
+	requestKey := func(image string) interface{} {
+		key, err := json.Marshal(verificationRequest{Image: image, PublicKeys: publicKeys})
+		require.NoError(t, err)
+		return string(key)
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"apiVersion": "externaldata.gatekeeper.sh/v1beta1",
+		"kind":       "ProviderRequest",
+		"request":    map[string]interface{}{"keys": []interface{}{requestKey(signed.String()), requestKey(unsigned.String()), "not json"}},
+	})
+	require.NoError(t, err)
+
+	response := NewSignatureVerifier(logr.Discard()).ScanReport(context.Background(), data)
+	require.Empty(t, response.SystemError)
+	require.Len(t, response.Items, 3)
+	assert.Equal(t, "signature verified", response.Items[0].Value)
+	assert.Empty(t, response.Items[0].Error)
+	assert.Contains(t, response.Items[1].Error, "no signatures found")
+	assert.Contains(t, response.Items[2].Error, "unable to unmarshal verification request")
+
+	response = NewSignatureVerifier(logr.Discard()).ScanReport(context.Background(), []byte("not json"))
+	assert.Contains(t, response.SystemError, "unable to unmarshal data")
+}
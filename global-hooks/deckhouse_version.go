@@ -20,6 +20,8 @@ import (
 
 	"github.com/flant/addon-operator/pkg/module_manager/go_hook"
 	"github.com/flant/addon-operator/sdk"
+
+	"github.com/deckhouse/deckhouse/go_lib/dependency/requirements"
 )
 
 var _ = sdk.RegisterFunc(&go_hook.HookConfig{
@@ -41,5 +43,6 @@ func discoverDeckhouseVersion(input *go_hook.HookInput) error {
 	}
 
 	input.Values.Set("global.deckhouseVersion", version)
+	requirements.SaveValue("global.deckhouseVersion", version)
 	return nil
 }
@@ -0,0 +1,177 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debugbundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deckhouse/deckhouse/go_lib/set"
+)
+
+const errorsFile = "collect-errors.txt"
+
+// Options control which collectors run and how.
+type Options struct {
+	// Modules limits module collectors to the listed modules, core collectors always run.
+	Modules []string
+	// Timeout overrides the default collector timeout.
+	Timeout time.Duration
+	// Parallelism is the number of collectors running at the same time.
+	Parallelism int
+}
+
+type result struct {
+	path string
+	data []byte
+	err  error
+}
+
+// Write runs collectors in parallel and writes the redacted results to w as a gzipped tarball.
+// Failed collectors do not stop the others, their errors are written to the collect-errors.txt file of the bundle
+// and returned joined together.
+func Write(ctx context.Context, w io.Writer, env *Env, collectors []Collector, opts Options) error {
+	collectors = filterCollectors(collectors, opts.Modules)
+
+	parallelism := opts.Parallelism
+	if parallelism <= 0 {
+		parallelism = 1
+	}
+
+	results := make([]result, len(collectors))
+	sem := make(chan struct{}, parallelism)
+
+	var wg sync.WaitGroup
+	for i, c := range collectors {
+		wg.Add(1)
+		go func(i int, c Collector) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i] = run(ctx, env, c, opts.Timeout)
+		}(i, c)
+	}
+	wg.Wait()
+
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	var errs []string
+	for _, res := range results {
+		if res.err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", res.path, res.err))
+			continue
+		}
+		if err := writeFile(tarWriter, res.path, Redact(res.data)); err != nil {
+			return err
+		}
+	}
+
+	if len(errs) > 0 {
+		if err := writeFile(tarWriter, errorsFile, []byte(strings.Join(errs, "\n")+"\n")); err != nil {
+			return err
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return fmt.Errorf("close tar: %w", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return fmt.Errorf("close gzip: %w", err)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%d collectors failed:\n%s", len(errs), strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+func run(ctx context.Context, env *Env, c Collector, timeout time.Duration) result {
+
+	switch {
+	case c.Timeout > 0:
+		timeout = c.Timeout
+	case timeout <= 0:
+		timeout = DefaultTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan result, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- result{path: c.Path(), err: fmt.Errorf("panic: %v", r)}
+			}
+		}()
+
+		data, err := c.Collect(ctx, env)
+		done <- result{path: c.Path(), data: data, err: err}
+	}()
+
+	// Do not wait for collectors ignoring the context.
+	select {
+	case res := <-done:
+		return res
+	case <-ctx.Done():
+		return result{path: c.Path(), err: fmt.Errorf("timed out after %s", timeout)}
+	}
+}
+
+func filterCollectors(collectors []Collector, modules []string) []Collector {
+	res := make([]Collector, 0, len(collectors))
+	selected := set.New(modules...)
+	for _, c := range collectors {
+		if c.Module != "" && selected.Size() > 0 && !selected.Has(c.Module) {
+			continue
+		}
+		res = append(res, c)
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Path() < res[j].Path()
+	})
+	return res
+}
+
+func writeFile(tarWriter *tar.Writer, name string, content []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(content)),
+		ModTime: time.Now(),
+	}
+
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return fmt.Errorf("write tar header: %v", err)
+	}
+
+	if _, err := tarWriter.Write(content); err != nil {
+		return fmt.Errorf("copy content: %v", err)
+	}
+
+	return nil
+}
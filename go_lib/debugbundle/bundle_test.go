@@ -0,0 +1,106 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debugbundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readBundle(t *testing.T, data []byte) map[string]string {
+	gzipReader, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	files := make(map[string]string)
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+
+		content, err := io.ReadAll(tarReader)
+		require.NoError(t, err)
+		files[header.Name] = string(content)
+	}
+	return files
+}
+
+func static(content string) CollectFunc {
+	return func(_ context.Context, _ *Env) ([]byte, error) {
+		return []byte(content), nil
+	}
+}
+
+func TestWrite(t *testing.T) {
+	started := make(chan struct{}, 2)
+	parallel := func(ctx context.Context, _ *Env) ([]byte, error) {
+		started <- struct{}{}
+		// Both collectors must be started at the same time to finish.
+		for len(started) < 2 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(time.Millisecond):
+			}
+		}
+		return []byte("ok"), nil
+	}
+
+	collectors := []Collector{
+		{File: "values.json", Collect: static(`{"password": "qwerty"}`)},
+		{File: "parallel-1.txt", Collect: parallel},
+		{File: "parallel-2.txt", Collect: parallel},
+		{File: "slow.txt", Timeout: 10 * time.Millisecond, Collect: func(ctx context.Context, _ *Env) ([]byte, error) {
+			<-time.After(time.Second)
+			return []byte("too late"), nil
+		}},
+		{File: "failed.txt", Collect: func(_ context.Context, _ *Env) ([]byte, error) {
+			return nil, errors.New("forbidden")
+		}},
+		{File: "status.json", Module: "log-shipper", Collect: static(`{}`)},
+		{File: "alerts.json", Module: "prometheus", Collect: static(`[]`)},
+	}
+
+	var buf bytes.Buffer
+	err := Write(context.Background(), &buf, &Env{}, collectors, Options{
+		Modules:     []string{"log-shipper"},
+		Timeout:     time.Second,
+		Parallelism: 2,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2 collectors failed")
+
+	files := readBundle(t, buf.Bytes())
+	assert.JSONEq(t, `{"password": "<redacted>"}`, files["values.json"])
+	assert.Equal(t, "ok", files["parallel-1.txt"])
+	assert.Equal(t, "ok", files["parallel-2.txt"])
+	assert.Contains(t, files, "log-shipper/status.json")
+	assert.NotContains(t, files, "prometheus/alerts.json")
+	assert.NotContains(t, files, "slow.txt")
+	assert.Equal(t, "failed.txt: forbidden\nslow.txt: timed out after 10ms\n", files[errorsFile])
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debugbundle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ListItems returns objects of the resource as a JSON array.
+// An empty namespace lists objects in all namespaces.
+func (e *Env) ListItems(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) ([]byte, error) {
+	list, err := e.Kube.Dynamic().Resource(gvr).Namespace(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", gvr.String(), err)
+	}
+
+	items := make([]interface{}, 0, len(list.Items))
+	for _, item := range list.Items {
+		items = append(items, item.Object)
+	}
+	return json.Marshal(items)
+}
+
+// PodLogs returns the last lines of the container logs of pods matching the label selector.
+// Logs are limited by the Since time of the environment.
+func (e *Env) PodLogs(ctx context.Context, namespace, selector, container string, tailLines int64) ([]byte, error) {
+	pods, err := e.Kube.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("list pods: %w", err)
+	}
+
+	opts := &corev1.PodLogOptions{Container: container}
+	if tailLines > 0 {
+		opts.TailLines = &tailLines
+	}
+	if !e.Since.IsZero() {
+		opts.SinceTime = &metav1.Time{Time: e.Since}
+	}
+
+	var buf bytes.Buffer
+	for _, pod := range pods.Items {
+		if len(pods.Items) > 1 {
+			fmt.Fprintf(&buf, "==> %s/%s <==\n", pod.Namespace, pod.Name)
+		}
+
+		stream, err := e.Kube.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, opts).Stream(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("get logs of %s/%s: %w", pod.Namespace, pod.Name, err)
+		}
+		_, err = io.Copy(&buf, stream)
+		stream.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read logs of %s/%s: %w", pod.Namespace, pod.Name, err)
+		}
+	}
+	return buf.Bytes(), nil
+}
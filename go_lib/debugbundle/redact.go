@@ -0,0 +1,159 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debugbundle
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+)
+
+const redacted = "<redacted>"
+
+var (
+	sensitiveKeyRe = regexp.MustCompile(`(?i)(password|passwd|passphrase|secret|token|dockercfg|dockerconfigjson|privatekey|private_key|apikey|api_key|credentials)`)
+	// Keys that refer to sensitive data but do not contain it, e.g., secretName or tokenTTL.
+	referenceKeyRe = regexp.MustCompile(`(?i)(name|ref|ttl|namespace|path|file)$`)
+
+	textRules = []struct {
+		re   *regexp.Regexp
+		repl string
+	}{
+		{
+			re:   regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+			repl: redacted,
+		},
+		{
+			re:   regexp.MustCompile(`eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]*`),
+			repl: redacted,
+		},
+		{
+			re:   regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9\-._~+/]+=*`),
+			repl: "${1}" + redacted,
+		},
+		{
+			re:   regexp.MustCompile(`(?i)(password|passwd|token|secret|dockercfg)(\\?["']?\s*[=:]\s*\\?["']?)[^\s"'\\,}]+`),
+			repl: "${1}${2}" + redacted,
+		},
+	}
+)
+
+// Redact hides Secrets data, tokens, passwords and docker configs.
+// JSON documents are redacted by keys, other data is redacted line by line by known patterns.
+func Redact(data []byte) []byte {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid(trimmed) {
+		if res, ok := redactJSON(trimmed, true); ok {
+			return res
+		}
+	}
+
+	lines := bytes.Split(data, []byte("\n"))
+	for i, line := range lines {
+		trimmedLine := bytes.TrimSpace(line)
+		if len(trimmedLine) > 0 && trimmedLine[0] == '{' && json.Valid(trimmedLine) {
+			if res, ok := redactJSON(trimmedLine, false); ok {
+				lines[i] = res
+			}
+		}
+	}
+	return redactText(bytes.Join(lines, []byte("\n")))
+}
+
+func redactJSON(data []byte, indent bool) ([]byte, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return nil, false
+	}
+
+	v = redactValue(v)
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if indent {
+		encoder.SetIndent("", "  ")
+	}
+	if err := encoder.Encode(v); err != nil {
+		return nil, false
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), true
+}
+
+func redactValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		if val["kind"] == "Secret" {
+			redactSecret(val)
+		}
+		for k, item := range val {
+			if s, ok := item.(string); ok && s != "" && isSensitiveKey(k) {
+				val[k] = redacted
+				continue
+			}
+			val[k] = redactValue(item)
+		}
+		return val
+	case []interface{}:
+		for i := range val {
+			val[i] = redactValue(val[i])
+		}
+		return val
+	case string:
+		return string(redactText([]byte(val)))
+	default:
+		return v
+	}
+}
+
+func redactSecret(secret map[string]interface{}) {
+	for _, field := range []string{"data", "stringData"} {
+		data, ok := secret[field].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for k := range data {
+			data[k] = redacted
+		}
+	}
+
+	metadata, ok := secret["metadata"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	annotations, ok := metadata["annotations"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	if _, ok := annotations["kubectl.kubernetes.io/last-applied-configuration"]; ok {
+		annotations["kubectl.kubernetes.io/last-applied-configuration"] = redacted
+	}
+}
+
+func isSensitiveKey(key string) bool {
+	return sensitiveKeyRe.MatchString(key) && !referenceKeyRe.MatchString(key)
+}
+
+func redactText(data []byte) []byte {
+	for _, rule := range textRules {
+		data = rule.re.ReplaceAll(data, []byte(rule.repl))
+	}
+	return data
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debugbundle
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/rest"
+
+	"github.com/deckhouse/deckhouse/go_lib/dependency/k8s"
+)
+
+// DefaultTimeout limits a collector run if the collector does not set its own timeout.
+const DefaultTimeout = time.Minute
+
+var (
+	registryMu sync.Mutex
+	registry   []Collector
+)
+
+// CollectFunc returns data to put in the bundle. The data is redacted before writing.
+type CollectFunc func(ctx context.Context, env *Env) ([]byte, error)
+
+// Collector describes a file of the debug bundle.
+type Collector struct {
+	// File is the path of the collected data in the bundle.
+	// Files of module collectors are placed in the directory named after the module.
+	File string
+	// Module is the name of the module the collector belongs to.
+	// It is detected from the package of the collect function if empty, core collectors have no module.
+	Module string
+	// Timeout limits the collector run, DefaultTimeout is used if it is zero.
+	Timeout time.Duration
+
+	Collect CollectFunc
+}
+
+// Env is passed to collectors.
+type Env struct {
+	Kube       k8s.Client
+	RestConfig *rest.Config
+	// Since limits logs and events to the time window, zero value means no limit.
+	Since time.Time
+}
+
+var moduleRe = regexp.MustCompile(`/modules/([0-9]+-)?([^/.]+)/`)
+
+// Register adds collector to the default registry, it is supposed to be called in init() of module debug packages.
+func Register(c Collector) {
+	if c.Module == "" {
+		c.Module = moduleOf(c.Collect)
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = append(registry, c)
+}
+
+// Collectors returns registered collectors sorted by the bundle path.
+func Collectors() []Collector {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	res := make([]Collector, len(registry))
+	copy(res, registry)
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Path() < res[j].Path()
+	})
+	return res
+}
+
+// Path returns the path of the collected data in the bundle.
+func (c Collector) Path() string {
+	if c.Module == "" {
+		return c.File
+	}
+	return c.Module + "/" + c.File
+}
+
+// moduleOf returns the module name by the package of the function,
+// e.g., github.com/deckhouse/deckhouse/modules/460-log-shipper/debug.init.0.func1 belongs to log-shipper.
+func moduleOf(f CollectFunc) string {
+	if f == nil {
+		return ""
+	}
+
+	fn := runtime.FuncForPC(reflect.ValueOf(f).Pointer())
+	if fn == nil {
+		return ""
+	}
+
+	match := moduleRe.FindStringSubmatch(fn.Name())
+	if len(match) == 0 {
+		return ""
+	}
+	return match[2]
+}
@@ -46,6 +46,7 @@ func InitService(mm ModuleManager) {
 		configValidator:      NewConfigValidator(mm.GetValuesValidator()),
 		statusReporter:       NewModuleInfo(mm, possibleNames),
 		moduleNamesToSources: make(map[string]string),
+		moduleDependencies:   make(map[string][]string),
 	}
 }
 
@@ -68,6 +69,9 @@ type ConfigService struct {
 
 	moduleNamesToSourcesMu sync.RWMutex
 	moduleNamesToSources   map[string]string
+
+	moduleDependenciesMu sync.RWMutex
+	moduleDependencies   map[string][]string
 }
 
 func (srv *ConfigService) PossibleNames() set.Set {
@@ -106,6 +110,28 @@ func (srv *ConfigService) ModuleToSourcesNames() map[string]string {
 	return res
 }
 
+// SetModuleDependencies saves modules required by the module to be enabled
+func (srv *ConfigService) SetModuleDependencies(moduleName string, dependencies []string) {
+	srv.moduleDependenciesMu.Lock()
+	srv.moduleDependencies[moduleName] = dependencies
+	srv.moduleDependenciesMu.Unlock()
+}
+
+// MissingModuleDependencies returns disabled modules required by the module
+func (srv *ConfigService) MissingModuleDependencies(moduleName string) []string {
+	srv.moduleDependenciesMu.RLock()
+	defer srv.moduleDependenciesMu.RUnlock()
+
+	missing := make([]string, 0)
+	for _, dependency := range srv.moduleDependencies[moduleName] {
+		if !srv.moduleManager.IsModuleEnabled(dependency) {
+			missing = append(missing, dependency)
+		}
+	}
+
+	return missing
+}
+
 func (srv *ConfigService) GetValuesValidator() *validation.ValuesValidator {
 	return srv.moduleManager.GetValuesValidator()
 }
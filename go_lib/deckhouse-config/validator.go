@@ -183,6 +183,19 @@ func (c *ConfigValidator) validateSettings(cfgName string, cfgSettings map[strin
 		return nil
 	}
 
+	valuesKey := valuesKeyFromObjectName(cfgName)
+	values := utils.Values{valuesKey: c.SettingsWithDefaults(cfgName, cfgSettings)}
+
+	if cfgName == "global" {
+		return c.valuesValidator.ValidateGlobalConfigValues(values)
+	}
+
+	return c.valuesValidator.ValidateModuleConfigValues(valuesKey, values)
+}
+
+// SettingsWithDefaults returns spec.settings merged with defaults from the OpenAPI schema as addon-operator will do.
+// The result is a map with 'plain values', i.e. without camelCased module name as a root key.
+func (c *ConfigValidator) SettingsWithDefaults(cfgName string, cfgSettings map[string]interface{}) utils.Values {
 	// init cfg settings if it equals nil
 	if cfgSettings == nil {
 		cfgSettings = make(map[string]interface{})
@@ -196,22 +209,14 @@ func (c *ConfigValidator) validateSettings(cfgName string, cfgSettings map[strin
 
 	// Instantiate defaults from the OpenAPI schema.
 	defaultSettings := make(map[string]interface{})
-	s := c.valuesValidator.GetSchema(schemaType, validation.ConfigValuesSchema, valuesKey)
-	if s != nil {
-		validation.ApplyDefaults(defaultSettings, s)
-	}
-
-	// Merge defaults with passed settings as addon-operator will do.
-	values := utils.MergeValues(
-		utils.Values{valuesKey: defaultSettings},
-		utils.Values{valuesKey: cfgSettings},
-	)
-
-	if cfgName == "global" {
-		return c.valuesValidator.ValidateGlobalConfigValues(values)
+	if c.valuesValidator != nil {
+		s := c.valuesValidator.GetSchema(schemaType, validation.ConfigValuesSchema, valuesKey)
+		if s != nil {
+			validation.ApplyDefaults(defaultSettings, s)
+		}
 	}
 
-	return c.valuesValidator.ValidateModuleConfigValues(valuesKey, values)
+	return utils.MergeValues(defaultSettings, cfgSettings)
 }
 
 func valuesKeyFromObjectName(name string) string {
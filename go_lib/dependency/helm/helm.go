@@ -19,6 +19,7 @@ package helm
 import (
 	"crypto/md5"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"maps"
 	"strings"
@@ -214,6 +215,25 @@ func (client *helmClient) rollbackLatestRelease(releases []*release.Release) {
 	}
 }
 
+// GetDeployedManifest returns manifests of the last deployed revision of the release from the `namespace` storage.
+// Empty string is returned if the release is not deployed.
+func GetDeployedManifest(namespace, releaseName string) (string, error) {
+	conf, err := getActionConfig(namespace)
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := conf.Releases.Deployed(releaseName)
+	if err != nil {
+		if errors.Is(err, driver.ErrReleaseNotFound) || errors.Is(err, driver.ErrNoDeployedReleases) {
+			return "", nil
+		}
+		return "", fmt.Errorf("get deployed release %s: %w", releaseName, err)
+	}
+
+	return rel.Manifest, nil
+}
+
 func getActionConfig(namespace string) (*action.Configuration, error) {
 	if namespace == "" {
 		return nil, fmt.Errorf("namespace must be specified")
@@ -18,12 +18,20 @@ package update
 
 import (
 	"encoding/json"
+	"fmt"
 	"strings"
 	"time"
+	// embed tz database, images may not have /usr/share/zoneinfo
+	_ "time/tzdata"
 )
 
 const (
-	hh_mm = "15:04" // nolint: revive
+	hh_mm      = "15:04"      // nolint: revive
+	yyyy_mm_dd = "2006-01-02" // nolint: revive
+
+	// maxLookupDays limits the search of the next allowed time,
+	// blackout dates could close all windows for a long period
+	maxLookupDays = 366
 )
 
 // Windows update windows
@@ -34,6 +42,10 @@ type Window struct {
 	From string   `json:"from"`
 	To   string   `json:"to"`
 	Days []string `json:"days"`
+	// Timezone is an IANA timezone name (Europe/Moscow), UTC is used if empty
+	Timezone string `json:"timezone,omitempty"`
+	// BlackoutDates is a list of dates (YYYY-MM-DD, in the window timezone) when the window is closed regardless of Days
+	BlackoutDates []string `json:"blackoutDates,omitempty"`
 }
 
 // FromJSON returns update Windows from json
@@ -41,8 +53,45 @@ func FromJSON(data []byte) (Windows, error) {
 	var w Windows
 
 	err := json.Unmarshal(data, &w)
+	if err != nil {
+		return nil, err
+	}
+
+	return w, w.Validate()
+}
+
+// Validate checks fields which could not be validated through the openapi spec
+func (ws Windows) Validate() error {
+	for _, window := range ws {
+		if err := window.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
 
-	return w, err
+// Validate checks window time, timezone and blackout dates
+func (uw Window) Validate() error {
+	if _, err := time.Parse(hh_mm, uw.From); err != nil {
+		return fmt.Errorf("invalid window start %q: %w", uw.From, err)
+	}
+
+	if _, err := time.Parse(hh_mm, uw.To); err != nil {
+		return fmt.Errorf("invalid window end %q: %w", uw.To, err)
+	}
+
+	if _, err := uw.location(); err != nil {
+		return fmt.Errorf("invalid window timezone %q: %w", uw.Timezone, err)
+	}
+
+	for _, date := range uw.BlackoutDates {
+		if _, err := time.Parse(yyyy_mm_dd, date); err != nil {
+			return fmt.Errorf("invalid blackout date %q: %w", date, err)
+		}
+	}
+
+	return nil
 }
 
 // IsAllowed returns if specified time get into windows
@@ -61,24 +110,28 @@ func (ws Windows) IsAllowed(t time.Time) bool {
 }
 
 // IsAllowed check if specified window is allowed at the moment or not
+// a window with an invalid timezone is never allowed, use Validate to get the reason
 func (uw Window) IsAllowed(now time.Time) bool {
-	now = now.UTC()
-	// input is validated through the openapi spec
-	// we must have only a valid time here
-	fromInput, _ := time.Parse(hh_mm, uw.From)
-	toInput, _ := time.Parse(hh_mm, uw.To)
-
-	fromTime := time.Date(now.Year(), now.Month(), now.Day(), fromInput.Hour(), fromInput.Minute(), 0, 0, time.UTC)
-	toTime := time.Date(now.Year(), now.Month(), now.Day(), toInput.Hour(), toInput.Minute(), 0, 0, time.UTC)
-
-	updateToday := uw.isTodayAllowed(now, uw.Days)
+	loc, err := uw.location()
+	if err != nil {
+		return false
+	}
+	now = now.In(loc)
 
-	if !updateToday {
+	if uw.isBlackoutDate(now) {
 		return false
 	}
 
-	if now.After(fromTime) && now.Before(toTime) {
-		return true
+	// window which crosses midnight could be started yesterday
+	for _, day := range []time.Time{now.AddDate(0, 0, -1), now} {
+		if !uw.isTodayAllowed(day, uw.Days) {
+			continue
+		}
+
+		fromTime, toTime := uw.bounds(day)
+		if !now.Before(fromTime) && now.Before(toTime) {
+			return true
+		}
 	}
 
 	return false
@@ -86,6 +139,7 @@ func (uw Window) IsAllowed(now time.Time) bool {
 
 // NextAllowedTime calculates next update window with respect on minimalTime
 // if minimal time is out of window - this function checks next days to find the nearest one
+// zero time is returned if all windows are closed for more than a year (blackout dates)
 func (ws Windows) NextAllowedTime(min time.Time) time.Time {
 	min = min.UTC()
 
@@ -96,48 +150,9 @@ func (ws Windows) NextAllowedTime(min time.Time) time.Time {
 	var minTime time.Time
 
 	for _, window := range ws {
-		var windowMinTime time.Time
-
-		fromInput, _ := time.Parse(hh_mm, window.From)
-		toInput, _ := time.Parse(hh_mm, window.To)
-
-		fromTime := time.Date(min.Year(), min.Month(), min.Day(), fromInput.Hour(), fromInput.Minute(), 0, 0, time.UTC)
-		toTime := time.Date(min.Year(), min.Month(), min.Day(), toInput.Hour(), toInput.Minute(), 0, 0, time.UTC)
-
-		if window.isTodayAllowed(min, window.Days) {
-			if (min.After(fromTime) || min.Equal(fromTime)) && min.Before(toTime) {
-				windowMinTime = min
-				if minTime.IsZero() || windowMinTime.Before(minTime) {
-					minTime = windowMinTime
-				}
-				continue
-			} else if min.Before(fromTime) {
-				windowMinTime = fromTime
-				if minTime.IsZero() || windowMinTime.Before(minTime) {
-					minTime = windowMinTime
-				}
-				continue
-			}
-		}
-
-		// if not today
-		nextDay := min.AddDate(0, 0, 1)
-		for {
-			if window.isTodayAllowed(nextDay, window.Days) {
-				fromTime = time.Date(nextDay.Year(), nextDay.Month(), nextDay.Day(), fromInput.Hour(), fromInput.Minute(), 0, 0, time.UTC)
-				toTime = time.Date(nextDay.Year(), nextDay.Month(), nextDay.Day(), toInput.Hour(), toInput.Minute(), 0, 0, time.UTC)
-
-				if min.Before(toTime) {
-					if min.After(fromTime) || min.Equal(fromTime) {
-						windowMinTime = min
-						break
-					}
-
-					windowMinTime = fromTime
-					break
-				}
-			}
-			nextDay = nextDay.AddDate(0, 0, 1)
+		windowMinTime, ok := window.nextAllowedTime(min)
+		if !ok {
+			continue
 		}
 
 		if minTime.IsZero() || windowMinTime.Before(minTime) {
@@ -145,7 +160,90 @@ func (ws Windows) NextAllowedTime(min time.Time) time.Time {
 		}
 	}
 
-	return minTime.Round(time.Minute)
+	if minTime.IsZero() {
+		return minTime
+	}
+
+	return minTime.UTC().Round(time.Minute)
+}
+
+func (uw Window) nextAllowedTime(min time.Time) (time.Time, bool) {
+	loc, err := uw.location()
+	if err != nil {
+		return time.Time{}, false
+	}
+	local := min.In(loc)
+
+	// start from the previous day, because the window could cross midnight
+	for i := -1; i <= maxLookupDays; i++ {
+		day := local.AddDate(0, 0, i)
+		if !uw.isTodayAllowed(day, uw.Days) {
+			continue
+		}
+
+		fromTime, toTime := uw.bounds(day)
+		if !toTime.After(min) {
+			continue
+		}
+
+		next := fromTime
+		if min.After(fromTime) {
+			next = min
+		}
+
+		// skip blackout days inside the window
+		for next.Before(toTime) && uw.isBlackoutDate(next) {
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, next.Location())
+		}
+
+		if next.Before(toTime) {
+			return next, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// bounds returns start and end of the window which starts at the specified day.
+// if end time is not after the start time, the window ends at the next day
+func (uw Window) bounds(day time.Time) (time.Time, time.Time) {
+	// input is validated through the openapi spec
+	// we must have only a valid time here
+	fromInput, _ := time.Parse(hh_mm, uw.From)
+	toInput, _ := time.Parse(hh_mm, uw.To)
+
+	loc := day.Location()
+	fromTime := time.Date(day.Year(), day.Month(), day.Day(), fromInput.Hour(), fromInput.Minute(), 0, 0, loc)
+	toTime := time.Date(day.Year(), day.Month(), day.Day(), toInput.Hour(), toInput.Minute(), 0, 0, loc)
+
+	if !toTime.After(fromTime) {
+		toTime = time.Date(day.Year(), day.Month(), day.Day()+1, toInput.Hour(), toInput.Minute(), 0, 0, loc)
+	}
+
+	return fromTime, toTime
+}
+
+func (uw Window) location() (*time.Location, error) {
+	if uw.Timezone == "" {
+		return time.UTC, nil
+	}
+
+	return time.LoadLocation(uw.Timezone)
+}
+
+func (uw Window) isBlackoutDate(now time.Time) bool {
+	if len(uw.BlackoutDates) == 0 {
+		return false
+	}
+
+	date := now.Format(yyyy_mm_dd)
+	for _, blackout := range uw.BlackoutDates {
+		if blackout == date {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (uw Window) isDayEqual(today time.Time, dayString string) bool {
@@ -201,6 +299,11 @@ func (uw *Window) DeepCopyInto(out *Window) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if uw.BlackoutDates != nil {
+		in, out := &uw.BlackoutDates, &out.BlackoutDates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpdateWindow.
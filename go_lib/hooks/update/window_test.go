@@ -182,3 +182,140 @@ func TestNextAllowedWindow(t *testing.T) {
 		assert.Equal(t, time.Sunday, res.Weekday())
 	})
 }
+
+func TestWindowIsAllowed(t *testing.T) {
+	t.Run("overnight window", func(t *testing.T) {
+		ws := Windows{
+			{
+				From: "22:00",
+				To:   "04:00",
+				Days: []string{"fri"},
+			},
+		}
+		// friday 23:00
+		assert.True(t, ws.IsAllowed(time.Date(2021, 10, 15, 23, 00, 00, 0, time.UTC)))
+		// saturday 03:59, the window was started on friday
+		assert.True(t, ws.IsAllowed(time.Date(2021, 10, 16, 3, 59, 00, 0, time.UTC)))
+		// saturday 04:00
+		assert.False(t, ws.IsAllowed(time.Date(2021, 10, 16, 4, 00, 00, 0, time.UTC)))
+		// friday 03:00, the window was not started on thursday
+		assert.False(t, ws.IsAllowed(time.Date(2021, 10, 15, 3, 00, 00, 0, time.UTC)))
+	})
+
+	t.Run("window with timezone", func(t *testing.T) {
+		ws := Windows{
+			{
+				From:     "22:00",
+				To:       "04:00",
+				Timezone: "Europe/Moscow",
+			},
+		}
+		// 20:30 UTC is 23:30 MSK
+		assert.True(t, ws.IsAllowed(time.Date(2021, 10, 15, 20, 30, 00, 0, time.UTC)))
+		// 02:30 UTC is 05:30 MSK
+		assert.False(t, ws.IsAllowed(time.Date(2021, 10, 15, 2, 30, 00, 0, time.UTC)))
+	})
+
+	t.Run("window with invalid timezone is never allowed", func(t *testing.T) {
+		ws := Windows{
+			{
+				From:     "00:00",
+				To:       "23:59",
+				Timezone: "Mars/Olympus",
+			},
+		}
+		assert.False(t, ws.IsAllowed(time.Date(2021, 10, 15, 12, 00, 00, 0, time.UTC)))
+		assert.True(t, ws.NextAllowedTime(time.Date(2021, 10, 15, 12, 00, 00, 0, time.UTC)).IsZero())
+	})
+
+	t.Run("blackout date overrides days", func(t *testing.T) {
+		ws := Windows{
+			{
+				From:          "10:00",
+				To:            "12:00",
+				Days:          []string{"wed"},
+				BlackoutDates: []string{"2021-10-13"},
+			},
+		}
+		// wednesday 11:00, blackout
+		assert.False(t, ws.IsAllowed(time.Date(2021, 10, 13, 11, 00, 00, 0, time.UTC)))
+		// next wednesday 11:00
+		assert.True(t, ws.IsAllowed(time.Date(2021, 10, 20, 11, 00, 00, 0, time.UTC)))
+	})
+}
+
+func TestNextAllowedWindowExtended(t *testing.T) {
+	t.Run("overnight window: min time after midnight", func(t *testing.T) {
+		ws := Windows{
+			{
+				From: "22:00",
+				To:   "04:00",
+			},
+		}
+		min := time.Date(2021, 10, 17, 1, 15, 00, 0, time.UTC)
+
+		res := ws.NextAllowedTime(min)
+		assert.Equal(t, min, res)
+	})
+
+	t.Run("window with timezone", func(t *testing.T) {
+		ws := Windows{
+			{
+				From:     "22:00",
+				To:       "04:00",
+				Timezone: "Europe/Moscow",
+				Days:     []string{"sat"},
+			},
+		}
+		// wednesday 12:00 UTC
+		min := time.Date(2021, 10, 13, 12, 00, 00, 0, time.UTC)
+
+		res := ws.NextAllowedTime(min)
+		// saturday 22:00 MSK
+		assert.Equal(t, time.Date(2021, 10, 16, 19, 00, 00, 0, time.UTC), res)
+	})
+
+	t.Run("blackout date", func(t *testing.T) {
+		ws := Windows{
+			{
+				From:          "16:00",
+				To:            "18:00",
+				Days:          []string{"wed"},
+				BlackoutDates: []string{"2021-10-13", "2021-10-20"},
+			},
+		}
+		// wednesday 10:00
+		min := time.Date(2021, 10, 13, 10, 00, 00, 0, time.UTC)
+
+		res := ws.NextAllowedTime(min)
+		assert.Equal(t, time.Date(2021, 10, 27, 16, 00, 00, 0, time.UTC), res)
+	})
+
+	t.Run("blackout date inside overnight window", func(t *testing.T) {
+		ws := Windows{
+			{
+				From:          "22:00",
+				To:            "04:00",
+				Days:          []string{"fri"},
+				BlackoutDates: []string{"2021-10-15"},
+			},
+		}
+		// friday 21:00
+		min := time.Date(2021, 10, 15, 21, 00, 00, 0, time.UTC)
+
+		res := ws.NextAllowedTime(min)
+		// saturday 00:00, the rest of the friday window
+		assert.Equal(t, time.Date(2021, 10, 16, 0, 00, 00, 0, time.UTC), res)
+	})
+}
+
+func TestWindowsValidate(t *testing.T) {
+	_, err := FromJSON([]byte(`[{"from": "8:00", "to": "15:00", "timezone": "Europe/Moscow", "blackoutDates": ["2024-12-31"]}]`))
+	assert.NoError(t, err)
+
+	_, err = FromJSON([]byte(`[{"from": "8:00", "to": "15:00", "timezone": "Mars/Olympus"}]`))
+	assert.Error(t, err)
+
+	_, err = FromJSON([]byte(`[{"from": "8:00", "to": "15:00", "blackoutDates": ["31.12.2024"]}]`))
+	assert.Error(t, err)
+}
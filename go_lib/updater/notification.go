@@ -17,9 +17,8 @@ limitations under the License.
 package updater
 
 import (
-	"bytes"
-	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -30,11 +29,32 @@ import (
 	"github.com/deckhouse/deckhouse/go_lib/libapi"
 )
 
+// NotificationDeliveryAnnotation keeps the notification delivery state of the release per target
+const NotificationDeliveryAnnotation = "release.deckhouse.io/notification-delivery"
+
 type NotificationConfig struct {
-	WebhookURL              string          `json:"webhook"`
-	SkipTLSVerify           bool            `json:"tlsSkipVerify"`
-	MinimalNotificationTime libapi.Duration `json:"minimalNotificationTime"`
-	Auth                    *Auth           `json:"auth,omitempty"`
+	WebhookURL              string               `json:"webhook"`
+	SkipTLSVerify           bool                 `json:"tlsSkipVerify"`
+	MinimalNotificationTime libapi.Duration      `json:"minimalNotificationTime"`
+	Auth                    *Auth                `json:"auth,omitempty"`
+	Targets                 []NotificationTarget `json:"targets,omitempty"`
+}
+
+// AllTargets returns configured notification targets including the legacy webhook
+func (c *NotificationConfig) AllTargets() []NotificationTarget {
+	targets := make([]NotificationTarget, 0, len(c.Targets)+1)
+
+	if c.WebhookURL != "" {
+		targets = append(targets, NotificationTarget{
+			Name:          "webhook",
+			Type:          NotificationTargetWebhook,
+			URL:           c.WebhookURL,
+			SkipTLSVerify: c.SkipTLSVerify,
+			Auth:          c.Auth,
+		})
+	}
+
+	return append(targets, c.Targets...)
 }
 
 type Auth struct {
@@ -87,42 +107,97 @@ func ParseNotificationConfigFromValues(input *go_hook.HookInput) (*NotificationC
 		}
 	}
 
+	var targets []NotificationTarget
+	tg, ok := input.Values.GetOk("deckhouse.update.notification.targets")
+	if ok {
+		err := json.Unmarshal([]byte(tg.Raw), &targets)
+		if err != nil {
+			return nil, fmt.Errorf("parsing targets: %v", err)
+		}
+	}
+
 	return &NotificationConfig{
 		WebhookURL:              webhook.String(),
 		SkipTLSVerify:           skipTLSVertify,
 		MinimalNotificationTime: minimalTime,
 		Auth:                    auth,
+		Targets:                 targets,
 	}, nil
 }
 
-func sendWebhookNotification(config *NotificationConfig, data webhookData) error {
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: config.SkipTLSVerify},
+// notificationDelivery is the delivery state of the release notification by target name
+type notificationDelivery map[string]*targetDelivery
+
+type targetDelivery struct {
+	Delivered   bool       `json:"delivered,omitempty"`
+	Attempts    int        `json:"attempts,omitempty"`
+	NextAttempt *time.Time `json:"nextAttempt,omitempty"`
+}
+
+func parseNotificationDelivery(raw string) (notificationDelivery, error) {
+	delivery := make(notificationDelivery)
+	if raw == "" {
+		return delivery, nil
 	}
-	client := &http.Client{
-		Transport: tr,
-		Timeout:   10 * time.Second,
+
+	err := json.Unmarshal([]byte(raw), &delivery)
+	if err != nil {
+		return make(notificationDelivery), err
 	}
 
-	buf := bytes.NewBuffer(nil)
-	_ = json.NewEncoder(buf).Encode(data)
+	return delivery, nil
+}
 
-	req, err := http.NewRequest(http.MethodPost, config.WebhookURL, buf)
-	if err != nil {
-		return err
+func (nd notificationDelivery) String() string {
+	data, _ := json.Marshal(nd)
+	return string(data)
+}
+
+// sendNotification makes a single delivery attempt to every target that has not received the notification yet
+// and whose backoff has expired, the delivery state is updated in place.
+// It returns true when all targets have received the notification
+func sendNotification(targets []NotificationTarget, data webhookData, delivery notificationDelivery, now time.Time) (bool, error) {
+	var errs []error
+
+	for _, target := range targets {
+		state, ok := delivery[target.Name]
+		if !ok {
+			state = &targetDelivery{}
+			delivery[target.Name] = state
+		}
+
+		if state.Delivered {
+			continue
+		}
+
+		if state.NextAttempt != nil && now.Before(*state.NextAttempt) {
+			continue
+		}
+
+		state.Attempts++
+		err := target.send(data)
+		if err != nil {
+			nextAttempt := now.Add(target.Retry.backoff(state.Attempts - 1))
+			state.NextAttempt = &nextAttempt
+			errs = append(errs, fmt.Errorf("target %q (attempt %d): %w", target.Name, state.Attempts, err))
+			continue
+		}
+
+		state.Delivered = true
+		state.NextAttempt = nil
 	}
-	req.Header.Add("Content-Type", "application/json")
-	config.Auth.Fill(req)
 
-	for i := 0; i < 3; i++ {
-		_, err = client.Do(req)
-		if err == nil {
-			return nil
+	return allDelivered(targets, delivery), errors.Join(errs...)
+}
+
+func allDelivered(targets []NotificationTarget, delivery notificationDelivery) bool {
+	for _, target := range targets {
+		if state, ok := delivery[target.Name]; !ok || !state.Delivered {
+			return false
 		}
-		time.Sleep(3 * time.Second)
 	}
 
-	return err
+	return true
 }
 
 type webhookData struct {
@@ -132,4 +207,7 @@ type webhookData struct {
 	ApplyTime     string            `json:"applyTime,omitempty"`
 
 	Message string `json:"message"`
+
+	// ReleaseName is not a part of the webhook payload, it is available in targets templates only
+	ReleaseName string `json:"-"`
 }
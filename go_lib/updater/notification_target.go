@@ -0,0 +1,216 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package updater
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"text/template"
+	"time"
+
+	"github.com/Masterminds/sprig/v3"
+
+	"github.com/deckhouse/deckhouse/go_lib/libapi"
+)
+
+const (
+	NotificationTargetWebhook      = "Webhook"
+	NotificationTargetSlack        = "Slack"
+	NotificationTargetMattermost   = "Mattermost"
+	NotificationTargetAlertmanager = "Alertmanager"
+)
+
+const (
+	defaultNotificationInitialInterval = 3 * time.Second
+	defaultNotificationMaxInterval     = 30 * time.Second
+)
+
+// NotificationTarget describes a single receiver of the release notification
+type NotificationTarget struct {
+	Name          string `json:"name"`
+	Type          string `json:"type"`
+	URL           string `json:"url"`
+	SkipTLSVerify bool   `json:"tlsSkipVerify"`
+	Auth          *Auth  `json:"auth,omitempty"`
+	// Template is a Go template of the request body, it overrides the built-in format of the target type
+	Template string `json:"template,omitempty"`
+	// ExpectedStatusCodes are response codes treated as successful, any 2xx code is expected if empty
+	ExpectedStatusCodes []int              `json:"expectedStatusCodes,omitempty"`
+	Retry               *NotificationRetry `json:"retry,omitempty"`
+}
+
+// NotificationRetry exponential backoff settings, delivery is retried on the next updater runs
+type NotificationRetry struct {
+	InitialInterval libapi.Duration `json:"initialInterval,omitempty"`
+	MaxInterval     libapi.Duration `json:"maxInterval,omitempty"`
+}
+
+// backoff returns the delay before the next attempt, it is doubled after each failed attempt
+func (nr *NotificationRetry) backoff(attempt int) time.Duration {
+	initial, max := defaultNotificationInitialInterval, defaultNotificationMaxInterval
+	if nr != nil && nr.InitialInterval.Duration > 0 {
+		initial = nr.InitialInterval.Duration
+	}
+	if nr != nil && nr.MaxInterval.Duration > 0 {
+		max = nr.MaxInterval.Duration
+	}
+
+	delay := initial << attempt
+	if delay <= 0 || delay > max {
+		return max
+	}
+
+	return delay
+}
+
+func (nt *NotificationTarget) send(data webhookData) error {
+	body, err := nt.body(data)
+	if err != nil {
+		return fmt.Errorf("render payload: %w", err)
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: nt.SkipTLSVerify},
+		},
+		Timeout: 10 * time.Second,
+	}
+
+	return nt.post(client, body)
+}
+
+func (nt *NotificationTarget) post(client *http.Client, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, nt.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Content-Type", "application/json")
+	nt.Auth.Fill(req)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if !nt.isExpectedStatus(resp.StatusCode) {
+		return fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+
+	return nil
+}
+
+func (nt *NotificationTarget) isExpectedStatus(code int) bool {
+	if len(nt.ExpectedStatusCodes) == 0 {
+		return code >= 200 && code < 300
+	}
+
+	for _, expected := range nt.ExpectedStatusCodes {
+		if code == expected {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (nt *NotificationTarget) body(data webhookData) ([]byte, error) {
+	if nt.Template != "" {
+		tpl, err := template.New(nt.Name).Funcs(sprig.TxtFuncMap()).Parse(nt.Template)
+		if err != nil {
+			return nil, err
+		}
+
+		buf := bytes.NewBuffer(nil)
+		err = tpl.Execute(buf, data)
+		if err != nil {
+			return nil, err
+		}
+
+		return buf.Bytes(), nil
+	}
+
+	switch nt.Type {
+	case NotificationTargetSlack:
+		return json.Marshal(slackPayload{
+			Text: fmt.Sprintf("%s\n<%s|Changelog>", data.Message, data.ChangelogLink),
+		})
+
+	case NotificationTargetMattermost:
+		return json.Marshal(mattermostPayload{
+			Username: "Deckhouse",
+			Text:     fmt.Sprintf("%s\n[Changelog](%s)", data.Message, data.ChangelogLink),
+		})
+
+	case NotificationTargetAlertmanager:
+		return json.Marshal(newAlertmanagerPayload(data))
+
+	case NotificationTargetWebhook, "":
+		return json.Marshal(data)
+	}
+
+	return nil, fmt.Errorf("unknown target type %q", nt.Type)
+}
+
+// slackPayload is a Slack-compatible incoming webhook message
+type slackPayload struct {
+	Text string `json:"text"`
+}
+
+// mattermostPayload is a Mattermost-compatible incoming webhook message
+type mattermostPayload struct {
+	Username string `json:"username,omitempty"`
+	Text     string `json:"text"`
+}
+
+// alertmanagerAlert is an alert of the Alertmanager /api/v2/alerts API
+type alertmanagerAlert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     string            `json:"startsAt,omitempty"`
+	EndsAt       string            `json:"endsAt,omitempty"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+}
+
+// newAlertmanagerPayload returns the alert which fires until the release is applied
+func newAlertmanagerPayload(data webhookData) []alertmanagerAlert {
+	alert := alertmanagerAlert{
+		Labels: map[string]string{
+			"alertname": "D8ReleaseIsPending",
+			"severity":  "info",
+			"release":   data.ReleaseName,
+			"version":   data.Version,
+		},
+		Annotations: map[string]string{
+			"summary":     "New release is pending",
+			"description": data.Message,
+		},
+		StartsAt:     time.Now().UTC().Format(time.RFC3339),
+		GeneratorURL: data.ChangelogLink,
+	}
+
+	if applyTime, err := time.Parse(time.RFC3339, data.ApplyTime); err == nil && applyTime.After(time.Now()) {
+		alert.EndsAt = data.ApplyTime
+	}
+
+	return []alertmanagerAlert{alert}
+}
@@ -0,0 +1,336 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package updater
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/deckhouse/deckhouse/go_lib/libapi"
+)
+
+type receivedRequest struct {
+	body   []byte
+	header http.Header
+}
+
+// notificationServer records received requests and responds with the next status code from the list,
+// the last status code is repeated
+func notificationServer(t *testing.T, statuses ...int) (*httptest.Server, func() []receivedRequest) {
+	t.Helper()
+
+	var (
+		mu       sync.Mutex
+		requests []receivedRequest
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		mu.Lock()
+		requests = append(requests, receivedRequest{body: body, header: r.Header.Clone()})
+		status := statuses[len(statuses)-1]
+		if len(requests) <= len(statuses) {
+			status = statuses[len(requests)-1]
+		}
+		mu.Unlock()
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, func() []receivedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]receivedRequest(nil), requests...)
+	}
+}
+
+var testWebhookData = webhookData{
+	Version:       "1.60.0",
+	Requirements:  map[string]string{"k8s": "1.27"},
+	ChangelogLink: "https://github.com/deckhouse/deckhouse/releases/tag/v1.60.0",
+	ApplyTime:     "2124-01-01T13:30:00Z",
+	Message:       "New Deckhouse Release 1.60.0 is available. Release will be applied at: Monday, 01-Jan-24 13:30:00 UTC",
+	ReleaseName:   "v1.60.0",
+}
+
+func TestNotificationTargetFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		target NotificationTarget
+		check  func(t *testing.T, body []byte)
+	}{
+		{
+			name:   "webhook",
+			target: NotificationTarget{Type: NotificationTargetWebhook},
+			check: func(t *testing.T, body []byte) {
+				var got map[string]interface{}
+				require.NoError(t, json.Unmarshal(body, &got))
+				assert.Equal(t, map[string]interface{}{
+					"version":       "1.60.0",
+					"requirements":  map[string]interface{}{"k8s": "1.27"},
+					"changelogLink": testWebhookData.ChangelogLink,
+					"applyTime":     testWebhookData.ApplyTime,
+					"message":       testWebhookData.Message,
+				}, got)
+			},
+		},
+		{
+			name:   "slack",
+			target: NotificationTarget{Type: NotificationTargetSlack},
+			check: func(t *testing.T, body []byte) {
+				assert.JSONEq(t, `{"text": "`+testWebhookData.Message+`\n<`+testWebhookData.ChangelogLink+`|Changelog>"}`, string(body))
+			},
+		},
+		{
+			name:   "mattermost",
+			target: NotificationTarget{Type: NotificationTargetMattermost},
+			check: func(t *testing.T, body []byte) {
+				assert.JSONEq(t, `{"username": "Deckhouse", "text": "`+testWebhookData.Message+`\n[Changelog](`+testWebhookData.ChangelogLink+`)"}`, string(body))
+			},
+		},
+		{
+			name:   "alertmanager",
+			target: NotificationTarget{Type: NotificationTargetAlertmanager},
+			check: func(t *testing.T, body []byte) {
+				var alerts []alertmanagerAlert
+				require.NoError(t, json.Unmarshal(body, &alerts))
+				require.Len(t, alerts, 1)
+				assert.Equal(t, map[string]string{
+					"alertname": "D8ReleaseIsPending",
+					"severity":  "info",
+					"release":   "v1.60.0",
+					"version":   "1.60.0",
+				}, alerts[0].Labels)
+				assert.Equal(t, testWebhookData.Message, alerts[0].Annotations["description"])
+				assert.Equal(t, testWebhookData.ApplyTime, alerts[0].EndsAt)
+				assert.Equal(t, testWebhookData.ChangelogLink, alerts[0].GeneratorURL)
+			},
+		},
+		{
+			name: "custom template",
+			target: NotificationTarget{
+				Type:     NotificationTargetSlack,
+				Template: `{"text": "{{ .ReleaseName }} at {{ .ApplyTime }}", "version": {{ .Version | quote }}}`,
+			},
+			check: func(t *testing.T, body []byte) {
+				assert.JSONEq(t, `{"text": "v1.60.0 at 2124-01-01T13:30:00Z", "version": "1.60.0"}`, string(body))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, requests := notificationServer(t, http.StatusOK)
+			tt.target.URL = srv.URL
+
+			require.NoError(t, tt.target.send(testWebhookData))
+
+			received := requests()
+			require.Len(t, received, 1)
+			assert.Equal(t, "application/json", received[0].header.Get("Content-Type"))
+			tt.check(t, received[0].body)
+		})
+	}
+}
+
+func TestNotificationTargetUnknownType(t *testing.T) {
+	srv, requests := notificationServer(t, http.StatusOK)
+	target := NotificationTarget{Type: "Telegram", URL: srv.URL}
+
+	assert.Error(t, target.send(testWebhookData))
+	assert.Empty(t, requests())
+}
+
+func TestNotificationTargetStatuses(t *testing.T) {
+	tests := []struct {
+		name             string
+		status           int
+		expectedStatuses []int
+		wantErr          bool
+	}{
+		{
+			name:   "any 2xx status",
+			status: http.StatusNoContent,
+		},
+		{
+			name:    "server error",
+			status:  http.StatusServiceUnavailable,
+			wantErr: true,
+		},
+		{
+			name:             "expected status",
+			status:           http.StatusCreated,
+			expectedStatuses: []int{http.StatusCreated},
+		},
+		{
+			name:             "unexpected 2xx status",
+			status:           http.StatusAccepted,
+			expectedStatuses: []int{http.StatusCreated},
+			wantErr:          true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, requests := notificationServer(t, tt.status)
+			target := NotificationTarget{
+				Type:                NotificationTargetWebhook,
+				URL:                 srv.URL,
+				ExpectedStatusCodes: tt.expectedStatuses,
+			}
+
+			err := target.send(testWebhookData)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Len(t, requests(), 1, "send must make a single attempt")
+		})
+	}
+}
+
+func TestSendNotificationDelivery(t *testing.T) {
+	okSrv, okRequests := notificationServer(t, http.StatusOK)
+	failSrv, failRequests := notificationServer(t, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusOK)
+
+	retry := &NotificationRetry{
+		InitialInterval: libapi.Duration{Duration: time.Minute},
+		MaxInterval:     libapi.Duration{Duration: 10 * time.Minute},
+	}
+	targets := []NotificationTarget{
+		{Name: "ok", Type: NotificationTargetWebhook, URL: okSrv.URL},
+		{Name: "flaky", Type: NotificationTargetWebhook, URL: failSrv.URL, Retry: retry},
+	}
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	delivery, err := parseNotificationDelivery("")
+	require.NoError(t, err)
+
+	delivered, err := sendNotification(targets, testWebhookData, delivery, now)
+	assert.False(t, delivered)
+	assert.ErrorContains(t, err, `target "flaky" (attempt 1)`)
+	assert.True(t, delivery["ok"].Delivered)
+	assert.Equal(t, now.Add(time.Minute), *delivery["flaky"].NextAttempt)
+
+	// the state is kept in the release annotation between the updater runs
+	delivery, err = parseNotificationDelivery(delivery.String())
+	require.NoError(t, err)
+
+	// backoff has not expired yet
+	delivered, err = sendNotification(targets, testWebhookData, delivery, now.Add(30*time.Second))
+	assert.False(t, delivered)
+	assert.NoError(t, err)
+	assert.Len(t, failRequests(), 1)
+
+	delivered, err = sendNotification(targets, testWebhookData, delivery, now.Add(time.Minute))
+	assert.False(t, delivered)
+	assert.ErrorContains(t, err, `target "flaky" (attempt 2)`)
+	assert.Equal(t, now.Add(3*time.Minute), *delivery["flaky"].NextAttempt)
+
+	delivered, err = sendNotification(targets, testWebhookData, delivery, now.Add(3*time.Minute))
+	assert.True(t, delivered)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, delivery["flaky"].Attempts)
+	assert.Nil(t, delivery["flaky"].NextAttempt)
+
+	assert.Len(t, okRequests(), 1, "delivered target must not be notified again")
+	assert.Len(t, failRequests(), 3)
+
+	delivered, err = sendNotification(targets, testWebhookData, delivery, now.Add(time.Hour))
+	assert.True(t, delivered)
+	assert.NoError(t, err)
+	assert.Len(t, okRequests(), 1)
+	assert.Len(t, failRequests(), 3)
+}
+
+func TestNotificationRetryBackoff(t *testing.T) {
+	var defaults *NotificationRetry
+	assert.Equal(t, defaultNotificationInitialInterval, defaults.backoff(0))
+	assert.Equal(t, 2*defaultNotificationInitialInterval, defaults.backoff(1))
+	assert.Equal(t, defaultNotificationMaxInterval, defaults.backoff(10))
+
+	retry := &NotificationRetry{
+		InitialInterval: libapi.Duration{Duration: time.Second},
+		MaxInterval:     libapi.Duration{Duration: 5 * time.Second},
+	}
+	assert.Equal(t, 4*time.Second, retry.backoff(2))
+	assert.Equal(t, 5*time.Second, retry.backoff(3))
+	assert.Equal(t, 5*time.Second, retry.backoff(100))
+}
+
+func TestNotificationTargetAuth(t *testing.T) {
+	token := "secret-token"
+
+	tests := []struct {
+		name   string
+		auth   *Auth
+		header string
+	}{
+		{
+			name:   "no auth",
+			header: "",
+		},
+		{
+			name:   "basic",
+			auth:   &Auth{Basic: &BasicAuth{Username: "user", Password: "pass"}},
+			header: "Basic dXNlcjpwYXNz",
+		},
+		{
+			name:   "bearer token",
+			auth:   &Auth{Token: &token},
+			header: "Bearer secret-token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, requests := notificationServer(t, http.StatusOK)
+			target := NotificationTarget{Type: NotificationTargetWebhook, URL: srv.URL, Auth: tt.auth}
+
+			require.NoError(t, target.send(testWebhookData))
+
+			received := requests()
+			require.Len(t, received, 1)
+			assert.Equal(t, tt.header, received[0].header.Get("Authorization"))
+		})
+	}
+}
+
+func TestNotificationTargetSkipTLSVerify(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	target := NotificationTarget{Type: NotificationTargetWebhook, URL: srv.URL}
+	assert.Error(t, target.send(testWebhookData), "self-signed certificate must not be trusted")
+
+	target.SkipTLSVerify = true
+	assert.NoError(t, target.send(testWebhookData))
+}
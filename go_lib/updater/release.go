@@ -56,6 +56,8 @@ type Release interface {
 	GetSuspend() bool
 	GetManuallyApproved() bool
 	GetMessage() string
+	// GetNotificationDelivery returns the raw value of the NotificationDeliveryAnnotation
+	GetNotificationDelivery() string
 }
 
 type KubeAPI[R Release] interface {
@@ -68,6 +68,8 @@ type Updater[R Release] struct {
 	metricsUpdater    MetricsUpdater
 	settings          Settings
 	webhookDataGetter WebhookDataGetter[R]
+
+	rolloutWave *RolloutWave
 }
 
 func NewUpdater[R Release](logger logger.Logger, notificationConfig *NotificationConfig, mode string,
@@ -132,20 +134,45 @@ func (du *Updater[R]) checkReleaseNotification(predictedRelease *R, updateWindow
 		}
 	}
 	releaseApplyTime := updateWindows.NextAllowedTime(predictedReleaseApplyTime)
+	if releaseApplyTime.IsZero() {
+		// all windows are closed by blackout dates, we can't predict the exact time
+		releaseApplyTime = predictedReleaseApplyTime
+	}
 
 	predictedReleaseVersion := (*predictedRelease).GetVersion()
-	if du.notificationConfig.WebhookURL != "" {
+	if targets := du.notificationConfig.AllTargets(); len(targets) > 0 {
 		data := webhookData{
 			Version:       fmt.Sprintf("%d.%d", predictedReleaseVersion.Major(), predictedReleaseVersion.Minor()),
 			Requirements:  (*predictedRelease).GetRequirements(),
 			ChangelogLink: (*predictedRelease).GetChangelogLink(),
 			ApplyTime:     releaseApplyTime.Format(time.RFC3339),
 			Message:       du.webhookDataGetter.GetMessage(*predictedRelease, releaseApplyTime),
+			ReleaseName:   (*predictedRelease).GetName(),
+		}
+
+		releaseName := (*predictedRelease).GetName()
+		rawDelivery := (*predictedRelease).GetNotificationDelivery()
+		delivery, err := parseNotificationDelivery(rawDelivery)
+		if err != nil {
+			du.logger.Warnf("Parse notification delivery of the release %s, sending to all targets: %s", releaseName, err)
 		}
 
-		err := sendWebhookNotification(du.notificationConfig, data)
+		delivered, err := sendNotification(targets, data, delivery, du.now)
 		if err != nil {
 			du.logger.Errorf("Send release notification failed: %s", err)
+		}
+
+		if newDelivery := delivery.String(); newDelivery != rawDelivery {
+			patchErr := du.kubeAPI.PatchReleaseAnnotations(releaseName, map[string]interface{}{
+				NotificationDeliveryAnnotation: newDelivery,
+			})
+			if patchErr != nil {
+				du.logger.Errorf("patch notification delivery annotation: %s", patchErr)
+				return false
+			}
+		}
+
+		if !delivered {
 			return false
 		}
 	}
@@ -240,11 +267,24 @@ func (du *Updater[R]) checkMinorReleaseConditions(predictedRelease *R, updateWin
 		} else {
 			// check: update windows in Auto mode
 			if len(updateWindows) > 0 {
+				if err := updateWindows.Validate(); err != nil {
+					du.logger.Errorf("Release %s update windows are invalid: %v", (*predictedRelease).GetName(), err)
+					err = du.updateStatus(predictedRelease, fmt.Sprintf("Update windows are invalid: %v", err), PhasePending)
+					if err != nil {
+						du.logger.Error(err)
+					}
+					return false
+				}
+
 				updatePermitted := updateWindows.IsAllowed(du.now)
 				if !updatePermitted {
-					applyTime := updateWindows.NextAllowedTime(du.now)
+					msg := "Release is waiting for the update window"
+					// zero time means that all windows are closed by blackout dates
+					if applyTime := updateWindows.NextAllowedTime(du.now); !applyTime.IsZero() {
+						msg = fmt.Sprintf("%s: %s", msg, applyTime.Format(time.RFC822))
+					}
 					du.logger.Info("Deckhouse update does not get into update windows. Skipping")
-					err := du.updateStatus(predictedRelease, fmt.Sprintf("Release is waiting for the update window: %s", applyTime.Format(time.RFC822)), PhasePending)
+					err := du.updateStatus(predictedRelease, msg, PhasePending)
 					if err != nil {
 						du.logger.Error(err)
 					}
@@ -272,6 +312,7 @@ func (du *Updater[R]) checkMinorReleaseConditions(predictedRelease *R, updateWin
 //   - Canary settings
 //   - Manual approving
 //   - Release requirements
+//   - Rollout wave delay and failures of the earlier waves
 func (du *Updater[R]) ApplyPredictedRelease(updateWindows update.Windows) bool {
 	if du.predictedReleaseIndex == -1 {
 		return false // has no predicted release
@@ -291,6 +332,11 @@ func (du *Updater[R]) ApplyPredictedRelease(updateWindows update.Windows) bool {
 		return du.runReleaseDeploy(predictedRelease, currentRelease)
 	}
 
+	// check: staged rollout, both for patch and minor releases
+	if !du.checkRolloutWave(predictedRelease) {
+		return false
+	}
+
 	var readyForDeploy bool
 
 	if du.PredictedReleaseIsPatch() {
@@ -0,0 +1,182 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package updater
+
+import (
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Waves of the staged rollout in the order of the release propagation
+const (
+	WaveCanary = "Canary"
+	WaveEarly  = "Early"
+	WaveMain   = "Main"
+)
+
+var waveOrder = map[string]int{
+	WaveCanary: 0,
+	WaveEarly:  1,
+	WaveMain:   2,
+}
+
+// Statuses of the release published by the clusters
+const (
+	WaveStatusDeployed = "Deployed"
+	WaveStatusFailed   = "Failed"
+)
+
+// RolloutWave assigns the cluster to a wave of the staged rollout.
+// A release is applied not earlier than Delay after its appearance and is blocked
+// if any cluster of the earlier waves has reported the release failure.
+type RolloutWave struct {
+	Name  string
+	Delay time.Duration
+
+	// StatusGetter is optional, the failures of the earlier waves are not checked without it
+	StatusGetter WaveStatusGetter
+}
+
+// WaveStatusGetter returns the rollout statuses reported by the clusters of all waves
+type WaveStatusGetter interface {
+	GetWaveStatuses() ([]WaveStatus, error)
+}
+
+// WaveStatusReport is a document served by the shared status endpoint, the endpoint merges the reports of the clusters
+type WaveStatusReport struct {
+	Releases []WaveStatus `json:"releases"`
+}
+
+type WaveStatus struct {
+	// Release is a name of the release, it is the same in all clusters
+	Release string `json:"release"`
+	Wave    string `json:"wave"`
+	// Cluster is an optional name of the reporting cluster
+	Cluster string `json:"cluster,omitempty"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// AppearedRelease is implemented by releases which know the time they appeared in the cluster,
+// the wave delay is counted from this time
+type AppearedRelease interface {
+	GetAppearanceTime() time.Time
+}
+
+// ParseWaveStatusReport parses the wave status report in the JSON or YAML format
+func ParseWaveStatusReport(data []byte) ([]WaveStatus, error) {
+	var report WaveStatusReport
+	if err := yaml.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("unmarshal wave status report: %w", err)
+	}
+
+	return report.Releases, nil
+}
+
+// ValidateWaveName returns an error if the wave is unknown
+func ValidateWaveName(name string) error {
+	if _, ok := waveOrder[name]; !ok {
+		return fmt.Errorf("unknown rollout wave %q, must be one of: %s, %s, %s", name, WaveCanary, WaveEarly, WaveMain)
+	}
+
+	return nil
+}
+
+// SetRolloutWave enables the staged rollout checks for the predicted release
+func (du *Updater[R]) SetRolloutWave(wave *RolloutWave) {
+	du.rolloutWave = wave
+}
+
+// checkRolloutWave checks that earlier waves haven't reported the release failure (hard lock)
+// and the wave delay has passed since the release appearance
+func (du *Updater[R]) checkRolloutWave(predictedRelease *R) bool {
+	if du.rolloutWave == nil {
+		return true
+	}
+
+	releaseName := (*predictedRelease).GetName()
+
+	if du.rolloutWave.StatusGetter != nil {
+		statuses, err := du.rolloutWave.StatusGetter.GetWaveStatuses()
+		if err != nil {
+			// don't move the release forward without knowing how it went in the earlier waves
+			du.logger.Warnf("Get rollout wave statuses for the release %s: %s", releaseName, err)
+			err = du.updateStatus(predictedRelease, fmt.Sprintf("Release is waiting for the rollout status of the earlier waves: %s", err), PhasePending)
+			if err != nil {
+				du.logger.Error(err)
+			}
+			return false
+		}
+
+		if failed := du.earlierWaveFailure(releaseName, statuses); failed != nil {
+			du.metricsUpdater.ReleaseBlocked(releaseName, "wave")
+			du.logger.Warnf("Release %s is blocked: the %s wave reported a failure", releaseName, failed.Wave)
+			msg := fmt.Sprintf("Release is blocked: the %s wave reported a failure", failed.Wave)
+			if failed.Cluster != "" {
+				msg = fmt.Sprintf("%s in the %s cluster", msg, failed.Cluster)
+			}
+			if failed.Message != "" {
+				msg = fmt.Sprintf("%s: %s", msg, failed.Message)
+			}
+			err = du.updateStatus(predictedRelease, msg, PhasePending)
+			if err != nil {
+				du.logger.Error(err)
+			}
+			return false
+		}
+	}
+
+	if du.rolloutWave.Delay == 0 || (*predictedRelease).GetApplyNow() {
+		return true
+	}
+
+	appeared, ok := any(*predictedRelease).(AppearedRelease)
+	if !ok {
+		return true
+	}
+
+	applyTime := appeared.GetAppearanceTime().Add(du.rolloutWave.Delay)
+	if du.now.Before(applyTime) {
+		du.logger.Infof("Release %s is postponed by the %s rollout wave. Waiting", releaseName, du.rolloutWave.Name)
+		err := du.updateStatus(predictedRelease, fmt.Sprintf("Release is postponed by the %s rollout wave until: %s", du.rolloutWave.Name, applyTime.Format(time.RFC822)), PhasePending)
+		if err != nil {
+			du.logger.Error(err)
+		}
+		return false
+	}
+
+	return true
+}
+
+func (du *Updater[R]) earlierWaveFailure(releaseName string, statuses []WaveStatus) *WaveStatus {
+	current := waveOrder[du.rolloutWave.Name]
+
+	for i := range statuses {
+		status := &statuses[i]
+		if status.Release != releaseName || status.Status != WaveStatusFailed {
+			continue
+		}
+
+		if order, ok := waveOrder[status.Wave]; ok && order < current {
+			return status
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package updater
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testRelease struct {
+	name     string
+	appeared time.Time
+	applyNow bool
+
+	phase   string
+	message string
+}
+
+func (r *testRelease) GetName() string                    { return r.name }
+func (r *testRelease) GetApplyAfter() *time.Time          { return nil }
+func (r *testRelease) GetVersion() *semver.Version        { return semver.MustParse("1.0.0") }
+func (r *testRelease) GetRequirements() map[string]string { return nil }
+func (r *testRelease) GetChangelogLink() string           { return "" }
+func (r *testRelease) GetCooldownUntil() *time.Time       { return nil }
+func (r *testRelease) GetDisruptions() []string           { return nil }
+func (r *testRelease) GetDisruptionApproved() bool        { return false }
+func (r *testRelease) GetPhase() string                   { return r.phase }
+func (r *testRelease) GetForce() bool                     { return false }
+func (r *testRelease) GetApplyNow() bool                  { return r.applyNow }
+func (r *testRelease) GetApprovedStatus() bool            { return false }
+func (r *testRelease) SetApprovedStatus(_ bool)           {}
+func (r *testRelease) GetSuspend() bool                   { return false }
+func (r *testRelease) GetManuallyApproved() bool          { return false }
+func (r *testRelease) GetMessage() string                 { return r.message }
+func (r *testRelease) GetNotificationDelivery() string    { return "" }
+func (r *testRelease) GetAppearanceTime() time.Time       { return r.appeared }
+
+type testKubeAPI struct{}
+
+func (testKubeAPI) UpdateReleaseStatus(release *testRelease, msg, phase string) error {
+	release.phase = phase
+	release.message = msg
+	return nil
+}
+func (testKubeAPI) PatchReleaseAnnotations(_ string, _ map[string]interface{}) error { return nil }
+func (testKubeAPI) PatchReleaseApplyAfter(_ string, _ time.Time) error               { return nil }
+func (testKubeAPI) SaveReleaseData(_ string, _ DeckhouseReleaseData) error           { return nil }
+func (testKubeAPI) DeployRelease(_ *testRelease) error                               { return nil }
+
+type testMetricsUpdater struct {
+	blocked []string
+}
+
+func (m *testMetricsUpdater) ReleaseBlocked(name, reason string) {
+	m.blocked = append(m.blocked, name+":"+reason)
+}
+func (m *testMetricsUpdater) WaitingManual(_ string, _ float64) {}
+
+type testStatusGetter struct {
+	statuses []WaveStatus
+	err      error
+}
+
+func (g testStatusGetter) GetWaveStatuses() ([]WaveStatus, error) {
+	return g.statuses, g.err
+}
+
+func newTestUpdater(wave *RolloutWave) (*Updater[*testRelease], *testMetricsUpdater) {
+	metrics := &testMetricsUpdater{}
+	du := NewUpdater[*testRelease](log.NewEntry(log.New()), nil, "Auto", DeckhouseReleaseData{}, true, false,
+		testKubeAPI{}, metrics, nil, nil)
+	du.now = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	du.SetRolloutWave(wave)
+
+	return du, metrics
+}
+
+func TestCheckRolloutWave(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("no wave", func(t *testing.T) {
+		du, _ := newTestUpdater(nil)
+		rl := &testRelease{name: "echo-v1.0.0", appeared: now}
+		assert.True(t, du.checkRolloutWave(&rl))
+	})
+
+	t.Run("delay has not passed", func(t *testing.T) {
+		du, _ := newTestUpdater(&RolloutWave{Name: WaveEarly, Delay: 24 * time.Hour})
+		rl := &testRelease{name: "echo-v1.0.0", appeared: now.Add(-time.Hour)}
+		assert.False(t, du.checkRolloutWave(&rl))
+		assert.Equal(t, PhasePending, rl.phase)
+		assert.Contains(t, rl.message, "postponed by the Early rollout wave")
+	})
+
+	t.Run("delay has passed", func(t *testing.T) {
+		du, _ := newTestUpdater(&RolloutWave{Name: WaveEarly, Delay: 24 * time.Hour})
+		rl := &testRelease{name: "echo-v1.0.0", appeared: now.Add(-25 * time.Hour)}
+		assert.True(t, du.checkRolloutWave(&rl))
+	})
+
+	t.Run("apply-now skips delay", func(t *testing.T) {
+		du, _ := newTestUpdater(&RolloutWave{Name: WaveMain, Delay: 24 * time.Hour})
+		rl := &testRelease{name: "echo-v1.0.0", appeared: now, applyNow: true}
+		assert.True(t, du.checkRolloutWave(&rl))
+	})
+
+	t.Run("earlier wave failed", func(t *testing.T) {
+		du, metrics := newTestUpdater(&RolloutWave{
+			Name: WaveMain,
+			StatusGetter: testStatusGetter{statuses: []WaveStatus{
+				{Release: "echo-v1.0.0", Wave: WaveEarly, Status: "Succeeded"},
+				{Release: "echo-v0.9.0", Wave: WaveCanary, Status: WaveStatusFailed},
+				{Release: "echo-v1.0.0", Wave: WaveCanary, Cluster: "canary-1", Status: WaveStatusFailed, Message: "pods are crashlooping"},
+			}},
+		})
+		rl := &testRelease{name: "echo-v1.0.0", appeared: now, applyNow: true}
+		assert.False(t, du.checkRolloutWave(&rl))
+		assert.Equal(t, "Release is blocked: the Canary wave reported a failure in the canary-1 cluster: pods are crashlooping", rl.message)
+		assert.Equal(t, []string{"echo-v1.0.0:wave"}, metrics.blocked)
+	})
+
+	t.Run("same or later wave failed", func(t *testing.T) {
+		du, _ := newTestUpdater(&RolloutWave{
+			Name: WaveEarly,
+			StatusGetter: testStatusGetter{statuses: []WaveStatus{
+				{Release: "echo-v1.0.0", Wave: WaveEarly, Status: WaveStatusFailed},
+				{Release: "echo-v1.0.0", Wave: WaveMain, Status: WaveStatusFailed},
+			}},
+		})
+		rl := &testRelease{name: "echo-v1.0.0", appeared: now}
+		assert.True(t, du.checkRolloutWave(&rl))
+	})
+
+	t.Run("statuses are unavailable", func(t *testing.T) {
+		du, _ := newTestUpdater(&RolloutWave{Name: WaveMain, StatusGetter: testStatusGetter{err: errors.New("connection refused")}})
+		rl := &testRelease{name: "echo-v1.0.0", appeared: now}
+		assert.False(t, du.checkRolloutWave(&rl))
+		assert.Contains(t, rl.message, "connection refused")
+	})
+}
+
+func TestParseWaveStatusReport(t *testing.T) {
+	statuses, err := ParseWaveStatusReport([]byte(`
+releases:
+- release: echo-v1.0.0
+  wave: Canary
+  status: Failed
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []WaveStatus{{Release: "echo-v1.0.0", Wave: WaveCanary, Status: WaveStatusFailed}}, statuses)
+
+	statuses, err = ParseWaveStatusReport([]byte(`{"releases":[{"release":"echo-v1.0.0","wave":"Early","status":"Succeeded"}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, []WaveStatus{{Release: "echo-v1.0.0", Wave: WaveEarly, Status: "Succeeded"}}, statuses)
+}
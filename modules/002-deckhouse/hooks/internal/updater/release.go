@@ -29,8 +29,9 @@ type DeckhouseRelease struct {
 	Name    string
 	Version *semver.Version
 
-	ManuallyApproved bool
-	AnnotationFlags  DeckhouseReleaseAnnotationsFlags
+	ManuallyApproved     bool
+	AnnotationFlags      DeckhouseReleaseAnnotationsFlags
+	NotificationDelivery string
 
 	Requirements  map[string]string
 	ChangelogLink string
@@ -105,6 +106,10 @@ func (d *DeckhouseRelease) GetMessage() string {
 	return d.Status.Message
 }
 
+func (d *DeckhouseRelease) GetNotificationDelivery() string {
+	return d.NotificationDelivery
+}
+
 type DeckhouseReleaseAnnotationsFlags struct {
 	Suspend            bool
 	Force              bool
@@ -302,8 +302,9 @@ func filterDeckhouseRelease(unstructured *unstructured.Unstructured) (go_hook.Fi
 			Approved: release.Status.Approved,
 			Message:  release.Status.Message,
 		},
-		ManuallyApproved: releaseApproved,
-		AnnotationFlags:  annotationFlags,
+		ManuallyApproved:     releaseApproved,
+		AnnotationFlags:      annotationFlags,
+		NotificationDelivery: release.Annotations[updater.NotificationDeliveryAnnotation],
 	}, nil
 }
 
@@ -18,6 +18,7 @@ package hooks
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -645,6 +646,114 @@ status:
 		})
 	})
 
+	Context("Notification: release with notification targets", func() {
+		var httpBody string
+		svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			data, _ := io.ReadAll(r.Body)
+			httpBody = string(data)
+		}))
+		AfterEach(func() {
+			defer svr.Close()
+		})
+
+		BeforeEach(func() {
+			f.ValuesSetFromYaml("deckhouse.update.notification.targets", []byte(fmt.Sprintf(`[{"name": "slack", "type": "Slack", "url": %q}]`, svr.URL)))
+			f.ValuesSetFromYaml("deckhouse.update.notification.minimalNotificationTime", []byte("1h"))
+			f.KubeStateSet(deckhousePodYaml + deckhouseReleases)
+			f.BindingContexts.Set(f.GenerateScheduleContext("*/15 * * * * *"))
+
+			f.RunHook()
+		})
+
+		It("Should send Slack message and postpone the release", func() {
+			Expect(f).To(ExecuteSuccessfully())
+			Expect(httpBody).To(HavePrefix(`{"text":"New Deckhouse Release 1.26 is available. Release will be applied at: Friday, 01-Jan-21 14:30:00 UTC`))
+			cm := f.KubernetesResource("ConfigMap", "d8-system", "d8-release-data")
+			Expect(cm.Field("data.notified").Bool()).To(BeTrue())
+			r126 := f.KubernetesGlobalResource("DeckhouseRelease", "v1.26.0")
+			Expect(r126.Field("spec.applyAfter").String()).To(Equal("2021-01-01T14:30:00Z"))
+		})
+	})
+
+	Context("Notification: target responds with unexpected status", func() {
+		svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		AfterEach(func() {
+			defer svr.Close()
+		})
+
+		BeforeEach(func() {
+			f.ValuesSetFromYaml("deckhouse.update.notification.targets", []byte(fmt.Sprintf(`[{"name": "webhook", "url": %q}]`, svr.URL)))
+			f.ValuesSetFromYaml("deckhouse.update.notification.minimalNotificationTime", []byte("1h"))
+			f.KubeStateSet(deckhousePodYaml + deckhouseReleases)
+			f.BindingContexts.Set(f.GenerateScheduleContext("*/15 * * * * *"))
+
+			f.RunHook()
+		})
+
+		It("Should not mark release as notified", func() {
+			Expect(f).To(ExecuteSuccessfully())
+			cm := f.KubernetesResource("ConfigMap", "d8-system", "d8-release-data")
+			Expect(cm.Field("data.notified").Bool()).To(BeFalse())
+			r126 := f.KubernetesGlobalResource("DeckhouseRelease", "v1.26.0")
+			Expect(r126.Field("status.phase").String()).To(Equal("Pending"))
+			delivery := r126.Field("metadata.annotations.release\\.deckhouse\\.io/notification-delivery").String()
+			Expect(delivery).To(ContainSubstring(`"webhook":{"attempts":1,"nextAttempt":`))
+		})
+	})
+
+	Context("Notification: target has already received the notification", func() {
+		var requests int
+		svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.WriteHeader(http.StatusOK)
+		}))
+		AfterEach(func() {
+			defer svr.Close()
+		})
+
+		BeforeEach(func() {
+			f.ValuesSetFromYaml("deckhouse.update.notification.targets", []byte(fmt.Sprintf(`[{"name": "delivered", "url": %q}, {"name": "failed", "url": %q}]`, svr.URL, svr.URL)))
+			f.ValuesSetFromYaml("deckhouse.update.notification.minimalNotificationTime", []byte("1h"))
+			f.KubeStateSet(deckhousePodYaml + `
+---
+apiVersion: deckhouse.io/v1alpha1
+kind: DeckhouseRelease
+metadata:
+  name: v1.25.0
+spec:
+  version: "v1.25.0"
+status:
+  phase: Deployed
+---
+apiVersion: deckhouse.io/v1alpha1
+kind: DeckhouseRelease
+metadata:
+  name: v1.26.0
+  annotations:
+    release.deckhouse.io/notification-delivery: '{"delivered":{"delivered":true,"attempts":1},"failed":{"attempts":1,"nextAttempt":"2019-01-01T00:00:00Z"}}'
+spec:
+  version: "v1.26.0"
+status:
+  phase: Pending
+`)
+			f.BindingContexts.Set(f.GenerateScheduleContext("*/15 * * * * *"))
+
+			f.RunHook()
+		})
+
+		It("Should retry only the failed target", func() {
+			Expect(f).To(ExecuteSuccessfully())
+			Expect(requests).To(Equal(1))
+			cm := f.KubernetesResource("ConfigMap", "d8-system", "d8-release-data")
+			Expect(cm.Field("data.notified").Bool()).To(BeTrue())
+			r126 := f.KubernetesGlobalResource("DeckhouseRelease", "v1.26.0")
+			Expect(r126.Field("metadata.annotations.release\\.deckhouse\\.io/notification-delivery").String()).
+				To(Equal(`{"delivered":{"delivered":true,"attempts":1},"failed":{"delivered":true,"attempts":2}}`))
+		})
+	})
+
 	Context("Notification: after met conditions", func() {
 		BeforeEach(func() {
 			changedState := `
@@ -0,0 +1,130 @@
+{{- /* The parameter the module passes the workloads waived by PolicyExceptions in, see the "policy_exceptions" helper */}}
+{{- define "exceptions_parameter" }}
+exceptions:
+  type: array
+  description: "The workloads the constraint is waived for by PolicyExceptions."
+  items:
+    type: object
+    properties:
+      kind:
+        type: string
+      namespace:
+        type: string
+      name:
+        type: string
+      labelSelector:
+        type: object
+        properties:
+          matchLabels:
+            type: object
+            additionalProperties:
+              type: string
+          matchExpressions:
+            type: array
+            items:
+              type: object
+              properties:
+                key:
+                  type: string
+                operator:
+                  type: string
+                values:
+                  type: array
+                  items:
+                    type: string
+{{- end }}
+
+{{- /* The library checking if the reviewed object is one of the waived workloads, templates import data.lib.exceptions.is_exempt */}}
+{{- define "exceptions_lib" }}
+package lib.exceptions
+
+# The object is exempt if it is one of the workloads the constraint is waived for by a PolicyException.
+is_exempt(review, parameters) {
+    workload := parameters.exceptions[_]
+    workload.namespace == review.namespace
+    matches_workload(review, workload)
+}
+
+matches_workload(review, workload) {
+    workload.kind == review.kind.kind
+    workload.name == review.object.metadata.name
+}
+
+# Pods are matched by the workload owning them.
+matches_workload(review, workload) {
+    review.kind.kind == "Pod"
+    owner := review.object.metadata.ownerReferences[_]
+    owned_by(review.object, owner, workload)
+}
+
+matches_workload(review, workload) {
+    not workload.name
+    matches_selector(object_labels(review.object), workload.labelSelector)
+}
+
+owned_by(pod, owner, workload) {
+    workload.kind == ["ReplicaSet", "StatefulSet", "DaemonSet", "Job"][_]
+    owner.kind == workload.kind
+    owner.name == workload.name
+}
+
+owned_by(pod, owner, workload) {
+    workload.kind == "Deployment"
+    owner.kind == "ReplicaSet"
+    owner.name == concat("-", [workload.name, pod.metadata.labels["pod-template-hash"]])
+}
+
+owned_by(pod, owner, workload) {
+    workload.kind == "CronJob"
+    owner.kind == "Job"
+    startswith(owner.name, concat("", [workload.name, "-"]))
+    regex.match("^[0-9]+$", substring(owner.name, count(workload.name) + 1, -1))
+}
+
+object_labels(object) = labels {
+    labels := object.metadata.labels
+} else = {}
+
+matches_selector(labels, selector) {
+    not mismatched_label(labels, selector)
+    not mismatched_expression(labels, selector)
+}
+
+mismatched_label(labels, selector) {
+    value := selector.matchLabels[key]
+    not labels[key] == value
+}
+
+mismatched_expression(labels, selector) {
+    expression := selector.matchExpressions[_]
+    not matches_expression(labels, expression)
+}
+
+matches_expression(labels, expression) {
+    expression.operator == "In"
+    has_value(labels, expression)
+}
+
+matches_expression(labels, expression) {
+    expression.operator == "NotIn"
+    not has_value(labels, expression)
+}
+
+matches_expression(labels, expression) {
+    expression.operator == "Exists"
+    has_label(labels, expression.key)
+}
+
+matches_expression(labels, expression) {
+    expression.operator == "DoesNotExist"
+    not has_label(labels, expression.key)
+}
+
+has_value(labels, expression) {
+    labels[expression.key] == expression.values[_]
+}
+
+has_label(labels, key) {
+    _ = labels[key]
+}
+{{- end }}
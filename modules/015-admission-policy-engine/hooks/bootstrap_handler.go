@@ -21,6 +21,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
 	"time"
 
 	"github.com/flant/addon-operator/pkg/module_manager/go_hook"
@@ -35,6 +36,9 @@ const (
 	pattern = "*.yaml"
 )
 
+// helmActionLine matches lines of the templates including the shared parts, they are not valid YAML before rendering
+var helmActionLine = regexp.MustCompile(`(?m)^\s*\{\{.*\}\}\s*$`)
+
 type cTemplate struct {
 	Name      string
 	Processed bool
@@ -179,7 +183,7 @@ func getRequiredTemplates() ([]string, error) {
 		if err != nil {
 			return nil, err
 		}
-		err = yaml.Unmarshal(yamlFile, template)
+		err = yaml.Unmarshal(helmActionLine.ReplaceAll(yamlFile, nil), template)
 		if err != nil {
 			return nil, err
 		}
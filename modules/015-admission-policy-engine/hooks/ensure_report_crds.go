@@ -0,0 +1,104 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/flant/addon-operator/pkg/module_manager/go_hook"
+	"github.com/flant/addon-operator/sdk"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	"github.com/deckhouse/deckhouse/go_lib/dependency"
+	"github.com/deckhouse/deckhouse/go_lib/hooks/ensure_crds"
+)
+
+// The PolicyReport CRDs of the wgpolicyk8s.io group are shared by policy engines, e.g., Kyverno installs them as well.
+// They are installed only if they are absent or were installed by the module, to not take over the CRDs of another owner.
+
+var reportCRDsGlob = "/deckhouse/modules/015-admission-policy-engine/crds/native/reports/*.yaml"
+
+var crdGVR = schema.GroupVersionResource{
+	Group:    "apiextensions.k8s.io",
+	Version:  "v1",
+	Resource: "customresourcedefinitions",
+}
+
+var _ = sdk.RegisterFunc(&go_hook.HookConfig{
+	OnStartup: &go_hook.OrderedConfig{Order: 5},
+}, dependency.WithExternalDependencies(ensureReportCRDs))
+
+func ensureReportCRDs(input *go_hook.HookInput, dc dependency.Container) error {
+	client, err := dc.GetK8sClient()
+	if err != nil {
+		return err
+	}
+
+	files, err := filepath.Glob(reportCRDsGlob)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		name, err := crdName(file)
+		if err != nil {
+			return fmt.Errorf("read CRD name from %q: %w", file, err)
+		}
+
+		existing, err := client.Dynamic().Resource(crdGVR).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		if err == nil && existing.GetLabels()["module"] != "admission-policy-engine" {
+			input.LogEntry.Infof("CRD %s is installed by another owner, skip it", name)
+			continue
+		}
+
+		if merr := ensure_crds.EnsureCRDs(file, dc); merr.ErrorOrNil() != nil {
+			return merr
+		}
+	}
+
+	return nil
+}
+
+func crdName(file string) (string, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+
+	var crd struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+	}
+	if err := yaml.Unmarshal(content, &crd); err != nil {
+		return "", err
+	}
+	if crd.Metadata.Name == "" {
+		return "", fmt.Errorf("name is empty")
+	}
+
+	return crd.Metadata.Name, nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/deckhouse/deckhouse/testing/hooks"
+)
+
+var _ = Describe("Modules :: admission-policy-engine :: hooks :: ensure_report_crds", func() {
+	f := HookExecutionConfigInit(`{"admissionPolicyEngine": {"internal": {}}}`, `{"admissionPolicyEngine":{}}`)
+
+	BeforeEach(func() {
+		reportCRDsGlob = "../crds/native/reports/*.yaml"
+	})
+
+	Context("Empty cluster", func() {
+		BeforeEach(func() {
+			f.KubeStateSet("")
+			f.BindingContexts.Set(f.GenerateOnStartupContext())
+			f.RunHook()
+		})
+
+		It("Should install the report CRDs", func() {
+			Expect(f).To(ExecuteSuccessfully())
+			Expect(f.KubernetesGlobalResource("CustomResourceDefinition", "policyreports.wgpolicyk8s.io").Field("metadata.labels.module").String()).To(Equal("admission-policy-engine"))
+			Expect(f.KubernetesGlobalResource("CustomResourceDefinition", "clusterpolicyreports.wgpolicyk8s.io").Exists()).To(BeTrue())
+		})
+	})
+
+	Context("Report CRDs are installed by the module", func() {
+		BeforeEach(func() {
+			f.KubeStateSet(reportCRD("policyreports", "heritage: deckhouse\n    module: admission-policy-engine"))
+			f.BindingContexts.Set(f.GenerateOnStartupContext())
+			f.RunHook()
+		})
+
+		It("Should update the report CRDs", func() {
+			Expect(f).To(ExecuteSuccessfully())
+			Expect(f.KubernetesGlobalResource("CustomResourceDefinition", "policyreports.wgpolicyk8s.io").Field("spec.versions.0.name").String()).To(Equal("v1alpha2"))
+			Expect(f.KubernetesGlobalResource("CustomResourceDefinition", "clusterpolicyreports.wgpolicyk8s.io").Exists()).To(BeTrue())
+		})
+	})
+
+	Context("Report CRDs are installed by Kyverno", func() {
+		BeforeEach(func() {
+			f.KubeStateSet(reportCRD("policyreports", "app.kubernetes.io/part-of: kyverno"))
+			f.BindingContexts.Set(f.GenerateOnStartupContext())
+			f.RunHook()
+		})
+
+		It("Should keep the CRD of another owner", func() {
+			Expect(f).To(ExecuteSuccessfully())
+			crd := f.KubernetesGlobalResource("CustomResourceDefinition", "policyreports.wgpolicyk8s.io")
+			Expect(crd.Field("metadata.labels.module").Exists()).To(BeFalse())
+			Expect(crd.Field("spec.versions.0.name").String()).To(Equal("v1alpha1"))
+			Expect(f.KubernetesGlobalResource("CustomResourceDefinition", "clusterpolicyreports.wgpolicyk8s.io").Exists()).To(BeTrue())
+		})
+	})
+})
+
+func reportCRD(plural, labels string) string {
+	return `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: ` + plural + `.wgpolicyk8s.io
+  labels:
+    ` + labels + `
+spec:
+  group: wgpolicyk8s.io
+  names:
+    kind: PolicyReport
+    plural: ` + plural + `
+  scope: Namespaced
+  versions:
+  - name: v1alpha1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        x-kubernetes-preserve-unknown-fields: true
+`
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/clarketm/json"
+	"github.com/flant/addon-operator/pkg/module_manager/go_hook"
+	"github.com/flant/addon-operator/pkg/module_manager/go_hook/metrics"
+	"github.com/flant/addon-operator/sdk"
+	"github.com/flant/shell-operator/pkg/kube/object_patch"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	v1alpha1 "github.com/deckhouse/deckhouse/modules/015-admission-policy-engine/hooks/internal/apis"
+)
+
+const (
+	policyExceptionActive  = "Active"
+	policyExceptionExpired = "Expired"
+
+	expiredPolicyExceptionMetric = "d8_admission_policy_engine_expired_policy_exception"
+)
+
+// Exceptions are stored to values until they expire, expired exceptions are exposed as metrics to fire alerts.
+// The schedule makes the checks enforced again when the exception expires.
+
+var _ = sdk.RegisterFunc(&go_hook.HookConfig{
+	Queue: "/modules/admission-policy-engine/policy_exceptions",
+	Kubernetes: []go_hook.KubernetesConfig{
+		{
+			Name:       "policy-exceptions",
+			ApiVersion: "deckhouse.io/v1alpha1",
+			Kind:       "PolicyException",
+			FilterFunc: filterPolicyException,
+		},
+	},
+	Schedule: []go_hook.ScheduleConfig{
+		{
+			Name:    "policy-exceptions-expiration",
+			Crontab: "* * * * *",
+		},
+	},
+}, handlePolicyExceptions)
+
+var policyChecks = map[string]map[string]bool{
+	"SecurityPolicy":  jsonFields(reflect.TypeOf(v1alpha1.SecurityPolicySpec{})),
+	"OperationPolicy": jsonFields(reflect.TypeOf(v1alpha1.OperationPolicySpec{})),
+}
+
+func handlePolicyExceptions(input *go_hook.HookInput) error {
+	now := time.Now()
+	result := make([]*policyException, 0)
+
+	input.MetricsCollector.Expire(expiredPolicyExceptionMetric)
+
+	for _, sn := range input.Snapshots["policy-exceptions"] {
+		pe := sn.(*policyException)
+
+		phase := policyExceptionActive
+		if !now.Before(pe.Spec.ExpiresAt.Time) {
+			phase = policyExceptionExpired
+		}
+
+		if phase != pe.Phase {
+			patch := map[string]interface{}{
+				"status": map[string]interface{}{
+					"phase": phase,
+				},
+			}
+			input.PatchCollector.MergePatch(patch, "deckhouse.io/v1alpha1", "PolicyException", "", pe.Metadata.Name, object_patch.WithSubresource("/status"), object_patch.IgnoreMissingObject())
+		}
+
+		if phase == policyExceptionExpired {
+			input.MetricsCollector.Set(expiredPolicyExceptionMetric, 1, map[string]string{
+				"name":        pe.Metadata.Name,
+				"policy_kind": pe.Spec.Policy.Kind,
+				"policy_name": pe.Spec.Policy.Name,
+				"expires_at":  pe.Spec.ExpiresAt.UTC().Format(time.RFC3339),
+			}, metrics.WithGroup(expiredPolicyExceptionMetric))
+			continue
+		}
+
+		for _, check := range pe.Spec.Checks {
+			if !policyChecks[pe.Spec.Policy.Kind][check] {
+				input.LogEntry.Warnf("PolicyException %s: %s doesn't have the %q check", pe.Metadata.Name, pe.Spec.Policy.Kind, check)
+			}
+		}
+
+		result = append(result, pe)
+	}
+
+	data, _ := json.Marshal(result)
+
+	input.Values.Set("admissionPolicyEngine.internal.policyExceptions", json.RawMessage(data))
+
+	return nil
+}
+
+func filterPolicyException(obj *unstructured.Unstructured) (go_hook.FilterResult, error) {
+	var pe policyException
+
+	err := sdk.FromUnstructured(obj, &pe)
+	if err != nil {
+		return nil, err
+	}
+
+	pe.Phase, _, err = unstructured.NestedString(obj.Object, "status", "phase")
+	if err != nil {
+		return nil, err
+	}
+
+	return &pe, nil
+}
+
+// jsonFields returns names of the policies fields of the policy spec, which are the checks names
+func jsonFields(spec reflect.Type) map[string]bool {
+	field, _ := spec.FieldByName("Policies")
+
+	fields := make(map[string]bool, field.Type.NumField())
+	for i := 0; i < field.Type.NumField(); i++ {
+		name := strings.Split(field.Type.Field(i).Tag.Get("json"), ",")[0]
+		fields[name] = true
+	}
+
+	return fields
+}
+
+type policyException struct {
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec  v1alpha1.PolicyExceptionSpec `json:"spec"`
+	Phase string                       `json:"-"`
+}
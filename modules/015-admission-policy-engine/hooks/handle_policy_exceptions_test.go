@@ -0,0 +1,137 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	"github.com/flant/shell-operator/pkg/metric_storage/operation"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/pointer"
+
+	. "github.com/deckhouse/deckhouse/testing/hooks"
+)
+
+var _ = Describe("Modules :: admission-policy-engine :: hooks :: handle policy exceptions", func() {
+	f := HookExecutionConfigInit(
+		`{"admissionPolicyEngine": {"internal": {"bootstrapped": true} } }`,
+		`{"admissionPolicyEngine":{}}`,
+	)
+	f.RegisterCRD("deckhouse.io", "v1alpha1", "PolicyException", false)
+
+	Context("Empty cluster", func() {
+		BeforeEach(func() {
+			f.BindingContexts.Set(f.KubeStateSet(""))
+			f.RunHook()
+		})
+
+		It("should store no exceptions", func() {
+			Expect(f).To(ExecuteSuccessfully())
+			Expect(f.ValuesGet("admissionPolicyEngine.internal.policyExceptions").Array()).To(BeEmpty())
+		})
+	})
+
+	Context("Active and expired exceptions", func() {
+		BeforeEach(func() {
+			f.BindingContexts.Set(f.KubeStateSet(testPolicyExceptions))
+			f.RunHook()
+		})
+
+		It("should store active exceptions only", func() {
+			Expect(f).To(ExecuteSuccessfully())
+			exceptions := f.ValuesGet("admissionPolicyEngine.internal.policyExceptions").Array()
+			Expect(exceptions).To(HaveLen(1))
+			Expect(exceptions[0].Get("metadata.name").String()).To(Equal("node-exporter"))
+			Expect(exceptions[0].Get("spec").String()).To(MatchJSON(`{
+				"policy": {"kind": "SecurityPolicy", "name": "genpolicy"},
+				"checks": ["allowHostNetwork"],
+				"workloads": [
+					{"kind": "DaemonSet", "namespace": "monitoring", "name": "node-exporter"},
+					{"namespace": "monitoring", "labelSelector": {"matchLabels": {"app": "node-exporter"}}}
+				],
+				"expiresAt": "2099-01-01T00:00:00Z",
+				"justification": "Collects the host network metrics."
+			}`))
+		})
+
+		It("should set phases", func() {
+			Expect(f.KubernetesGlobalResource("PolicyException", "node-exporter").Field("status.phase").String()).To(Equal("Active"))
+			Expect(f.KubernetesGlobalResource("PolicyException", "legacy-registry").Field("status.phase").String()).To(Equal("Expired"))
+		})
+
+		It("should expose expired exceptions", func() {
+			m := f.MetricsCollector.CollectedMetrics()
+			Expect(m).To(HaveLen(2))
+			Expect(m[0]).To(BeEquivalentTo(operation.MetricOperation{
+				Group:  "d8_admission_policy_engine_expired_policy_exception",
+				Action: "expire",
+			}))
+			Expect(m[1]).To(BeEquivalentTo(operation.MetricOperation{
+				Name:   "d8_admission_policy_engine_expired_policy_exception",
+				Group:  "d8_admission_policy_engine_expired_policy_exception",
+				Action: "set",
+				Value:  pointer.Float64(1),
+				Labels: map[string]string{
+					"name":        "legacy-registry",
+					"policy_kind": "OperationPolicy",
+					"policy_name": "registries",
+					"expires_at":  "2020-01-01T00:00:00Z",
+				},
+			}))
+		})
+	})
+})
+
+const testPolicyExceptions = `
+---
+apiVersion: deckhouse.io/v1alpha1
+kind: PolicyException
+metadata:
+  name: node-exporter
+spec:
+  policy:
+    kind: SecurityPolicy
+    name: genpolicy
+  checks: ["allowHostNetwork"]
+  workloads:
+  - kind: DaemonSet
+    namespace: monitoring
+    name: node-exporter
+  - namespace: monitoring
+    labelSelector:
+      matchLabels:
+        app: node-exporter
+  expiresAt: "2099-01-01T00:00:00Z"
+  justification: Collects the host network metrics.
+---
+apiVersion: deckhouse.io/v1alpha1
+kind: PolicyException
+metadata:
+  name: legacy-registry
+spec:
+  policy:
+    kind: OperationPolicy
+    name: registries
+  checks: ["allowedRepos"]
+  workloads:
+  - kind: Deployment
+    namespace: legacy
+    name: billing
+  expiresAt: "2020-01-01T00:00:00Z"
+  justification: The billing images are being moved to the new registry.
+status:
+  phase: Active
+`
@@ -136,7 +136,12 @@ type OperationPolicySpec struct {
 		PriorityClassNames        []string `json:"priorityClassNames,omitempty"`
 		CheckHostNetworkDNSPolicy bool     `json:"checkHostNetworkDNSPolicy,omitempty"`
 		CheckContainerDuplicates  bool     `json:"checkContainerDuplicates,omitempty"`
-		ReplicaLimits             struct {
+		RequireImageDigest        bool     `json:"requireImageDigest,omitempty"`
+		VerifyImageSignatures     []struct {
+			Reference  string   `json:"reference"`
+			PublicKeys []string `json:"publicKeys"`
+		} `json:"verifyImageSignatures,omitempty"`
+		ReplicaLimits struct {
 			MinReplicas int `json:"minReplicas,omitempty"`
 			MaxReplicas int `json:"maxReplicas,omitempty"`
 		} `json:"replicaLimits,omitempty"`
@@ -148,6 +153,19 @@ type OperationPolicySpec struct {
 }
 
 type PolicyStatus struct {
+	// Violations summary written by the constraint exporter after the gatekeeper audit.
+	Violations *PolicyViolations `json:"violations,omitempty"`
+}
+
+type PolicyViolations struct {
+	Total       int                    `json:"total"`
+	Constraints []ConstraintViolations `json:"constraints,omitempty"`
+}
+
+type ConstraintViolations struct {
+	Kind              string `json:"kind"`
+	EnforcementAction string `json:"enforcementAction"`
+	Total             int    `json:"total"`
 }
 
 type NamespaceSelector struct {
@@ -156,3 +174,41 @@ type NamespaceSelector struct {
 
 	LabelSelector metav1.LabelSelector `json:"labelSelector,omitempty"`
 }
+
+type PolicyException struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec defines the policy checks to waive and the workloads to waive them for.
+	Spec PolicyExceptionSpec `json:"spec"`
+
+	// Most recently observed status of the exception.
+	// Populated by the system.
+
+	Status PolicyExceptionStatus `json:"status,omitempty"`
+}
+
+type PolicyExceptionSpec struct {
+	Policy struct {
+		Kind string `json:"kind"`
+		Name string `json:"name"`
+	} `json:"policy"`
+	Checks        []string            `json:"checks"`
+	Workloads     []ExceptionWorkload `json:"workloads"`
+	ExpiresAt     metav1.Time         `json:"expiresAt"`
+	Justification string              `json:"justification"`
+}
+
+type ExceptionWorkload struct {
+	Kind          string                `json:"kind,omitempty"`
+	Namespace     string                `json:"namespace"`
+	Name          string                `json:"name,omitempty"`
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+}
+
+type PolicyExceptionStatus struct {
+	Phase string `json:"phase,omitempty"`
+}
@@ -107,7 +107,7 @@ func registrySecretToAuthnConfig(secret *corev1.Secret) (*dockerConfig, error) {
 }
 
 func handleTrivyProviderSecrets(input *go_hook.HookInput, dc dependency.Container) error {
-	if !input.Values.Get("admissionPolicyEngine.denyVulnerableImages.enabled").Bool() {
+	if !input.Values.Get("admissionPolicyEngine.denyVulnerableImages.enabled").Bool() && !imageSignaturesVerificationEnabled(input) {
 		return nil
 	}
 
@@ -142,6 +142,17 @@ func handleTrivyProviderSecrets(input *go_hook.HookInput, dc dependency.Containe
 	return nil
 }
 
+// imageSignaturesVerificationEnabled reports whether any OperationPolicy verifies image signatures,
+// the trivy provider pulls signatures from the registries with the same credentials as images for scanning.
+func imageSignaturesVerificationEnabled(input *go_hook.HookInput) bool {
+	for _, policy := range input.Values.Get("admissionPolicyEngine.internal.operationPolicies").Array() {
+		if len(policy.Get("spec.policies.verifyImageSignatures").Array()) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 var ErrNilSnapshot = errors.New("nil snapshot")
 
 func convertSnapToAuthnConfig(authSnap interface{}, resultCfg *dockerConfig) error {
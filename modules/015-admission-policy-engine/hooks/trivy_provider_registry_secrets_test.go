@@ -70,6 +70,29 @@ var _ = Describe("Modules :: admission-policy-engine :: hooks :: handle trivy pr
 			Expect(f.ValuesGet("admissionPolicyEngine.internal.denyVulnerableImages.dockerConfigJson").String()).To(MatchJSON(testDenyVulnerableImagesSecretsValues))
 		})
 	})
+
+	Context("Registry secrets data is stored in values for image signatures verification", func() {
+		BeforeEach(func() {
+			f.ValuesSetFromYaml("admissionPolicyEngine.internal.operationPolicies", []byte(`
+- metadata:
+    name: signed-images
+  spec:
+    policies:
+      verifyImageSignatures:
+      - reference: registry.test-2.com/*
+        publicKeys: ["key"]
+`))
+			f.RunHook()
+		})
+
+		It("Executes successfully", func() {
+			Expect(f).To(ExecuteSuccessfully())
+		})
+
+		It("should store data in values", func() {
+			Expect(f.ValuesGet("admissionPolicyEngine.internal.denyVulnerableImages.dockerConfigJson.auths").Map()).To(HaveKey("registry.test-2.com"))
+		})
+	})
 })
 var (
 	testDenyVulnerableImagesSecret = &corev1.Secret{
@@ -22,6 +22,7 @@ import (
 
 	"github.com/flant/constraint_exporter/pkg/gatekeeper"
 	"github.com/flant/constraint_exporter/pkg/kinds"
+	"github.com/flant/constraint_exporter/pkg/reports"
 	"github.com/prometheus/client_golang/prometheus"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -34,6 +35,7 @@ type Exporter struct {
 	kubeConfig *rest.Config
 
 	kindTracker *kinds.KindTracker
+	reporter    *reports.Reporter
 
 	metrics []prometheus.Metric
 }
@@ -68,6 +70,10 @@ func (e *Exporter) initKindTracker(cmNS, cmName string) error {
 	return nil
 }
 
+func (e *Exporter) initReporter(clientGVR controllerClient.Client) {
+	e.reporter = reports.NewReporter(clientGVR)
+}
+
 func (e *Exporter) createKubeClientGroupVersion() (controllerClient.Client, error) {
 	client, err := controllerClient.New(e.kubeConfig, controllerClient.Options{})
 	if err != nil {
@@ -101,9 +107,10 @@ func (e *Exporter) startScheduled(clientGVR controllerClient.Client, t time.Dura
 			return
 		case <-ticker.C:
 			var (
-				constraints []gatekeeper.Constraint
-				mutations   []gatekeeper.Mutation
-				wg          sync.WaitGroup
+				constraints        []gatekeeper.Constraint
+				constraintsFetched bool
+				mutations          []gatekeeper.Mutation
+				wg                 sync.WaitGroup
 			)
 
 			wg.Add(1)
@@ -112,6 +119,8 @@ func (e *Exporter) startScheduled(clientGVR controllerClient.Client, t time.Dura
 				constraints, err = e.fetchConstraints(clientGVR)
 				if err != nil {
 					klog.Warningf("Get constraints failed: %+v\n", err)
+				} else {
+					constraintsFetched = true
 				}
 				wg.Done()
 			}()
@@ -130,6 +139,11 @@ func (e *Exporter) startScheduled(clientGVR controllerClient.Client, t time.Dura
 			if e.kindTracker != nil {
 				go e.kindTracker.UpdateTrackedObjects(constraints, mutations)
 			}
+
+			// keep the reports as is if constraints were not fetched
+			if e.reporter != nil && constraintsFetched {
+				go e.reporter.Update(constraints)
+			}
 		}
 	}
 }
@@ -38,6 +38,7 @@ var (
 	interval      time.Duration
 
 	trackObjectsCMName string
+	policyReports      bool
 )
 
 func init() {
@@ -48,6 +49,7 @@ func init() {
 	flag.DurationVar(&interval, "server.interval", 30*time.Second,
 		"Kubernetes API server polling interval")
 	flag.StringVar(&trackObjectsCMName, "track-objects-configmap", "constraint-exporter", "ConfigMap for export tracking resource kinds")
+	flag.BoolVar(&policyReports, "policy-reports", true, "Write PolicyReports with violations and summarize violations in the policies statuses")
 }
 
 var (
@@ -74,6 +76,10 @@ func main() {
 		klog.Fatal(err)
 	}
 
+	if policyReports {
+		exporter.initReporter(clientGVR)
+	}
+
 	go exporter.startScheduled(clientGVR, interval)
 	prometheus.Unregister(collectors.NewGoCollector())
 	prometheus.MustRegister(exporter)
@@ -37,6 +37,8 @@ type ConstraintMeta struct {
 
 // Violation represents each constraintViolation under status
 type Violation struct {
+	Group             string `json:"group"`
+	Version           string `json:"version"`
 	Kind              string `json:"kind"`
 	Name              string `json:"name"`
 	Namespace         string `json:"namespace,omitempty"`
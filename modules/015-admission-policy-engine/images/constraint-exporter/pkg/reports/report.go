@@ -0,0 +1,159 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reports
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/flant/constraint_exporter/pkg/gatekeeper"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	reportsGroup   = "wgpolicyk8s.io"
+	reportsVersion = "v1alpha2"
+
+	// ReportName is the name of the reports in namespaces and of the cluster report
+	ReportName   = "d8-admission-policy-engine"
+	reportSource = "admission-policy-engine"
+
+	resultFail = "fail"
+	resultWarn = "warn"
+)
+
+var reportLabels = map[string]string{
+	"heritage": "deckhouse",
+	"module":   "admission-policy-engine",
+}
+
+// PolicyReport represents the wgpolicyk8s.io PolicyReport and ClusterPolicyReport objects
+type PolicyReport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Summary PolicyReportSummary  `json:"summary"`
+	Results []PolicyReportResult `json:"results,omitempty"`
+}
+
+type PolicyReportSummary struct {
+	Pass  int `json:"pass"`
+	Fail  int `json:"fail"`
+	Warn  int `json:"warn"`
+	Error int `json:"error"`
+	Skip  int `json:"skip"`
+}
+
+type PolicyReportResult struct {
+	Source     string                   `json:"source"`
+	Policy     string                   `json:"policy"`
+	Rule       string                   `json:"rule"`
+	Category   string                   `json:"category,omitempty"`
+	Result     string                   `json:"result"`
+	Message    string                   `json:"message"`
+	Scored     bool                     `json:"scored"`
+	Resources  []corev1.ObjectReference `json:"resources"`
+	Properties map[string]string        `json:"properties,omitempty"`
+}
+
+// BuildPolicyReports returns reports for the violations found by the gatekeeper audit, the reports are keyed by namespace.
+// Violations of cluster-scoped objects are reported in the ClusterPolicyReport under the empty key.
+func BuildPolicyReports(constraints []gatekeeper.Constraint) map[string]*PolicyReport {
+	reports := make(map[string]*PolicyReport)
+
+	for _, c := range constraints {
+		for _, v := range c.Status.Violations {
+			report, ok := reports[v.Namespace]
+			if !ok {
+				report = newPolicyReport(v.Namespace)
+				reports[v.Namespace] = report
+			}
+
+			result := resultWarn
+			if strings.EqualFold(v.EnforcementAction, "deny") {
+				result = resultFail
+				report.Summary.Fail++
+			} else {
+				report.Summary.Warn++
+			}
+
+			report.Results = append(report.Results, PolicyReportResult{
+				Source:   reportSource,
+				Policy:   c.Meta.Name,
+				Rule:     c.Meta.Kind,
+				Category: c.Meta.SourceType,
+				Result:   result,
+				Message:  v.Message,
+				Scored:   true,
+				Resources: []corev1.ObjectReference{{
+					APIVersion: apiVersion(v.Group, v.Version),
+					Kind:       v.Kind,
+					Namespace:  v.Namespace,
+					Name:       v.Name,
+				}},
+				Properties: map[string]string{
+					"enforcementAction": v.EnforcementAction,
+				},
+			})
+		}
+	}
+
+	for _, report := range reports {
+		sort.SliceStable(report.Results, func(i, j int) bool {
+			a, b := report.Results[i], report.Results[j]
+			if a.Resources[0].Kind != b.Resources[0].Kind {
+				return a.Resources[0].Kind < b.Resources[0].Kind
+			}
+			if a.Resources[0].Name != b.Resources[0].Name {
+				return a.Resources[0].Name < b.Resources[0].Name
+			}
+			if a.Policy != b.Policy {
+				return a.Policy < b.Policy
+			}
+			return a.Rule < b.Rule
+		})
+	}
+
+	return reports
+}
+
+func newPolicyReport(namespace string) *PolicyReport {
+	kind := "PolicyReport"
+	if namespace == "" {
+		kind = "ClusterPolicyReport"
+	}
+
+	return &PolicyReport{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: reportsGroup + "/" + reportsVersion,
+			Kind:       kind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ReportName,
+			Namespace: namespace,
+			Labels:    reportLabels,
+		},
+	}
+}
+
+func apiVersion(group, version string) string {
+	if group == "" {
+		return version
+	}
+	return group + "/" + version
+}
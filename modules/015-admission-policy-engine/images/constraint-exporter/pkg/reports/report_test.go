@@ -0,0 +1,127 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reports
+
+import (
+	"testing"
+
+	"github.com/flant/constraint_exporter/pkg/gatekeeper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var testConstraints = []gatekeeper.Constraint{
+	{
+		Meta: gatekeeper.ConstraintMeta{Kind: "D8HostNetwork", Name: "genpolicy", SourceType: "SecurityPolicy"},
+		Spec: gatekeeper.ConstraintSpec{EnforcementAction: "dryrun"},
+		Status: gatekeeper.ConstraintStatus{
+			TotalViolations: 25,
+			Violations: []*gatekeeper.Violation{
+				{Version: "v1", Kind: "Pod", Namespace: "shop", Name: "web", Message: "hostNetwork is not allowed", EnforcementAction: "dryrun"},
+				{Version: "v1", Kind: "Pod", Namespace: "billing", Name: "api", Message: "hostNetwork is not allowed", EnforcementAction: "dryrun"},
+			},
+		},
+	},
+	{
+		Meta: gatekeeper.ConstraintMeta{Kind: "D8AllowedRepos", Name: "registries", SourceType: "OperationPolicy"},
+		Spec: gatekeeper.ConstraintSpec{EnforcementAction: "deny"},
+		Status: gatekeeper.ConstraintStatus{
+			TotalViolations: 1,
+			Violations: []*gatekeeper.Violation{
+				{Version: "v1", Kind: "Pod", Namespace: "shop", Name: "cache", Message: "image is not allowed", EnforcementAction: "deny"},
+			},
+		},
+	},
+	{
+		Meta: gatekeeper.ConstraintMeta{Kind: "D8AllowedClusterRoles", Name: "genpolicy", SourceType: "SecurityPolicy"},
+		Spec: gatekeeper.ConstraintSpec{EnforcementAction: "dryrun"},
+		Status: gatekeeper.ConstraintStatus{
+			TotalViolations: 1,
+			Violations: []*gatekeeper.Violation{
+				{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRoleBinding", Name: "admins", Message: "cluster role is not allowed", EnforcementAction: "dryrun"},
+			},
+		},
+	},
+	{
+		Meta: gatekeeper.ConstraintMeta{Kind: "D8PrivilegedContainer", Name: "d8-pod-security-baseline-deny-default", SourceType: "PSS"},
+		Spec: gatekeeper.ConstraintSpec{EnforcementAction: "deny"},
+	},
+}
+
+func TestBuildPolicyReports(t *testing.T) {
+	reports := BuildPolicyReports(testConstraints)
+	require.Len(t, reports, 3)
+
+	shop := reports["shop"]
+	require.NotNil(t, shop)
+	assert.Equal(t, "PolicyReport", shop.Kind)
+	assert.Equal(t, ReportName, shop.Name)
+	assert.Equal(t, "shop", shop.Namespace)
+	assert.Equal(t, PolicyReportSummary{Fail: 1, Warn: 1}, shop.Summary)
+	assert.Equal(t, []PolicyReportResult{
+		{
+			Source:     reportSource,
+			Policy:     "registries",
+			Rule:       "D8AllowedRepos",
+			Category:   "OperationPolicy",
+			Result:     resultFail,
+			Message:    "image is not allowed",
+			Scored:     true,
+			Resources:  []corev1.ObjectReference{{APIVersion: "v1", Kind: "Pod", Namespace: "shop", Name: "cache"}},
+			Properties: map[string]string{"enforcementAction": "deny"},
+		},
+		{
+			Source:     reportSource,
+			Policy:     "genpolicy",
+			Rule:       "D8HostNetwork",
+			Category:   "SecurityPolicy",
+			Result:     resultWarn,
+			Message:    "hostNetwork is not allowed",
+			Scored:     true,
+			Resources:  []corev1.ObjectReference{{APIVersion: "v1", Kind: "Pod", Namespace: "shop", Name: "web"}},
+			Properties: map[string]string{"enforcementAction": "dryrun"},
+		},
+	}, shop.Results)
+
+	assert.Equal(t, PolicyReportSummary{Warn: 1}, reports["billing"].Summary)
+
+	cluster := reports[""]
+	require.NotNil(t, cluster)
+	assert.Equal(t, "ClusterPolicyReport", cluster.Kind)
+	assert.Empty(t, cluster.Namespace)
+	require.Len(t, cluster.Results, 1)
+	assert.Equal(t, "rbac.authorization.k8s.io/v1", cluster.Results[0].Resources[0].APIVersion)
+}
+
+func TestBuildPolicyViolations(t *testing.T) {
+	policies := BuildPolicyViolations(testConstraints)
+	require.Len(t, policies, 2)
+
+	assert.Equal(t, &PolicyViolations{
+		Total: 26,
+		Constraints: []ConstraintViolations{
+			{Kind: "D8AllowedClusterRoles", EnforcementAction: "dryrun", Total: 1},
+			{Kind: "D8HostNetwork", EnforcementAction: "dryrun", Total: 25},
+		},
+	}, policies[PolicyKey{Kind: "SecurityPolicy", Name: "genpolicy"}])
+
+	assert.Equal(t, &PolicyViolations{
+		Total:       1,
+		Constraints: []ConstraintViolations{{Kind: "D8AllowedRepos", EnforcementAction: "deny", Total: 1}},
+	}, policies[PolicyKey{Kind: "OperationPolicy", Name: "registries"}])
+}
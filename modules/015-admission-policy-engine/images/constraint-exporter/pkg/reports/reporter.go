@@ -0,0 +1,205 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reports
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sync"
+
+	"github.com/flant/constraint_exporter/pkg/gatekeeper"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	policiesGroup   = "deckhouse.io"
+	policiesVersion = "v1alpha1"
+)
+
+// Reporter writes PolicyReports for the gatekeeper audit results and the violations summary to the policies statuses
+type Reporter struct {
+	client controllerClient.Client
+
+	mu sync.Mutex
+	// policies statuses patched by the reporter, to patch only the changed ones
+	statuses map[PolicyKey]*PolicyViolations
+}
+
+func NewReporter(client controllerClient.Client) *Reporter {
+	return &Reporter{
+		client:   client,
+		statuses: make(map[PolicyKey]*PolicyViolations),
+	}
+}
+
+// Update synchronizes reports and policies statuses with the constraints
+func (r *Reporter) Update(constraints []gatekeeper.Constraint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ctx := context.TODO()
+	reports := BuildPolicyReports(constraints)
+
+	err := r.syncReports(ctx, "ClusterPolicyReport", filterReports(reports, true))
+	if err != nil {
+		klog.Warningf("Update cluster policy reports failed: %+v\n", err)
+	}
+
+	err = r.syncReports(ctx, "PolicyReport", filterReports(reports, false))
+	if err != nil {
+		klog.Warningf("Update policy reports failed: %+v\n", err)
+	}
+
+	r.updatePolicyStatuses(ctx, BuildPolicyViolations(constraints))
+}
+
+func filterReports(reports map[string]*PolicyReport, clusterScoped bool) map[string]*PolicyReport {
+	filtered := make(map[string]*PolicyReport)
+	for namespace, report := range reports {
+		if (namespace == "") == clusterScoped {
+			filtered[namespace] = report
+		}
+	}
+	return filtered
+}
+
+// syncReports creates or updates the reports and deletes stale ones
+func (r *Reporter) syncReports(ctx context.Context, kind string, reports map[string]*PolicyReport) error {
+	existing := &unstructured.UnstructuredList{}
+	existing.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   reportsGroup,
+		Version: reportsVersion,
+		Kind:    kind + "List",
+	})
+
+	err := r.client.List(ctx, existing, controllerClient.MatchingLabels(reportLabels))
+	if err != nil {
+		return err
+	}
+
+	for _, item := range existing.Items {
+		item := item
+
+		report, ok := reports[item.GetNamespace()]
+		if !ok || item.GetName() != ReportName {
+			err := r.client.Delete(ctx, &item)
+			if err != nil && !errors.IsNotFound(err) {
+				klog.Warningf("Delete %s %s/%s failed: %+v\n", kind, item.GetNamespace(), item.GetName(), err)
+			}
+			continue
+		}
+		delete(reports, item.GetNamespace())
+
+		var current PolicyReport
+		err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.UnstructuredContent(), &current)
+		if err == nil && current.Summary == report.Summary && reflect.DeepEqual(current.Results, report.Results) {
+			continue
+		}
+
+		report.ResourceVersion = item.GetResourceVersion()
+		obj, err := toUnstructured(report)
+		if err == nil {
+			err = r.client.Update(ctx, obj)
+		}
+		if err != nil {
+			klog.Warningf("Update %s %s/%s failed: %+v\n", kind, report.Namespace, report.Name, err)
+		}
+	}
+
+	for _, report := range reports {
+		obj, err := toUnstructured(report)
+		if err == nil {
+			err = r.client.Create(ctx, obj)
+		}
+		if err != nil && !errors.IsAlreadyExists(err) {
+			klog.Warningf("Create %s %s/%s failed: %+v\n", kind, report.Namespace, report.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func toUnstructured(report *PolicyReport) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(report)
+	if err != nil {
+		return nil, err
+	}
+
+	return &unstructured.Unstructured{Object: content}, nil
+}
+
+// updatePolicyStatuses patches the statuses of the policies with changed violations
+// and clears the statuses of the policies that no longer have constraints
+func (r *Reporter) updatePolicyStatuses(ctx context.Context, policies map[PolicyKey]*PolicyViolations) {
+	for key, violations := range policies {
+		if reflect.DeepEqual(r.statuses[key], violations) {
+			continue
+		}
+
+		err := r.patchPolicyStatus(ctx, key, violations)
+		if err != nil {
+			klog.Warningf("Update %s %s status failed: %+v\n", key.Kind, key.Name, err)
+			continue
+		}
+
+		r.statuses[key] = violations
+	}
+
+	for key := range r.statuses {
+		if _, ok := policies[key]; ok {
+			continue
+		}
+
+		// the policy is kept in the statuses to retry if the patch failed
+		err := r.patchPolicyStatus(ctx, key, nil)
+		if err != nil && !errors.IsNotFound(err) {
+			klog.Warningf("Clear %s %s status failed: %+v\n", key.Kind, key.Name, err)
+			continue
+		}
+
+		delete(r.statuses, key)
+	}
+}
+
+// patchPolicyStatus sets the violations of the policy status, nil violations remove the field
+func (r *Reporter) patchPolicyStatus(ctx context.Context, key PolicyKey, violations *PolicyViolations) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{
+			"violations": violations,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	policy := &unstructured.Unstructured{}
+	policy.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   policiesGroup,
+		Version: policiesVersion,
+		Kind:    key.Kind,
+	})
+	policy.SetName(key.Name)
+
+	return r.client.Status().Patch(ctx, policy, controllerClient.RawPatch(types.MergePatchType, patch))
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reports
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	controllerClient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// statusClient records the status patches of the policies
+type statusClient struct {
+	controllerClient.Client
+
+	patches map[string]string
+	failing map[string]bool
+}
+
+func (c *statusClient) Status() controllerClient.SubResourceWriter {
+	return &statusWriter{client: c}
+}
+
+type statusWriter struct {
+	controllerClient.SubResourceWriter
+
+	client *statusClient
+}
+
+func (w *statusWriter) Patch(_ context.Context, obj controllerClient.Object, patch controllerClient.Patch, _ ...controllerClient.SubResourcePatchOption) error {
+	if w.client.failing[obj.GetName()] {
+		return fmt.Errorf("patch failed")
+	}
+
+	data, err := patch.Data(obj)
+	if err != nil {
+		return err
+	}
+	w.client.patches[obj.GetObjectKind().GroupVersionKind().Kind+"/"+obj.GetName()] = string(data)
+	return nil
+}
+
+func TestUpdatePolicyStatuses(t *testing.T) {
+	client := &statusClient{patches: make(map[string]string), failing: make(map[string]bool)}
+	r := NewReporter(client)
+
+	genpolicy := PolicyKey{Kind: "SecurityPolicy", Name: "genpolicy"}
+	images := PolicyKey{Kind: "OperationPolicy", Name: "images"}
+	violations := func(total int) *PolicyViolations {
+		return &PolicyViolations{Total: total, Constraints: []ConstraintViolations{{Kind: "D8HostNetwork", EnforcementAction: "deny", Total: total}}}
+	}
+
+	r.updatePolicyStatuses(context.Background(), map[PolicyKey]*PolicyViolations{genpolicy: violations(2), images: violations(1)})
+	assert.JSONEq(t, `{"status": {"violations": {"total": 2, "constraints": [{"kind": "D8HostNetwork", "enforcementAction": "deny", "total": 2}]}}}`, client.patches["SecurityPolicy/genpolicy"])
+	assert.Len(t, client.patches, 2)
+
+	// unchanged violations are not patched, statuses of the policies without constraints are cleared
+	client.patches = make(map[string]string)
+	r.updatePolicyStatuses(context.Background(), map[PolicyKey]*PolicyViolations{genpolicy: violations(2)})
+	assert.Equal(t, map[string]string{"OperationPolicy/images": `{"status":{"violations":null}}`}, client.patches)
+	assert.NotContains(t, r.statuses, images)
+
+	// failed patches are retried on the next update
+	client.patches = make(map[string]string)
+	client.failing["genpolicy"] = true
+	r.updatePolicyStatuses(context.Background(), map[PolicyKey]*PolicyViolations{})
+	assert.Contains(t, r.statuses, genpolicy)
+
+	client.failing["genpolicy"] = false
+	r.updatePolicyStatuses(context.Background(), map[PolicyKey]*PolicyViolations{})
+	assert.Equal(t, map[string]string{"SecurityPolicy/genpolicy": `{"status":{"violations":null}}`}, client.patches)
+	assert.Empty(t, r.statuses)
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reports
+
+import (
+	"sort"
+
+	"github.com/flant/constraint_exporter/pkg/gatekeeper"
+)
+
+// PolicyKey identifies the SecurityPolicy or the OperationPolicy the constraints are generated from
+type PolicyKey struct {
+	Kind string
+	Name string
+}
+
+// PolicyViolations is the violations summary stored in the policy status
+type PolicyViolations struct {
+	Total       int                    `json:"total"`
+	Constraints []ConstraintViolations `json:"constraints"`
+}
+
+type ConstraintViolations struct {
+	Kind              string `json:"kind"`
+	EnforcementAction string `json:"enforcementAction"`
+	Total             int    `json:"total"`
+}
+
+// BuildPolicyViolations summarizes violations of the constraints generated from the policies.
+// Totals are taken from the audit results, so they are not limited by the number of violations listed in the constraints.
+func BuildPolicyViolations(constraints []gatekeeper.Constraint) map[PolicyKey]*PolicyViolations {
+	policies := make(map[PolicyKey]*PolicyViolations)
+
+	for _, c := range constraints {
+		if c.Meta.SourceType != "SecurityPolicy" && c.Meta.SourceType != "OperationPolicy" {
+			continue
+		}
+
+		key := PolicyKey{Kind: c.Meta.SourceType, Name: c.Meta.Name}
+		policy, ok := policies[key]
+		if !ok {
+			policy = &PolicyViolations{Constraints: make([]ConstraintViolations, 0)}
+			policies[key] = policy
+		}
+
+		total := int(c.Status.TotalViolations)
+		policy.Total += total
+		policy.Constraints = append(policy.Constraints, ConstraintViolations{
+			Kind:              c.Meta.Kind,
+			EnforcementAction: c.Spec.EnforcementAction,
+			Total:             total,
+		})
+	}
+
+	for _, policy := range policies {
+		sort.Slice(policy.Constraints, func(i, j int) bool {
+			return policy.Constraints[i].Kind < policy.Constraints[j].Kind
+		})
+	}
+
+	return policies
+}
@@ -214,4 +214,31 @@ var _ = Describe("Module :: admissionPolicyEngine :: helm template ::", func() {
 			})
 		})
 	})
+
+	Context("Cluster with image signatures verification", func() {
+		BeforeEach(func() {
+			f.ValuesSet("admissionPolicyEngine.internal.bootstrapped", true)
+			f.ValuesSetFromYaml("admissionPolicyEngine.internal.operationPolicies.0.spec.policies.verifyImageSignatures", `[{"reference": "registry.example.com/apps/*", "publicKeys": ["key"]}]`)
+			f.ValuesSetFromYaml("admissionPolicyEngine.internal.denyVulnerableImages.webhook", `{"ca": "ca", "crt": "crt", "key": "key"}`)
+			f.ValuesSetFromYaml("admissionPolicyEngine.internal.denyVulnerableImages.dockerConfigJson", `{"auths": {"registry.example.com": {"auth": "dXNlcjpwYXNzd29yZAo="}}}`)
+			f.HelmRender()
+		})
+
+		It("Everything must render properly", func() {
+			Expect(f.RenderError).ShouldNot(HaveOccurred())
+		})
+
+		It("Creates trivy-provider with the image signature provider only", func() {
+			Expect(f.KubernetesResource("StatefulSet", nsName, "trivy-provider").Exists()).To(BeTrue())
+			Expect(f.KubernetesResource("Service", nsName, "trivy-provider").Exists()).To(BeTrue())
+			Expect(f.KubernetesGlobalResource("Provider", "image-signature-provider").Field("spec.url").String()).To(Equal("https://trivy-provider.d8-admission-policy-engine:8443/verify-signatures"))
+			Expect(f.KubernetesGlobalResource("Provider", "trivy-provider").Exists()).To(BeFalse())
+			Expect(f.KubernetesGlobalResource("D8VulnerableImages", "vulnerable-image").Exists()).To(BeFalse())
+		})
+
+		It("Increases the webhook timeout", func() {
+			vw := f.KubernetesGlobalResource("ValidatingWebhookConfiguration", "d8-admission-policy-engine-config")
+			Expect(vw.Field("webhooks.0.timeoutSeconds").Int()).To(BeEquivalentTo(10))
+		})
+	})
 })
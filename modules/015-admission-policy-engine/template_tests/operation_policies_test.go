@@ -56,6 +56,8 @@ var _ = Describe("Module :: admissionPolicyEngine :: helm template :: operation
 			"priorityClassNames":["foo","bar"],
 			"checkHostNetworkDNSPolicy":true,
 			"checkContainerDuplicates":true,
+			"requireImageDigest":true,
+			"verifyImageSignatures":[{"reference":"registry.example.com/apps/*","publicKeys":["key"]}],
 			"replicaLimits":{
 					"minReplicas":1,
 					"maxReplicas":10
@@ -64,7 +66,8 @@ var _ = Describe("Module :: admissionPolicyEngine :: helm template :: operation
 		"match":{"namespaceSelector":{"matchNames":["default"]}}}}],
 		"trackedConstraintResources": [{"apiGroups":[""],"resources":["pods"]},{"apiGroups":["extensions","networking.k8s.io"],"resources":["ingresses"]}],
 		"trackedMutateResources": [{"apiGroups":[""],"resources":["pods"]},{"apiGroups":["extensions","networking.k8s.io"],"resources":["ingresses"]}],
-		"webhook": {ca: YjY0ZW5jX3N0cmluZwo=, crt: YjY0ZW5jX3N0cmluZwo=, key: YjY0ZW5jX3N0cmluZwo=}}}}`)
+		"webhook": {ca: YjY0ZW5jX3N0cmluZwo=, crt: YjY0ZW5jX3N0cmluZwo=, key: YjY0ZW5jX3N0cmluZwo=},
+		"denyVulnerableImages": {"webhook": {ca: YjY0ZW5jX3N0cmluZwo=, crt: YjY0ZW5jX3N0cmluZwo=, key: YjY0ZW5jX3N0cmluZwo=}, "dockerConfigJson": {}}}}}`)
 
 	Context("Cluster with operation policies", func() {
 		BeforeEach(func() {
@@ -88,6 +91,8 @@ var _ = Describe("Module :: admissionPolicyEngine :: helm template :: operation
 			Expect(f.KubernetesGlobalResource("D8RequiredAnnotations", testPolicyName).Exists()).To(BeTrue())
 			Expect(f.KubernetesGlobalResource("D8ContainerDuplicates", testPolicyName).Exists()).To(BeTrue())
 			Expect(f.KubernetesGlobalResource("D8ReplicaLimits", testPolicyName).Exists()).To(BeTrue())
+			Expect(f.KubernetesGlobalResource("D8RequiredImageDigest", testPolicyName).Exists()).To(BeTrue())
+			Expect(f.KubernetesGlobalResource("D8VerifyImageSignatures", testPolicyName).Field("spec.parameters.rules").String()).To(MatchJSON(`[{"reference":"registry.example.com/apps/*","publicKeys":["key"]}]`))
 
 		})
 	})
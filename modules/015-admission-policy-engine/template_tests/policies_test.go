@@ -18,8 +18,10 @@ package template_tests
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
 	. "github.com/onsi/ginkgo"
@@ -54,7 +56,28 @@ admissionPolicyEngine:
             min: 42000
 `)
 
+	Context("Constraint templates", func() {
+		BeforeEach(func() {
+			f.ValuesSetFromYaml("global", globalValues)
+			f.ValuesSet("global.modulesImages", GetModulesImages())
+			f.HelmRender()
+		})
+
+		It("Templates must include the exceptions library", func() {
+			Expect(f.RenderError).ShouldNot(HaveOccurred())
+
+			for _, name := range []string{"d8allowedrepos", "d8hostnetwork", "d8automountserviceaccounttokenpod"} {
+				template := f.KubernetesGlobalResource("ConstraintTemplate", name)
+				Expect(template.Exists()).To(BeTrue(), name)
+				Expect(template.Field("spec.crd.spec.validation.openAPIV3Schema.properties.exceptions.type").String()).To(Equal("array"), name)
+				Expect(template.Field("spec.targets.0.libs").String()).To(ContainSubstring("package lib.exceptions"), name)
+			}
+		})
+	})
+
 	Context("Test rego policies", func() {
+		rendered := make(map[string]string)
+
 		BeforeEach(func() {
 			if gatorPath, gatorFound = gatorAvailable(); !gatorFound {
 				Skip("gator binary is not available")
@@ -62,15 +85,24 @@ admissionPolicyEngine:
 
 			f.ValuesSetFromYaml("global", globalValues)
 			f.ValuesSet("global.modulesImages", GetModulesImages())
-			f.HelmRender()
+			f.HelmRender(WithFilteredRenderOutput(rendered, constraintTemplatesPath+"/templates/"))
 		})
 
 		It("Rego policy test must have passed", func() {
 			Expect(f.RenderError).ShouldNot(HaveOccurred())
-			gatorCLI := exec.Command(gatorPath, "verify", "-v", "../charts/constraint-templates/tests/...")
+
+			// Templates include shared helpers, so the suites are run against the rendered ones
+			tmpDir, err := os.MkdirTemp("", "constraint-templates-")
+			Expect(err).ShouldNot(HaveOccurred())
+			defer os.RemoveAll(tmpDir)
+
+			chartDir, err := renderedConstraintTemplatesChart(tmpDir, rendered)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			gatorCLI := exec.Command(gatorPath, "verify", "-v", filepath.Join(chartDir, "tests")+"/...")
 			res, err := gatorCLI.Output()
 			if err != nil {
-				output := strings.ReplaceAll(string(res), "modules/015-admission-policy-engine/charts/constraint-templates", "...")
+				output := strings.ReplaceAll(string(res), chartDir, "...")
 				fmt.Println(output)
 				Fail("Gatekeeper policy tests failed:" + err.Error())
 			}
@@ -78,6 +110,41 @@ admissionPolicyEngine:
 	})
 })
 
+const constraintTemplatesPath = "charts/constraint-templates"
+
+// renderedConstraintTemplatesChart lays out the rendered constraint templates and the gator suites
+// the same way they are laid out in the chart
+func renderedConstraintTemplatesChart(dir string, rendered map[string]string) (string, error) {
+	for path, manifests := range rendered {
+		_, relPath, _ := strings.Cut(path, constraintTemplatesPath+"/")
+		target := filepath.Join(dir, relPath)
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(target, []byte(manifests), 0o644); err != nil {
+			return "", err
+		}
+	}
+
+	testsDir := filepath.Join("..", constraintTemplatesPath, "tests")
+	err := filepath.WalkDir(testsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, "tests", strings.TrimPrefix(path, testsDir))
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, content, 0o644)
+	})
+
+	return dir, err
+}
+
 func gatorAvailable() (string, bool) {
 	gatorPath, err := exec.LookPath("gator")
 	if err != nil {
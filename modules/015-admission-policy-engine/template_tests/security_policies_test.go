@@ -83,6 +83,74 @@ var _ = Describe("Module :: admissionPolicyEngine :: helm template :: security p
 			Expect(f.KubernetesGlobalResource("D8AutomountServiceAccountTokenPod", testPolicyName).Exists()).To(BeTrue())
 			Expect(f.KubernetesGlobalResource("D8SeLinux", testPolicyName).Exists()).To(BeTrue())
 			Expect(f.KubernetesGlobalResource("D8AppArmor", testPolicyName).Exists()).To(BeTrue())
+
+			Expect(f.KubernetesGlobalResource("D8HostNetwork", testPolicyName).Field("spec.parameters.exceptions").Exists()).To(BeFalse())
+			Expect(f.KubernetesGlobalResource("D8PrivilegedContainer", testPolicyName).Field("spec.parameters").Exists()).To(BeFalse())
+		})
+	})
+
+	Context("Cluster with security policies and policy exceptions", func() {
+		BeforeEach(func() {
+			f.ValuesSetFromYaml("global", globalValues)
+			f.ValuesSet("global.modulesImages", GetModulesImages())
+			f.ValuesSetFromYaml("admissionPolicyEngine.internal.policyExceptions", testPolicyExceptions)
+			f.HelmRender()
+		})
+
+		It("Waived checks must have exceptions", func() {
+			Expect(f.RenderError).ShouldNot(HaveOccurred())
+
+			Expect(f.KubernetesGlobalResource("D8HostNetwork", testPolicyName).Field("spec.parameters.allowHostNetwork").Bool()).To(BeFalse())
+			Expect(f.KubernetesGlobalResource("D8HostNetwork", testPolicyName).Field("spec.parameters.exceptions").String()).To(MatchJSON(`[
+				{"kind": "DaemonSet", "namespace": "monitoring", "name": "node-exporter"},
+				{"namespace": "monitoring", "labelSelector": {"matchLabels": {"app": "node-exporter"}}}
+			]`))
+			Expect(f.KubernetesGlobalResource("D8PrivilegedContainer", testPolicyName).Field("spec.parameters.exceptions").String()).To(MatchJSON(`[
+				{"kind": "Deployment", "namespace": "debug", "name": "toolbox"}
+			]`))
+
+			Expect(f.KubernetesGlobalResource("D8HostProcesses", testPolicyName).Field("spec.parameters.exceptions").Exists()).To(BeFalse())
+			Expect(f.KubernetesGlobalResource("D8AllowPrivilegeEscalation", testPolicyName).Field("spec.parameters").Exists()).To(BeFalse())
 		})
 	})
 })
+
+const testPolicyExceptions = `
+- metadata:
+    name: node-exporter
+  spec:
+    policy:
+      kind: SecurityPolicy
+      name: genpolicy
+    checks: ["allowHostNetwork"]
+    workloads:
+    - kind: DaemonSet
+      namespace: monitoring
+      name: node-exporter
+    - namespace: monitoring
+      labelSelector:
+        matchLabels:
+          app: node-exporter
+- metadata:
+    name: toolbox
+  spec:
+    policy:
+      kind: SecurityPolicy
+      name: genpolicy
+    checks: ["allowPrivileged"]
+    workloads:
+    - kind: Deployment
+      namespace: debug
+      name: toolbox
+- metadata:
+    name: other-policy
+  spec:
+    policy:
+      kind: OperationPolicy
+      name: genpolicy
+    checks: ["allowHostPID"]
+    workloads:
+    - kind: Deployment
+      namespace: debug
+      name: toolbox
+`
@@ -21,6 +21,49 @@
     {{- end }}
 {{- end }}
 
+{{- define "policy_exception_workloads" }}
+  {{- $context := index . 0 }}
+  {{- $cr := index . 1 }}
+  {{- $kind := index . 2 }}
+  {{- $checks := index . 3 }}
+  {{- $workloads := list }}
+
+  {{- range $exception := $context.Values.admissionPolicyEngine.internal.policyExceptions }}
+    {{- if and (eq $exception.spec.policy.kind $kind) (eq $exception.spec.policy.name $cr.metadata.name) }}
+      {{- $waived := false }}
+      {{- range $check := $exception.spec.checks }}
+        {{- if has $check $checks }}
+          {{- $waived = true }}
+        {{- end }}
+      {{- end }}
+      {{- if $waived }}
+        {{- $workloads = concat $workloads $exception.spec.workloads }}
+      {{- end }}
+    {{- end }}
+  {{- end }}
+
+  {{- if $workloads }}
+    {{- $workloads | toYaml }}
+  {{- end }}
+{{- end }}
+
+{{- define "policy_exceptions" }}
+  {{- $workloads := include "policy_exception_workloads" . }}
+  {{- if $workloads }}
+    exceptions:
+      {{- $workloads | nindent 6 }}
+  {{- end }}
+{{- end }}
+
+{{- define "policy_exceptions_parameters" }}
+  {{- $workloads := include "policy_exception_workloads" . }}
+  {{- if $workloads }}
+  parameters:
+    exceptions:
+      {{- $workloads | nindent 6 }}
+  {{- end }}
+{{- end }}
+
 {{- define "pod_security_standard_baseline" }}
   {{- $context := index . 0 }}
   {{- $policyCRDName := index . 1 }}
@@ -112,3 +155,25 @@ spec:
   {{- end }}
   {{- print "" }}
 {{- end }}
+
+{{/* Image signatures are verified by the trivy-provider through the image-signature-provider external data provider */}}
+{{- define "image.signature.provider.enabled" }}
+  {{- $context := . }}
+  {{- $enabled := false }}
+  {{- range $cr := $context.Values.admissionPolicyEngine.internal.operationPolicies }}
+    {{- if $cr.spec.policies.verifyImageSignatures }}
+      {{- $enabled = true }}
+    {{- end }}
+  {{- end }}
+  {{- if $enabled }}
+    {{- print "true" }}
+  {{- end }}
+  {{- print "" }}
+{{- end }}
+
+{{- define "trivy.provider.deployed" }}
+  {{- if or (include "trivy.provider.enabled" .) (include "image.signature.provider.enabled" .) }}
+    {{- print "true" }}
+  {{- end }}
+  {{- print "" }}
+{{- end }}
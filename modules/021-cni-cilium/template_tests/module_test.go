@@ -95,6 +95,10 @@ var _ = Describe("Module :: cniCilium :: helm template ::", func() {
 
 		It("Everything must render properly", func() {
 			Expect(f.RenderError).ShouldNot(HaveOccurred())
+
+			agent := f.KubernetesResource("DaemonSet", "d8-cni-cilium", "agent")
+			Expect(agent.Exists()).To(BeTrue())
+			Expect(agent.Field(`spec.template.spec.initContainers.#(name=="check-linux-kernel").env.#(name=="KERNEL_CONSTRAINT").value`).String()).To(Equal(">= 4.9.17"))
 		})
 	})
 })
@@ -1,4 +1,10 @@
-{{- /* Usage: {{ include "helm_lib_module_init_container_check_linux_kernel" (list . ">= 4.9.17") }} */ -}}
+{{- /* Usage: {{ include "module_minimal_kernel_version" . }} */ -}}
+{{- /* returns the minimal kernel version constraint from candi/version_map.yml, dhctl preflight checks the same constraint */ -}}
+{{- define "module_minimal_kernel_version" }}
+  {{- (.Files.Get "candi/version_map.yml" | fromYaml).minimalKernelVersion }}
+{{- end }}
+
+{{- /* Usage: {{ include "module_init_container_check_linux_kernel" (list . (include "module_minimal_kernel_version" .)) }} */ -}}
 {{- /* returns initContainer which checks the kernel version on the node for compliance to semver constraint */ -}}
 {{- define "module_init_container_check_linux_kernel"  }}
   {{- $context := index . 0 -}} {{- /* Template context with .Values, .Chart, etc */ -}}
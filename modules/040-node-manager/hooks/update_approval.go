@@ -31,6 +31,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/pointer"
 
+	"github.com/deckhouse/deckhouse/go_lib/hooks/update"
 	"github.com/deckhouse/deckhouse/modules/040-node-manager/hooks/internal/shared"
 	ngv1 "github.com/deckhouse/deckhouse/modules/040-node-manager/hooks/internal/v1"
 )
@@ -280,6 +281,17 @@ func (ar *updateApprover) needDrainNode(input *go_hook.HookInput, node *updateAp
 	return *nodeNg.Disruptions.Automatic.DrainBeforeApproval
 }
 
+// windowsAllowed returns if disruptions are allowed by the NodeGroup update windows,
+// invalid windows (e.g. unknown timezone) never allow disruptions
+func windowsAllowed(input *go_hook.HookInput, ngName string, windows update.Windows, now time.Time) bool {
+	if err := windows.Validate(); err != nil {
+		input.LogEntry.Errorf("NodeGroup %s has invalid disruption windows, disruptive updates are not approved: %v", ngName, err)
+		return false
+	}
+
+	return windows.IsAllowed(now)
+}
+
 // Approve disruption updates for NodeGroups with approvalMode == Automatic
 // We don't limit number of Nodes here, because it's already limited
 func (ar *updateApprover) approveDisruptions(input *go_hook.HookInput) error {
@@ -305,12 +317,12 @@ func (ar *updateApprover) approveDisruptions(input *go_hook.HookInput) error {
 
 		// Skip node if update is not permitted in the current time window
 		case "Automatic":
-			if !ng.Disruptions.Automatic.Windows.IsAllowed(now) {
+			if !windowsAllowed(input, ngName, ng.Disruptions.Automatic.Windows, now) {
 				continue
 			}
 
 		case "RollingUpdate":
-			if !ng.Disruptions.RollingUpdate.Windows.IsAllowed(now) {
+			if !windowsAllowed(input, ngName, ng.Disruptions.RollingUpdate.Windows, now) {
 				continue
 			}
 		}
@@ -29,6 +29,9 @@ const (
 
 var _ = sdk.RegisterFunc(&go_hook.HookConfig{
 	Queue: internal.Queue(authRuleSnapshot),
+	Schedule: []go_hook.ScheduleConfig{
+		{Name: "expiration", Crontab: internal.ExpirationSchedule},
+	},
 	Kubernetes: []go_hook.KubernetesConfig{
 		{
 			Name:       authRuleSnapshot,
@@ -37,4 +40,4 @@ var _ = sdk.RegisterFunc(&go_hook.HookConfig{
 			FilterFunc: internal.ApplyAuthorizationRuleFilter,
 		},
 	},
-}, internal.AuthorizationRulesHandler("userAuthz.internal.authRuleCrds", authRuleSnapshot))
+}, internal.AuthorizationRulesHandler("userAuthz.internal.authRuleCrds", authRuleSnapshot, "deckhouse.io/v1alpha1", "AuthorizationRule"))
@@ -29,6 +29,9 @@ const (
 
 var _ = sdk.RegisterFunc(&go_hook.HookConfig{
 	Queue: internal.Queue(clusterAuthRuleSnapshot),
+	Schedule: []go_hook.ScheduleConfig{
+		{Name: "expiration", Crontab: internal.ExpirationSchedule},
+	},
 	Kubernetes: []go_hook.KubernetesConfig{
 		{
 			Name:       clusterAuthRuleSnapshot,
@@ -37,4 +40,4 @@ var _ = sdk.RegisterFunc(&go_hook.HookConfig{
 			FilterFunc: internal.ApplyAuthorizationRuleFilter,
 		},
 	},
-}, internal.AuthorizationRulesHandler("userAuthz.internal.clusterAuthRuleCrds", clusterAuthRuleSnapshot))
+}, internal.AuthorizationRulesHandler("userAuthz.internal.clusterAuthRuleCrds", clusterAuthRuleSnapshot, "deckhouse.io/v1", "ClusterAuthorizationRule"))
@@ -17,8 +17,15 @@ limitations under the License.
 package hooks
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	. "github.com/deckhouse/deckhouse/testing/hooks"
 )
@@ -75,4 +82,80 @@ var _ = Describe("User Authz hooks :: handle cluster authorization rules ::", fu
 			Expect(f.ValuesGet("userAuthz.internal.clusterAuthRuleCrds").String()).To(MatchJSON(`[{"name":"car0","spec":{"accessLevel":"ClusterEditor", "subjects":[{"kind":"Group", "name":"NotEveryone"}]}},{"name":"car1","spec":{"accessLevel":"ClusterAdmin", "subjects":[{"kind":"Group", "name":"Everyone"}]}}]`))
 		})
 	})
+
+	Context("Cluster with temporary CARs", func() {
+		BeforeEach(func() {
+			now := time.Now().UTC()
+			hour := time.Hour
+
+			f.BindingContexts.Set(f.KubeStateSet(fmt.Sprintf(`
+---
+apiVersion: deckhouse.io/v1
+kind: ClusterAuthorizationRule
+metadata:
+  name: pending
+spec:
+  accessLevel: ClusterAdmin
+  validFrom: %[1]s
+  subjects:
+  - kind: User
+    name: on-call
+---
+apiVersion: deckhouse.io/v1
+kind: ClusterAuthorizationRule
+metadata:
+  name: active
+spec:
+  accessLevel: ClusterAdmin
+  validFrom: %[2]s
+  validUntil: %[1]s
+  subjects:
+  - kind: User
+    name: on-call
+---
+apiVersion: deckhouse.io/v1
+kind: ClusterAuthorizationRule
+metadata:
+  name: expired
+spec:
+  accessLevel: ClusterAdmin
+  validUntil: %[2]s
+  subjects:
+  - kind: User
+    name: on-call
+status:
+  phase: Active
+`, now.Add(hour).Format(time.RFC3339), now.Add(-hour).Format(time.RFC3339))))
+			f.RunHook()
+		})
+
+		It("Only active CARs must be stored in values", func() {
+			Expect(f).To(ExecuteSuccessfully())
+			Expect(f.ValuesGet("userAuthz.internal.clusterAuthRuleCrds.#.name").String()).To(MatchJSON(`["active"]`))
+		})
+
+		It("Phases must be stored in the status", func() {
+			Expect(f.KubernetesGlobalResource("ClusterAuthorizationRule", "pending").Field("status.phase").String()).To(Equal("Pending"))
+			Expect(f.KubernetesGlobalResource("ClusterAuthorizationRule", "active").Field("status.phase").String()).To(Equal("Active"))
+			Expect(f.KubernetesGlobalResource("ClusterAuthorizationRule", "expired").Field("status.phase").String()).To(Equal("Expired"))
+		})
+
+		It("Grants and expirations must be recorded as events", func() {
+			events, err := f.BindingContextController.FakeCluster().Client.Dynamic().
+				Resource(schema.GroupVersionResource{Group: "events.k8s.io", Version: "v1", Resource: "events"}).
+				Namespace("default").List(context.Background(), metav1.ListOptions{})
+			Expect(err).ToNot(HaveOccurred())
+
+			reasons := make(map[string]string)
+			for _, event := range events.Items {
+				reason, _, _ := unstructured.NestedString(event.Object, "reason")
+				name, _, _ := unstructured.NestedString(event.Object, "regarding", "name")
+				reasons[name] = reason
+			}
+			Expect(reasons).To(Equal(map[string]string{
+				"active":  "AccessGranted",
+				"expired": "AccessExpired",
+			}))
+		})
+	})
 })
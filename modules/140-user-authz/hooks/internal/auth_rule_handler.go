@@ -18,16 +18,71 @@ package internal
 
 import (
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/flant/addon-operator/pkg/module_manager/go_hook"
+	"github.com/flant/shell-operator/pkg/kube/object_patch"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
 )
 
+// Phases of rules with the validity period
+const (
+	PhasePending = "Pending"
+	PhaseActive  = "Active"
+	PhaseExpired = "Expired"
+)
+
+// ExpirationSchedule is the schedule of the rules activation and expiration checks
+const ExpirationSchedule = "* * * * *"
+
 type authorizationRule struct {
 	Name      string                 `json:"name"`
 	Spec      map[string]interface{} `json:"spec"`
 	Namespace string                 `json:"namespace,omitempty"`
+
+	UID   types.UID `json:"-"`
+	Phase string    `json:"-"`
+}
+
+// phase returns the phase of the rule at the moment, it is empty for rules without the validity period
+func (ar *authorizationRule) phase(now time.Time) (string, error) {
+	validFrom, err := specTime(ar.Spec, "validFrom")
+	if err != nil {
+		return "", err
+	}
+	validUntil, err := specTime(ar.Spec, "validUntil")
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case validFrom.IsZero() && validUntil.IsZero():
+		return "", nil
+	case !validFrom.IsZero() && now.Before(validFrom):
+		return PhasePending, nil
+	case !validUntil.IsZero() && !now.Before(validUntil):
+		return PhaseExpired, nil
+	default:
+		return PhaseActive, nil
+	}
+}
+
+func specTime(spec map[string]interface{}, field string) (time.Time, error) {
+	value, ok := spec[field].(string)
+	if !ok || value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("cannot parse .spec.%s: %v", field, err)
+	}
+	return t, nil
 }
 
 func ApplyAuthorizationRuleFilter(obj *unstructured.Unstructured) (go_hook.FilterResult, error) {
@@ -39,22 +94,109 @@ func ApplyAuthorizationRuleFilter(obj *unstructured.Unstructured) (go_hook.Filte
 		return nil, err
 	}
 
+	phase, _, err := unstructured.NestedString(obj.Object, "status", "phase")
+	if err != nil {
+		return nil, err
+	}
+
 	car := &authorizationRule{
 		Name:      obj.GetName(),
 		Namespace: obj.GetNamespace(),
 		Spec:      spec,
+		UID:       obj.GetUID(),
+		Phase:     phase,
 	}
 
 	return car, nil
 }
 
-func AuthorizationRulesHandler(valuesPath, snapshotKey string) func(input *go_hook.HookInput) error {
+// AuthorizationRulesHandler stores rules in effect to values. Phases of rules with the validity period are stored
+// in their status, activations and expirations are recorded as events.
+func AuthorizationRulesHandler(valuesPath, snapshotKey, apiVersion, kind string) func(input *go_hook.HookInput) error {
 	return func(input *go_hook.HookInput) error {
-		input.Values.Set(valuesPath, snapshotsToAuthorizationRulesSlice(input.Snapshots[snapshotKey]))
+		now := time.Now()
+
+		rules := snapshotsToAuthorizationRulesSlice(input.Snapshots[snapshotKey])
+		activeRules := make([]authorizationRule, 0, len(rules))
+		for _, ar := range rules {
+			phase, err := ar.phase(now)
+			if err != nil {
+				input.LogEntry.Warnf("%s %s is skipped: %v", kind, ar.Name, err)
+				continue
+			}
+
+			if phase != ar.Phase {
+				updatePhase(input, apiVersion, kind, &ar, phase, now)
+			}
+
+			if phase == "" || phase == PhaseActive {
+				activeRules = append(activeRules, ar)
+			}
+		}
+
+		input.Values.Set(valuesPath, activeRules)
 		return nil
 	}
 }
 
+func updatePhase(input *go_hook.HookInput, apiVersion, kind string, ar *authorizationRule, phase string, now time.Time) {
+	var phaseValue interface{}
+	if phase != "" {
+		phaseValue = phase
+	}
+	patch := map[string]interface{}{
+		"status": map[string]interface{}{
+			"phase": phaseValue,
+		},
+	}
+	input.PatchCollector.MergePatch(patch, apiVersion, kind, ar.Namespace, ar.Name, object_patch.WithSubresource("/status"))
+
+	switch phase {
+	case PhaseActive:
+		validUntil, _ := ar.Spec["validUntil"].(string)
+		note := "Access is granted"
+		if validUntil != "" {
+			note += " until " + validUntil
+		}
+		input.PatchCollector.Create(ruleEvent(apiVersion, kind, ar, "AccessGranted", note, now), object_patch.IgnoreIfExists())
+	case PhaseExpired:
+		input.PatchCollector.Create(ruleEvent(apiVersion, kind, ar, "AccessExpired", "Access is revoked, the rule has expired", now), object_patch.IgnoreIfExists())
+	}
+}
+
+func ruleEvent(apiVersion, kind string, ar *authorizationRule, reason, note string, now time.Time) *eventsv1.Event {
+	namespace := ar.Namespace
+	if namespace == "" {
+		// Events of cluster-wide objects are stored in the default namespace
+		namespace = "default"
+	}
+
+	return &eventsv1.Event{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Event",
+			APIVersion: "events.k8s.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      ar.Name + "." + strconv.FormatInt(now.UnixNano(), 16),
+		},
+		Regarding: corev1.ObjectReference{
+			Kind:       kind,
+			Name:       ar.Name,
+			Namespace:  ar.Namespace,
+			UID:        ar.UID,
+			APIVersion: apiVersion,
+		},
+		Reason:              reason,
+		Note:                note,
+		Type:                corev1.EventTypeNormal,
+		EventTime:           metav1.MicroTime{Time: now},
+		Action:              "Authorize",
+		ReportingInstance:   "deckhouse",
+		ReportingController: "deckhouse",
+	}
+}
+
 func snapshotsToAuthorizationRulesSlice(snapshots []go_hook.FilterResult) []authorizationRule {
 	ars := make([]authorizationRule, 0, len(snapshots))
 	for _, snapshot := range snapshots {
@@ -38,7 +38,7 @@ type ClusterLogDestination struct {
 }
 
 type ClusterLogDestinationSpec struct {
-	// Type of cluster log source: Loki, Elasticsearch, Logstash, Vector
+	// Type of cluster log source: Loki, Elasticsearch, Logstash, Vector, Kafka, Splunk, S3, Syslog
 	Type string `json:"type,omitempty"`
 
 	// Loki describes spec for loki endpoint
@@ -59,6 +59,12 @@ type ClusterLogDestinationSpec struct {
 	// Vector spec for the Vector endpoint
 	Vector VectorSpec `json:"vector"`
 
+	// S3 spec for the S3-compatible object storage
+	S3 S3Spec `json:"s3"`
+
+	// Syslog spec for the syslog server
+	Syslog SyslogSpec `json:"syslog"`
+
 	// Add extra labels for sources
 	ExtraLabels map[string]string `json:"extraLabels,omitempty"`
 
@@ -69,6 +75,80 @@ type ClusterLogDestinationSpec struct {
 }
 
 type ClusterLogDestinationStatus struct {
+	// Conditions describe whether the destination is referenced, valid and delivering logs.
+	Conditions []ClusterLogDestinationCondition `json:"conditions,omitempty"`
+
+	// ReferencedBy lists logging configs which send logs to the destination.
+	ReferencedBy []LoggingConfigReference `json:"referencedBy,omitempty"`
+
+	// Delivery is the aggregated state of the destination sinks of all log-shipper agents.
+	Delivery *DeliveryStatus `json:"delivery,omitempty"`
+}
+
+type ClusterLogDestinationConditionType string
+
+const (
+	// DestinationConditionReferenced is true if at least one logging config sends logs to the destination.
+	DestinationConditionReferenced ClusterLogDestinationConditionType = "Referenced"
+	// DestinationConditionConfigValid is true if the vector config composed for the destination passed validation.
+	DestinationConditionConfigValid ClusterLogDestinationConditionType = "ConfigValid"
+	// DestinationConditionDelivering is true if agents send events to the destination without errors.
+	DestinationConditionDelivering ClusterLogDestinationConditionType = "Delivering"
+)
+
+type ConditionStatus string
+
+const (
+	ConditionTrue    ConditionStatus = "True"
+	ConditionFalse   ConditionStatus = "False"
+	ConditionUnknown ConditionStatus = "Unknown"
+)
+
+type ClusterLogDestinationCondition struct {
+	// Type is the type of the condition.
+	Type ClusterLogDestinationConditionType `json:"type"`
+	// Status is the status of the condition.
+	// Can be True, False, Unknown.
+	Status ConditionStatus `json:"status"`
+	// Last time the condition transitioned from one status to another.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Machine-readable reason of the last transition.
+	Reason string `json:"reason,omitempty"`
+	// Human-readable message indicating details about last transition.
+	Message string `json:"message,omitempty"`
+}
+
+type LoggingConfigReference struct {
+	// Kind is ClusterLoggingConfig or PodLoggingConfig.
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+type DeliveryStatus struct {
+	// Agents is the number of log-shipper agents whose metrics were collected.
+	Agents int `json:"agents"`
+	// SentEvents is the number of events sent by agents since they started.
+	SentEvents int64 `json:"sentEvents"`
+	// SentBytes is the number of bytes sent by agents since they started.
+	SentBytes int64 `json:"sentBytes"`
+	// Errors is the number of delivery errors since agents started.
+	Errors int64 `json:"errors"`
+	// LastError is the last observed delivery error.
+	LastError *DeliveryError `json:"lastError,omitempty"`
+	// UpdateTime is the time the counters were last changed.
+	UpdateTime metav1.Time `json:"updateTime,omitempty"`
+}
+
+type DeliveryError struct {
+	// Type of the error reported by vector, e.g., request_failed.
+	Type string `json:"type"`
+	// Stage of the pipeline where the error occurred, e.g., sending.
+	Stage string `json:"stage,omitempty"`
+	// Node is the node of the agent that reported the error.
+	Node string `json:"node,omitempty"`
+	// Time the error was observed.
+	Time metav1.Time `json:"time"`
 }
 
 type LokiAuthSpec struct {
@@ -193,6 +273,63 @@ type SplunkSpec struct {
 	TLS CommonTLSSpec `json:"tls,omitempty"`
 }
 
+type S3Spec struct {
+	// Endpoint of S3-compatible storage, e.g., MinIO. AWS endpoint is used if empty.
+	Endpoint string `json:"endpoint,omitempty"`
+
+	Bucket string `json:"bucket,omitempty"`
+	Region string `json:"region,omitempty"`
+
+	// KeyPrefix is a template of object key prefix, e.g., "{{ namespace }}/date=%F/"
+	KeyPrefix string `json:"keyPrefix,omitempty"`
+
+	Auth S3AuthSpec `json:"auth,omitempty"`
+
+	Compression S3Compression `json:"compression,omitempty"`
+
+	Batch BatchSpec `json:"batch,omitempty"`
+
+	TLS CommonTLSSpec `json:"tls,omitempty"`
+}
+
+type S3AuthSpec struct {
+	AccessKeyID     string `json:"accessKeyID,omitempty"`
+	SecretAccessKey string `json:"secretAccessKey,omitempty"`
+}
+
+type S3Compression = string
+
+const (
+	S3CompressionGzip S3Compression = "Gzip"
+	S3CompressionZstd S3Compression = "Zstd"
+	S3CompressionNone S3Compression = "None"
+)
+
+// BatchSpec configures how many events are collected before sending them in one request.
+type BatchSpec struct {
+	// The maximum size of a batch before it is flushed.
+	MaxSize resource.Quantity `json:"maxSize,omitempty"`
+
+	// The maximum number of events in a batch before it is flushed.
+	MaxEvents uint32 `json:"maxEvents,omitempty"`
+
+	// The maximum age of a batch before it is flushed.
+	TimeoutSeconds uint32 `json:"timeoutSeconds,omitempty"`
+}
+
+type SyslogSpec struct {
+	// Endpoint of the syslog server in the host:port format.
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// Facility of messages, e.g., local0.
+	Facility string `json:"facility,omitempty"`
+
+	// AppName overrides the APP-NAME field which is the container or unit name by default.
+	AppName string `json:"appName,omitempty"`
+
+	TLS CommonTLSSpec `json:"tls,omitempty"`
+}
+
 type Buffer struct {
 	// The type of buffer to use.
 	Type BufferType `json:"type,omitempty"`
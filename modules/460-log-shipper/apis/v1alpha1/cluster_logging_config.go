@@ -38,7 +38,7 @@ type ClusterLoggingConfig struct {
 }
 
 type ClusterLoggingConfigSpec struct {
-	// Type of cluster log source: KubernetesPods, File
+	// Type of cluster log source: KubernetesPods, File, Journald, KubernetesEvents
 	Type string `json:"type,omitempty"`
 
 	// KubernetesPods describes spec for kubernetes pod source
@@ -47,6 +47,12 @@ type ClusterLoggingConfigSpec struct {
 	// File describes spec for file source
 	File FileSpec `json:"file,omitempty"`
 
+	// Journald describes spec for systemd journal source
+	Journald JournaldSpec `json:"journald,omitempty"`
+
+	// KubernetesEvents describes spec for kubernetes events source
+	KubernetesEvents KubernetesEventsSpec `json:"kubernetesEvents,omitempty"`
+
 	// Filters
 	LogFilters   []Filter `json:"logFilter,omitempty"`
 	LabelFilters []Filter `json:"labelFilter,omitempty"`
@@ -79,3 +85,22 @@ type FileSpec struct {
 	Exclude       []string `json:"exclude,omitempty"`
 	LineDelimiter string   `json:"lineDelimiter,omitempty"`
 }
+
+type JournaldSpec struct {
+	IncludeUnits []string `json:"includeUnits,omitempty"`
+	ExcludeUnits []string `json:"excludeUnits,omitempty"`
+	// Priority is the lowest priority of records to collect, e.g., Warning
+	Priority string `json:"priority,omitempty"`
+}
+
+type KubernetesEventsSpec struct {
+	NamespaceSelector   EventsNamespaceSelector `json:"namespaceSelector,omitempty"`
+	Types               []string                `json:"types,omitempty"`
+	InvolvedObjectKinds []string                `json:"involvedObjectKinds,omitempty"`
+	Reasons             []string                `json:"reasons,omitempty"`
+}
+
+type EventsNamespaceSelector struct {
+	MatchNames   []string `json:"matchNames,omitempty"`
+	ExcludeNames []string `json:"excludeNames,omitempty"`
+}
@@ -23,9 +23,13 @@ const (
 	DestVector        = "Vector"
 	DestKafka         = "Kafka"
 	DestSplunk        = "Splunk"
+	DestS3            = "S3"
+	DestSyslog        = "Syslog"
 )
 
 const (
-	SourceKubernetesPods = "KubernetesPods"
-	SourceFile           = "File"
+	SourceKubernetesPods   = "KubernetesPods"
+	SourceFile             = "File"
+	SourceJournald         = "Journald"
+	SourceKubernetesEvents = "KubernetesEvents"
 )
@@ -0,0 +1,59 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debug
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/deckhouse/deckhouse/go_lib/debugbundle"
+)
+
+var (
+	clusterLogDestinationsGVR = schema.GroupVersionResource{Group: "deckhouse.io", Version: "v1alpha1", Resource: "clusterlogdestinations"}
+	clusterLoggingConfigsGVR  = schema.GroupVersionResource{Group: "deckhouse.io", Version: "v1alpha1", Resource: "clusterloggingconfigs"}
+	podLoggingConfigsGVR      = schema.GroupVersionResource{Group: "deckhouse.io", Version: "v1alpha1", Resource: "podloggingconfigs"}
+)
+
+func init() {
+	debugbundle.Register(debugbundle.Collector{
+		File: "cluster-log-destinations.json",
+		Collect: func(ctx context.Context, env *debugbundle.Env) ([]byte, error) {
+			return env.ListItems(ctx, clusterLogDestinationsGVR, "", metav1.ListOptions{})
+		},
+	})
+	debugbundle.Register(debugbundle.Collector{
+		File: "cluster-logging-configs.json",
+		Collect: func(ctx context.Context, env *debugbundle.Env) ([]byte, error) {
+			return env.ListItems(ctx, clusterLoggingConfigsGVR, "", metav1.ListOptions{})
+		},
+	})
+	debugbundle.Register(debugbundle.Collector{
+		File: "pod-logging-configs.json",
+		Collect: func(ctx context.Context, env *debugbundle.Env) ([]byte, error) {
+			return env.ListItems(ctx, podLoggingConfigsGVR, "", metav1.ListOptions{})
+		},
+	})
+	debugbundle.Register(debugbundle.Collector{
+		File: "agent-logs.txt",
+		Collect: func(ctx context.Context, env *debugbundle.Env) ([]byte, error) {
+			return env.PodLogs(ctx, "d8-log-shipper", "app=log-shipper-agent", "vector", 100)
+		},
+	})
+}
@@ -185,6 +185,7 @@ func generateConfig(input *go_hook.HookInput) error {
 	if len(input.Snapshots["namespace"]) < 1 {
 		// there is no namespace to manipulate the config map, the hook will create it later on afterHelm
 		input.Values.Set("logShipper.internal.activated", false)
+		input.Values.Set("logShipper.internal.eventsActivated", false)
 		return nil
 	}
 
@@ -226,19 +227,34 @@ func generateConfig(input *go_hook.HookInput) error {
 		destinations = append(destinations, *d)
 	}
 
-	configContent, err := composer.FromInput(input, destinations).Do()
+	cmp := composer.FromInput(input, destinations)
+
+	configContent, err := cmp.Do()
+	if err != nil {
+		return err
+	}
+
+	eventsConfigContent, err := cmp.DoEvents()
 	if err != nil {
 		return err
 	}
 
-	activated := len(configContent) != 0
-	input.Values.Set("logShipper.internal.activated", activated)
+	input.Values.Set("logShipper.internal.activated", len(configContent) != 0)
+	input.Values.Set("logShipper.internal.eventsActivated", len(eventsConfigContent) != 0)
+
+	updateConfigSecret(input, "d8-log-shipper-config", configContent)
+	updateConfigSecret(input, "d8-log-shipper-events-config", eventsConfigContent)
 
-	if !activated {
+	return nil
+}
+
+// updateConfigSecret stores the vector config to the secret or deletes the secret if the config is empty.
+func updateConfigSecret(input *go_hook.HookInput, name string, configContent []byte) {
+	if len(configContent) == 0 {
 		input.PatchCollector.Delete(
-			"v1", "Secret", "d8-log-shipper", "d8-log-shipper-config",
+			"v1", "Secret", "d8-log-shipper", name,
 			object_patch.InBackground())
-		return nil
+		return
 	}
 
 	secret := &corev1.Secret{
@@ -248,7 +264,7 @@ func generateConfig(input *go_hook.HookInput) error {
 			APIVersion: "v1",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "d8-log-shipper-config",
+			Name:      name,
 			Namespace: "d8-log-shipper",
 			Labels: map[string]string{
 				"heritage": "deckhouse",
@@ -283,8 +299,6 @@ func generateConfig(input *go_hook.HookInput) error {
 		ReportingController: "deckhouse",
 	}
 	input.PatchCollector.Create(event)
-
-	return nil
 }
 
 // migrateClusterLogDestinationLoki migrates ClusterLogDestination pointing to d8-loki.
@@ -139,7 +139,43 @@ spec:
 		Entry("File to Kafka with client certificate authentication", "file-to-kafka-tls"),
 		Entry("File to Loki", "file-to-loki"),
 		Entry("File to Splunk", "file-to-splunk"),
+		Entry("File to Syslog", "file-to-syslog"),
 		Entry("Two sources to single destination", "many-to-one"),
 		Entry("Throttle Transform with filter", "throttle-with-filter"),
+		Entry("Journald to Loki", "journald-to-loki"),
 	)
+
+	Context("Kubernetes Events source", func() {
+		BeforeEach(func() {
+			manifests, err := os.ReadFile(filepath.Join("testdata", "events-to-elastic", "manifests.yaml"))
+			Expect(err).To(BeNil())
+
+			f.BindingContexts.Set(f.KubeStateSet(namespaceManifest + string(manifests)))
+			f.RunHook()
+		})
+
+		It("Should render events to the singleton config only", func() {
+			Expect(f).To(ExecuteSuccessfully())
+
+			Expect(f.ValuesGet("logShipper.internal.activated").Bool()).To(BeFalse())
+			Expect(f.ValuesGet("logShipper.internal.eventsActivated").Bool()).To(BeTrue())
+			Expect(f.KubernetesResource("Secret", "d8-log-shipper", "d8-log-shipper-config").Exists()).To(BeFalse())
+
+			secret := f.KubernetesResource("Secret", "d8-log-shipper", "d8-log-shipper-events-config")
+			Expect(secret.Exists()).To(BeTrue())
+
+			config := secret.Field(`data`).Get("vector\\.json").String()
+			d, _ := base64.StdEncoding.DecodeString(config)
+
+			goldenFile := filepath.Join("testdata", "events-to-elastic", "result.json")
+			if os.Getenv("D8_LOG_SHIPPER_SAVE_TESTDATA") == "yes" {
+				err := os.WriteFile(goldenFile, d, 0600)
+				Expect(err).To(BeNil())
+			}
+
+			goldenFileData, err := os.ReadFile(goldenFile)
+			Expect(err).To(BeNil())
+			assert.JSONEq(GinkgoT(), string(goldenFileData), string(d))
+		})
+	})
 })
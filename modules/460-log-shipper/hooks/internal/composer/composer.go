@@ -70,7 +70,46 @@ func customResourceMetric(input *go_hook.HookInput, kind, name, namespace, _type
 	})
 }
 
+// ForDestination returns a composer limited to the destination with the passed name
+// and sources sending logs to it. It is used to validate destinations one by one.
+func (c *Composer) ForDestination(name string) *Composer {
+	res := &Composer{}
+
+	for _, d := range c.Dest {
+		if d.Name == name {
+			res.Dest = append(res.Dest, d)
+		}
+	}
+
+	for _, s := range c.Source {
+		for _, ref := range s.Spec.DestinationRefs {
+			if ref == name {
+				s.Spec.DestinationRefs = []string{name}
+				res.Source = append(res.Source, s)
+				break
+			}
+		}
+	}
+
+	return res
+}
+
+// Do composes the config for log-shipper agents.
+// KubernetesEvents sources are not collected by agents, see DoEvents.
 func (c *Composer) Do() ([]byte, error) {
+	return c.compose(func(sourceType string) bool {
+		return sourceType != v1alpha1.SourceKubernetesEvents
+	})
+}
+
+// DoEvents composes the config for the singleton collecting Kubernetes Events.
+func (c *Composer) DoEvents() ([]byte, error) {
+	return c.compose(func(sourceType string) bool {
+		return sourceType == v1alpha1.SourceKubernetesEvents
+	})
+}
+
+func (c *Composer) compose(sourceFilter func(sourceType string) bool) ([]byte, error) {
 	destinationRefs, err := c.composeDestinations()
 	if err != nil {
 		return nil, err
@@ -79,6 +118,10 @@ func (c *Composer) Do() ([]byte, error) {
 	file := NewVectorFile()
 
 	for _, s := range c.Source {
+		if !sourceFilter(s.Spec.Type) {
+			continue
+		}
+
 		transforms, err := transform.CreateLogSourceTransforms(s.Name, &transform.LogSourceConfig{
 			SourceType:            s.Spec.Type,
 			MultilineType:         s.Spec.MultiLineParser.Type,
@@ -145,6 +188,10 @@ func newLogSource(typ, name string, spec v1alpha1.ClusterLoggingConfigSpec) apis
 		return source.NewFile(name, spec.File)
 	case v1alpha1.SourceKubernetesPods:
 		return source.NewKubernetes(name, spec.KubernetesPods, false)
+	case v1alpha1.SourceJournald:
+		return source.NewJournald(name, spec.Journald)
+	case v1alpha1.SourceKubernetesEvents:
+		return source.NewKubernetesEvents(name, spec.KubernetesEvents)
 	}
 	return nil
 }
@@ -163,6 +210,10 @@ func newLogDest(typ, name string, spec v1alpha1.ClusterLogDestinationSpec) apis.
 		return destination.NewKafka(name, spec)
 	case v1alpha1.DestSplunk:
 		return destination.NewSplunk(name, spec)
+	case v1alpha1.DestS3:
+		return destination.NewS3(name, spec)
+	case v1alpha1.DestSyslog:
+		return destination.NewSyslog(name, spec)
 	}
 	return nil
 }
@@ -156,6 +156,56 @@ func TestConfig(t *testing.T) {
 				return src, dest
 			},
 		},
+		{
+			name:          "Config 5",
+			mockToCompare: "config_5.json",
+			buildFile: func() (apis.LogSource, apis.LogDestination) {
+				src := source.NewKubernetes("testsource", v1alpha1.KubernetesPodsSpec{}, false)
+
+				spec := v1alpha1.ClusterLogDestinationSpec{
+					S3: v1alpha1.S3Spec{
+						Endpoint:    "https://minio.storage:9000",
+						Bucket:      "logs",
+						KeyPrefix:   "{{ namespace }}/date=%F/",
+						Compression: v1alpha1.S3CompressionZstd,
+						Auth: v1alpha1.S3AuthSpec{
+							AccessKeyID:     "YWNjZXNza2V5",
+							SecretAccessKey: "c2VjcmV0a2V5",
+						},
+						Batch: v1alpha1.BatchSpec{
+							MaxSize:        *resource.NewQuantity(50*1024*1024, resource.BinarySI),
+							TimeoutSeconds: 60,
+						},
+						TLS: v1alpha1.CommonTLSSpec{VerifyHostname: pointer.Bool(false)},
+					},
+				}
+
+				dest := destination.NewS3("testoutput", spec)
+				return src, dest
+			},
+		},
+		{
+			name:          "Config 6",
+			mockToCompare: "config_6.json",
+			buildFile: func() (apis.LogSource, apis.LogDestination) {
+				src := source.NewFile("testfile", v1alpha1.FileSpec{
+					Include: []string{"/var/log/auth.log"},
+				})
+
+				spec := v1alpha1.ClusterLogDestinationSpec{
+					Syslog: v1alpha1.SyslogSpec{
+						Endpoint: "siem.example.com:6514",
+						Facility: "authpriv",
+						TLS: v1alpha1.CommonTLSSpec{
+							CAFile: "LS0tLS1CRUdJTiBDRVJUSUZJQ0FURS0tLS0tCk1JSUMwRENDQWJpZ0F3SUJBZ0lVU21UcEpRRVNKcGwwbkNRUGtIcG9PL3dzbGhVd0RRWUpLb1pJaHZjTkFRRUwKLS0tLS1FTkQgQ0VSVElGSUNBVEUtLS0tLQo=",
+						},
+					},
+				}
+
+				dest := destination.NewSyslog("testoutput", spec)
+				return src, dest
+			},
+		},
 	}
 
 	for _, tc := range tests {
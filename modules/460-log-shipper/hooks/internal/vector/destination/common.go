@@ -59,6 +59,16 @@ type Encoding struct {
 	CEF             CEFEncoding `json:"cef,omitempty"`
 }
 
+type Framing struct {
+	Method string `json:"method,omitempty"`
+}
+
+type Batch struct {
+	MaxBytes    uint32 `json:"max_bytes,omitempty"`
+	MaxEvents   uint32 `json:"max_events,omitempty"`
+	TimeoutSecs uint32 `json:"timeout_secs,omitempty"`
+}
+
 type CEFEncoding struct {
 	DeviceVendor       string            `json:"device_vendor,omitempty"`
 	DeviceProduct      string            `json:"device_product,omitempty"`
@@ -0,0 +1,136 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package destination
+
+import (
+	"github.com/deckhouse/deckhouse/go_lib/set"
+	"github.com/deckhouse/deckhouse/modules/460-log-shipper/apis/v1alpha1"
+)
+
+const (
+	s3DefaultKeyPrefix = "date=%F/"
+	s3DefaultRegion    = "us-east-1"
+)
+
+type S3 struct {
+	CommonSettings
+
+	Bucket    string `json:"bucket"`
+	KeyPrefix string `json:"key_prefix"`
+	Region    string `json:"region,omitempty"`
+	Endpoint  string `json:"endpoint,omitempty"`
+
+	Auth *S3Auth `json:"auth,omitempty"`
+
+	Encoding Encoding `json:"encoding"`
+	Framing  Framing  `json:"framing"`
+
+	Compression       string `json:"compression"`
+	FilenameExtension string `json:"filename_extension"`
+
+	Batch *Batch `json:"batch,omitempty"`
+
+	TLS CommonTLS `json:"tls"`
+}
+
+type S3Auth struct {
+	AccessKeyID     string `json:"access_key_id"`
+	SecretAccessKey string `json:"secret_access_key"`
+}
+
+func NewS3(name string, cspec v1alpha1.ClusterLogDestinationSpec) *S3 {
+	spec := cspec.S3
+
+	tls := CommonTLS{
+		CAFile:            decodeB64(spec.TLS.CAFile),
+		CertFile:          decodeB64(spec.TLS.CertFile),
+		KeyFile:           decodeB64(spec.TLS.KeyFile),
+		KeyPass:           decodeB64(spec.TLS.KeyPass),
+		VerifyCertificate: true,
+		VerifyHostname:    true,
+	}
+	if spec.TLS.VerifyCertificate != nil {
+		tls.VerifyCertificate = *spec.TLS.VerifyCertificate
+	}
+	if spec.TLS.VerifyHostname != nil {
+		tls.VerifyHostname = *spec.TLS.VerifyHostname
+	}
+
+	var auth *S3Auth
+	if spec.Auth.AccessKeyID != "" && spec.Auth.SecretAccessKey != "" {
+		auth = &S3Auth{
+			AccessKeyID:     decodeB64(spec.Auth.AccessKeyID),
+			SecretAccessKey: decodeB64(spec.Auth.SecretAccessKey),
+		}
+	}
+
+	keyPrefix := spec.KeyPrefix
+	if keyPrefix == "" {
+		keyPrefix = s3DefaultKeyPrefix
+	}
+
+	region := spec.Region
+	if region == "" {
+		region = s3DefaultRegion
+	}
+
+	// Objects are NDJSON files, the extension is set explicitly because vector omits the compression suffix in this case.
+	compression, extension := "gzip", "ndjson.gz"
+	switch spec.Compression {
+	case v1alpha1.S3CompressionZstd:
+		compression, extension = "zstd", "ndjson.zst"
+	case v1alpha1.S3CompressionNone:
+		compression, extension = "none", "ndjson"
+	}
+
+	return &S3{
+		CommonSettings: CommonSettings{
+			Name:   ComposeName(name),
+			Type:   "aws_s3",
+			Inputs: set.New(),
+			Buffer: buildVectorBuffer(cspec.Buffer),
+		},
+		Bucket:    spec.Bucket,
+		KeyPrefix: keyPrefix,
+		Region:    region,
+		Endpoint:  spec.Endpoint,
+		Auth:      auth,
+		Encoding: Encoding{
+			Codec:           "json",
+			TimestampFormat: "rfc3339",
+		},
+		Framing: Framing{
+			Method: "newline_delimited",
+		},
+		Compression:       compression,
+		FilenameExtension: extension,
+		Batch:             buildVectorBatch(spec.Batch),
+		TLS:               tls,
+	}
+}
+
+func buildVectorBatch(batch v1alpha1.BatchSpec) *Batch {
+	res := Batch{
+		MaxBytes:    uint32(batch.MaxSize.Value()),
+		MaxEvents:   batch.MaxEvents,
+		TimeoutSecs: batch.TimeoutSeconds,
+	}
+	if res == (Batch{}) {
+		return nil
+	}
+	return &res
+}
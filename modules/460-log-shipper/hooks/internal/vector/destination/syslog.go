@@ -0,0 +1,81 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package destination
+
+import (
+	"github.com/deckhouse/deckhouse/go_lib/set"
+	"github.com/deckhouse/deckhouse/modules/460-log-shipper/apis/v1alpha1"
+)
+
+// Syslog sends messages formatted by the syslog transform as RFC5424 lines over TCP.
+type Syslog struct {
+	CommonSettings
+
+	Address string `json:"address"`
+
+	Mode string `json:"mode"`
+
+	Encoding Encoding `json:"encoding"`
+	Framing  Framing  `json:"framing"`
+
+	TLS CommonTLS `json:"tls"`
+
+	Keepalive LogstashKeepalive `json:"keepalive,omitempty"`
+}
+
+func NewSyslog(name string, cspec v1alpha1.ClusterLogDestinationSpec) *Syslog {
+	spec := cspec.Syslog
+
+	tls := CommonTLS{
+		CAFile:            decodeB64(spec.TLS.CAFile),
+		CertFile:          decodeB64(spec.TLS.CertFile),
+		KeyFile:           decodeB64(spec.TLS.KeyFile),
+		KeyPass:           decodeB64(spec.TLS.KeyPass),
+		VerifyCertificate: true,
+		VerifyHostname:    true,
+	}
+	if spec.TLS.VerifyCertificate != nil {
+		tls.VerifyCertificate = *spec.TLS.VerifyCertificate
+	}
+	if spec.TLS.VerifyHostname != nil {
+		tls.VerifyHostname = *spec.TLS.VerifyHostname
+	}
+	if len(tls.CAFile) > 0 || len(tls.CertFile) > 0 {
+		tls.Enabled = true
+	}
+
+	return &Syslog{
+		CommonSettings: CommonSettings{
+			Name:   ComposeName(name),
+			Type:   "socket",
+			Inputs: set.New(),
+			Buffer: buildVectorBuffer(cspec.Buffer),
+		},
+		Address: spec.Endpoint,
+		Mode:    "tcp",
+		Encoding: Encoding{
+			Codec: "text",
+		},
+		Framing: Framing{
+			Method: "newline_delimited",
+		},
+		TLS: tls,
+		Keepalive: LogstashKeepalive{
+			TimeSecs: 7200,
+		},
+	}
+}
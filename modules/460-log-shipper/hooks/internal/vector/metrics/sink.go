@@ -0,0 +1,145 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"io"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+const (
+	sentEventsMetric = "vector_component_sent_events_total"
+	sentBytesMetric  = "vector_component_sent_event_bytes_total"
+	errorsMetric     = "vector_component_errors_total"
+)
+
+// SinkStats are counters of a single vector sink collected from the internal metrics of an agent.
+type SinkStats struct {
+	SentEvents int64
+	SentBytes  int64
+	Errors     int64
+
+	// ErrorsByType is the number of errors for each error type and stage pair.
+	ErrorsByType map[ErrorKey]int64
+}
+
+type ErrorKey struct {
+	Type  string
+	Stage string
+}
+
+// Add sums counters of another agent into the stats.
+func (s *SinkStats) Add(other *SinkStats) {
+	s.SentEvents += other.SentEvents
+	s.SentBytes += other.SentBytes
+	s.Errors += other.Errors
+
+	for k, v := range other.ErrorsByType {
+		if s.ErrorsByType == nil {
+			s.ErrorsByType = make(map[ErrorKey]int64)
+		}
+		s.ErrorsByType[k] += v
+	}
+}
+
+// TopError returns the error type with the largest number of occurrences.
+func (s *SinkStats) TopError() (ErrorKey, bool) {
+	var (
+		top   ErrorKey
+		count int64
+	)
+
+	for k, v := range s.ErrorsByType {
+		// Compare keys on equal counts to keep the result stable regardless of the map order.
+		if v > count || (v == count && v > 0 && k.Type+k.Stage < top.Type+top.Stage) {
+			top, count = k, v
+		}
+	}
+
+	return top, count > 0
+}
+
+// ParseSinkStats reads vector internal metrics in the Prometheus text format
+// and returns stats of sinks by their component ids.
+func ParseSinkStats(r io.Reader) (map[string]*SinkStats, error) {
+	var parser expfmt.TextParser
+
+	families, err := parser.TextToMetricFamilies(r)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]*SinkStats)
+
+	for _, name := range []string{sentEventsMetric, sentBytesMetric, errorsMetric} {
+		family, ok := families[name]
+		if !ok {
+			continue
+		}
+
+		for _, m := range family.GetMetric() {
+			labels := labelsMap(m.GetLabel())
+			if labels["component_kind"] != "sink" {
+				continue
+			}
+
+			stats, ok := res[labels["component_id"]]
+			if !ok {
+				stats = &SinkStats{}
+				res[labels["component_id"]] = stats
+			}
+
+			value := int64(metricValue(m))
+
+			switch name {
+			case sentEventsMetric:
+				stats.SentEvents += value
+			case sentBytesMetric:
+				stats.SentBytes += value
+			case errorsMetric:
+				stats.Errors += value
+				stats.Add(&SinkStats{ErrorsByType: map[ErrorKey]int64{
+					{Type: labels["error_type"], Stage: labels["stage"]}: value,
+				}})
+			}
+		}
+	}
+
+	return res, nil
+}
+
+func labelsMap(pairs []*dto.LabelPair) map[string]string {
+	res := make(map[string]string, len(pairs))
+	for _, p := range pairs {
+		res[p.GetName()] = p.GetValue()
+	}
+	return res
+}
+
+func metricValue(m *dto.Metric) float64 {
+	switch {
+	case m.GetCounter() != nil:
+		return m.GetCounter().GetValue()
+	case m.GetGauge() != nil:
+		return m.GetGauge().GetValue()
+	case m.GetUntyped() != nil:
+		return m.GetUntyped().GetValue()
+	}
+	return 0
+}
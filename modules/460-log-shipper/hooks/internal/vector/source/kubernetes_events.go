@@ -0,0 +1,85 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"github.com/deckhouse/deckhouse/modules/460-log-shipper/apis"
+	"github.com/deckhouse/deckhouse/modules/460-log-shipper/apis/v1alpha1"
+)
+
+// kubeEventsBinPath is a path to the binary in the vector image streaming Kubernetes Events as JSON lines.
+const kubeEventsBinPath = "/usr/bin/kube-events"
+
+var _ apis.LogSource = (*KubernetesEvents)(nil)
+
+// KubernetesEvents represents `exec` vector source running the kube-events binary.
+// https://vector.dev/docs/reference/configuration/sources/exec/
+//
+// Vector has no source for Kubernetes Events, and they have to be collected only once per cluster.
+// Thus, this source is rendered to the config of the singleton log-shipper-events Deployment, not agents.
+type KubernetesEvents struct {
+	commonSource
+
+	Mode          string          `json:"mode"`
+	Command       []string        `json:"command"`
+	IncludeStderr bool            `json:"include_stderr"`
+	Streaming     StreamingConfig `json:"streaming"`
+}
+
+type StreamingConfig struct {
+	RespawnOnExit       bool `json:"respawn_on_exit"`
+	RespawnIntervalSecs int  `json:"respawn_interval_secs"`
+}
+
+func NewKubernetesEvents(name string, spec v1alpha1.KubernetesEventsSpec) *KubernetesEvents {
+	command := []string{kubeEventsBinPath}
+
+	for _, ns := range spec.NamespaceSelector.MatchNames {
+		command = append(command, "--namespace="+ns)
+	}
+	for _, ns := range spec.NamespaceSelector.ExcludeNames {
+		command = append(command, "--exclude-namespace="+ns)
+	}
+	for _, t := range spec.Types {
+		command = append(command, "--type="+t)
+	}
+	for _, kind := range spec.InvolvedObjectKinds {
+		command = append(command, "--involved-object-kind="+kind)
+	}
+	for _, reason := range spec.Reasons {
+		command = append(command, "--reason="+reason)
+	}
+
+	return &KubernetesEvents{
+		commonSource: commonSource{
+			Name: "cluster_logging_config/" + name,
+			Type: "exec",
+		},
+		Mode:    "streaming",
+		Command: command,
+		// The binary writes its own logs to stderr, they must not be shipped as events
+		IncludeStderr: false,
+		Streaming: StreamingConfig{
+			RespawnOnExit:       true,
+			RespawnIntervalSecs: 5,
+		},
+	}
+}
+
+func (k *KubernetesEvents) BuildSources() []apis.LogSource {
+	return []apis.LogSource{k}
+}
@@ -67,6 +67,34 @@ func LocalTimezoneAfterSourceTransform() *DynamicTransform {
 	}
 }
 
+func JournaldSourceTransform() *DynamicTransform {
+	return &DynamicTransform{
+		CommonTransform: CommonTransform{
+			Name:   "journald",
+			Type:   "remap",
+			Inputs: set.New(),
+		},
+		DynamicArgsMap: map[string]interface{}{
+			"source":        vrl.JournaldRule.String(),
+			"drop_on_abort": false,
+		},
+	}
+}
+
+func KubernetesEventsSourceTransform() *DynamicTransform {
+	return &DynamicTransform{
+		CommonTransform: CommonTransform{
+			Name:   "kubernetes_events",
+			Type:   "remap",
+			Inputs: set.New(),
+		},
+		DynamicArgsMap: map[string]interface{}{
+			"source":        vrl.KubernetesEventsRule.String(),
+			"drop_on_abort": false,
+		},
+	}
+}
+
 type LogSourceConfig struct {
 	SourceType string
 
@@ -79,8 +107,13 @@ type LogSourceConfig struct {
 func CreateLogSourceTransforms(name string, cfg *LogSourceConfig) ([]apis.LogTransform, error) {
 	var transforms []apis.LogTransform
 
-	if cfg.SourceType == v1alpha1.SourceKubernetesPods {
+	switch cfg.SourceType {
+	case v1alpha1.SourceKubernetesPods:
 		transforms = append(transforms, OwnerReferenceSourceTransform())
+	case v1alpha1.SourceJournald:
+		transforms = append(transforms, JournaldSourceTransform())
+	case v1alpha1.SourceKubernetesEvents:
+		transforms = append(transforms, KubernetesEventsSourceTransform())
 	}
 
 	transforms = append(transforms, CleanUpAfterSourceTransform())
@@ -0,0 +1,48 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vrl
+
+// JournaldRule leaves only meaningful fields of journal records.
+//
+// Journal records contain dozens of trusted (_FIELD) and user (FIELD) fields.
+// The most useful of them are renamed to lowercase, the rest are dropped to not overload destinations.
+const JournaldRule Rule = `
+.unit = del(._SYSTEMD_UNIT)
+.syslog_identifier = del(.SYSLOG_IDENTIFIER)
+.priority = del(.PRIORITY)
+.node = get_env_var("VECTOR_SELF_NODE_NAME") ?? .host
+
+. = filter(.) -> |key, _value| {
+    !starts_with(key, "_") && upcase(key) != key
+}
+`
+
+// KubernetesEventsRule adds the namespace of the involved object to the record
+// and drops fields added by the exec source.
+const KubernetesEventsRule Rule = `
+event, err = parse_json(.message)
+if err == null {
+    namespace, err = get(event, ["involvedObject", "namespace"])
+    if err == null && is_string(namespace) {
+        .namespace = namespace
+    }
+}
+
+del(.command)
+del(.pid)
+del(.data_stream)
+`
@@ -0,0 +1,75 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vrl
+
+// SyslogRule formats the message as an RFC5424 syslog line.
+//
+// Severity is taken from the level field of a JSON message and defaults to informational.
+// Kubernetes metadata is passed as structured data, newlines are escaped to keep one record per line.
+const SyslogRule Rule = `
+parsed = .parsed_data
+if !exists(.parsed_data) {
+    parsed = parse_json(to_string(.message) ?? "") ?? null
+}
+
+level = "info"
+if is_object(parsed) {
+    parsed_level, err = get(parsed, ["level"])
+    if err == null && is_string(parsed_level) {
+        level = downcase(string!(parsed_level))
+    }
+}
+if level == "error" { level = "err" }
+if level == "warn" { level = "warning" }
+if level == "critical" || level == "fatal" { level = "crit" }
+if level == "informational" { level = "info" }
+severity = to_syslog_severity(level) ?? 6
+
+ts, err = parse_timestamp(.timestamp, format: "%+")
+if err != null {
+    ts = now()
+}
+
+hostname = string(.node) ?? string(.host) ?? "-"
+{{- if .appName }}
+app_name = {{ .appName | quote }}
+{{- else }}
+app_name = string(.container) ?? string(.unit) ?? "log-shipper"
+{{- end }}
+app_name = truncate(replace(app_name, " ", "_"), 48)
+proc_id = truncate(string(.pod) ?? "-", 128)
+
+structured_data = ""
+for_each(["namespace", "pod", "container", "node"]) -> |_index, key| {
+    value, err = get(., [key])
+    if err == null && value != null {
+        value = replace(replace(replace(to_string(value) ?? "", "\\", "\\\\"), "\"", "\\\""), "]", "\\]")
+        structured_data = structured_data + " " + key + "=\"" + value + "\""
+    }
+}
+if structured_data == "" {
+    structured_data = "-"
+} else {
+    structured_data = "[k8s@32473" + structured_data + "]"
+}
+
+message = replace(to_string(.message) ?? "", "\n", "\\n")
+
+header = "<" + to_string({{ .facility }} * 8 + severity) + ">1"
+timestamp = format_timestamp!(ts, format: "%Y-%m-%dT%H:%M:%S%.6f%:z")
+.message = header + " " + timestamp + " " + hostname + " " + app_name + " " + proc_id + " - " + structured_data + " " + message
+`
@@ -0,0 +1,440 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/flant/addon-operator/pkg/module_manager/go_hook"
+	"github.com/flant/addon-operator/sdk"
+	"github.com/flant/shell-operator/pkg/kube/object_patch"
+	"github.com/flant/shell-operator/pkg/kube_events_manager/types"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/deckhouse/deckhouse/go_lib/dependency"
+	d8http "github.com/deckhouse/deckhouse/go_lib/dependency/http"
+	"github.com/deckhouse/deckhouse/modules/460-log-shipper/apis/v1alpha1"
+	"github.com/deckhouse/deckhouse/modules/460-log-shipper/hooks/internal/composer"
+	"github.com/deckhouse/deckhouse/modules/460-log-shipper/hooks/internal/vector/destination"
+	"github.com/deckhouse/deckhouse/modules/460-log-shipper/hooks/internal/vector/metrics"
+)
+
+const (
+	// Port of the kube-rbac-proxy sidecar exposing vector internal metrics.
+	agentMetricsPort = "9254"
+	// Agents are scraped concurrently, the hook runs every minute, so all agents must be scraped in time.
+	agentScrapeConcurrency = 20
+	agentScrapeTimeout     = 5 * time.Second
+	agentsScrapeDeadline   = 30 * time.Second
+)
+
+type agentPod struct {
+	Name string
+	Node string
+	IP   string
+}
+
+func filterLogShipperAgentPod(obj *unstructured.Unstructured) (go_hook.FilterResult, error) {
+	var pod corev1.Pod
+
+	err := sdk.FromUnstructured(obj, &pod)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady && cond.Status == corev1.ConditionTrue {
+			return agentPod{Name: pod.Name, Node: pod.Spec.NodeName, IP: pod.Status.PodIP}, nil
+		}
+	}
+
+	// Metrics of not ready agents are not collected
+	return nil, nil
+}
+
+var _ = sdk.RegisterFunc(&go_hook.HookConfig{
+	Queue: "/modules/log-shipper/cluster_log_destination_status",
+	Schedule: []go_hook.ScheduleConfig{
+		{
+			Name:    "delivery",
+			Crontab: "* * * * *",
+		},
+	},
+	Kubernetes: []go_hook.KubernetesConfig{
+		{
+			Name:       "namespaced_log_source",
+			ApiVersion: "deckhouse.io/v1alpha1",
+			Kind:       "PodLoggingConfig",
+			FilterFunc: filterPodLoggingConfig,
+		},
+		{
+			Name:       "cluster_log_source",
+			ApiVersion: "deckhouse.io/v1alpha1",
+			Kind:       "ClusterLoggingConfig",
+			FilterFunc: filterClusterLoggingConfig,
+		},
+		{
+			Name:       "cluster_log_destination",
+			ApiVersion: "deckhouse.io/v1alpha1",
+			Kind:       "ClusterLogDestination",
+			FilterFunc: filterClusterLogDestination,
+		},
+		{
+			Name:       "agent_pods",
+			ApiVersion: "v1",
+			Kind:       "Pod",
+			NamespaceSelector: &types.NamespaceSelector{
+				NameSelector: &types.NameSelector{MatchNames: []string{
+					"d8-log-shipper",
+				}},
+			},
+			LabelSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{
+						Key:      "app",
+						Operator: metav1.LabelSelectorOpIn,
+						Values:   []string{"log-shipper-agent", "log-shipper-events"},
+					},
+				},
+			},
+			FilterFunc: filterLogShipperAgentPod,
+		},
+	},
+}, dependency.WithExternalDependencies(updateClusterLogDestinationStatus))
+
+// updateClusterLogDestinationStatus fills the status of ClusterLogDestinations to answer the question
+// "are my logs flowing?": which configs reference the destination, whether the config for it is valid,
+// and how many events agents sent to it according to vector internal metrics.
+func updateClusterLogDestinationStatus(input *go_hook.HookInput, dc dependency.Container) error {
+	destSnap := input.Snapshots["cluster_log_destination"]
+	if len(destSnap) == 0 {
+		return nil
+	}
+
+	cmp := &composer.Composer{}
+	references := make(map[string][]v1alpha1.LoggingConfigReference)
+
+	for _, s := range input.Snapshots["cluster_log_source"] {
+		src := s.(v1alpha1.ClusterLoggingConfig)
+		cmp.Source = append(cmp.Source, src)
+
+		for _, ref := range src.Spec.DestinationRefs {
+			references[ref] = append(references[ref], v1alpha1.LoggingConfigReference{
+				Kind: "ClusterLoggingConfig",
+				Name: src.Name,
+			})
+		}
+	}
+
+	for _, s := range input.Snapshots["namespaced_log_source"] {
+		src := s.(v1alpha1.PodLoggingConfig)
+		cmp.Source = append(cmp.Source, v1alpha1.NamespacedToCluster(src))
+
+		for _, ref := range src.Spec.ClusterDestinationRefs {
+			references[ref] = append(references[ref], v1alpha1.LoggingConfigReference{
+				Kind:      "PodLoggingConfig",
+				Name:      src.Name,
+				Namespace: src.Namespace,
+			})
+		}
+	}
+
+	for _, d := range destSnap {
+		cmp.Dest = append(cmp.Dest, d.(v1alpha1.ClusterLogDestination))
+	}
+
+	agents := collectAgentsSinkStats(input, dc)
+	now := time.Now().UTC()
+
+	for _, dest := range cmp.Dest {
+		destCmp := cmp.ForDestination(dest.Name)
+
+		_, validationErr := destCmp.Do()
+		if validationErr == nil {
+			_, validationErr = destCmp.DoEvents()
+		}
+
+		status := destinationStatus(dest, references[dest.Name], validationErr, agents, now)
+		if !statusChanged(dest.Status, status) {
+			continue
+		}
+
+		input.PatchCollector.MergePatch(
+			map[string]interface{}{"status": status},
+			"deckhouse.io/v1alpha1", "ClusterLogDestination", "", dest.Name,
+			object_patch.WithSubresource("/status"), object_patch.IgnoreMissingObject(),
+		)
+	}
+
+	return nil
+}
+
+type agentSinkStats struct {
+	node  string
+	sinks map[string]*metrics.SinkStats
+}
+
+// collectAgentsSinkStats scrapes internal metrics of ready log-shipper agents and the events collector concurrently.
+// Agents which cannot be scraped in time are skipped, the status is built on the rest of them.
+func collectAgentsSinkStats(input *go_hook.HookInput, dc dependency.Container) []agentSinkStats {
+	client := dc.GetHTTPClient(d8http.WithInsecureSkipVerify(), d8http.WithTimeout(agentScrapeTimeout))
+
+	ctx, cancel := context.WithTimeout(context.Background(), agentsScrapeDeadline)
+	defer cancel()
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		res = make([]agentSinkStats, 0, len(input.Snapshots["agent_pods"]))
+	)
+
+	sem := make(chan struct{}, agentScrapeConcurrency)
+
+	for _, p := range input.Snapshots["agent_pods"] {
+		if p == nil {
+			continue
+		}
+		pod := p.(agentPod)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				input.LogEntry.Warnf("cannot collect metrics of the %s log-shipper agent: %v", pod.Name, ctx.Err())
+				return
+			}
+
+			sinks, err := scrapeAgentSinkStats(ctx, client, pod)
+			if err != nil {
+				input.LogEntry.Warnf("cannot collect metrics of the %s log-shipper agent: %v", pod.Name, err)
+				return
+			}
+
+			mu.Lock()
+			res = append(res, agentSinkStats{node: pod.Node, sinks: sinks})
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	// the order affects the node of the last error if several agents have the same number of errors
+	sort.Slice(res, func(i, j int) bool { return res[i].node < res[j].node })
+
+	return res
+}
+
+func scrapeAgentSinkStats(ctx context.Context, client d8http.Client, pod agentPod) (map[string]*metrics.SinkStats, error) {
+	if pod.IP == "" {
+		return nil, fmt.Errorf("pod has no IP address")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://"+net.JoinHostPort(pod.IP, agentMetricsPort)+"/metrics", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = d8http.SetKubeAuthToken(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return metrics.ParseSinkStats(resp.Body)
+}
+
+func destinationStatus(
+	dest v1alpha1.ClusterLogDestination,
+	refs []v1alpha1.LoggingConfigReference,
+	validationErr error,
+	agents []agentSinkStats,
+	now time.Time,
+) v1alpha1.ClusterLogDestinationStatus {
+	prev := dest.Status
+
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Kind != refs[j].Kind {
+			return refs[i].Kind < refs[j].Kind
+		}
+		if refs[i].Namespace != refs[j].Namespace {
+			return refs[i].Namespace < refs[j].Namespace
+		}
+		return refs[i].Name < refs[j].Name
+	})
+
+	status := v1alpha1.ClusterLogDestinationStatus{ReferencedBy: refs}
+
+	if len(refs) > 0 {
+		status.Conditions = setCondition(status.Conditions, prev.Conditions, now, v1alpha1.DestinationConditionReferenced,
+			v1alpha1.ConditionTrue, "Referenced", fmt.Sprintf("Referenced by %d logging config(s).", len(refs)))
+	} else {
+		status.Conditions = setCondition(status.Conditions, prev.Conditions, now, v1alpha1.DestinationConditionReferenced,
+			v1alpha1.ConditionFalse, "NotReferenced", "No ClusterLoggingConfig or PodLoggingConfig sends logs to the destination.")
+	}
+
+	if validationErr != nil {
+		status.Conditions = setCondition(status.Conditions, prev.Conditions, now, v1alpha1.DestinationConditionConfigValid,
+			v1alpha1.ConditionFalse, "ValidationFailed", validationErr.Error())
+	} else {
+		status.Conditions = setCondition(status.Conditions, prev.Conditions, now, v1alpha1.DestinationConditionConfigValid,
+			v1alpha1.ConditionTrue, "Valid", "")
+	}
+
+	if len(agents) == 0 {
+		status.Conditions = setCondition(status.Conditions, prev.Conditions, now, v1alpha1.DestinationConditionDelivering,
+			v1alpha1.ConditionUnknown, "NoAgentMetrics", "Metrics of log-shipper agents are not available.")
+		// Keep the last known counters until agents are back
+		status.Delivery = prev.Delivery
+		return status
+	}
+
+	status.Delivery = deliveryStatus(destination.ComposeName(dest.Name), prev.Delivery, agents, now)
+
+	var newErrors int64
+	if prev.Delivery == nil || status.Delivery.Errors < prev.Delivery.Errors {
+		// Either the first observation or agents were restarted and counters were reset
+		newErrors = status.Delivery.Errors
+	} else {
+		newErrors = status.Delivery.Errors - prev.Delivery.Errors
+	}
+
+	switch {
+	case len(refs) == 0:
+		status.Conditions = setCondition(status.Conditions, prev.Conditions, now, v1alpha1.DestinationConditionDelivering,
+			v1alpha1.ConditionFalse, "NotReferenced", "Logs are not sent to the destination.")
+	case newErrors > 0:
+		lastErr := status.Delivery.LastError
+		status.Conditions = setCondition(status.Conditions, prev.Conditions, now, v1alpha1.DestinationConditionDelivering,
+			v1alpha1.ConditionFalse, "DeliveryErrors",
+			fmt.Sprintf("%d delivery error(s) since the last check, the last one is %q at the %q stage on the %q node.",
+				newErrors, lastErr.Type, lastErr.Stage, lastErr.Node))
+	case status.Delivery.SentEvents == 0:
+		status.Conditions = setCondition(status.Conditions, prev.Conditions, now, v1alpha1.DestinationConditionDelivering,
+			v1alpha1.ConditionUnknown, "NoEventsSent", "Agents have not sent any events to the destination yet.")
+	default:
+		status.Conditions = setCondition(status.Conditions, prev.Conditions, now, v1alpha1.DestinationConditionDelivering,
+			v1alpha1.ConditionTrue, "EventsSent", "")
+	}
+
+	return status
+}
+
+// statusChanged reports whether the status must be patched, the time of collecting counters is not compared
+// not to patch all destinations every minute while nothing happens
+func statusChanged(prev, status v1alpha1.ClusterLogDestinationStatus) bool {
+	if prev.Delivery != nil && status.Delivery != nil {
+		delivery := *status.Delivery
+		delivery.UpdateTime = prev.Delivery.UpdateTime
+		status.Delivery = &delivery
+	}
+
+	return !equality.Semantic.DeepEqual(prev, status)
+}
+
+// deliveryStatus sums sink counters of all agents. The last error is updated only if the number of errors grew.
+func deliveryStatus(sinkName string, prev *v1alpha1.DeliveryStatus, agents []agentSinkStats, now time.Time) *v1alpha1.DeliveryStatus {
+	res := &v1alpha1.DeliveryStatus{
+		Agents:     len(agents),
+		UpdateTime: metav1.NewTime(now),
+	}
+
+	var (
+		worstNode  string
+		worstStats *metrics.SinkStats
+	)
+
+	for _, agent := range agents {
+		stats, ok := agent.sinks[sinkName]
+		if !ok {
+			continue
+		}
+
+		res.SentEvents += stats.SentEvents
+		res.SentBytes += stats.SentBytes
+		res.Errors += stats.Errors
+
+		if stats.Errors > 0 && (worstStats == nil || stats.Errors > worstStats.Errors) {
+			worstNode, worstStats = agent.node, stats
+		}
+	}
+
+	if prev != nil {
+		res.LastError = prev.LastError
+	}
+
+	if worstStats != nil && (prev == nil || res.Errors != prev.Errors) {
+		if key, ok := worstStats.TopError(); ok {
+			res.LastError = &v1alpha1.DeliveryError{
+				Type:  key.Type,
+				Stage: key.Stage,
+				Node:  worstNode,
+				Time:  metav1.NewTime(now),
+			}
+		}
+	}
+
+	return res
+}
+
+// setCondition appends the condition to conditions keeping the transition time of the previous condition of the same type
+// if the status has not changed.
+func setCondition(
+	conditions, prev []v1alpha1.ClusterLogDestinationCondition,
+	now time.Time,
+	condType v1alpha1.ClusterLogDestinationConditionType,
+	status v1alpha1.ConditionStatus,
+	reason, message string,
+) []v1alpha1.ClusterLogDestinationCondition {
+	cond := v1alpha1.ClusterLogDestinationCondition{
+		Type:               condType,
+		Status:             status,
+		LastTransitionTime: metav1.NewTime(now),
+		Reason:             reason,
+		Message:            message,
+	}
+
+	for _, p := range prev {
+		if p.Type == condType && p.Status == status {
+			cond.LastTransitionTime = p.LastTransitionTime
+			break
+		}
+	}
+
+	return append(conditions, cond)
+}
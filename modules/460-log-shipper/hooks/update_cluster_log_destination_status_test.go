@@ -0,0 +1,288 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/deckhouse/deckhouse/go_lib/dependency"
+	. "github.com/deckhouse/deckhouse/testing/hooks"
+)
+
+const lokiStorageDestination = `
+---
+apiVersion: deckhouse.io/v1alpha1
+kind: ClusterLogDestination
+metadata:
+  name: loki-storage
+spec:
+  type: Loki
+  loki:
+    endpoint: http://loki.loki:3100
+`
+
+const statusTestManifests = `
+---
+apiVersion: deckhouse.io/v1alpha1
+kind: ClusterLogDestination
+metadata:
+  name: unused-storage
+spec:
+  type: Elasticsearch
+  elasticsearch:
+    endpoint: http://192.168.1.1:9200
+---
+apiVersion: deckhouse.io/v1alpha1
+kind: ClusterLoggingConfig
+metadata:
+  name: all-pods
+spec:
+  type: KubernetesPods
+  destinationRefs:
+    - loki-storage
+---
+apiVersion: deckhouse.io/v1alpha1
+kind: PodLoggingConfig
+metadata:
+  name: app
+  namespace: tests
+spec:
+  clusterDestinationRefs:
+    - loki-storage
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: log-shipper-agent-a
+  namespace: d8-log-shipper
+  labels:
+    app: log-shipper-agent
+spec:
+  nodeName: node-a
+status:
+  podIP: 10.0.0.1
+  conditions:
+  - type: Ready
+    status: "True"
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: log-shipper-agent-b
+  namespace: d8-log-shipper
+  labels:
+    app: log-shipper-agent
+spec:
+  nodeName: node-b
+status:
+  podIP: 10.0.0.2
+  conditions:
+  - type: Ready
+    status: "False"
+`
+
+const agentMetrics = `# TYPE vector_component_sent_events_total counter
+vector_component_sent_events_total{component_id="destination/cluster/loki-storage",component_kind="sink",component_type="loki",host="a"} 120
+vector_component_sent_events_total{component_id="cluster_logging_config/all-pods",component_kind="source",component_type="kubernetes_logs",host="a"} 500
+# TYPE vector_component_sent_event_bytes_total counter
+vector_component_sent_event_bytes_total{component_id="destination/cluster/loki-storage",component_kind="sink",component_type="loki",host="a"} 4096
+# TYPE vector_component_errors_total counter
+vector_component_errors_total{component_id="destination/cluster/loki-storage",component_kind="sink",component_type="loki",error_type="request_failed",stage="sending",host="a"} 3
+`
+
+var _ = Describe("Log shipper :: update cluster log destination status ::", func() {
+	f := HookExecutionConfigInit(`{"logShipper": {"internal": {"activated": false}}}`, ``)
+	f.RegisterCRD("deckhouse.io", "v1alpha1", "ClusterLoggingConfig", false)
+	f.RegisterCRD("deckhouse.io", "v1alpha1", "ClusterLogDestination", false)
+	f.RegisterCRD("deckhouse.io", "v1alpha1", "PodLoggingConfig", true)
+
+	os.Setenv("D8_IS_TESTS_ENVIRONMENT", "yes")
+
+	Context("Destinations with a ready agent", func() {
+		var requestedURLs []string
+
+		BeforeEach(func() {
+			requestedURLs = nil
+			dependency.TestDC.HTTPClient.DoMock.Set(func(req *http.Request) (*http.Response, error) {
+				requestedURLs = append(requestedURLs, req.URL.String())
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(agentMetrics)),
+				}, nil
+			})
+
+			f.KubeStateSet(lokiStorageDestination + statusTestManifests)
+			f.BindingContexts.Set(f.GenerateScheduleContext("* * * * *"))
+			f.RunHook()
+		})
+
+		It("Should fill in references, validation and delivery status", func() {
+			Expect(f).To(ExecuteSuccessfully())
+			Expect(requestedURLs).To(Equal([]string{"https://10.0.0.1:9254/metrics"}))
+
+			dest := f.KubernetesGlobalResource("ClusterLogDestination", "loki-storage")
+			Expect(dest.Field("status.referencedBy").String()).To(MatchJSON(`[
+				{"kind": "ClusterLoggingConfig", "name": "all-pods"},
+				{"kind": "PodLoggingConfig", "name": "app", "namespace": "tests"}
+			]`))
+			Expect(dest.Field(`status.conditions.#(type=="Referenced").status`).String()).To(Equal("True"))
+			Expect(dest.Field(`status.conditions.#(type=="ConfigValid").status`).String()).To(Equal("True"))
+
+			Expect(dest.Field("status.delivery.agents").Int()).To(BeEquivalentTo(1))
+			Expect(dest.Field("status.delivery.sentEvents").Int()).To(BeEquivalentTo(120))
+			Expect(dest.Field("status.delivery.sentBytes").Int()).To(BeEquivalentTo(4096))
+			Expect(dest.Field("status.delivery.errors").Int()).To(BeEquivalentTo(3))
+			Expect(dest.Field("status.delivery.lastError.type").String()).To(Equal("request_failed"))
+			Expect(dest.Field("status.delivery.lastError.stage").String()).To(Equal("sending"))
+			Expect(dest.Field("status.delivery.lastError.node").String()).To(Equal("node-a"))
+			Expect(dest.Field(`status.conditions.#(type=="Delivering").status`).String()).To(Equal("False"))
+			Expect(dest.Field(`status.conditions.#(type=="Delivering").reason`).String()).To(Equal("DeliveryErrors"))
+
+			unused := f.KubernetesGlobalResource("ClusterLogDestination", "unused-storage")
+			Expect(unused.Field("status.referencedBy").Exists()).To(BeFalse())
+			Expect(unused.Field(`status.conditions.#(type=="Referenced").status`).String()).To(Equal("False"))
+			Expect(unused.Field(`status.conditions.#(type=="Delivering").reason`).String()).To(Equal("NotReferenced"))
+		})
+	})
+
+	Context("No new errors since the previous run", func() {
+		BeforeEach(func() {
+			dependency.TestDC.HTTPClient.DoMock.Set(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(agentMetrics)),
+				}, nil
+			})
+
+			f.KubeStateSet(lokiStorageDestination + `status:
+  conditions:
+  - type: Delivering
+    status: "False"
+    reason: DeliveryErrors
+    lastTransitionTime: "2024-01-01T00:00:00Z"
+  delivery:
+    agents: 1
+    sentEvents: 100
+    sentBytes: 1024
+    errors: 3
+    lastError:
+      type: request_failed
+      stage: sending
+      node: node-a
+      time: "2024-01-01T00:00:00Z"
+` + statusTestManifests)
+			f.BindingContexts.Set(f.GenerateScheduleContext("* * * * *"))
+			f.RunHook()
+		})
+
+		It("Should mark the destination as delivering and keep the last error", func() {
+			Expect(f).To(ExecuteSuccessfully())
+
+			dest := f.KubernetesGlobalResource("ClusterLogDestination", "loki-storage")
+			Expect(dest.Field(`status.conditions.#(type=="Delivering").status`).String()).To(Equal("True"))
+			Expect(dest.Field("status.delivery.sentEvents").Int()).To(BeEquivalentTo(120))
+			Expect(dest.Field("status.delivery.lastError.time").String()).To(Equal("2024-01-01T00:00:00Z"))
+		})
+	})
+
+	Context("Status has not changed since the previous run", func() {
+		BeforeEach(func() {
+			dependency.TestDC.HTTPClient.DoMock.Set(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(agentMetrics)),
+				}, nil
+			})
+
+			f.KubeStateSet(lokiStorageDestination + `status:
+  referencedBy:
+  - kind: ClusterLoggingConfig
+    name: all-pods
+  - kind: PodLoggingConfig
+    name: app
+    namespace: tests
+  conditions:
+  - type: Referenced
+    status: "True"
+    reason: Referenced
+    message: Referenced by 2 logging config(s).
+    lastTransitionTime: "2024-01-01T00:00:00Z"
+  - type: ConfigValid
+    status: "True"
+    reason: Valid
+    lastTransitionTime: "2024-01-01T00:00:00Z"
+  - type: Delivering
+    status: "True"
+    reason: EventsSent
+    lastTransitionTime: "2024-01-01T00:00:00Z"
+  delivery:
+    agents: 1
+    sentEvents: 120
+    sentBytes: 4096
+    errors: 3
+    lastError:
+      type: request_failed
+      stage: sending
+      node: node-a
+      time: "2024-01-01T00:00:00Z"
+    updateTime: "2024-01-01T00:00:00Z"
+` + statusTestManifests)
+			f.BindingContexts.Set(f.GenerateScheduleContext("* * * * *"))
+			f.RunHook()
+		})
+
+		It("Should not patch the status", func() {
+			Expect(f).To(ExecuteSuccessfully())
+
+			dest := f.KubernetesGlobalResource("ClusterLogDestination", "loki-storage")
+			Expect(dest.Field("status.delivery.updateTime").String()).To(Equal("2024-01-01T00:00:00Z"))
+
+			unused := f.KubernetesGlobalResource("ClusterLogDestination", "unused-storage")
+			Expect(unused.Field("status.delivery.updateTime").Exists()).To(BeTrue())
+		})
+	})
+
+	Context("Agents metrics are not available", func() {
+		BeforeEach(func() {
+			dependency.TestDC.HTTPClient.DoMock.Set(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusForbidden,
+					Body:       io.NopCloser(bytes.NewBufferString("")),
+				}, nil
+			})
+
+			f.KubeStateSet(lokiStorageDestination + statusTestManifests)
+			f.BindingContexts.Set(f.GenerateScheduleContext("* * * * *"))
+			f.RunHook()
+		})
+
+		It("Should set unknown delivery status", func() {
+			Expect(f).To(ExecuteSuccessfully())
+
+			dest := f.KubernetesGlobalResource("ClusterLogDestination", "loki-storage")
+			Expect(dest.Field(`status.conditions.#(type=="Delivering").status`).String()).To(Equal("Unknown"))
+			Expect(dest.Field(`status.conditions.#(type=="Delivering").reason`).String()).To(Equal("NoAgentMetrics"))
+			Expect(dest.Field("status.delivery").Exists()).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,206 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// kube-events streams Kubernetes Events of the cluster to stdout as JSON lines.
+//
+// It is run by the `exec` source of the vector instance collecting Kubernetes Events.
+// Only events that appeared after the start are streamed, the existing ones are skipped
+// to not send them again after every restart.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+)
+
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringsFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// filter selects events to stream. Empty lists match everything.
+type filter struct {
+	excludeNamespaces   map[string]struct{}
+	types               map[string]struct{}
+	involvedObjectKinds map[string]struct{}
+	reasons             map[string]struct{}
+}
+
+func toSet(values []string) map[string]struct{} {
+	if len(values) == 0 {
+		return nil
+	}
+
+	res := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		res[v] = struct{}{}
+	}
+	return res
+}
+
+func matchSet(set map[string]struct{}, value string) bool {
+	if set == nil {
+		return true
+	}
+	_, ok := set[value]
+	return ok
+}
+
+func (f *filter) match(e *corev1.Event) bool {
+	if _, ok := f.excludeNamespaces[e.Namespace]; ok {
+		return false
+	}
+
+	return matchSet(f.types, e.Type) &&
+		matchSet(f.involvedObjectKinds, e.InvolvedObject.Kind) &&
+		matchSet(f.reasons, e.Reason)
+}
+
+type streamer struct {
+	filter *filter
+
+	mu  sync.Mutex
+	out *json.Encoder
+}
+
+func (s *streamer) write(obj interface{}) {
+	e, ok := obj.(*corev1.Event)
+	if !ok || !s.filter.match(e) {
+		return
+	}
+
+	// Objects from the informer cache have no type meta
+	e = e.DeepCopy()
+	e.APIVersion, e.Kind = "v1", "Event"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.out.Encode(e); err != nil {
+		log.Fatalf("cannot write the event: %v", err)
+	}
+}
+
+// handler streams added and modified events. Events of the initial list are skipped,
+// as well as updates without changes which are delivered after the informer relists events.
+func (s *streamer) handler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerDetailedFuncs{
+		AddFunc: func(obj interface{}, isInInitialList bool) {
+			if !isInInitialList {
+				s.write(obj)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldMeta, err := meta.Accessor(oldObj)
+			if err != nil {
+				return
+			}
+			newMeta, err := meta.Accessor(newObj)
+			if err != nil {
+				return
+			}
+			if oldMeta.GetResourceVersion() != newMeta.GetResourceVersion() {
+				s.write(newObj)
+			}
+		},
+	}
+}
+
+// stripManagedFields reduces the memory consumed by the informer cache, managed fields are not streamed anyway.
+func stripManagedFields(obj interface{}) (interface{}, error) {
+	if accessor, err := meta.Accessor(obj); err == nil {
+		accessor.SetManagedFields(nil)
+	}
+	return obj, nil
+}
+
+func main() {
+	var namespaces, excludeNamespaces, types, kinds, reasons stringsFlag
+
+	flag.Var(&namespaces, "namespace", "Namespace to stream events of, all namespaces if not set. Can be repeated.")
+	flag.Var(&excludeNamespaces, "exclude-namespace", "Namespace to skip events of. Can be repeated.")
+	flag.Var(&types, "type", "Type of events to stream. Can be repeated.")
+	flag.Var(&kinds, "involved-object-kind", "Kind of objects to stream events about. Can be repeated.")
+	flag.Var(&reasons, "reason", "Reason of events to stream. Can be repeated.")
+	flag.Parse()
+
+	log.SetOutput(os.Stderr)
+
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	s := &streamer{
+		filter: &filter{
+			excludeNamespaces:   toSet(excludeNamespaces),
+			types:               toSet(types),
+			involvedObjectKinds: toSet(kinds),
+			reasons:             toSet(reasons),
+		},
+		out: json.NewEncoder(os.Stdout),
+	}
+
+	if len(namespaces) == 0 {
+		// An empty namespace means events of all namespaces
+		namespaces = stringsFlag{""}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	for _, ns := range namespaces {
+		factory := informers.NewSharedInformerFactoryWithOptions(client, 0, informers.WithNamespace(ns))
+
+		informer := factory.Core().V1().Events().Informer()
+		if err := informer.SetTransform(stripManagedFields); err != nil {
+			log.Fatal(err)
+		}
+		if _, err := informer.AddEventHandler(s.handler()); err != nil {
+			log.Fatal(err)
+		}
+
+		factory.Start(ctx.Done())
+		defer factory.Shutdown()
+	}
+
+	<-ctx.Done()
+}
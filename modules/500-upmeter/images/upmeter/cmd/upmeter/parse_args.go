@@ -76,6 +76,17 @@ func parseServerArgs(cmd *kingpin.CmdClause, config *server.Config) {
 		Envar("UPMETER_USER_AGENT").
 		Default("Upmeter/1.0").
 		StringVar(&config.UserAgent)
+
+	// Monthly reports
+	cmd.Flag("reports-namespace", "Namespace to store monthly availability reports in ConfigMaps.").
+		Envar("UPMETER_REPORTS_NAMESPACE").
+		Default("d8-upmeter").
+		StringVar(&config.ReportsNamespace)
+
+	cmd.Flag("reports-history", "The number of monthly availability reports to keep.").
+		Envar("UPMETER_REPORTS_HISTORY").
+		Default("12").
+		IntVar(&config.ReportsHistory)
 }
 
 func parseAgentArgs(cmd *kingpin.CmdClause, config *agent.Config) {
@@ -137,6 +148,14 @@ func parseAgentArgs(cmd *kingpin.CmdClause, config *agent.Config) {
 	cmd.Flag("dynamic-probe-known-zoneprefix", "A known zone prefix for current cloud provider").
 		StringVar(&config.DynamicProbes.ZonePrefix)
 
+	// Targets of custom probes, addresses of the node are always denied
+	cmd.Flag("custom-probe-allowed-network", "CIDR of the network custom probes are limited to").
+		StringsVar(&config.CustomProbes.AllowedNetworks)
+	cmd.Flag("custom-probe-denied-network", "CIDR of the network custom probes must not connect to").
+		StringsVar(&config.CustomProbes.DeniedNetworks)
+	cmd.Flag("custom-probe-denied-port", "Port custom probes must not connect to").
+		IntsVar(&config.CustomProbes.DeniedPorts)
+
 	// User-Agent
 	// TODO generate from CI?
 	cmd.Flag("user-agent", "User Agent for HTTP client").
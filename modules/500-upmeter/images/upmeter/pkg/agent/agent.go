@@ -29,6 +29,7 @@ import (
 	"d8.io/upmeter/pkg/db"
 	dbcontext "d8.io/upmeter/pkg/db/context"
 	"d8.io/upmeter/pkg/kubernetes"
+	"d8.io/upmeter/pkg/monitor/customprobe"
 	"d8.io/upmeter/pkg/monitor/node"
 	"d8.io/upmeter/pkg/probe"
 	"d8.io/upmeter/pkg/probe/calculated"
@@ -44,6 +45,8 @@ type Agent struct {
 
 	sender    *sender.Sender
 	scheduler *scheduler.Scheduler
+
+	customProbeMonitor *customprobe.Monitor
 }
 
 type Config struct {
@@ -54,6 +57,7 @@ type Config struct {
 
 	DisabledProbes []string
 	DynamicProbes  *DynamicProbesConfig
+	CustomProbes   *CustomProbesConfig
 }
 
 type DynamicProbesConfig struct {
@@ -63,10 +67,18 @@ type DynamicProbesConfig struct {
 	ZonePrefix         string
 }
 
+// CustomProbesConfig restricts targets of UpmeterCustomProbe checks
+type CustomProbesConfig struct {
+	AllowedNetworks []string
+	DeniedNetworks  []string
+	DeniedPorts     []int
+}
+
 func NewConfig() *Config {
 	return &Config{
 		ClientConfig:  &sender.ClientConfig{},
 		DynamicProbes: &DynamicProbesConfig{},
+		CustomProbes:  &CustomProbesConfig{},
 	}
 }
 
@@ -86,6 +98,12 @@ func (a *Agent) Start(ctx context.Context) error {
 		return fmt.Errorf("cannot init access to Kubernetes cluster: %v", err)
 	}
 
+	targetPolicy, err := checker.ParseTargetPolicy(a.config.CustomProbes.AllowedNetworks, a.config.CustomProbes.DeniedNetworks, a.config.CustomProbes.DeniedPorts)
+	if err != nil {
+		return fmt.Errorf("cannot parse custom probes targets: %v", err)
+	}
+	checker.SetTargetPolicy(targetPolicy)
+
 	// Probe registry
 	ftr := probe.NewProbeFilter(a.config.DisabledProbes)
 	dynamicConfig := probe.DynamicConfig{
@@ -108,7 +126,16 @@ func (a *Agent) Start(ctx context.Context) error {
 
 	runnerLoader := probe.NewLoader(ftr, kubeAccess, nodeMon, dynamicConfig, controlPlanePreflight, a.logger)
 	calcLoader := calculated.NewLoader(ftr, a.logger)
-	registry := registry.New(runnerLoader, calcLoader)
+
+	// Custom probes are defined by UpmeterCustomProbe resources and can change at runtime
+	customProbes := probe.NewCustomProbes(ftr, runnerLoader.BuiltinGroups(), a.config.UserAgent, a.logger)
+	a.customProbeMonitor = customprobe.NewMonitor(kubeAccess.Kubernetes(), log.NewEntry(a.logger))
+	a.customProbeMonitor.Subscribe(customProbes)
+	if err := a.customProbeMonitor.Start(ctx); err != nil {
+		return fmt.Errorf("starting upmetercustomprobes.deckhouse.io monitor: %v", err)
+	}
+
+	registry := registry.New(runnerLoader, calcLoader, customProbes)
 
 	// Database connection with pool
 	dbctx, err := db.Connect(a.config.DatabasePath, dbcontext.DefaultConnectionOptions())
@@ -133,5 +160,6 @@ func (a *Agent) Start(ctx context.Context) error {
 func (a *Agent) Stop() error {
 	a.scheduler.Stop()
 	a.sender.Stop()
+	a.customProbeMonitor.Stop()
 	return nil
 }
@@ -25,6 +25,7 @@ import (
 	"d8.io/upmeter/pkg/check"
 	"d8.io/upmeter/pkg/db/dao"
 	"d8.io/upmeter/pkg/registry"
+	"d8.io/upmeter/pkg/set"
 )
 
 type Scheduler struct {
@@ -185,12 +186,30 @@ func (e *Scheduler) export(start time.Time) error {
 		series := e.series[id]
 		series.Clean()
 	}
+	e.forget()
 
 	e.send <- episodes
 
 	return nil
 }
 
+// forget drops results of probes that are not registered anymore, e.g. deleted custom probes, so
+// their episodes are not exported further.
+func (e *Scheduler) forget() {
+	registered := set.New()
+	for _, runner := range e.registry.Runners() {
+		registered.Add(runner.ProbeRef().Id())
+	}
+
+	for id := range e.results {
+		if registered.Has(id) {
+			continue
+		}
+		delete(e.results, id)
+		delete(e.series, id)
+	}
+}
+
 func (e *Scheduler) convert(start time.Time) ([]check.Episode, error) {
 	episodes := make([]check.Episode, 0, len(e.results))
 
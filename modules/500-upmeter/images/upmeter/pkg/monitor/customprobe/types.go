@@ -0,0 +1,67 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package customprobe
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Spec is the spec in the UpmeterCustomProbe CRD. Exactly one of HTTP, TCP or DNS is expected.
+type Spec struct {
+	Group           string    `json:"group"`
+	IntervalSeconds int       `json:"intervalSeconds"`
+	TimeoutSeconds  int       `json:"timeoutSeconds"`
+	HTTP            *HTTPSpec `json:"http,omitempty"`
+	TCP             *TCPSpec  `json:"tcp,omitempty"`
+	DNS             *DNSSpec  `json:"dns,omitempty"`
+}
+
+// HTTPSpec describes the check of an HTTP endpoint
+type HTTPSpec struct {
+	URL                 string `json:"url"`
+	Method              string `json:"method,omitempty"`
+	ExpectedStatusCodes []int  `json:"expectedStatusCodes,omitempty"`
+	BodyRegex           string `json:"bodyRegex,omitempty"`
+	InsecureSkipVerify  bool   `json:"insecureSkipVerify,omitempty"`
+}
+
+// TCPSpec describes the check of TCP connection establishment
+type TCPSpec struct {
+	Address string `json:"address"`
+}
+
+// DNSSpec describes the check of a domain name resolution
+type DNSSpec struct {
+	Name   string `json:"name"`
+	Server string `json:"server,omitempty"`
+}
+
+// CustomProbe is the Schema for user-defined probes
+type CustomProbe struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec Spec `json:"spec,omitempty"`
+}
+
+// CustomProbeList contains a list of CustomProbe objects
+type CustomProbeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []CustomProbe `json:"items"`
+}
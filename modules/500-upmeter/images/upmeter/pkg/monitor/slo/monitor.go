@@ -0,0 +1,141 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	kube "github.com/flant/kube-client/client"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+var gvr = schema.GroupVersionResource{
+	Group:    "deckhouse.io",
+	Version:  "v1alpha1",
+	Resource: "upmeterslos",
+}
+
+type Monitor struct {
+	kubeClient kube.Client
+	informer   cache.SharedInformer
+	stopCh     chan struct{}
+
+	logger *log.Entry
+}
+
+func NewMonitor(kubeClient kube.Client, logger *log.Entry) *Monitor {
+	var (
+		indexers     = cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}
+		resyncPeriod = 5 * time.Minute
+
+		tweakListOptions dynamicinformer.TweakListOptionsFunc = nil
+	)
+
+	informer := dynamicinformer.NewFilteredDynamicInformer(
+		kubeClient.Dynamic(), gvr, corev1.NamespaceAll, resyncPeriod, indexers, tweakListOptions)
+
+	return &Monitor{
+		kubeClient: kubeClient,
+		informer:   informer.Informer(),
+		stopCh:     make(chan struct{}),
+		logger:     logger.WithField("component", "upmeterslo-monitor"),
+	}
+}
+
+func (m *Monitor) Start(ctx context.Context) error {
+	if err := m.informer.SetWatchErrorHandler(cache.DefaultWatchErrorHandler); err != nil {
+		return fmt.Errorf("unable to set watch error handler: %w", err)
+	}
+
+	go m.informer.Run(m.stopCh)
+	if !cache.WaitForCacheSync(ctx.Done(), m.informer.HasSynced) {
+		return fmt.Errorf("unable to sync caches: %v", ctx.Err())
+	}
+	return nil
+}
+
+func (m *Monitor) Stop() {
+	close(m.stopCh)
+}
+
+func (m *Monitor) List() ([]*SLO, error) {
+	list := make([]*SLO, 0)
+	for _, obj := range m.informer.GetStore().List() {
+		slo, err := convert(obj)
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, slo)
+	}
+	return list, nil
+}
+
+// UpdateStatus replaces the status of the UpmeterSLO object
+func (m *Monitor) UpdateStatus(ctx context.Context, name string, status Status) error {
+	// Empty fields are set to null explicitly, otherwise the merge patch keeps the previous values
+	patch, err := json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{
+			"availability":       status.Availability,
+			"errorBudget":        status.ErrorBudget,
+			"burnRates":          status.BurnRates,
+			"exhaustionForecast": nullIfEmpty(status.ExhaustionForecast),
+			"calculationTime":    nullIfEmpty(status.CalculationTime),
+			"message":            nullIfEmpty(status.Message),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = m.kubeClient.Dynamic().Resource(gvr).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{}, "status")
+	if err != nil {
+		return fmt.Errorf("cannot patch status of UpmeterSLO %q: %v", name, err)
+	}
+	return nil
+}
+
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func convert(o interface{}) (*SLO, error) {
+	unstrObj, ok := o.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("cannot convert object to *unstructured.Unstructured: %v", o)
+	}
+	var slo SLO
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstrObj.UnstructuredContent(), &slo)
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert unstructured to UpmeterSLO: %v", err)
+	}
+	return &slo, nil
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slo
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Spec is the spec in the UpmeterSLO CRD
+type Spec struct {
+	Group string `json:"group"`
+	// Probe is optional, the group availability is used if it is empty
+	Probe string `json:"probe,omitempty"`
+	// Objective is the availability target in percents, e.g. 99.9
+	Objective  float64 `json:"objective"`
+	WindowDays int     `json:"windowDays,omitempty"`
+}
+
+// Status is the status in the UpmeterSLO CRD
+type Status struct {
+	// Availability in percents, it is empty if there is no data in the window
+	Availability       *float64     `json:"availability,omitempty"`
+	ErrorBudget        *ErrorBudget `json:"errorBudget,omitempty"`
+	BurnRates          []BurnRate   `json:"burnRates,omitempty"`
+	ExhaustionForecast string       `json:"exhaustionForecast,omitempty"`
+	CalculationTime    string       `json:"calculationTime,omitempty"`
+	Message            string       `json:"message,omitempty"`
+}
+
+type ErrorBudget struct {
+	TotalSeconds     int64   `json:"totalSeconds"`
+	ConsumedSeconds  int64   `json:"consumedSeconds"`
+	RemainingPercent float64 `json:"remainingPercent"`
+}
+
+// BurnRate is the ratio of the downtime share in the window to the error budget share,
+// the budget is exhausted exactly at the end of the SLO window if the rate is 1
+type BurnRate struct {
+	Window string  `json:"window"`
+	Rate   float64 `json:"rate"`
+}
+
+// SLO is the Schema for the upmeterslos.deckhouse.io
+type SLO struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   Spec   `json:"spec,omitempty"`
+	Status Status `json:"status,omitempty"`
+}
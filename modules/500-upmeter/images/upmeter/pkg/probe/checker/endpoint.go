@@ -0,0 +1,312 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"syscall"
+	"time"
+
+	"d8.io/upmeter/pkg/check"
+)
+
+// maxBodySize limits the response body read to match against the regular expression
+const maxBodySize = 1 << 20
+
+// dialControl is called for every connection of user-defined checks, it is replaced in tests to reach local servers
+var dialControl = denyTargets
+
+// interfaceAddrs returns addresses of the node, it is replaced in tests
+var interfaceAddrs = net.InterfaceAddrs
+
+// TargetPolicy restricts targets of user-defined checks in addition to node-local addresses
+type TargetPolicy struct {
+	// AllowedNetworks limit targets to the networks if not empty
+	AllowedNetworks []*net.IPNet
+	DeniedNetworks  []*net.IPNet
+	DeniedPorts     []int
+}
+
+// ParseTargetPolicy parses CIDRs of allowed and denied networks
+func ParseTargetPolicy(allowedNetworks, deniedNetworks []string, deniedPorts []int) (TargetPolicy, error) {
+	policy := TargetPolicy{DeniedPorts: deniedPorts}
+
+	var err error
+	policy.AllowedNetworks, err = parseCIDRs(allowedNetworks)
+	if err != nil {
+		return TargetPolicy{}, fmt.Errorf("allowed networks: %v", err)
+	}
+	policy.DeniedNetworks, err = parseCIDRs(deniedNetworks)
+	if err != nil {
+		return TargetPolicy{}, fmt.Errorf("denied networks: %v", err)
+	}
+
+	return policy, nil
+}
+
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// targetPolicy is set once on the agent start
+var targetPolicy TargetPolicy
+
+// SetTargetPolicy configures the restrictions of targets of user-defined checks
+func SetTargetPolicy(policy TargetPolicy) {
+	targetPolicy = policy
+}
+
+// denyTargets rejects connections to loopback and link-local addresses, to addresses of the node itself, and
+// to targets restricted by the target policy. Agents run in the host network namespace, so such targets would
+// expose node-local services, e.g., the kubelet API or the cloud metadata API. The resolved address is checked,
+// so domain names and HTTP redirects are covered as well.
+func denyTargets(_, address string, _ syscall.RawConn) error {
+	host, portArg, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	port, err := strconv.Atoi(portArg)
+	if ip == nil || err != nil {
+		return fmt.Errorf("unexpected address %q", address)
+	}
+
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+		return fmt.Errorf("connections to loopback and link-local addresses are not allowed: %s", host)
+	}
+
+	nodeAddrs, err := interfaceAddrs()
+	if err != nil {
+		return fmt.Errorf("cannot get node addresses: %v", err)
+	}
+	for _, addr := range nodeAddrs {
+		if network, ok := addr.(*net.IPNet); ok && network.IP.Equal(ip) {
+			return fmt.Errorf("connections to the node addresses are not allowed: %s", host)
+		}
+	}
+
+	for _, deniedPort := range targetPolicy.DeniedPorts {
+		if port == deniedPort {
+			return fmt.Errorf("connections to port %d are not allowed", port)
+		}
+	}
+
+	for _, network := range targetPolicy.DeniedNetworks {
+		if network.Contains(ip) {
+			return fmt.Errorf("connections to %s are not allowed: %s", network, host)
+		}
+	}
+
+	if len(targetPolicy.AllowedNetworks) == 0 {
+		return nil
+	}
+	for _, network := range targetPolicy.AllowedNetworks {
+		if network.Contains(ip) {
+			return nil
+		}
+	}
+	return fmt.Errorf("connections outside of the allowed networks are not allowed: %s", host)
+}
+
+func newEndpointDialer(timeout time.Duration) *net.Dialer {
+	return &net.Dialer{Timeout: timeout, Control: dialControl}
+}
+
+// HTTPEndpointAvailable is a checker constructor and configurator for user-defined HTTP endpoints.
+// The check fails if the endpoint does not respond in time, responds with an unexpected status
+// code, or the body does not match the regular expression.
+type HTTPEndpointAvailable struct {
+	URL                 string
+	Method              string
+	ExpectedStatusCodes []int
+	BodyRegex           *regexp.Regexp
+	InsecureSkipVerify  bool
+	UserAgent           string
+	Timeout             time.Duration
+}
+
+func (c HTTPEndpointAvailable) Checker() check.Checker {
+	method := c.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	codes := c.ExpectedStatusCodes
+	if len(codes) == 0 {
+		codes = []int{http.StatusOK}
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext:     newEndpointDialer(c.Timeout).DialContext,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: c.InsecureSkipVerify},
+			// Every check establishes a new connection to catch network and TLS issues
+			DisableKeepAlives: true,
+		},
+		Timeout: c.Timeout,
+	}
+
+	checker := &httpEndpointChecker{
+		client:    client,
+		url:       c.URL,
+		method:    method,
+		codes:     codes,
+		bodyRegex: c.BodyRegex,
+		userAgent: c.UserAgent,
+	}
+	return failOnError(withTimeout(checker, c.Timeout))
+}
+
+type httpEndpointChecker struct {
+	client    *http.Client
+	url       string
+	method    string
+	codes     []int
+	bodyRegex *regexp.Regexp
+	userAgent string
+}
+
+func (c *httpEndpointChecker) Check() check.Error {
+	req, err := http.NewRequest(c.method, c.url, nil)
+	if err != nil {
+		return check.ErrFail("cannot create request: %v", err)
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return check.ErrFail("cannot dial %q: %v", c.url, err)
+	}
+	defer resp.Body.Close()
+
+	if !c.expectedStatus(resp.StatusCode) {
+		return check.ErrFail("HTTP: %s %s returned unexpected status %d", c.method, c.url, resp.StatusCode)
+	}
+
+	if c.bodyRegex == nil {
+		return nil
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return check.ErrFail("cannot read response body: %v", err)
+	}
+	if !c.bodyRegex.Match(body) {
+		return check.ErrFail("HTTP: %s %s response body does not match %q", c.method, c.url, c.bodyRegex.String())
+	}
+	return nil
+}
+
+func (c *httpEndpointChecker) expectedStatus(code int) bool {
+	for _, expected := range c.codes {
+		if code == expected {
+			return true
+		}
+	}
+	return false
+}
+
+// TCPEndpointAvailable is a checker constructor and configurator for user-defined TCP endpoints.
+// The check fails if the TCP connection is not established in time.
+type TCPEndpointAvailable struct {
+	Address string
+	Timeout time.Duration
+}
+
+func (c TCPEndpointAvailable) Checker() check.Checker {
+	return &tcpEndpointChecker{
+		address: c.Address,
+		timeout: c.Timeout,
+	}
+}
+
+type tcpEndpointChecker struct {
+	address string
+	timeout time.Duration
+}
+
+func (c *tcpEndpointChecker) Check() check.Error {
+	conn, err := newEndpointDialer(c.timeout).Dial("tcp", c.address)
+	if err != nil {
+		return check.ErrFail("cannot connect to %q: %v", c.address, err)
+	}
+	_ = conn.Close()
+	return nil
+}
+
+// DNSNameResolvable is a checker constructor and configurator for user-defined domain names. The
+// check fails if the name is not resolved to at least one address in time. When the server is
+// specified, it is used instead of the system resolver.
+type DNSNameResolvable struct {
+	Name    string
+	Server  string
+	Timeout time.Duration
+}
+
+func (c DNSNameResolvable) Checker() check.Checker {
+	resolver := &net.Resolver{}
+	if c.Server != "" {
+		server := c.Server
+		resolver.PreferGo = true
+		resolver.Dial = func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return newEndpointDialer(c.Timeout).DialContext(ctx, network, server)
+		}
+	}
+
+	return &dnsNameChecker{
+		resolver: resolver,
+		name:     c.Name,
+		timeout:  c.Timeout,
+	}
+}
+
+type dnsNameChecker struct {
+	resolver *net.Resolver
+	name     string
+	timeout  time.Duration
+}
+
+func (c *dnsNameChecker) Check() check.Error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	addrs, err := c.resolver.LookupIPAddr(ctx, c.name)
+	if err != nil {
+		return check.ErrFail("cannot resolve %q: %v", c.name, err)
+	}
+	if len(addrs) == 0 {
+		return check.ErrFail("resolved no addresses for %q", c.name)
+	}
+	return nil
+}
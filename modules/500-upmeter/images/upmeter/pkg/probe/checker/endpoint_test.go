@@ -0,0 +1,228 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checker
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"d8.io/upmeter/pkg/check"
+)
+
+// allowLocalTargets lets checks reach test servers listening on the loopback interface
+func allowLocalTargets(t *testing.T) {
+	dialControl = nil
+	t.Cleanup(func() { dialControl = denyTargets })
+}
+
+func Test_HTTPEndpointAvailable(t *testing.T) {
+	allowLocalTargets(t)
+	timeout := 100 * time.Millisecond
+
+	respondWithBody := func(status int, body string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			rw.WriteHeader(status)
+			_, _ = rw.Write([]byte(body))
+		}))
+	}
+
+	tests := []struct {
+		name   string
+		server *httptest.Server
+		config HTTPEndpointAvailable
+		status check.Status
+	}{
+		{
+			name:   "200 is expected by default",
+			server: respondWith(200),
+			status: check.Up,
+		},
+		{
+			name:   "unexpected status fails",
+			server: respondWith(503),
+			status: check.Down,
+		},
+		{
+			name:   "status from the expected list",
+			server: respondWith(204),
+			config: HTTPEndpointAvailable{ExpectedStatusCodes: []int{200, 204}},
+			status: check.Up,
+		},
+		{
+			name:   "body matches regex",
+			server: respondWithBody(200, `{"status": "ok"}`),
+			config: HTTPEndpointAvailable{BodyRegex: regexp.MustCompile(`"status":\s*"ok"`)},
+			status: check.Up,
+		},
+		{
+			name:   "body does not match regex",
+			server: respondWithBody(200, `{"status": "degraded"}`),
+			config: HTTPEndpointAvailable{BodyRegex: regexp.MustCompile(`"status":\s*"ok"`)},
+			status: check.Down,
+		},
+		{
+			name:   "slow response fails",
+			server: respondSlowlyWith(2*timeout, 200),
+			status: check.Down,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer tt.server.Close()
+
+			config := tt.config
+			config.URL = tt.server.URL
+			config.Timeout = timeout
+
+			err := config.Checker().Check()
+
+			assertStatus(t, tt.status, err)
+		})
+	}
+}
+
+func Test_TCPEndpointAvailable(t *testing.T) {
+	allowLocalTargets(t)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("cannot listen: %v", err)
+	}
+	address := listener.Addr().String()
+
+	config := TCPEndpointAvailable{Address: address, Timeout: time.Second}
+
+	assertStatus(t, check.Up, config.Checker().Check())
+
+	listener.Close()
+	assertStatus(t, check.Down, config.Checker().Check())
+}
+
+func Test_DNSNameResolvable(t *testing.T) {
+	allowLocalTargets(t)
+	timeout := 200 * time.Millisecond
+
+	resolvable := DNSNameResolvable{Name: "localhost", Timeout: timeout}
+	assertStatus(t, check.Up, resolvable.Checker().Check())
+
+	// Nothing listens on the port of the closed listener
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("cannot listen: %v", err)
+	}
+	server := listener.LocalAddr().String()
+	listener.Close()
+
+	unresolvable := DNSNameResolvable{Name: "upmeter.invalid", Server: server, Timeout: timeout}
+	assertStatus(t, check.Down, unresolvable.Checker().Check())
+}
+
+func Test_LocalTargetsAreDenied(t *testing.T) {
+	server := respondWith(200)
+	defer server.Close()
+
+	httpConfig := HTTPEndpointAvailable{URL: server.URL, Timeout: time.Second}
+	assertStatus(t, check.Down, httpConfig.Checker().Check())
+
+	tcpConfig := TCPEndpointAvailable{Address: server.Listener.Addr().String(), Timeout: time.Second}
+	assertStatus(t, check.Down, tcpConfig.Checker().Check())
+
+	// Redirects to local targets are not followed
+	allowLocalTargets(t)
+	redirect := httptest.NewServer(http.RedirectHandler("http://169.254.169.254/latest/meta-data/", http.StatusFound))
+	defer redirect.Close()
+	dialControl = func(network, address string, c syscall.RawConn) error {
+		if address == redirect.Listener.Addr().String() {
+			return nil
+		}
+		return denyTargets(network, address, c)
+	}
+	redirectConfig := HTTPEndpointAvailable{URL: redirect.URL, Timeout: time.Second}
+	err := redirectConfig.Checker().Check()
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "not allowed")
+	}
+}
+
+func Test_denyTargets(t *testing.T) {
+	interfaceAddrs = func() ([]net.Addr, error) {
+		return []net.Addr{&net.IPNet{IP: net.ParseIP("192.168.1.10"), Mask: net.CIDRMask(24, 32)}}, nil
+	}
+	policy, err := ParseTargetPolicy([]string{"10.0.0.0/8", "93.184.216.0/24", "2001:db8::/32"}, []string{"10.222.0.0/16"}, []int{10250})
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetTargetPolicy(policy)
+	t.Cleanup(func() {
+		interfaceAddrs = net.InterfaceAddrs
+		SetTargetPolicy(TargetPolicy{})
+	})
+
+	tests := []struct {
+		address string
+		denied  bool
+	}{
+		{address: "127.0.0.1:80", denied: true},
+		{address: "127.10.0.1:10250", denied: true},
+		{address: "[::1]:80", denied: true},
+		{address: "169.254.169.254:80", denied: true},
+		{address: "[fe80::1]:80", denied: true},
+		{address: "0.0.0.0:80", denied: true},
+		{address: "[::]:80", denied: true},
+		{address: "10.0.0.1:443", denied: false},
+		{address: "93.184.216.34:443", denied: false},
+		{address: "[2001:db8::1]:443", denied: false},
+		// node address
+		{address: "192.168.1.10:443", denied: true},
+		// denied port
+		{address: "10.0.0.1:10250", denied: true},
+		// denied network
+		{address: "10.222.0.1:443", denied: true},
+		// outside of the allowed networks
+		{address: "192.168.1.11:443", denied: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			err := denyTargets("tcp", tt.address, nil)
+			if tt.denied {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func assertStatus(t *testing.T, expected check.Status, err check.Error) {
+	t.Helper()
+
+	if expected == check.Up {
+		assert.Nil(t, err)
+		return
+	}
+	if assert.NotNil(t, err) {
+		assert.Equal(t, expected, err.Status(), err.Error())
+	}
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package probe
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"d8.io/upmeter/pkg/check"
+	"d8.io/upmeter/pkg/monitor/customprobe"
+	"d8.io/upmeter/pkg/probe/checker"
+	"d8.io/upmeter/pkg/set"
+)
+
+const (
+	defaultCustomGroup    = "custom"
+	defaultCustomInterval = 30 * time.Second
+	defaultCustomTimeout  = 5 * time.Second
+)
+
+// CustomProbes keeps runners of UpmeterCustomProbe resources. It is the handler for the custom probe
+// monitor, and it serves both as the dynamic runner source for the scheduler and as the probe lister.
+type CustomProbes struct {
+	filter    Filter
+	reserved  set.StringSet
+	userAgent string
+	logger    *logrus.Logger
+
+	mu      sync.RWMutex
+	runners map[string]customRunner // by resource name
+}
+
+type customRunner struct {
+	generation int64
+	runner     *check.Runner
+}
+
+// NewCustomProbes creates the custom probes registry. Reserved groups belong to built-in probes and
+// cannot be used by custom probes.
+func NewCustomProbes(filter Filter, reserved []string, userAgent string, logger *logrus.Logger) *CustomProbes {
+	return &CustomProbes{
+		filter:    filter,
+		reserved:  set.New(reserved...),
+		userAgent: userAgent,
+		logger:    logger,
+		runners:   make(map[string]customRunner),
+	}
+}
+
+func (c *CustomProbes) OnAdd(cp *customprobe.CustomProbe) {
+	c.set(cp)
+}
+
+func (c *CustomProbes) OnModify(cp *customprobe.CustomProbe) {
+	c.set(cp)
+}
+
+func (c *CustomProbes) OnDelete(cp *customprobe.CustomProbe) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.runners[cp.Name]; ok {
+		delete(c.runners, cp.Name)
+		c.logger.Infof("Unregister custom probe %q", cp.Name)
+	}
+}
+
+func (c *CustomProbes) set(cp *customprobe.CustomProbe) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Periodic resyncs do not change the spec, the runner keeps its schedule then
+	if prev, ok := c.runners[cp.Name]; ok && prev.generation == cp.Generation {
+		return
+	}
+
+	// The previous runner is removed anyway, the probe might have become invalid or disabled
+	delete(c.runners, cp.Name)
+
+	rc, err := c.runnerConfig(cp)
+	if err != nil {
+		c.logger.Errorf("Skipping custom probe %q: %v", cp.Name, err)
+		return
+	}
+	if !c.filter.Enabled(rc.Ref()) {
+		return
+	}
+
+	runnerLogger := c.logger.WithFields(map[string]interface{}{
+		"group": rc.group,
+		"probe": rc.probe,
+		"check": rc.check,
+	})
+	runner := check.NewRunner(rc.group, rc.probe, rc.check, rc.period, rc.config.Checker(), runnerLogger)
+	c.runners[cp.Name] = customRunner{generation: cp.Generation, runner: runner}
+	c.logger.Infof("Register custom probe %s", runner.ProbeRef().Id())
+}
+
+func (c *CustomProbes) runnerConfig(cp *customprobe.CustomProbe) (runnerConfig, error) {
+	spec := cp.Spec
+
+	group := spec.Group
+	if group == "" {
+		group = defaultCustomGroup
+	}
+	if c.reserved.Has(group) {
+		return runnerConfig{}, fmt.Errorf("group %q is reserved for built-in probes", group)
+	}
+
+	period := defaultCustomInterval
+	if spec.IntervalSeconds > 0 {
+		period = time.Duration(spec.IntervalSeconds) * time.Second
+	}
+	timeout := defaultCustomTimeout
+	if spec.TimeoutSeconds > 0 {
+		timeout = time.Duration(spec.TimeoutSeconds) * time.Second
+	}
+
+	rc := runnerConfig{
+		group:  group,
+		probe:  cp.Name,
+		check:  "_",
+		period: period,
+	}
+
+	switch {
+	case spec.HTTP != nil:
+		var bodyRegex *regexp.Regexp
+		if spec.HTTP.BodyRegex != "" {
+			re, err := regexp.Compile(spec.HTTP.BodyRegex)
+			if err != nil {
+				return runnerConfig{}, fmt.Errorf("invalid body regex: %v", err)
+			}
+			bodyRegex = re
+		}
+		rc.config = checker.HTTPEndpointAvailable{
+			URL:                 spec.HTTP.URL,
+			Method:              spec.HTTP.Method,
+			ExpectedStatusCodes: spec.HTTP.ExpectedStatusCodes,
+			BodyRegex:           bodyRegex,
+			InsecureSkipVerify:  spec.HTTP.InsecureSkipVerify,
+			UserAgent:           c.userAgent,
+			Timeout:             timeout,
+		}
+	case spec.TCP != nil:
+		rc.config = checker.TCPEndpointAvailable{
+			Address: spec.TCP.Address,
+			Timeout: timeout,
+		}
+	case spec.DNS != nil:
+		rc.config = checker.DNSNameResolvable{
+			Name:    spec.DNS.Name,
+			Server:  spec.DNS.Server,
+			Timeout: timeout,
+		}
+	default:
+		return runnerConfig{}, fmt.Errorf("none of http, tcp, or dns checks is specified")
+	}
+
+	return rc, nil
+}
+
+// Runners returns the current set of custom probe runners
+func (c *CustomProbes) Runners() []*check.Runner {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	runners := make([]*check.Runner, 0, len(c.runners))
+	for _, cr := range c.runners {
+		runners = append(runners, cr.runner)
+	}
+	return runners
+}
+
+func (c *CustomProbes) Groups() []string {
+	groups := set.New()
+	for _, runner := range c.Runners() {
+		groups.Add(runner.ProbeRef().Group)
+	}
+	return groups.Slice()
+}
+
+func (c *CustomProbes) Probes() []check.ProbeRef {
+	runners := c.Runners()
+	refs := make([]check.ProbeRef, 0, len(runners))
+	for _, runner := range runners {
+		refs = append(refs, runner.ProbeRef())
+	}
+	sort.Sort(check.ByProbeRef(refs))
+	return refs
+}
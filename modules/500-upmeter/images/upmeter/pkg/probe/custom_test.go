@@ -0,0 +1,104 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package probe
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"d8.io/upmeter/pkg/check"
+	"d8.io/upmeter/pkg/monitor/customprobe"
+)
+
+func TestCustomProbes(t *testing.T) {
+	newCustomProbe := func(name string, generation int64, spec customprobe.Spec) *customprobe.CustomProbe {
+		return &customprobe.CustomProbe{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Generation: generation},
+			Spec:       spec,
+		}
+	}
+
+	httpSpec := &customprobe.HTTPSpec{URL: "https://shop.example.com/healthz"}
+	tcpSpec := &customprobe.TCPSpec{Address: "db.example.com:5432"}
+	dnsSpec := &customprobe.DNSSpec{Name: "example.com"}
+
+	t.Run("probes are registered in their groups", func(t *testing.T) {
+		cps := NewCustomProbes(NewProbeFilter(nil), []string{"control-plane"}, "upmeter", newDummyLogger().Logger)
+
+		cps.OnAdd(newCustomProbe("shop", 1, customprobe.Spec{Group: "shop", HTTP: httpSpec}))
+		cps.OnAdd(newCustomProbe("db", 1, customprobe.Spec{Group: "shop", TCP: tcpSpec}))
+		cps.OnAdd(newCustomProbe("dns", 1, customprobe.Spec{DNS: dnsSpec}))
+
+		assert.Len(t, cps.Runners(), 3)
+		assert.Equal(t, []string{"custom", "shop"}, cps.Groups())
+		assert.Equal(t, []check.ProbeRef{
+			{Group: "custom", Probe: "dns"},
+			{Group: "shop", Probe: "db"},
+			{Group: "shop", Probe: "shop"},
+		}, cps.Probes())
+	})
+
+	t.Run("interval defaults to 30 seconds", func(t *testing.T) {
+		cps := NewCustomProbes(NewProbeFilter(nil), nil, "upmeter", newDummyLogger().Logger)
+
+		cps.OnAdd(newCustomProbe("default", 1, customprobe.Spec{TCP: tcpSpec}))
+		cps.OnAdd(newCustomProbe("frequent", 1, customprobe.Spec{IntervalSeconds: 5, TCP: tcpSpec}))
+
+		periods := map[string]time.Duration{}
+		for _, runner := range cps.Runners() {
+			periods[runner.ProbeRef().Probe] = runner.Period()
+		}
+		assert.Equal(t, map[string]time.Duration{"default": 30 * time.Second, "frequent": 5 * time.Second}, periods)
+	})
+
+	t.Run("invalid, disabled and reserved probes are skipped", func(t *testing.T) {
+		cps := NewCustomProbes(NewProbeFilter([]string{"disabled"}), []string{"control-plane"}, "upmeter", newDummyLogger().Logger)
+
+		cps.OnAdd(newCustomProbe("reserved", 1, customprobe.Spec{Group: "control-plane", HTTP: httpSpec}))
+		cps.OnAdd(newCustomProbe("disabled", 1, customprobe.Spec{Group: "disabled", HTTP: httpSpec}))
+		cps.OnAdd(newCustomProbe("empty", 1, customprobe.Spec{}))
+		cps.OnAdd(newCustomProbe("regex", 1, customprobe.Spec{HTTP: &customprobe.HTTPSpec{URL: "http://x", BodyRegex: "("}}))
+
+		assert.Empty(t, cps.Runners())
+	})
+
+	t.Run("probes are replaced on spec change and removed on deletion", func(t *testing.T) {
+		cps := NewCustomProbes(NewProbeFilter(nil), nil, "upmeter", newDummyLogger().Logger)
+
+		cps.OnAdd(newCustomProbe("shop", 1, customprobe.Spec{HTTP: httpSpec}))
+		first := cps.Runners()[0]
+
+		// resync
+		cps.OnModify(newCustomProbe("shop", 1, customprobe.Spec{HTTP: httpSpec}))
+		assert.Same(t, first, cps.Runners()[0])
+
+		// spec change moves the probe to another group
+		cps.OnModify(newCustomProbe("shop", 2, customprobe.Spec{Group: "shop", HTTP: httpSpec}))
+		assert.Equal(t, []check.ProbeRef{{Group: "shop", Probe: "shop"}}, cps.Probes())
+
+		// spec change breaks the probe
+		cps.OnModify(newCustomProbe("shop", 3, customprobe.Spec{Group: "shop"}))
+		assert.Empty(t, cps.Runners())
+
+		cps.OnModify(newCustomProbe("shop", 4, customprobe.Spec{Group: "shop", HTTP: httpSpec}))
+		cps.OnDelete(newCustomProbe("shop", 4, customprobe.Spec{Group: "shop", HTTP: httpSpec}))
+		assert.Empty(t, cps.Runners())
+	})
+}
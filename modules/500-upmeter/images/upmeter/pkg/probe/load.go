@@ -110,6 +110,15 @@ func (l *Loader) Groups() []string {
 	return l.groups
 }
 
+// BuiltinGroups returns groups of all compiled-in probes including disabled ones
+func (l *Loader) BuiltinGroups() []string {
+	groups := set.New()
+	for _, rc := range l.collectConfigs() {
+		groups.Add(rc.group)
+	}
+	return groups.Slice()
+}
+
 func (l *Loader) Probes() []check.ProbeRef {
 	if l.probes != nil {
 		return l.probes
@@ -31,17 +31,35 @@ type Registry struct {
 
 	// calculators contains calculators probes definitions
 	calculators []*calculated.Probe
+
+	// dynamic contains sources of runners that can change in time, e.g. custom probes
+	dynamic []RunnerLister
+}
+
+// RunnerLister is the source of check runners that can change in time
+type RunnerLister interface {
+	Runners() []*check.Runner
 }
 
-func New(runLoader *probe.Loader, calcLoader *calculated.Loader) *Registry {
+func New(runLoader *probe.Loader, calcLoader *calculated.Loader, dynamic ...RunnerLister) *Registry {
 	return &Registry{
 		runners:     runLoader.Load(),
 		calculators: calcLoader.Load(),
+		dynamic:     dynamic,
 	}
 }
 
 func (r *Registry) Runners() []*check.Runner {
-	return r.runners
+	if len(r.dynamic) == 0 {
+		return r.runners
+	}
+
+	runners := make([]*check.Runner, len(r.runners))
+	copy(runners, r.runners)
+	for _, lister := range r.dynamic {
+		runners = append(runners, lister.Runners()...)
+	}
+	return runners
 }
 
 func (r *Registry) Calculators() []*calculated.Probe {
@@ -61,6 +79,24 @@ func NewProbeLister(listers ...ProbeLister) *RegistryProbeLister {
 	}
 }
 
+// NewDynamicProbeLister returns the lister that collects groups and probes on every call. It is
+// used when listers can change their content in time.
+func NewDynamicProbeLister(listers ...ProbeLister) *DynamicProbeLister {
+	return &DynamicProbeLister{listers: listers}
+}
+
+type DynamicProbeLister struct {
+	listers []ProbeLister
+}
+
+func (pl *DynamicProbeLister) Probes() []check.ProbeRef {
+	return collectProbes(pl.listers...)
+}
+
+func (pl *DynamicProbeLister) Groups() []string {
+	return collectGroups(pl.listers...)
+}
+
 type RegistryProbeLister struct {
 	// groups contain loaded groups
 	groups []string
@@ -63,6 +63,24 @@ func TestNewProbeLister(t *testing.T) {
 	assert.Equal(t, allGroupsSorted, pl.Groups())
 }
 
+// Test how the dynamic lister reflects changes of the underlying listers
+func TestNewDynamicProbeLister(t *testing.T) {
+	builtin := lister{
+		groups: []string{"z"},
+		probes: []check.ProbeRef{{Group: "z", Probe: "pz"}},
+	}
+	custom := &lister{}
+
+	pl := NewDynamicProbeLister(builtin, custom)
+	assert.Equal(t, []string{"z"}, pl.Groups())
+
+	custom.groups = []string{"a"}
+	custom.probes = []check.ProbeRef{{Group: "a", Probe: "pa"}}
+
+	assert.Equal(t, []check.ProbeRef{{Group: "a", Probe: "pa"}, {Group: "z", Probe: "pz"}}, pl.Probes())
+	assert.Equal(t, []string{"a", "z"}, pl.Groups())
+}
+
 type lister struct {
 	groups []string
 	probes []check.ProbeRef
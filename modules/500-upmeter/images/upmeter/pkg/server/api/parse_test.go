@@ -18,6 +18,7 @@ package api
 
 import (
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 
@@ -57,3 +58,22 @@ func Test_parseStepRange(t *testing.T) {
 		g.Expect(r.Step).Should(BeEquivalentTo(tt.want.Step))
 	}
 }
+
+func Test_parseReportRange(t *testing.T) {
+	g := NewWithT(t)
+
+	want := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	for _, arg := range []string{"1714521600", "2024-05-01T00:00:00Z", "2024-05-01"} {
+		from, to, err := parseReportRange(arg, arg)
+
+		g.Expect(err).ShouldNot(HaveOccurred())
+		g.Expect(from.Equal(want)).Should(BeTrue(), arg)
+		g.Expect(to.Equal(want)).Should(BeTrue(), arg)
+	}
+
+	_, _, err := parseReportRange("", "2024-05-01")
+	g.Expect(err).Should(HaveOccurred())
+
+	_, _, err = parseReportRange("yesterday", "")
+	g.Expect(err).Should(HaveOccurred())
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"d8.io/upmeter/pkg/server/report"
+)
+
+type AvailabilityReporter interface {
+	Report(from, to time.Time, group string, muteDowntimeTypes []string) (*report.Report, error)
+}
+
+// ReportHandler returns the availability report for an arbitrary range in JSON or CSV format
+type ReportHandler struct {
+	Reporter AvailabilityReporter
+}
+
+func (h *ReportHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Infoln("Report", r.RemoteAddr, r.RequestURI)
+
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		fmt.Fprintf(w, "%d GET is required\n", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+
+	from, to, err := parseReportRange(query.Get("from"), query.Get("to"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, "%d %s\n", http.StatusBadRequest, err)
+		return
+	}
+
+	format := query.Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "csv" {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, "%d format=%q must be json or csv\n", http.StatusBadRequest, format)
+		return
+	}
+
+	muteDowntimeTypes := parseDowntimeTypes(query.Get("muteDowntimeTypes"))
+	if len(muteDowntimeTypes) == 0 {
+		muteDowntimeTypes = report.DefaultMuteDowntimeTypes
+	}
+
+	rep, err := h.Reporter.Report(from, to, query.Get("group"), muteDowntimeTypes)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "%d Error: %s\n", http.StatusInternalServerError, err)
+		return
+	}
+
+	var body bytes.Buffer
+	if format == "csv" {
+		err = report.WriteCSV(&body, rep)
+	} else {
+		err = json.NewEncoder(&body).Encode(rep)
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "%d Error: %s\n", http.StatusInternalServerError, err)
+		return
+	}
+
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=upmeter-report-%d-%d.csv", from.Unix(), to.Unix()))
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	w.Write(body.Bytes())
+}
+
+// parseReportRange parses the range borders as Unix timestamps, RFC3339 times or dates. The range ends
+// now if `to` is omitted.
+func parseReportRange(fromArg, toArg string) (time.Time, time.Time, error) {
+	if fromArg == "" {
+		return time.Time{}, time.Time{}, fmt.Errorf("'from' is required")
+	}
+	from, err := parseReportTime(fromArg)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("from=%q: %v", fromArg, err)
+	}
+
+	to := time.Now()
+	if toArg != "" {
+		to, err = parseReportTime(toArg)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("to=%q: %v", toArg, err)
+		}
+	}
+
+	return from, to, nil
+}
+
+func parseReportTime(s string) (time.Time, error) {
+	if ts, err := parseTimestamp(s); err == nil {
+		return time.Unix(ts, 0), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("expected Unix timestamp, RFC3339 time, or YYYY-MM-DD date")
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	slomonitor "d8.io/upmeter/pkg/monitor/slo"
+	"d8.io/upmeter/pkg/server/slo"
+)
+
+type SLOResponse struct {
+	At      int64         `json:"at"`
+	Reports []*slo.Report `json:"reports"`
+}
+
+type SLOReporter interface {
+	Objectives() ([]slo.Objective, error)
+	Report(objective slo.Objective, at time.Time) (*slo.Report, error)
+}
+
+// SLOHandler returns SLO reports for UpmeterSLO objects or for the objective passed in the query.
+// Reports can be requested for a moment in the past with the `at` argument.
+type SLOHandler struct {
+	Reporter SLOReporter
+	// MaxWindowDays limits the window of the objective passed in the query, episodes are not stored longer
+	MaxWindowDays int
+}
+
+func (h *SLOHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Infoln("SLO", r.RemoteAddr, r.RequestURI)
+
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		fmt.Fprintf(w, "%d GET is required\n", http.StatusMethodNotAllowed)
+		return
+	}
+
+	at, objectives, err := h.parseSLOQuery(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, "%d %s\n", http.StatusBadRequest, err)
+		return
+	}
+
+	resp := &SLOResponse{
+		At:      at.Unix(),
+		Reports: make([]*slo.Report, 0, len(objectives)),
+	}
+	for _, objective := range objectives {
+		report, err := h.Reporter.Report(objective, at)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, "%d Error: %s\n", http.StatusInternalServerError, err)
+			return
+		}
+		resp.Reports = append(resp.Reports, report)
+	}
+
+	respJSON, err := json.Marshal(resp)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "%d Error: %s\n", http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	w.Write(respJSON)
+}
+
+// parseSLOQuery returns the moment of the report and objectives to report on. The objective is taken
+// from the query if `group` is set, otherwise all UpmeterSLO objects are used optionally filtered by `name`.
+func (h *SLOHandler) parseSLOQuery(r *http.Request) (time.Time, []slo.Objective, error) {
+	query := r.URL.Query()
+
+	at := time.Now()
+	if arg := query.Get("at"); arg != "" {
+		ts, err := parseTimestamp(arg)
+		if err != nil {
+			return at, nil, fmt.Errorf("at=%q is not timestamp: %v", arg, err)
+		}
+		at = time.Unix(ts, 0)
+	}
+
+	if group := query.Get("group"); group != "" {
+		objective, err := parseObjective(group, query.Get("probe"), query.Get("objective"), query.Get("windowDays"), h.MaxWindowDays)
+		if err != nil {
+			return at, nil, err
+		}
+		return at, []slo.Objective{objective}, nil
+	}
+
+	objectives, err := h.Reporter.Objectives()
+	if err != nil {
+		return at, nil, err
+	}
+
+	name := query.Get("name")
+	if name == "" {
+		return at, objectives, nil
+	}
+	for _, objective := range objectives {
+		if objective.Name == name {
+			return at, []slo.Objective{objective}, nil
+		}
+	}
+	return at, nil, fmt.Errorf("UpmeterSLO %q not found", name)
+}
+
+func parseObjective(group, probe, objectiveArg, windowArg string, maxWindowDays int) (slo.Objective, error) {
+	spec := slomonitor.Spec{Group: group, Probe: probe}
+
+	var err error
+	spec.Objective, err = strconv.ParseFloat(objectiveArg, 64)
+	if err != nil {
+		return slo.Objective{}, fmt.Errorf("objective=%q is not a number: %v", objectiveArg, err)
+	}
+	if spec.Objective <= 0 || spec.Objective >= 100 {
+		return slo.Objective{}, fmt.Errorf("objective=%q must be greater than 0 and less than 100", objectiveArg)
+	}
+
+	if windowArg != "" {
+		spec.WindowDays, err = strconv.Atoi(windowArg)
+		if err != nil || spec.WindowDays <= 0 {
+			return slo.Objective{}, fmt.Errorf("windowDays=%q is not a positive integer", windowArg)
+		}
+		if maxWindowDays > 0 && spec.WindowDays > maxWindowDays {
+			return slo.Objective{}, fmt.Errorf("windowDays=%q must not exceed the episode retention of %d days", windowArg, maxWindowDays)
+		}
+	}
+
+	return slo.NewObjective("", spec), nil
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_parseObjective(t *testing.T) {
+	g := NewWithT(t)
+
+	objective, err := parseObjective("control-plane", "", "99.9", "30", 548)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(objective.Window).Should(Equal(30 * 24 * time.Hour))
+
+	_, err = parseObjective("control-plane", "", "99.9", "549", 548)
+	g.Expect(err).Should(MatchError(ContainSubstring("must not exceed the episode retention")))
+
+	_, err = parseObjective("control-plane", "", "100", "30", 548)
+	g.Expect(err).Should(HaveOccurred())
+}
@@ -99,6 +99,16 @@ type RangeEpisodeLister interface {
 	ListEpisodeSumsForRanges(rng ranges.StepRange, ref check.ProbeRef) ([]check.Episode, error)
 }
 
+// SlotEpisodeLister lists stored 5m episodes as is, because they are already summed up by 5m slots.
+// It is much faster than the summing for each 5m slot of a long range.
+type SlotEpisodeLister struct {
+	Dao *dao.EpisodeDao5m
+}
+
+func (l *SlotEpisodeLister) ListEpisodeSumsForRanges(rng ranges.StepRange, ref check.ProbeRef) ([]check.Episode, error) {
+	return l.Dao.ListEpisodesByRange(rng.From, rng.To, ref)
+}
+
 func GetSummary(lister RangeEpisodeLister, ref check.ProbeRef, srng ranges.StepRange, incidents []check.DowntimeIncident) (map[string]map[string][]EpisodeSummary, error) {
 	episodes, err := lister.ListEpisodeSumsForRanges(srng, ref)
 	if err != nil {
@@ -143,15 +153,13 @@ func calculateStatuses(episodes []check.Episode, incidents []check.DowntimeIncid
 	statuses := newSummaryTable(episodes, rangeList)
 
 	// Sum up episodes for each probe by Start within each step range.
-	// TODO various optimizations can be applied here.
-	for _, stepRange := range rangeList {
-		for _, episode := range episodes {
-			if !episode.IsInRange(stepRange.From, stepRange.To) {
-				continue
-			}
-
-			statuses[episode.ProbeRef.Group][episode.ProbeRef.Probe][stepRange.From].addEpisode(episode)
+	for _, episode := range episodes {
+		stepRange, ok := findRange(rangeList, episode)
+		if !ok {
+			continue
 		}
+
+		statuses[episode.ProbeRef.Group][episode.ProbeRef.Probe][stepRange.From].addEpisode(episode)
 	}
 
 	updateMute(statuses, incidents, rangeList)
@@ -162,6 +170,19 @@ func calculateStatuses(episodes []check.Episode, incidents []check.DowntimeIncid
 	return transformTimestampedMapsToSortedArrays(statuses, ref)
 }
 
+// findRange returns the range containing the episode. Ranges are sorted and do not overlap, so the binary
+// search is used to handle long ranges with small steps, e.g. 30 days with 5 minutes step.
+func findRange(rangeList []ranges.Range, episode check.Episode) (ranges.Range, bool) {
+	ts := episode.TimeSlot.Unix()
+	i := sort.Search(len(rangeList), func(i int) bool {
+		return rangeList[i].To > ts
+	})
+	if i == len(rangeList) || !episode.IsInRange(rangeList[i].From, rangeList[i].To) {
+		return ranges.Range{}, false
+	}
+	return rangeList[i], true
+}
+
 // Each group/probe should have only 1 Episode per Start.
 func combineEpisodesByTimeslot(episodes []check.Episode, slotSize time.Duration) []check.Episode {
 	// It could have been a more shallow map map[string][]check.Episode, the key being
@@ -338,10 +359,9 @@ func newSummaryTable(episodes []check.Episode, rangeList []ranges.Range) map[str
 		_, ok = statuses[group][probe]
 		if !ok {
 			statuses[group][probe] = map[int64]*EpisodeSummary{}
-		}
-
-		for _, rng := range rangeList {
-			statuses[group][probe][rng.From] = newEpisodeSummary(rng)
+			for _, rng := range rangeList {
+				statuses[group][probe][rng.From] = newEpisodeSummary(rng)
+			}
 		}
 	}
 
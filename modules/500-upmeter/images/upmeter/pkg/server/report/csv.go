@@ -0,0 +1,91 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// Row types of the CSV report
+const (
+	rowAvailability = "availability"
+	rowDowntime     = "downtime"
+	rowExcluded     = "excluded"
+)
+
+var csvHeader = []string{
+	"type", "group", "probe", "from", "to",
+	"uptime", "up_seconds", "down_seconds", "unknown_seconds", "muted_seconds", "nodata_seconds",
+	"downtime", "downtime_type", "description",
+}
+
+// WriteCSV writes the report as a flat table. Availability rows contain the stats for the whole
+// range, downtime rows contain intervals of downtime, excluded rows contain periods described by
+// Downtime resources. Group rows have an empty probe.
+func WriteCSV(w io.Writer, r *Report) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, g := range r.Groups {
+		rows := [][]string{statsRow(g.Group, "", r, g.Stats)}
+		rows = append(rows, downtimeRows(g.Group, "", g.Downtimes)...)
+		for _, p := range g.Probes {
+			rows = append(rows, statsRow(g.Group, p.Probe, r, p.Stats))
+			rows = append(rows, downtimeRows(g.Group, p.Probe, p.Downtimes)...)
+		}
+		for _, e := range g.Excluded {
+			rows = append(rows, []string{rowExcluded, g.Group, "", e.From, e.To, "", "", "", "", "", "", e.Downtime, e.Type, e.Description})
+		}
+
+		if err := cw.WriteAll(rows); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func statsRow(group, probe string, r *Report, s Stats) []string {
+	uptime := ""
+	if s.Uptime != nil {
+		uptime = strconv.FormatFloat(*s.Uptime, 'f', -1, 64)
+	}
+	return []string{
+		rowAvailability, group, probe, r.From, r.To,
+		uptime,
+		strconv.FormatInt(s.UpSeconds, 10),
+		strconv.FormatInt(s.DownSeconds, 10),
+		strconv.FormatInt(s.UnknownSeconds, 10),
+		strconv.FormatInt(s.MutedSeconds, 10),
+		strconv.FormatInt(s.NoDataSeconds, 10),
+		"", "", "",
+	}
+}
+
+func downtimeRows(group, probe string, intervals []Interval) [][]string {
+	rows := make([][]string, 0, len(intervals))
+	for _, i := range intervals {
+		rows = append(rows, []string{rowDowntime, group, probe, i.From, i.To, "", "", strconv.FormatInt(i.DownSeconds, 10), "", "", "", "", "", ""})
+	}
+	return rows
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	monthlyReportLabel  = "upmeter.deckhouse.io/report"
+	monthlyReportPrefix = "upmeter-report-"
+
+	// Agents send episodes with a delay, the last slots of the month are waited for
+	monthlyReportDelay = 15 * time.Minute
+
+	// The API server rejects ConfigMaps with data exceeding 1MiB
+	configMapMaxDataSize = 1 << 20
+)
+
+var errReportTooLarge = errors.New("report exceeds the ConfigMap size limit")
+
+// MonthlyExporter stores the report for the previous month in a ConfigMap when the month is over.
+// The ConfigMap is named after the month, e.g. upmeter-report-2024-05, and contains the report in
+// JSON format, CSV is rendered by the API on demand. Only the latest reports are kept.
+type MonthlyExporter struct {
+	client    kubernetes.Interface
+	reporter  *Reporter
+	namespace string
+	history   int
+
+	// skipped is the name of the report that is too large to be stored, it is not built again
+	skipped string
+
+	period time.Duration
+	logger *log.Entry
+}
+
+func NewMonthlyExporter(client kubernetes.Interface, reporter *Reporter, namespace string, history int, logger *log.Logger) *MonthlyExporter {
+	return &MonthlyExporter{
+		client:    client,
+		reporter:  reporter,
+		namespace: namespace,
+		history:   history,
+		period:    time.Hour,
+		logger:    logger.WithField("who", "monthlyReportExporter"),
+	}
+}
+
+func (e *MonthlyExporter) Start(ctx context.Context) {
+	go e.run(ctx)
+}
+
+func (e *MonthlyExporter) run(ctx context.Context) {
+	ticker := time.NewTicker(e.period)
+	defer ticker.Stop()
+
+	for {
+		if err := e.sync(ctx, time.Now()); err != nil {
+			e.logger.Errorf("cannot export monthly report: %v", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (e *MonthlyExporter) sync(ctx context.Context, now time.Time) error {
+	from, to := previousMonth(now)
+	if now.Sub(to) < monthlyReportDelay {
+		return nil
+	}
+
+	configMaps := e.client.CoreV1().ConfigMaps(e.namespace)
+	name := monthlyReportName(from)
+
+	_, err := configMaps.Get(ctx, name, metav1.GetOptions{})
+	if err == nil {
+		return e.cleanup(ctx)
+	}
+	if !apierrors.IsNotFound(err) {
+		return fmt.Errorf("getting ConfigMap %s: %v", name, err)
+	}
+
+	if e.skipped == name {
+		return e.cleanup(ctx)
+	}
+
+	report, err := e.reporter.Report(from, to, "", DefaultMuteDowntimeTypes)
+	if err != nil {
+		return fmt.Errorf("building report: %v", err)
+	}
+	cm, err := newMonthlyReportConfigMap(name, e.namespace, report)
+	if errors.Is(err, errReportTooLarge) {
+		e.logger.Warnf("Monthly report %s is not stored: %v, get it from the API", name, err)
+		e.skipped = name
+		return e.cleanup(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = configMaps.Create(ctx, cm, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("creating ConfigMap %s: %v", name, err)
+	}
+	e.logger.Infof("Monthly report %s is stored", name)
+
+	return e.cleanup(ctx)
+}
+
+// cleanup deletes the oldest reports exceeding the history size
+func (e *MonthlyExporter) cleanup(ctx context.Context) error {
+	configMaps := e.client.CoreV1().ConfigMaps(e.namespace)
+
+	list, err := configMaps.List(ctx, metav1.ListOptions{LabelSelector: monthlyReportLabel + "=monthly"})
+	if err != nil {
+		return fmt.Errorf("listing report ConfigMaps: %v", err)
+	}
+
+	// the names contain the month, so they are sorted chronologically
+	names := make([]string, 0, len(list.Items))
+	for _, cm := range list.Items {
+		names = append(names, cm.Name)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+
+	if e.history < 1 || len(names) <= e.history {
+		return nil
+	}
+	for _, name := range names[e.history:] {
+		err := configMaps.Delete(ctx, name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("deleting ConfigMap %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+func newMonthlyReportConfigMap(name, namespace string, report *Report) (*corev1.ConfigMap, error) {
+	reportJSON, err := json.Marshal(report)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling report: %v", err)
+	}
+	if len(reportJSON) > configMapMaxDataSize {
+		return nil, fmt.Errorf("%w: %d bytes", errReportTooLarge, len(reportJSON))
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				"heritage":         "deckhouse",
+				"module":           "upmeter",
+				"app":              "upmeter",
+				monthlyReportLabel: "monthly",
+			},
+			Annotations: map[string]string{
+				"upmeter.deckhouse.io/report-from": report.From,
+				"upmeter.deckhouse.io/report-to":   report.To,
+			},
+		},
+		Data: map[string]string{
+			"report.json": string(reportJSON),
+		},
+	}, nil
+}
+
+// previousMonth returns the range of the previous calendar month in UTC
+func previousMonth(now time.Time) (time.Time, time.Time) {
+	now = now.UTC()
+	to := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return to.AddDate(0, -1, 0), to
+}
+
+func monthlyReportName(month time.Time) string {
+	return monthlyReportPrefix + month.Format("2006-01")
+}
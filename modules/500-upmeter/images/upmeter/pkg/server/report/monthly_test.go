@@ -0,0 +1,85 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_previousMonth(t *testing.T) {
+	g := NewWithT(t)
+
+	from, to := previousMonth(time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC))
+	g.Expect(from).To(Equal(time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC)))
+	g.Expect(to).To(Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+	g.Expect(monthlyReportName(from)).To(Equal("upmeter-report-2023-12"))
+}
+
+func Test_MonthlyExporter_cleanup(t *testing.T) {
+	g := NewWithT(t)
+
+	newReport := func(name string, labels map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "d8-upmeter", Labels: labels}}
+	}
+	monthly := map[string]string{monthlyReportLabel: "monthly"}
+
+	client := fake.NewSimpleClientset(
+		newReport("upmeter-report-2024-03", monthly),
+		newReport("upmeter-report-2024-01", monthly),
+		newReport("upmeter-report-2024-02", monthly),
+		newReport("upmeter-report-2023-12", nil),
+	)
+	exporter := &MonthlyExporter{client: client, namespace: "d8-upmeter", history: 2}
+
+	err := exporter.cleanup(context.Background())
+	g.Expect(err).ToNot(HaveOccurred())
+
+	list, err := client.CoreV1().ConfigMaps("d8-upmeter").List(context.Background(), metav1.ListOptions{})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	names := make([]string, 0)
+	for _, cm := range list.Items {
+		names = append(names, cm.Name)
+	}
+	g.Expect(names).To(ConsistOf("upmeter-report-2024-03", "upmeter-report-2024-02", "upmeter-report-2023-12"))
+}
+
+func Test_newMonthlyReportConfigMap(t *testing.T) {
+	g := NewWithT(t)
+
+	report := &Report{From: "2024-05-01T00:00:00Z", To: "2024-06-01T00:00:00Z", Groups: []GroupReport{{Group: "control-plane"}}}
+
+	cm, err := newMonthlyReportConfigMap("upmeter-report-2024-05", "d8-upmeter", report)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(cm.Labels).To(HaveKeyWithValue("heritage", "deckhouse"))
+	g.Expect(cm.Labels).To(HaveKeyWithValue("module", "upmeter"))
+	g.Expect(cm.Data).To(HaveLen(1))
+	g.Expect(cm.Data["report.json"]).To(ContainSubstring(`"group":"control-plane"`))
+
+	report.Groups[0].Excluded = []ExcludedPeriod{{Description: strings.Repeat("x", configMapMaxDataSize)}}
+	_, err = newMonthlyReportConfigMap("upmeter-report-2024-05", "d8-upmeter", report)
+	g.Expect(errors.Is(err, errReportTooLarge)).To(BeTrue())
+}
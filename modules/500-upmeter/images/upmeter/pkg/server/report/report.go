@@ -0,0 +1,188 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"d8.io/upmeter/pkg/check"
+	"d8.io/upmeter/pkg/server/entity"
+)
+
+// DefaultMuteDowntimeTypes are the Downtime types excluded from reports by default, the same as in the status API
+var DefaultMuteDowntimeTypes = []string{
+	"Maintenance",
+	"InfrastructureMaintenance",
+	"InfrastructureAccident",
+}
+
+// Report is the availability report for a time range
+type Report struct {
+	From   string        `json:"from"`
+	To     string        `json:"to"`
+	Groups []GroupReport `json:"groups"`
+}
+
+// GroupReport contains the group availability, the availability of its probes, and the periods
+// excluded from the calculation by Downtime resources
+type GroupReport struct {
+	Group string `json:"group"`
+	Stats
+	Downtimes []Interval       `json:"downtimes"`
+	Excluded  []ExcludedPeriod `json:"excluded"`
+	Probes    []ProbeReport    `json:"probes"`
+}
+
+type ProbeReport struct {
+	Probe string `json:"probe"`
+	Stats
+	Downtimes []Interval `json:"downtimes"`
+}
+
+// Stats contains the time spent in each state for the whole range
+type Stats struct {
+	// Uptime in percents, it is absent if there is no data. Unknown time is not counted as downtime,
+	// the same as in the status API.
+	Uptime         *float64 `json:"uptime"`
+	UpSeconds      int64    `json:"upSeconds"`
+	DownSeconds    int64    `json:"downSeconds"`
+	UnknownSeconds int64    `json:"unknownSeconds"`
+	MutedSeconds   int64    `json:"mutedSeconds"`
+	NoDataSeconds  int64    `json:"noDataSeconds"`
+}
+
+// Interval is the period of consecutive 5m slots with downtime
+type Interval struct {
+	From        string `json:"from"`
+	To          string `json:"to"`
+	DownSeconds int64  `json:"downSeconds"`
+}
+
+// ExcludedPeriod is the period described by a Downtime resource
+type ExcludedPeriod struct {
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Downtime    string `json:"downtime"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+}
+
+// buildGroup builds the group report from episode summaries of 5m slots including the total ones. The
+// `total` contains summaries of the group aggregation. Downtimes are expected to be already applied
+// to summaries.
+func buildGroup(group string, total []entity.EpisodeSummary, probes map[string][]entity.EpisodeSummary, incidents []check.DowntimeIncident) GroupReport {
+	report := GroupReport{
+		Group:     group,
+		Stats:     newStats(total),
+		Downtimes: downtimeIntervals(total),
+		Excluded:  excludedPeriods(incidents),
+		Probes:    make([]ProbeReport, 0, len(probes)),
+	}
+
+	for probe, summaries := range probes {
+		report.Probes = append(report.Probes, ProbeReport{
+			Probe:     probe,
+			Stats:     newStats(summaries),
+			Downtimes: downtimeIntervals(summaries),
+		})
+	}
+	sort.Slice(report.Probes, func(i, j int) bool {
+		return report.Probes[i].Probe < report.Probes[j].Probe
+	})
+
+	return report
+}
+
+func newStats(summaries []entity.EpisodeSummary) Stats {
+	var stats Stats
+	for _, s := range summaries {
+		if s.TimeSlot == -1 {
+			// the total column, slots are summed up instead
+			continue
+		}
+		stats.UpSeconds += int64(s.Up.Seconds())
+		stats.DownSeconds += int64(s.Down.Seconds())
+		stats.UnknownSeconds += int64(s.Unknown.Seconds())
+		stats.MutedSeconds += int64(s.Muted.Seconds())
+		stats.NoDataSeconds += int64(s.NoData.Seconds())
+	}
+
+	measured := stats.UpSeconds + stats.DownSeconds + stats.UnknownSeconds
+	if measured > 0 {
+		uptime := float64(stats.UpSeconds+stats.UnknownSeconds) / float64(measured) * 100
+		uptime = math.Round(uptime*10000) / 10000
+		stats.Uptime = &uptime
+	}
+
+	return stats
+}
+
+// downtimeIntervals merges consecutive 5m slots with downtime into intervals
+func downtimeIntervals(summaries []entity.EpisodeSummary) []Interval {
+	intervals := make([]Interval, 0)
+
+	var (
+		current *Interval
+		end     int64
+	)
+	for _, s := range summaries {
+		if s.TimeSlot == -1 || s.Down == 0 {
+			continue
+		}
+
+		slotEnd := s.TimeSlot + int64(s.SlotSize.Seconds())
+		if current != nil && s.TimeSlot == end {
+			current.DownSeconds += int64(s.Down.Seconds())
+			current.To = formatTime(slotEnd)
+			end = slotEnd
+			continue
+		}
+
+		intervals = append(intervals, Interval{
+			From:        formatTime(s.TimeSlot),
+			To:          formatTime(slotEnd),
+			DownSeconds: int64(s.Down.Seconds()),
+		})
+		current = &intervals[len(intervals)-1]
+		end = slotEnd
+	}
+
+	return intervals
+}
+
+func excludedPeriods(incidents []check.DowntimeIncident) []ExcludedPeriod {
+	periods := make([]ExcludedPeriod, 0, len(incidents))
+	for _, inc := range incidents {
+		periods = append(periods, ExcludedPeriod{
+			From:        formatTime(inc.Start),
+			To:          formatTime(inc.End),
+			Downtime:    inc.DowntimeName,
+			Type:        inc.Type,
+			Description: inc.Description,
+		})
+	}
+	sort.SliceStable(periods, func(i, j int) bool {
+		return periods[i].From < periods[j].From
+	})
+	return periods
+}
+
+func formatTime(ts int64) string {
+	return time.Unix(ts, 0).UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"d8.io/upmeter/pkg/check"
+	"d8.io/upmeter/pkg/server/entity"
+)
+
+var reportStart = time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+// newSummaries returns 5m slot summaries starting at reportStart with the given downtime in seconds
+// for each slot, and the total column
+func newSummaries(down ...int) []entity.EpisodeSummary {
+	summaries := make([]entity.EpisodeSummary, 0, len(down)+1)
+	for i, d := range down {
+		downtime := time.Duration(d) * time.Second
+		summaries = append(summaries, entity.EpisodeSummary{
+			TimeSlot: reportStart.Add(time.Duration(i) * slotSize).Unix(),
+			SlotSize: slotSize,
+			Up:       slotSize - downtime,
+			Down:     downtime,
+		})
+	}
+	return append(summaries, entity.EpisodeSummary{TimeSlot: -1, Up: time.Hour})
+}
+
+func Test_buildGroup(t *testing.T) {
+	g := NewWithT(t)
+
+	total := newSummaries(0, 60, 300, 0, 30, 0)
+	probes := map[string][]entity.EpisodeSummary{
+		"scheduler": newSummaries(0, 0, 0, 0, 0, 0),
+		"apiserver": newSummaries(0, 60, 300, 0, 30, 0),
+	}
+	incidents := []check.DowntimeIncident{{
+		Start:        reportStart.Add(20 * time.Minute).Unix(),
+		End:          reportStart.Add(time.Hour).Unix(),
+		Type:         "Maintenance",
+		Description:  "Kubernetes upgrade",
+		DowntimeName: "upgrade",
+	}}
+
+	gr := buildGroup("control-plane", total, probes, incidents)
+
+	g.Expect(gr.Group).To(Equal("control-plane"))
+	g.Expect(gr.UpSeconds).To(BeEquivalentTo(1800 - 390))
+	g.Expect(gr.DownSeconds).To(BeEquivalentTo(390))
+	g.Expect(*gr.Uptime).To(Equal(78.3333))
+
+	g.Expect(gr.Downtimes).To(Equal([]Interval{
+		{From: "2024-05-01T00:05:00Z", To: "2024-05-01T00:15:00Z", DownSeconds: 360},
+		{From: "2024-05-01T00:20:00Z", To: "2024-05-01T00:25:00Z", DownSeconds: 30},
+	}))
+	g.Expect(gr.Excluded).To(Equal([]ExcludedPeriod{{
+		From:        "2024-05-01T00:20:00Z",
+		To:          "2024-05-01T01:00:00Z",
+		Downtime:    "upgrade",
+		Type:        "Maintenance",
+		Description: "Kubernetes upgrade",
+	}}))
+
+	g.Expect(gr.Probes).To(HaveLen(2))
+	g.Expect(gr.Probes[0].Probe).To(Equal("apiserver"))
+	g.Expect(gr.Probes[1].Probe).To(Equal("scheduler"))
+	g.Expect(*gr.Probes[1].Uptime).To(BeEquivalentTo(100))
+	g.Expect(gr.Probes[1].Downtimes).To(BeEmpty())
+}
+
+func Test_buildGroup_no_data(t *testing.T) {
+	g := NewWithT(t)
+
+	gr := buildGroup("synthetic", nil, nil, nil)
+
+	g.Expect(gr.Uptime).To(BeNil())
+	g.Expect(gr.Probes).To(BeEmpty())
+	g.Expect(gr.Downtimes).To(BeEmpty())
+	g.Expect(gr.Excluded).To(BeEmpty())
+}
+
+func Test_WriteCSV(t *testing.T) {
+	g := NewWithT(t)
+
+	uptime := 99.5
+	r := &Report{
+		From: "2024-05-01T00:00:00Z",
+		To:   "2024-06-01T00:00:00Z",
+		Groups: []GroupReport{{
+			Group:     "control-plane",
+			Stats:     Stats{Uptime: &uptime, UpSeconds: 597, DownSeconds: 3},
+			Downtimes: []Interval{{From: "2024-05-02T00:00:00Z", To: "2024-05-02T00:05:00Z", DownSeconds: 3}},
+			Excluded:  []ExcludedPeriod{{From: "2024-05-03T00:00:00Z", To: "2024-05-03T01:00:00Z", Downtime: "upgrade", Type: "Maintenance", Description: "Upgrade, phase 1"}},
+			Probes: []ProbeReport{{
+				Probe: "apiserver",
+				Stats: Stats{NoDataSeconds: 600},
+			}},
+		}},
+	}
+
+	var buf bytes.Buffer
+	err := WriteCSV(&buf, r)
+
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(buf.String()).To(Equal(
+		"type,group,probe,from,to,uptime,up_seconds,down_seconds,unknown_seconds,muted_seconds,nodata_seconds,downtime,downtime_type,description\n" +
+			"availability,control-plane,,2024-05-01T00:00:00Z,2024-06-01T00:00:00Z,99.5,597,3,0,0,0,,,\n" +
+			"downtime,control-plane,,2024-05-02T00:00:00Z,2024-05-02T00:05:00Z,,,3,,,,,,\n" +
+			"availability,control-plane,apiserver,2024-05-01T00:00:00Z,2024-06-01T00:00:00Z,,0,0,0,0,600,,,\n" +
+			"excluded,control-plane,,2024-05-03T00:00:00Z,2024-05-03T01:00:00Z,,,,,,,upgrade,Maintenance,\"Upgrade, phase 1\"\n",
+	))
+}
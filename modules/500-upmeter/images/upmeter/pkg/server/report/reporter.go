@@ -0,0 +1,116 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"fmt"
+	"time"
+
+	"d8.io/upmeter/pkg/check"
+	dbcontext "d8.io/upmeter/pkg/db/context"
+	"d8.io/upmeter/pkg/db/dao"
+	"d8.io/upmeter/pkg/monitor/downtime"
+	"d8.io/upmeter/pkg/registry"
+	"d8.io/upmeter/pkg/server/entity"
+	"d8.io/upmeter/pkg/server/ranges"
+)
+
+const slotSize = 5 * time.Minute
+
+// MaxRange limits the report range to keep the memory consumption reasonable, 5m slots are
+// calculated for every probe
+const MaxRange = 93 * 24 * time.Hour
+
+// Reporter builds availability reports from the stored 5m episodes
+type Reporter struct {
+	DbCtx           *dbcontext.DbContext
+	DowntimeMonitor *downtime.Monitor
+	ProbeLister     registry.ProbeLister
+}
+
+// Report returns the report for the range [from, to) aligned to 5m slots. If the group is empty, all
+// groups are included.
+func (r *Reporter) Report(from, to time.Time, group string, muteDowntimeTypes []string) (*Report, error) {
+	from, to = from.Truncate(slotSize), to.Truncate(slotSize)
+	if !from.Before(to) {
+		return nil, fmt.Errorf("the range is less than %s", slotSize)
+	}
+	if to.Sub(from) > MaxRange {
+		return nil, fmt.Errorf("the range exceeds %s", MaxRange)
+	}
+
+	groups := r.ProbeLister.Groups()
+	if group != "" {
+		groups = []string{group}
+	}
+
+	incidents, err := r.DowntimeMonitor.List()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get incidents: %v", err)
+	}
+
+	rng := ranges.New5MinStepRange(from.Unix(), to.Unix(), int64(slotSize.Seconds()))
+
+	daoCtx := r.DbCtx.Start()
+	defer daoCtx.Stop()
+	lister := &entity.SlotEpisodeLister{Dao: dao.NewEpisodeDao5m(daoCtx)}
+
+	report := &Report{
+		From:   formatTime(rng.From),
+		To:     formatTime(rng.To),
+		Groups: make([]GroupReport, 0, len(groups)),
+	}
+	for _, g := range groups {
+		groupIncidents := filterIncidents(incidents, g, muteDowntimeTypes, rng)
+
+		// Probes and the group total are requested separately, the summary contains either of them
+		probes, err := entity.GetSummary(lister, check.ProbeRef{Group: g, Probe: dao.ProbeEnumeration}, rng, groupIncidents)
+		if err != nil {
+			return nil, err
+		}
+		total, err := entity.GetSummary(lister, check.ProbeRef{Group: g, Probe: dao.GroupAggregation}, rng, groupIncidents)
+		if err != nil {
+			return nil, err
+		}
+
+		report.Groups = append(report.Groups, buildGroup(g, total[g][dao.GroupAggregation], probes[g], groupIncidents))
+	}
+
+	return report, nil
+}
+
+// filterIncidents returns the incidents of muted types affecting the group within the range
+func filterIncidents(incidents []check.DowntimeIncident, group string, muteTypes []string, rng ranges.StepRange) []check.DowntimeIncident {
+	muted := make(map[string]bool)
+	for _, t := range muteTypes {
+		muted[t] = true
+	}
+
+	res := make([]check.DowntimeIncident, 0)
+	for _, inc := range incidents {
+		if !muted[inc.Type] || inc.Start >= rng.To || inc.End <= rng.From {
+			continue
+		}
+		for _, affected := range inc.Affected {
+			if affected == group {
+				res = append(res, inc)
+				break
+			}
+		}
+	}
+	return res
+}
@@ -32,6 +32,7 @@ import (
 	dbcontext "d8.io/upmeter/pkg/db/context"
 	"d8.io/upmeter/pkg/db/dao"
 	"d8.io/upmeter/pkg/kubernetes"
+	"d8.io/upmeter/pkg/monitor/customprobe"
 	"d8.io/upmeter/pkg/monitor/downtime"
 	"d8.io/upmeter/pkg/probe"
 	"d8.io/upmeter/pkg/probe/calculated"
@@ -39,13 +40,17 @@ import (
 	"d8.io/upmeter/pkg/registry"
 	"d8.io/upmeter/pkg/server/api"
 	"d8.io/upmeter/pkg/server/remotewrite"
+	"d8.io/upmeter/pkg/server/report"
+	"d8.io/upmeter/pkg/server/slo"
 )
 
 // server initializes all dependencies:
 // - kubernetes client
-// - crd monitor
+// - crd monitors
 // - database connection
 // - metrics storage
+// - SLO reports controller
+// - monthly availability reports exporter
 // If everything is ok, it starts http server.
 
 type Server struct {
@@ -57,6 +62,8 @@ type Server struct {
 	server                *http.Server
 	downtimeMonitor       *downtime.Monitor
 	remoteWriteController *remotewrite.Controller
+	sloController         *slo.Controller
+	customProbeMonitor    *customprobe.Monitor
 }
 
 type Config struct {
@@ -71,6 +78,10 @@ type Config struct {
 
 	DisabledProbes []string
 	DynamicProbes  *DynamicProbesConfig
+
+	// ReportsNamespace is where monthly reports are stored, ReportsHistory is the number of kept reports
+	ReportsNamespace string
+	ReportsHistory   int
 }
 
 type DynamicProbesConfig struct {
@@ -119,16 +130,37 @@ func (s *Server) Start(ctx context.Context) error {
 		return fmt.Errorf("cannot start remote_write controller: %v", err)
 	}
 
+	// SLO reports controller
+	s.sloController = slo.NewController(kubeClient, dbctx, s.downtimeMonitor, s.logger)
+	err = s.sloController.Start(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot start upmeterslos.deckhouse.io controller: %v", err)
+	}
+
+	// UpmeterCustomProbe CR monitor
+	s.customProbeMonitor = customprobe.NewMonitor(kubeClient, log.NewEntry(s.logger))
+	err = s.customProbeMonitor.Start(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot start upmetercustomprobes.deckhouse.io monitor: %v", err)
+	}
+
 	go cleanOld30sEpisodes(ctx, dbctx)
 	go cleanOld5mEpisodes(ctx, dbctx, s.config.DatabaseRetentionDays)
 
 	// Probe lister that can only list groups and probes
-	probeLister := newProbeLister(s.config.DisabledProbes, s.config.DynamicProbes)
+	probeLister := registry.NewDynamicProbeLister(
+		newProbeLister(s.config.DisabledProbes, s.config.DynamicProbes),
+		newCustomProbeLister(s.config.DisabledProbes, s.config.DynamicProbes, s.customProbeMonitor),
+	)
+
+	// Availability reports on demand and monthly
+	reporter := &report.Reporter{DbCtx: dbctx, DowntimeMonitor: s.downtimeMonitor, ProbeLister: probeLister}
+	report.NewMonthlyExporter(kubeClient, reporter, s.config.ReportsNamespace, s.config.ReportsHistory, s.logger).Start(ctx)
 
 	// Start http server. It blocks, that's why it is the last here.
 	s.logger.Debugf("starting HTTP server")
 	listenAddr := s.config.ListenHost + ":" + s.config.ListenPort
-	s.server = initHttpServer(dbctx, s.downtimeMonitor, s.remoteWriteController, probeLister, listenAddr)
+	s.server = initHttpServer(dbctx, s.downtimeMonitor, s.remoteWriteController, s.sloController, reporter, probeLister, s.config.DatabaseRetentionDays, listenAddr)
 
 	err = s.server.ListenAndServe()
 	if err == http.ErrServerClosed {
@@ -144,6 +176,8 @@ func (s *Server) Stop() error {
 		return err
 	}
 	s.remoteWriteController.Stop()
+	s.sloController.Stop()
+	s.customProbeMonitor.Stop()
 	s.downtimeMonitor.Stop()
 
 	return nil
@@ -202,7 +236,7 @@ func cleanOld5mEpisodes(ctx context.Context, dbCtx *dbcontext.DbContext, retDays
 	}
 }
 
-func initHttpServer(dbCtx *dbcontext.DbContext, downtimeMonitor *downtime.Monitor, controller *remotewrite.Controller, probeLister registry.ProbeLister, addr string) *http.Server {
+func initHttpServer(dbCtx *dbcontext.DbContext, downtimeMonitor *downtime.Monitor, controller *remotewrite.Controller, sloController *slo.Controller, reporter *report.Reporter, probeLister registry.ProbeLister, retentionDays int, addr string) *http.Server {
 	mux := http.NewServeMux()
 
 	// API handlers
@@ -211,6 +245,10 @@ func initHttpServer(dbCtx *dbcontext.DbContext, downtimeMonitor *downtime.Monito
 	mux.Handle("/public/api/status", &api.PublicStatusHandler{DbCtx: dbCtx, DowntimeMonitor: downtimeMonitor, ProbeLister: probeLister})
 	mux.Handle("/downtime", &api.AddEpisodesHandler{DbCtx: dbCtx, RemoteWrite: controller})
 	mux.Handle("/stats", &api.StatsHandler{DbCtx: dbCtx})
+	mux.Handle("/api/slo", &api.SLOHandler{Reporter: sloController, MaxWindowDays: retentionDays})
+	mux.Handle("/api/report", &api.ReportHandler{Reporter: reporter})
+	// Prometheus metrics
+	mux.Handle("/metrics", sloController.MetricsHandler())
 	// Kubernetes probes
 	mux.HandleFunc("/healthz", writeOk)
 	mux.HandleFunc("/ready", writeOk)
@@ -263,6 +301,21 @@ func newProbeLister(disabled []string, dynamic *DynamicProbesConfig) *registry.R
 	return registry.NewProbeLister(runLoader, calcLoader)
 }
 
+// newCustomProbeLister lists probes defined by UpmeterCustomProbe resources. They do not run in the
+// server, the runners only keep track of groups and probes.
+func newCustomProbeLister(disabled []string, dynamic *DynamicProbesConfig, monitor *customprobe.Monitor) *probe.CustomProbes {
+	noLogger := newDummyLogger()
+	dynamicConfig := probe.DynamicConfig{
+		IngressNginxControllers: dynamic.IngressControllers,
+		NodeGroups:              dynamic.NodeGroups,
+	}
+	builtinLoader := probe.NewLoader(probe.NewProbeFilter(nil), kubernetes.FakeAccessor(), nil, dynamicConfig, checker.NoopDoer{}, noLogger)
+
+	customProbes := probe.NewCustomProbes(probe.NewProbeFilter(disabled), builtinLoader.BuiltinGroups(), "", noLogger)
+	monitor.Subscribe(customProbes)
+	return customProbes
+}
+
 func newDummyLogger() *log.Logger {
 	logger := log.New()
 	logger.SetOutput(ioutil.Discard)
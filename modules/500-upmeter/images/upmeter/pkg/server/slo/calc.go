@@ -0,0 +1,195 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slo
+
+import (
+	"math"
+	"time"
+
+	"d8.io/upmeter/pkg/check"
+	"d8.io/upmeter/pkg/monitor/slo"
+	"d8.io/upmeter/pkg/server/entity"
+)
+
+const (
+	slotSize = 5 * time.Minute
+
+	DefaultWindow = 30 * 24 * time.Hour
+)
+
+// burnRateWindows are the windows to calculate the burn rate for. Short windows are used in pairs with
+// long ones to alert on fast and slow budget burning, e.g. 1h and 5m.
+var burnRateWindows = []struct {
+	name string
+	dur  time.Duration
+}{
+	{"5m", 5 * time.Minute},
+	{"30m", 30 * time.Minute},
+	{"1h", time.Hour},
+	{"6h", 6 * time.Hour},
+	{"1d", 24 * time.Hour},
+	{"3d", 3 * 24 * time.Hour},
+}
+
+// forecastWindow is the window which burn rate is used to forecast the budget exhaustion
+const forecastWindow = 24 * time.Hour
+
+// Objective is the availability target of a group or a probe
+type Objective struct {
+	// Name is the name of UpmeterSLO object, it is empty for ad-hoc objectives
+	Name string
+	// Ref points to the group total if the probe is not specified
+	Ref check.ProbeRef
+	// Objective in percents, e.g. 99.9
+	Objective float64
+	Window    time.Duration
+}
+
+type Report struct {
+	Name       string  `json:"name,omitempty"`
+	Group      string  `json:"group"`
+	Probe      string  `json:"probe,omitempty"`
+	Objective  float64 `json:"objective"`
+	WindowDays int     `json:"windowDays"`
+	From       string  `json:"from"`
+	To         string  `json:"to"`
+
+	slo.Status
+}
+
+// Ratios are used in metrics, the report contains percents which are rounded for readability
+type ratios struct {
+	availability    float64
+	budgetRemaining float64
+	burnRates       map[string]float64
+	exhaustion      time.Time
+	hasData         bool
+}
+
+// calculate returns the report for the objective by episode summaries of 5m slots of the window ending at `to`.
+// Downtimes are expected to be already applied to summaries, muted time is not counted.
+func calculate(objective Objective, summaries []entity.EpisodeSummary, to time.Time) (*Report, ratios) {
+	from := to.Add(-objective.Window)
+	report := &Report{
+		Name:       objective.Name,
+		Group:      objective.Ref.Group,
+		Probe:      objective.Ref.Probe,
+		Objective:  objective.Objective,
+		WindowDays: int(objective.Window / (24 * time.Hour)),
+		From:       from.Format(time.RFC3339),
+		To:         to.Format(time.RFC3339),
+	}
+	if report.Probe == groupProbe {
+		report.Probe = ""
+	}
+
+	var r ratios
+
+	total := sumWindow(summaries, from, to)
+	if total.measured() == 0 {
+		return report, r
+	}
+	r.hasData = true
+
+	// Unknown time is not counted as downtime, the same as in the status API
+	r.availability = float64(total.good) / float64(total.measured())
+	report.Availability = float64Ptr(roundPercent(r.availability))
+
+	budgetShare := 1 - objective.Objective/100
+	budget := time.Duration(budgetShare * float64(total.measured()))
+	r.budgetRemaining = remainingBudget(budgetShare*float64(total.measured()), total.bad)
+	report.ErrorBudget = &slo.ErrorBudget{
+		TotalSeconds:     int64(budget.Seconds()),
+		ConsumedSeconds:  int64(total.bad.Seconds()),
+		RemainingPercent: roundPercent(r.budgetRemaining),
+	}
+
+	r.burnRates = make(map[string]float64)
+	for _, w := range burnRateWindows {
+		// the burn rate of the zero budget is infinite
+		if w.dur > objective.Window || budgetShare <= 0 {
+			break
+		}
+
+		sum := sumWindow(summaries, to.Add(-w.dur), to)
+		if sum.measured() == 0 {
+			continue
+		}
+
+		rate := float64(sum.bad) / float64(sum.measured()) / budgetShare
+		r.burnRates[w.name] = rate
+		report.BurnRates = append(report.BurnRates, slo.BurnRate{Window: w.name, Rate: round(rate, 2)})
+	}
+
+	// The remaining budget is exhausted at the downtime pace of the last day. It is a rough forecast,
+	// the window slides and old downtime is forgotten.
+	remaining := budget - total.bad
+	recentDown := sumWindow(summaries, to.Add(-forecastWindow), to).bad
+	if remaining > 0 && recentDown > 0 {
+		pace := float64(recentDown) / float64(forecastWindow)
+		r.exhaustion = to.Add(time.Duration(float64(remaining) / pace))
+		report.ExhaustionForecast = r.exhaustion.Format(time.RFC3339)
+	}
+
+	return report, r
+}
+
+// remainingBudget returns the share of the budget left, the budget is not truncated to avoid the division by zero
+// for short measured time. The zero budget is exhausted by any downtime.
+func remainingBudget(budget float64, bad time.Duration) float64 {
+	if budget <= 0 {
+		if bad > 0 {
+			return 0
+		}
+		return 1
+	}
+	return 1 - float64(bad)/budget
+}
+
+type windowSum struct {
+	good, bad time.Duration
+}
+
+func (s windowSum) measured() time.Duration {
+	return s.good + s.bad
+}
+
+// sumWindow sums up the summaries of slots starting within [from, to)
+func sumWindow(summaries []entity.EpisodeSummary, from, to time.Time) windowSum {
+	var sum windowSum
+	for _, s := range summaries {
+		if s.TimeSlot < from.Unix() || s.TimeSlot >= to.Unix() {
+			continue
+		}
+		sum.good += s.Up + s.Unknown
+		sum.bad += s.Down
+	}
+	return sum
+}
+
+func roundPercent(ratio float64) float64 {
+	return round(ratio*100, 4)
+}
+
+func round(v float64, precision int) float64 {
+	p := math.Pow10(precision)
+	return math.Round(v*p) / p
+}
+
+func float64Ptr(v float64) *float64 {
+	return &v
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slo
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"d8.io/upmeter/pkg/check"
+	"d8.io/upmeter/pkg/monitor/slo"
+	"d8.io/upmeter/pkg/server/entity"
+)
+
+// newSummaries returns full uptime summaries for the window ending at `to`, slots listed in `down` are full downtime
+func newSummaries(to time.Time, window time.Duration, down ...time.Time) []entity.EpisodeSummary {
+	isDown := make(map[int64]bool)
+	for _, t := range down {
+		isDown[t.Unix()] = true
+	}
+
+	var summaries []entity.EpisodeSummary
+	for ts := to.Add(-window); ts.Before(to); ts = ts.Add(slotSize) {
+		s := entity.EpisodeSummary{TimeSlot: ts.Unix(), SlotSize: slotSize}
+		if isDown[ts.Unix()] {
+			s.Down = slotSize
+		} else {
+			s.Up = slotSize
+		}
+		summaries = append(summaries, s)
+	}
+
+	// the total column is ignored
+	summaries = append(summaries, entity.EpisodeSummary{TimeSlot: -1, Down: window})
+
+	return summaries
+}
+
+func Test_calculate(t *testing.T) {
+	to := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	objective := Objective{
+		Name:      "control-plane",
+		Ref:       check.ProbeRef{Group: "control-plane", Probe: groupProbe},
+		Objective: 99,
+		Window:    2 * 24 * time.Hour,
+	}
+
+	t.Run("no data", func(t *testing.T) {
+		g := NewWithT(t)
+
+		report, ratios := calculate(objective, nil, to)
+
+		g.Expect(ratios.hasData).To(BeFalse())
+		g.Expect(report.Availability).To(BeNil())
+		g.Expect(report.ErrorBudget).To(BeNil())
+		g.Expect(report.Probe).To(BeEmpty())
+		g.Expect(report.WindowDays).To(Equal(2))
+		g.Expect(report.From).To(Equal("2024-05-30T12:00:00Z"))
+	})
+
+	t.Run("budget is exceeded by the last 30 minutes of downtime", func(t *testing.T) {
+		g := NewWithT(t)
+
+		var down []time.Time
+		for i := 1; i <= 6; i++ {
+			down = append(down, to.Add(-time.Duration(i)*slotSize))
+		}
+
+		report, ratios := calculate(objective, newSummaries(to, objective.Window, down...), to)
+
+		g.Expect(*report.Availability).To(Equal(98.9583))
+		g.Expect(*report.ErrorBudget).To(Equal(slo.ErrorBudget{
+			TotalSeconds:     1728,
+			ConsumedSeconds:  1800,
+			RemainingPercent: -4.1667,
+		}))
+		g.Expect(report.BurnRates).To(Equal([]slo.BurnRate{
+			{Window: "5m", Rate: 100},
+			{Window: "30m", Rate: 100},
+			{Window: "1h", Rate: 50},
+			{Window: "6h", Rate: 8.33},
+			{Window: "1d", Rate: 2.08},
+		}))
+		g.Expect(report.ExhaustionForecast).To(BeEmpty())
+		g.Expect(ratios.budgetRemaining).To(BeNumerically("<", 0))
+	})
+
+	t.Run("budget exhaustion is forecasted by the last day", func(t *testing.T) {
+		g := NewWithT(t)
+
+		report, ratios := calculate(objective, newSummaries(to, objective.Window, to.Add(-time.Hour)), to)
+
+		g.Expect(report.ErrorBudget.RemainingPercent).To(Equal(82.6389))
+		// 1428s of the budget remains, 300s are burnt per day
+		g.Expect(report.ExhaustionForecast).To(Equal("2024-06-06T06:14:24Z"))
+		g.Expect(ratios.exhaustion.Sub(to)).To(Equal(time.Duration(1428.0 / 300 * float64(24*time.Hour))))
+	})
+
+	t.Run("zero budget is exhausted by any downtime", func(t *testing.T) {
+		g := NewWithT(t)
+
+		strict := objective
+		strict.Objective = 100
+
+		report, ratios := calculate(strict, newSummaries(to, strict.Window), to)
+		g.Expect(ratios.budgetRemaining).To(Equal(1.0))
+		g.Expect(report.BurnRates).To(BeEmpty())
+
+		report, ratios = calculate(strict, newSummaries(to, strict.Window, to.Add(-time.Hour)), to)
+		g.Expect(ratios.budgetRemaining).To(Equal(0.0))
+		g.Expect(report.ErrorBudget.TotalSeconds).To(BeZero())
+		g.Expect(report.ExhaustionForecast).To(BeEmpty())
+
+		_, err := json.Marshal(report)
+		g.Expect(err).ToNot(HaveOccurred())
+	})
+}
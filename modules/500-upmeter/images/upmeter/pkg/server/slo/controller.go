@@ -0,0 +1,230 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	kube "github.com/flant/kube-client/client"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+
+	"d8.io/upmeter/pkg/check"
+	dbcontext "d8.io/upmeter/pkg/db/context"
+	"d8.io/upmeter/pkg/monitor/downtime"
+	"d8.io/upmeter/pkg/monitor/slo"
+)
+
+// Controller calculates reports for UpmeterSLO objects, stores them in the objects status
+// and exports them as metrics
+type Controller struct {
+	kubeMonitor *slo.Monitor
+	reporter    *Reporter
+	collector   *collector
+
+	period time.Duration
+	logger *log.Entry
+}
+
+func NewController(kubeClient kube.Client, dbCtx *dbcontext.DbContext, downtimeMonitor *downtime.Monitor, logger *log.Logger) *Controller {
+	return &Controller{
+		kubeMonitor: slo.NewMonitor(kubeClient, logger.WithField("who", "kubeMonitor")),
+		reporter:    &Reporter{DbCtx: dbCtx, DowntimeMonitor: downtimeMonitor},
+		collector:   newCollector(),
+		period:      time.Minute,
+		logger:      logger.WithField("who", "sloController"),
+	}
+}
+
+func (c *Controller) Start(ctx context.Context) error {
+	err := c.kubeMonitor.Start(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot start monitor: %v", err)
+	}
+
+	go c.run(ctx)
+	return nil
+}
+
+func (c *Controller) Stop() {
+	c.kubeMonitor.Stop()
+}
+
+// Objectives returns the objectives defined by UpmeterSLO objects
+func (c *Controller) Objectives() ([]Objective, error) {
+	list, err := c.kubeMonitor.List()
+	if err != nil {
+		return nil, err
+	}
+
+	objectives := make([]Objective, 0, len(list))
+	for _, obj := range list {
+		objectives = append(objectives, NewObjective(obj.Name, obj.Spec))
+	}
+	return objectives, nil
+}
+
+// Report returns the SLO report for the moment in the past or now
+func (c *Controller) Report(objective Objective, at time.Time) (*Report, error) {
+	return c.reporter.Report(objective, at)
+}
+
+// MetricsHandler serves SLO metrics in the Prometheus format
+func (c *Controller) MetricsHandler() http.Handler {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(c.collector)
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+func (c *Controller) run(ctx context.Context) {
+	ticker := time.NewTicker(c.period)
+	defer ticker.Stop()
+
+	for {
+		c.sync(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (c *Controller) sync(ctx context.Context) {
+	objectives, err := c.Objectives()
+	if err != nil {
+		c.logger.Errorf("cannot list UpmeterSLO objects: %v", err)
+		return
+	}
+
+	now := time.Now()
+	samples := make([]sample, 0, len(objectives))
+	for _, objective := range objectives {
+		report, ratios, err := c.reporter.report(objective, now)
+		if err != nil {
+			c.logger.Errorf("cannot calculate SLO %q: %v", objective.Name, err)
+			report = &Report{}
+			report.Message = err.Error()
+		} else {
+			samples = append(samples, sample{objective: objective, ratios: ratios})
+		}
+
+		report.CalculationTime = now.UTC().Format(time.RFC3339)
+		err = c.kubeMonitor.UpdateStatus(ctx, objective.Name, report.Status)
+		if err != nil {
+			c.logger.Errorf("cannot update status: %v", err)
+		}
+	}
+
+	c.collector.set(samples)
+}
+
+// NewObjective converts the UpmeterSLO spec to the objective
+func NewObjective(name string, spec slo.Spec) Objective {
+	ref := check.ProbeRef{Group: spec.Group, Probe: spec.Probe}
+	if ref.Probe == "" {
+		ref.Probe = groupProbe
+	}
+
+	window := DefaultWindow
+	if spec.WindowDays > 0 {
+		window = time.Duration(spec.WindowDays) * 24 * time.Hour
+	}
+
+	return Objective{
+		Name:      name,
+		Ref:       ref,
+		Objective: spec.Objective,
+		Window:    window,
+	}
+}
+
+type sample struct {
+	objective Objective
+	ratios    ratios
+}
+
+// collector exports the last calculated SLO reports
+type collector struct {
+	mu      sync.RWMutex
+	samples []sample
+
+	objective       *prometheus.Desc
+	availability    *prometheus.Desc
+	budgetRemaining *prometheus.Desc
+	burnRate        *prometheus.Desc
+	exhaustion      *prometheus.Desc
+}
+
+func newCollector() *collector {
+	labels := []string{"slo", "group", "probe"}
+	return &collector{
+		objective: prometheus.NewDesc("upmeter_slo_objective_ratio",
+			"Availability objective of the SLO.", labels, nil),
+		availability: prometheus.NewDesc("upmeter_slo_availability_ratio",
+			"Availability in the SLO window.", labels, nil),
+		budgetRemaining: prometheus.NewDesc("upmeter_slo_error_budget_remaining_ratio",
+			"Remaining share of the error budget in the SLO window, it is negative if the budget is exceeded.", labels, nil),
+		burnRate: prometheus.NewDesc("upmeter_slo_burn_rate",
+			"Error budget burn rate in the window, 1 means the budget is exhausted exactly at the end of the SLO window.", append(labels, "window"), nil),
+		exhaustion: prometheus.NewDesc("upmeter_slo_error_budget_exhaustion_timestamp_seconds",
+			"Forecasted time of the error budget exhaustion at the burn rate of the last day.", labels, nil),
+	}
+}
+
+func (c *collector) set(samples []sample) {
+	c.mu.Lock()
+	c.samples = samples
+	c.mu.Unlock()
+}
+
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.objective
+	ch <- c.availability
+	ch <- c.budgetRemaining
+	ch <- c.burnRate
+	ch <- c.exhaustion
+}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, s := range c.samples {
+		labels := []string{s.objective.Name, s.objective.Ref.Group, s.objective.Ref.Probe}
+		ch <- prometheus.MustNewConstMetric(c.objective, prometheus.GaugeValue, s.objective.Objective/100, labels...)
+
+		if !s.ratios.hasData {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(c.availability, prometheus.GaugeValue, s.ratios.availability, labels...)
+		ch <- prometheus.MustNewConstMetric(c.budgetRemaining, prometheus.GaugeValue, s.ratios.budgetRemaining, labels...)
+		for window, rate := range s.ratios.burnRates {
+			ch <- prometheus.MustNewConstMetric(c.burnRate, prometheus.GaugeValue, rate, append(labels, window)...)
+		}
+		if !s.ratios.exhaustion.IsZero() {
+			ch <- prometheus.MustNewConstMetric(c.exhaustion, prometheus.GaugeValue, float64(s.ratios.exhaustion.Unix()), labels...)
+		}
+	}
+}
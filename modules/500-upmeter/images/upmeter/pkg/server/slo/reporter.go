@@ -0,0 +1,94 @@
+/*
+Copyright 2024 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slo
+
+import (
+	"fmt"
+	"time"
+
+	"d8.io/upmeter/pkg/check"
+	dbcontext "d8.io/upmeter/pkg/db/context"
+	"d8.io/upmeter/pkg/db/dao"
+	"d8.io/upmeter/pkg/monitor/downtime"
+	"d8.io/upmeter/pkg/server/entity"
+	"d8.io/upmeter/pkg/server/ranges"
+)
+
+const groupProbe = dao.GroupAggregation
+
+// muteDowntimeTypes are the Downtime types excluded from the SLO, the same as the default ones in the status API
+var muteDowntimeTypes = map[string]bool{
+	"Maintenance":               true,
+	"InfrastructureMaintenance": true,
+	"InfrastructureAccident":    true,
+}
+
+// Reporter calculates SLO reports from the stored 5m episodes
+type Reporter struct {
+	DbCtx           *dbcontext.DbContext
+	DowntimeMonitor *downtime.Monitor
+}
+
+// Report returns the SLO report for the window ending at the last complete 5m slot before `at`
+func (r *Reporter) Report(objective Objective, at time.Time) (*Report, error) {
+	report, _, err := r.report(objective, at)
+	return report, err
+}
+
+func (r *Reporter) report(objective Objective, at time.Time) (*Report, ratios, error) {
+	to := at.Truncate(slotSize)
+	rng := ranges.New5MinStepRange(to.Add(-objective.Window).Unix(), to.Unix(), int64(slotSize.Seconds()))
+
+	incidents, err := r.incidents()
+	if err != nil {
+		return nil, ratios{}, err
+	}
+
+	daoCtx := r.DbCtx.Start()
+	defer daoCtx.Stop()
+
+	lister := &entity.SlotEpisodeLister{Dao: dao.NewEpisodeDao5m(daoCtx)}
+	statuses, err := entity.GetSummary(lister, objective.Ref, rng, incidents)
+	if err != nil {
+		return nil, ratios{}, err
+	}
+
+	// no data in the window for the group or probe is not an error, the report will be empty
+	var summaries []entity.EpisodeSummary
+	if probes, ok := statuses[objective.Ref.Group]; ok {
+		summaries = probes[objective.Ref.Probe]
+	}
+
+	report, ratios := calculate(objective, summaries, to)
+	return report, ratios, nil
+}
+
+func (r *Reporter) incidents() ([]check.DowntimeIncident, error) {
+	all, err := r.DowntimeMonitor.List()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get incidents: %v", err)
+	}
+
+	// time range and affected groups are checked when the summary is calculated
+	incidents := make([]check.DowntimeIncident, 0)
+	for _, incident := range all {
+		if muteDowntimeTypes[incident.Type] {
+			incidents = append(incidents, incident)
+		}
+	}
+	return incidents, nil
+}
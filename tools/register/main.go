@@ -167,7 +167,8 @@ func main() {
 		moduleDirs = append(moduleDirs, additionalModuleDirs...)
 	}
 
-	moduleDirs = append(moduleDirs, requirementCheckDirs(workDir, e)...)
+	moduleDirs = append(moduleDirs, moduleSubpackageDirs(workDir, e, "requirements")...)
+	moduleDirs = append(moduleDirs, moduleSubpackageDirs(workDir, e, "debug")...)
 
 	for _, dir := range moduleDirs {
 		if err := searchHooks(&hookModules, dir, workDir); err != nil {
@@ -189,10 +190,12 @@ func main() {
 	}
 }
 
-func requirementCheckDirs(workDir string, e editions) []string {
+// moduleSubpackageDirs returns directories of module packages registering themselves in init(),
+// e.g., requirements checks or debug collectors.
+func moduleSubpackageDirs(workDir string, e editions, subpackage string) []string {
 	moduleDirs := make([]string, 0)
 	for _, ed := range e.Editions {
-		additionalModuleDirs, err := filepath.Glob(filepath.Join(workDir, fmt.Sprintf("%s/*/requirements", ed.ModulesDir)))
+		additionalModuleDirs, err := filepath.Glob(filepath.Join(workDir, fmt.Sprintf("%s/*/%s", ed.ModulesDir, subpackage)))
 		if err != nil {
 			panic(err)
 		}